@@ -1,27 +1,45 @@
 // Package main serves as the entry point for the application.
-// It handles the initialization of dependencies and starts the HTTP server.
+// It handles the initialization of dependencies and starts the HTTP and gRPC servers.
 package main
 
 import (
 	_ "gobo/docs"
 	"gobo/internal/app"
+	"gobo/internal/audit"
 	"gobo/internal/cache"
 	"gobo/internal/db"
+	"gobo/internal/events"
+	"gobo/internal/gql"
+	"gobo/internal/jobs"
 	"gobo/internal/logger"
 	"gobo/internal/models"
+	"gobo/internal/rpc"
+	"gobo/internal/scheduler"
+	"gobo/internal/search"
+	"gobo/internal/sse"
+	"gobo/internal/tenant"
+	"gobo/internal/transfer"
+	"gobo/internal/webhooks"
+	"gobo/internal/ws"
+	"context"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/joho/godotenv"
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
 // Setup initializes the application's dependencies, including:
 // - Loading environment variables
-// - Connecting to the database (GORM)
-// - Running database migrations for all models
-// - Initializing Redis
 // - Setting up the logger
+// - Connecting to the database (GORM)
+// - Running database migrations for all models, and setting up the full-text search
+// - Initializing Redis and the background job queue
+// - Creating the webhook deliveries of published events
 // Returns an error if any step in the initialization fails.
 func Setup() error {
 	// Load the .env file only if it exists
@@ -36,44 +54,147 @@ func Setup() error {
 		log.Println("No .env file found, using existing environment variables.")
 	}
 
+	// Initialize the application logger before any other dependency,
+	// so that database and cache output is routed through it.
+	config := logger.DefaultConfig()
+	config.Environment = "development"        // Set logger environment to development
+	config.OutputPaths = []string{"stdout"}   // Log output to standard output
+	logger.InitLogger(config)
+
 	// Initialize the database connection using GORM
 	db.ConnectGORM()
 	log.Println("Database connection established with GORM.")
 
-	// Run database migrations for all models
-	err := AutoMigrateAllModels(db.GormDB)
+	// Scope the queries and inserts of tenant-owned models to the tenant of the request
+	rowLevelSecurity := os.Getenv("TENANT_ROW_LEVEL_SECURITY") == "true"
+	tenants := tenant.New(tenantModels()...)
+	if rowLevelSecurity {
+		tenants = tenants.WithRowLevelSecurity()
+	}
+	if err := db.GormDB.Use(tenants); err != nil {
+		return err
+	}
+
+	// Record every change of the audited models in the audit log
+	if err := db.GormDB.Use(audit.New(auditedModels()...)); err != nil {
+		return err
+	}
+
+	// Run database migrations for all models, bypassing the row-level security policies
+	migrations := db.GormDB.WithContext(tenant.Bypass(context.Background()))
+	err := AutoMigrateAllModels(migrations)
 	if err != nil {
 		// Return an error if migrations fail
 		return err
 	}
 	log.Println("Database migrations completed.")
 
+	// Search the examples with the full-text search of Postgres; the search endpoint is unavailable if the
+	// tsvector column or the pg_trgm extension cannot be set up
+	searcher := search.NewPostgres(db.GormDB, search.DefaultPostgresConfig())
+	if err := searcher.Migrate(); err != nil {
+		logger.Log.Error("Full-text search is not available", zap.Error(err))
+	} else {
+		search.Default = searcher
+	}
+
+	// Enforce tenant isolation in Postgres as well, if enabled
+	if rowLevelSecurity {
+		if err := tenant.EnableRowLevelSecurity(migrations, tenantModels()...); err != nil {
+			return err
+		}
+		log.Println("Row-level security enabled.")
+	}
+
 	// Initialize Redis connection
 	cache.Connect()
 	log.Println("Redis connected.")
 
-	// Initialize the application logger
-	config := logger.DefaultConfig()
-	config.Environment = "development"        // Set logger environment to development
-	config.OutputPaths = []string{"stdout"}   // Log output to standard output
-	logger.InitLogger(config)
+	// Create the background job queue; register job handlers with jobs.Register before the workers start
+	jobs.Default = jobs.New(jobs.DefaultConfig())
+
+	// Import the large files uploaded to POST /v2/examples/import in the background
+	transfer.Register(jobs.Default, transfer.DefaultConfig())
+
+	// Create the webhook deliveries of the events, in the transaction of the change
+	events.AddHook(webhooks.Hook)
+
+	// Create the broker pushing the delivered events to the event streams (Server-Sent Events)
+	sse.Default = sse.New(sse.DefaultConfig())
+
+	// Create the hub of the WebSocket connections, fanning their messages out to all instances
+	ws.Default = ws.New(ws.DefaultConfig())
+
+	// Create the executor of the GraphQL API
+	gql.Default = gql.New(gql.DefaultConfig())
 
 	// Log a message indicating that setup was successful
 	logger.Log.Info("Setup completed successfully.")
 	return nil
 }
 
+// allModels lists all models of the application.
+// They are migrated at startup, and their soft-deleted records are purged after the retention period.
+func allModels() []interface{} {
+	return []interface{}{
+		&models.Example{},
+		&models.User{}, // Add other models here as needed
+	}
+}
+
+// auditedModels lists the models whose changes are recorded in the audit log.
+func auditedModels() []interface{} {
+	return []interface{}{
+		&models.Example{},
+		&models.User{},
+	}
+}
+
+// tenantModels lists the models whose records are owned by a tenant, including the audit log.
+func tenantModels() []interface{} {
+	return []interface{}{
+		&models.Example{},
+		&models.User{},
+		&audit.Entry{},
+		&events.Message{},
+		&webhooks.Subscription{},
+		&webhooks.Delivery{},
+		&transfer.Import{},
+	}
+}
+
+// registerTasks registers the periodic tasks of the application with the scheduler.
+func registerTasks(s *scheduler.Scheduler) error {
+	// Permanently delete records that were soft deleted longer ago than the retention period.
+	purge := models.DefaultPurgeConfig()
+	return s.Add(scheduler.Task{
+		Name:     "purge.soft_deleted",
+		Schedule: scheduler.Every(purge.Interval),
+		Run: func(ctx context.Context) error {
+			// Permanent deletes are recorded in the audit log with the purge job as the actor. The soft-deleted
+			// records of every tenant are purged.
+			ctx = audit.NewContext(tenant.Bypass(ctx), audit.Actor{User: models.PurgeActor})
+			_, err := models.PurgeSoftDeleted(db.GormDB.WithContext(ctx), time.Now().Add(-purge.Retention), allModels()...)
+			return err
+		},
+	})
+}
+
 // AutoMigrateAllModels migrates all the models automatically using GORM.
 // It accepts the GORM DB connection as a parameter and migrates each model in the list.
 func AutoMigrateAllModels(db *gorm.DB) error {
-	// List all models you want to migrate
-	models := []interface{}{
-		&models.Example{},
-		&models.User{},  // Add other models here as needed
+	// Loop through all models, the audit log, the outbox, the run history of the scheduler,
+	// the webhook tables and the imports, and run AutoMigrate
+	system := []interface{}{
+		&audit.Entry{},
+		&events.Message{},
+		&scheduler.Run{},
+		&webhooks.Subscription{},
+		&webhooks.Delivery{},
+		&webhooks.Attempt{},
+		&transfer.Import{},
 	}
-
-	// Loop through all models and run AutoMigrate
-	for _, model := range models {
+	for _, model := range append(allModels(), system...) {
 		if err := db.AutoMigrate(model); err != nil {
 			return err
 		}
@@ -82,20 +203,128 @@ func AutoMigrateAllModels(db *gorm.DB) error {
 }
 
 // main is the entry point for the application.
-// It performs setup, starts the HTTP server, and handles fatal errors.
+// It performs setup, starts the HTTP and gRPC servers, and handles fatal errors.
+//
+// @title                      GoBo - Go Fiber Boilerplate
+// @version                    0.2
+// @description                A boilerplate application for building web services using Go and Fiber.
+// @termsOfService             http://swagger.io/terms/
+// @contact.name               Barathrum54
+// @contact.url                linkedin.com/in/barathrum54
+// @contact.email              tahabdurmus0@gmail.com
+// @license.name               Apache 2.0
+// @license.url                http://www.apache.org/licenses/LICENSE-2.0.html
+// @host                       localhost:3000
+// @BasePath                   /
+// @securityDefinitions.basic  BasicAuth
 func main() {
 	// Run the setup process and handle any errors
 	if err := Setup(); err != nil {
 		log.Fatalf("Application setup failed: %v", err)
 	}
+	// Flush buffered logs and pending Sentry events on exit.
+	defer logger.Sync()
+
+	// Allow switching to DEBUG logging with SIGUSR1 (reverted after 15 minutes) and back with SIGUSR2.
+	stopSignals := logger.HandleSignals(15 * time.Minute)
+	defer stopSignals()
+
+	// Run the periodic tasks, each run once across all instances.
+	tasks := scheduler.New(db.GormDB, scheduler.DefaultConfig())
+	if err := registerTasks(tasks); err != nil {
+		log.Fatalf("Failed to register the scheduled tasks: %v", err)
+	}
+	stopScheduler := tasks.Start()
+	defer stopScheduler()
+
+	// Push the events published by all instances to the event streams of the connected clients.
+	stopStreams := sse.Default.Start()
+
+	// Send the messages published on the WebSocket channels by all instances to the connected clients.
+	stopWebsockets := ws.Default.Start()
+
+	// Deliver the domain events stored in the outbox to Redis Streams, and push them to the event streams.
+	relay := events.DefaultRelayConfig()
+	relay.Delivered = publishToStreams
+	stopRelay := events.StartRelay(db.GormDB, relay)
+	defer stopRelay()
+
+	// Send the webhook deliveries to the subscribed endpoints, with retries.
+	stopWebhooks := webhooks.StartDispatcher(db.GormDB, webhooks.DefaultConfig())
+	defer stopWebhooks()
+
+	// Process background jobs until shutdown.
+	stopWorkers := jobs.Default.Start()
 
 	// Initialize and start the Fiber HTTP server
 	application := app.NewApp()
 
 	// Log the server startup message and listen for incoming requests
 	log.Println("Server is running on http://localhost:3000")
-	if err := application.Listen(":3000"); err != nil {
-		// Log and terminate the application if the server fails to start
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- application.Listen(":3000")
+	}()
+
+	// Serve the gRPC API alongside, on its own port, with the same service layer as the HTTP handlers.
+	grpcServer := rpc.New(rpc.DefaultConfig())
+	log.Printf("gRPC server is running on %s", grpcServer.Address())
+	go func() {
+		serverErr <- grpcServer.ListenAndServe()
+	}()
+
+	// Shut down gracefully on SIGINT or SIGTERM: stop accepting requests, let running requests
+	// and jobs finish within the shutdown timeout, then stop the background jobs.
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	select {
+	case err := <-serverErr:
+		// Log and terminate the application if the HTTP or gRPC server fails to start
 		log.Fatalf("Error starting server: %v", err)
+	case <-quit:
+	}
+	logger.Log.Info("Shutting down.")
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout())
+	defer cancel()
+	// The servers stop listening first, then wait for the open connections; the event streams and WebSocket
+	// connections are ended meanwhile, as they would stay open until the timeout. Clients reconnect to another instance.
+	shutdown := make(chan error, 2)
+	go func() {
+		shutdown <- application.ShutdownWithContext(ctx)
+	}()
+	go func() {
+		shutdown <- grpcServer.Shutdown(ctx)
+	}()
+	stopStreams()
+	stopWebsockets()
+	for i := 0; i < 2; i++ {
+		if err := <-shutdown; err != nil {
+			logger.Log.Error("Failed to shut down the server gracefully", zap.Error(err))
+		}
+	}
+	if err := stopWorkers(ctx); err != nil {
+		logger.Log.Warn("Running jobs were canceled at shutdown and will be retried", zap.Error(err))
+	}
+}
+
+// publishToStreams pushes a delivered event to the clients streaming the changes of its aggregate type
+// (e.g., GET /v2/examples/stream for the "example" events).
+func publishToStreams(ctx context.Context, message events.Message) {
+	event := sse.Event{Type: message.Type, Tenant: message.TenantID, Data: message.Payload}
+	if _, err := sse.Default.Publish(ctx, message.AggregateType, event); err != nil && ctx.Err() == nil {
+		logger.FromContext(ctx).Named("sse").Warn("Failed to publish an event to the streams",
+			zap.String("eventId", message.EventID),
+			zap.Error(err),
+		)
+	}
+}
+
+// shutdownTimeout returns how long running requests and jobs may take to finish at shutdown:
+// 30 seconds, or the SHUTDOWN_TIMEOUT environment variable (e.g., "1m").
+func shutdownTimeout() time.Duration {
+	if timeout, err := time.ParseDuration(os.Getenv("SHUTDOWN_TIMEOUT")); err == nil && timeout > 0 {
+		return timeout
 	}
+	return 30 * time.Second
 }
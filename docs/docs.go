@@ -47,122 +47,2945 @@ const docTemplate = `{
                 }
             }
         },
-        "/examples": {
+        "/admin/audit": {
             "get": {
-                "description": "Retrieves all examples from the database.",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
                 ],
+                "description": "Returns who created, updated, deleted or restored records, with the changed columns before and after each change.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "examples"
+                    "admin"
+                ],
+                "summary": "Query Audit Log",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Table of the changed records (e.g., examples)",
+                        "name": "entity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Primary key of the changed record",
+                        "name": "id",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "create",
+                            "update",
+                            "delete",
+                            "restore"
+                        ],
+                        "type": "string",
+                        "description": "Action",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Username of the actor",
+                        "name": "actor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Request ID",
+                        "name": "request_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Earliest change (RFC 3339, inclusive)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Latest change (RFC 3339, exclusive)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Entries per page",
+                        "name": "page_size",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Get All Examples",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Example"
-                            }
+                            "$ref": "#/definitions/routes.AuditLogListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/routes.ErrorResponse"
+                            "$ref": "#/definitions/apperror.Problem"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/admin/examples/{id}/restore": {
             "post": {
-                "description": "Creates a new example in the database.",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
                 ],
+                "description": "Restores a soft-deleted example that has not been purged yet.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "examples"
+                    "admin"
                 ],
-                "summary": "Create Example",
+                "summary": "Restore Example",
                 "parameters": [
                     {
-                        "description": "Example Request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/routes.CreateExampleRequest"
-                        }
+                        "type": "integer",
+                        "description": "Example ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/routes.CreateExampleResponse"
+                            "$ref": "#/definitions/routes.ExampleResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/routes.ErrorResponse"
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "The example is not deleted",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/routes.ErrorResponse"
+                            "$ref": "#/definitions/apperror.Problem"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "models.Example": {
-            "type": "object",
-            "properties": {
-                "id": {
-                    "description": "Primary key for the record.",
-                    "type": "integer"
-                },
-                "name": {
-                    "description": "Name field, required with a max length of 100 characters.",
-                    "type": "string"
+        },
+        "/admin/grpc/metrics": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Returns the number of calls of each gRPC method by status code, and their average and longest durations.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get gRPC Metrics",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/routes.GRPCMetricsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
                 }
             }
         },
-        "routes.CreateExampleRequest": {
-            "type": "object",
-            "properties": {
-                "name": {
-                    "description": "The name of the example to be created.",
-                    "type": "string"
+        "/admin/jobs": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Returns the number of queued, scheduled, active and dead background jobs.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get Job Counts",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/routes.JobStatsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
                 }
             }
         },
-        "routes.CreateExampleResponse": {
-            "type": "object",
-            "properties": {
-                "id": {
-                    "type": "integer"
-                },
-                "message": {
-                    "type": "string"
+        "/admin/jobs/dead": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Returns the background jobs that failed their last attempt, most recently failed first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List Dead Jobs",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Jobs per page",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/routes.JobListResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
                 }
             }
         },
-        "routes.ErrorResponse": {
-            "type": "object",
-            "properties": {
-                "error": {
-                    "type": "string"
+        "/admin/jobs/dead/{id}/retry": {
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Moves a job of the dead-letter queue back to the queue, with its attempts reset.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Retry Dead Job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/routes.JobResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/jobs/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Returns a background job and its state. Completed jobs are not kept.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get Job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/routes.JobResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/log-level": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Returns the global log level and the per-logger overrides.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get Log Levels",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/routes.LogLevelResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Changes the log level globally or for a named logger. If a duration is given, the change is reverted automatically.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Set Log Level",
+                "parameters": [
+                    {
+                        "description": "Log Level Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/routes.LogLevelRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/routes.LogLevelResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Removes the override of a named logger, or restores the configured global level if no logger is given.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Reset Log Level",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Named logger to reset",
+                        "name": "logger",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/routes.LogLevelResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/graphql": {
+            "get": {
+                "description": "Serves the GraphiQL playground, sending its queries to POST /graphql. Only available when APP_ENV is \"development\".",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "graphql"
+                ],
+                "summary": "GraphQL Playground",
+                "responses": {
+                    "200": {
+                        "description": "GraphiQL page",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Runs a GraphQL query or mutation over the examples and users of the tenant. Queries list them with pagination (\"page\", \"pageSize\") and filters, or read them by ID with their change history; mutations create, update and delete them. Anonymous requests may only read the examples that are not deleted: the users, the history, the deleted examples and the mutations require the Basic credentials. Errors are returned in \"errors\" with a status of 200, with their machine-readable code in the extensions. Queries nested more than 10 levels deep or with a complexity above 1000 (each field counts 1; the fields of lists count once per requested item) are rejected before they run.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "graphql"
+                ],
+                "summary": "GraphQL API",
+                "parameters": [
+                    {
+                        "description": "Query, operation name and variables",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/routes.GraphQLRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/routes.GraphQLResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/examples": {
+            "get": {
+                "description": "Retrieves all examples from the database.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "examples"
+                ],
+                "summary": "Get All Examples",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Include soft-deleted examples (requires admin credentials)",
+                        "name": "include_deleted",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Example"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Creates a new example in the database.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "examples"
+                ],
+                "summary": "Create Example",
+                "parameters": [
+                    {
+                        "description": "Example Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/routes.CreateExampleRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key making the request safe to retry",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/routes.CreateExampleResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Duplicate name or request with the same Idempotency-Key in progress",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid fields or Idempotency-Key reused with a different payload",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/examples": {
+            "get": {
+                "description": "Retrieves all examples from the database.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "examples"
+                ],
+                "summary": "Get All Examples",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Include soft-deleted examples (requires admin credentials)",
+                        "name": "include_deleted",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/routes.ExampleListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Creates a new example in the database and returns it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "examples"
+                ],
+                "summary": "Create Example",
+                "parameters": [
+                    {
+                        "description": "Example Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/routes.CreateExampleRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key making the request safe to retry",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/routes.ExampleResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Duplicate name or request with the same Idempotency-Key in progress",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid fields or Idempotency-Key reused with a different payload",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/examples/bulk": {
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Applies a list of operations on examples: {\"action\": \"create\", \"name\": \"...\"}, {\"action\": \"update\", \"id\": 1, \"name\": \"...\", \"version\": 2} or {\"action\": \"delete\", \"id\": 1}. The created examples are inserted in batches before the updates and deletes are applied in order. In the \"all_or_nothing\" mode (default), nothing is applied if an operation is invalid or fails: the other operations fail with 424. In the \"best_effort\" mode, the valid operations are applied even if others fail. The response is 207 Multi-Status, with the status and the example or the error of each operation. Requests may contain at most 1000 operations and 1 MiB.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "examples"
+                ],
+                "summary": "Bulk Create, Update and Delete Examples",
+                "parameters": [
+                    {
+                        "description": "Mode and operations",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/routes.BulkExamplesRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key making the request safe to retry",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "207": {
+                        "description": "Multi-Status",
+                        "schema": {
+                            "$ref": "#/definitions/routes.BulkExamplesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "Too many operations or request body too large",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/examples/export": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Streams the examples as a CSV file with a header row (id, name, version, created_at, updated_at, deleted_at), NDJSON (one example per line) or an XLSX workbook. The examples are read from the database one page at a time, so that exports of any size use constant memory.",
+                "produces": [
+                    "text/csv",
+                    "application/x-ndjson",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+                ],
+                "tags": [
+                    "examples"
+                ],
+                "summary": "Export Examples",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "ndjson",
+                            "xlsx"
+                        ],
+                        "type": "string",
+                        "default": "csv",
+                        "description": "File format",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Case-insensitive substring of the name",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include soft-deleted examples",
+                        "name": "include_deleted",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/examples/import": {
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Creates an example for each row of a CSV file (with a header row naming a \"name\" column) or an NDJSON file (one object with a \"name\" member per line), sent in the \"file\" field. Other columns, such as the id and version of exported files, are ignored. Each row is validated: valid rows are created even if others are rejected, and the errors of the rejected rows are reported with their line. Small files are imported in the request (200). Larger files are imported by a background job (202): poll the import at the Location header.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "examples"
+                ],
+                "summary": "Import Examples",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "CSV or NDJSON file",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "csv",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "File format; defaults to the extension of the file (.csv, .ndjson or .jsonl)",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Imported in the request",
+                        "schema": {
+                            "$ref": "#/definitions/routes.ImportResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Import started",
+                        "schema": {
+                            "$ref": "#/definitions/routes.ImportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unsupported format or missing name column",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "The job queue is unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/examples/imports/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Returns the status of an import run by a background job and, once it is completed, the number of created examples and the errors of the rejected rows.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "examples"
+                ],
+                "summary": "Get Import",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Import ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/routes.ImportResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/examples/search": {
+            "get": {
+                "description": "Searches the examples by name, best matches first. All the words must match, the last one as a prefix (\"red ca\" matches \"Red Car\"), and names similar to the text also match (\"alpah\" matches \"Alpha\"). Each result has a rank and a snippet of its name, HTML-escaped, with the matching words in \u003cmark\u003e elements.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "examples"
+                ],
+                "summary": "Search Examples",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Searched words",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Examples per page (at most 100)",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/routes.SearchExamplesResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Search is not available",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/examples/stream": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Streams the created, updated, deleted and restored examples of the tenant as Server-Sent Events (e.g., with EventSource). Each event has an ID, a type (e.g., \"example.created\") and the example as data. Reconnecting clients send the Last-Event-ID header to receive the events they missed; if they are no longer in the history, a \"reset\" event is sent and the examples should be reloaded. A comment is sent as a heartbeat every 15 seconds.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "examples"
+                ],
+                "summary": "Stream Example Changes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the last event received, to resume the stream",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Event stream",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/examples/{id}": {
+            "get": {
+                "description": "Retrieves an example. The ETag header contains its version, for use with If-Match when updating it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "examples"
+                ],
+                "summary": "Get Example",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Example ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/routes.ExampleResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the example"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Updates an example if its version matches the If-Match header (or the \"version\" field). On a version mismatch, the problem details contain the current example in the \"current\" member.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "examples"
+                ],
+                "summary": "Update Example",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Example ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the version being updated, as returned by Get Example",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Update Example Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/routes.UpdateExampleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/routes.ExampleResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New version of the example"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "The example was modified by another request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "Neither If-Match nor version was sent",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Soft deletes an example. Deleted examples are hidden from the list, can be restored by an administrator and are purged after the retention period.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "examples"
+                ],
+                "summary": "Delete Example",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Example ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Lists the webhook subscriptions of the tenant, without their secrets.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "List Webhooks",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/routes.WebhookListResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Subscribes an endpoint to events of the tenant. Events are sent as POST requests signed with the returned secret: the X-Webhook-Signature header contains \"sha256=\" followed by the hex HMAC-SHA256 of \"\u003cX-Webhook-Timestamp\u003e.\u003cbody\u003e\". The secret is only returned by this endpoint.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Create Webhook",
+                "parameters": [
+                    {
+                        "description": "Create Webhook Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/routes.CreateWebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/routes.WebhookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/webhooks/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Returns a webhook subscription, without its secret.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Get Webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/routes.WebhookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Updates the endpoint and events of a webhook subscription, and disables or enables it. Enabling a subscription disabled after repeated failures resets its failures.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Update Webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update Webhook Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/routes.UpdateWebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/routes.WebhookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Deletes a webhook subscription. Its pending deliveries are not sent.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Delete Webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/webhooks/{id}/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Lists the events sent, or to be sent, to a webhook subscription, newest first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "List Webhook Deliveries",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "succeeded",
+                            "failed"
+                        ],
+                        "type": "string",
+                        "description": "Only the deliveries in this state",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Deliveries per page",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/routes.WebhookDeliveryListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/webhooks/{id}/deliveries/{delivery_id}": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Returns a delivery of a webhook subscription, with the requests sent and the responses received.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Get Webhook Delivery",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Delivery ID",
+                        "name": "delivery_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/routes.WebhookDeliveryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/webhooks/{id}/deliveries/{delivery_id}/replay": {
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Sends the event of a delivery again, e.g. after the endpoint was fixed. The replay is a new delivery with the same event ID, sent in the background.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Replay Webhook Delivery",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Delivery ID",
+                        "name": "delivery_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/routes.WebhookDeliveryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "The subscription is disabled",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/ws": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Upgrades the request to a WebSocket connection exchanging JSON messages on the channels of the tenant. Clients send {\"type\":\"subscribe\"|\"unsubscribe\"|\"publish\"|\"presence\"|\"ping\",\"channel\":\"chat\",\"data\":{...},\"id\":\"1\"}; the server answers with \"ack\", \"error\" or \"pong\" (echoing the id), and sends \"message\", \"join\", \"leave\" and \"presence\" messages of the subscribed channels. Messages reach the subscribers connected to any instance. Each connection may send 10 messages per second (bursts of 20); further messages are dropped with a \"rate_limited\" error.",
+                "tags": [
+                    "websocket"
+                ],
+                "summary": "WebSocket Messaging",
+                "responses": {
+                    "101": {
+                        "description": "Switching Protocols",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "426": {
+                        "description": "Upgrade Required",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "apperror.FieldError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Machine-readable reason (e.g., \"required\", \"max\").",
+                    "type": "string"
+                },
+                "field": {
+                    "description": "The field name as sent by the client (e.g., \"name\").",
+                    "type": "string"
+                },
+                "message": {
+                    "description": "Human-readable description in the default language.",
+                    "type": "string"
+                }
+            }
+        },
+        "apperror.Problem": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Machine-readable error code (e.g., \"not_found\").",
+                    "type": "string"
+                },
+                "detail": {
+                    "description": "Explanation specific to this occurrence.",
+                    "type": "string"
+                },
+                "errors": {
+                    "description": "Field-level problems (validation errors).",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/apperror.FieldError"
+                    }
+                },
+                "instance": {
+                    "description": "The request path where the problem occurred.",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "HTTP status code.",
+                    "type": "integer"
+                },
+                "title": {
+                    "description": "Short summary of the problem type (the HTTP status text).",
+                    "type": "string"
+                },
+                "type": {
+                    "description": "URI identifying the problem type (\"about:blank\" for plain HTTP errors).",
+                    "type": "string"
+                }
+            }
+        },
+        "models.Example": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "description": "Creation time, set by GORM.",
+                    "type": "string"
+                },
+                "deletedAt": {
+                    "description": "Soft delete time; indexed because every query filters on it.",
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "id": {
+                    "description": "Primary key for the record.",
+                    "type": "integer"
+                },
+                "name": {
+                    "description": "Name field, required with a max length of 100 characters.",
+                    "type": "string"
+                },
+                "tenantID": {
+                    "description": "Tenant owning the record, set by the tenant plugin.",
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "description": "Last update time, set by GORM.",
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Version of the record, starting at 1.",
+                    "type": "integer"
+                }
+            }
+        },
+        "routes.AuditLogListResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "description": "The entries, newest first.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/routes.AuditLogResponse"
+                    }
+                },
+                "page": {
+                    "description": "The page number.",
+                    "type": "integer"
+                },
+                "page_size": {
+                    "description": "Entries per page.",
+                    "type": "integer"
+                },
+                "total": {
+                    "description": "Number of matching entries.",
+                    "type": "integer"
+                }
+            }
+        },
+        "routes.AuditLogResponse": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "description": "create, update, delete or restore.",
+                    "type": "string"
+                },
+                "actor": {
+                    "description": "Username of the actor; empty for anonymous requests.",
+                    "type": "string"
+                },
+                "after": {
+                    "description": "Changed columns after the change.",
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "before": {
+                    "description": "Changed columns before the change.",
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "created_at": {
+                    "description": "Time of the change.",
+                    "type": "string"
+                },
+                "entity": {
+                    "description": "Table of the changed record.",
+                    "type": "string"
+                },
+                "entity_id": {
+                    "description": "Primary key of the changed record.",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "The ID of the entry.",
+                    "type": "integer"
+                },
+                "ip": {
+                    "description": "Client IP address.",
+                    "type": "string"
+                },
+                "request_id": {
+                    "description": "Request ID.",
+                    "type": "string"
+                }
+            }
+        },
+        "routes.BulkExampleResult": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "description": "The action of the operation.",
+                    "type": "string"
+                },
+                "error": {
+                    "description": "Why the operation failed; 424 if it was rolled back because another one failed.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/apperror.Problem"
+                        }
+                    ]
+                },
+                "example": {
+                    "description": "The created or updated example.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/routes.ExampleResponse"
+                        }
+                    ]
+                },
+                "index": {
+                    "description": "The index of the operation in the request.",
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "201 (created), 200 (updated), 204 (deleted), or the status of the error.",
+                    "type": "integer"
+                }
+            }
+        },
+        "routes.BulkExamplesRequest": {
+            "type": "object",
+            "required": [
+                "operations"
+            ],
+            "properties": {
+                "mode": {
+                    "description": "all_or_nothing (default) or best_effort.",
+                    "type": "string",
+                    "enum": [
+                        "all_or_nothing",
+                        "best_effort"
+                    ]
+                },
+                "operations": {
+                    "description": "The operations, validated one by one.",
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/routes.BulkOperationRequest"
+                    }
+                }
+            }
+        },
+        "routes.BulkExamplesResponse": {
+            "type": "object",
+            "properties": {
+                "failed": {
+                    "description": "The number of operations that were not applied.",
+                    "type": "integer"
+                },
+                "mode": {
+                    "description": "The mode of the request.",
+                    "type": "string"
+                },
+                "results": {
+                    "description": "The outcome of each operation, in the order of the request.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/routes.BulkExampleResult"
+                    }
+                },
+                "succeeded": {
+                    "description": "The number of applied operations.",
+                    "type": "integer"
+                }
+            }
+        },
+        "routes.BulkOperationRequest": {
+            "type": "object",
+            "required": [
+                "action"
+            ],
+            "properties": {
+                "action": {
+                    "description": "create, update or delete.",
+                    "type": "string",
+                    "enum": [
+                        "create",
+                        "update",
+                        "delete"
+                    ]
+                },
+                "id": {
+                    "description": "The ID of the updated or deleted example.",
+                    "type": "integer"
+                },
+                "name": {
+                    "description": "The name of the created or updated example.",
+                    "type": "string"
+                },
+                "version": {
+                    "description": "The version being updated.",
+                    "type": "integer"
+                }
+            }
+        },
+        "routes.CreateExampleRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "description": "The name of the example to be created.",
+                    "type": "string",
+                    "maxLength": 100
+                }
+            }
+        },
+        "routes.CreateExampleResponse": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "routes.CreateWebhookRequest": {
+            "type": "object",
+            "required": [
+                "events",
+                "url"
+            ],
+            "properties": {
+                "description": {
+                    "description": "Optional free text.",
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "events": {
+                    "description": "The event types sent to the endpoint, or \"*\" for all.",
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "url": {
+                    "description": "The endpoint receiving the events (POST), over HTTP or HTTPS.",
+                    "type": "string",
+                    "maxLength": 2048
+                }
+            }
+        },
+        "routes.ExampleListResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "description": "The examples.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/routes.ExampleResponse"
+                    }
+                }
+            }
+        },
+        "routes.ExampleResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "When the example was created.",
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "description": "When the example was soft deleted; omitted if it is not deleted.",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "The ID of the example.",
+                    "type": "integer"
+                },
+                "name": {
+                    "description": "The name of the example.",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "description": "When the example was last updated.",
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Version of the example, incremented on every update (also sent as ETag).",
+                    "type": "integer"
+                }
+            }
+        },
+        "routes.GRPCMetricsResponse": {
+            "type": "object",
+            "properties": {
+                "methods": {
+                    "description": "Metrics of each called method, by method name.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/rpc.MethodMetrics"
+                    }
+                }
+            }
+        },
+        "routes.GraphQLError": {
+            "type": "object",
+            "properties": {
+                "extensions": {
+                    "description": "The machine-readable \"code\", the HTTP \"status\" and the invalid \"fields\".",
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "message": {
+                    "description": "The localized message.",
+                    "type": "string"
+                },
+                "path": {
+                    "description": "The path of the field that failed (e.g., [\"example\", \"history\"]).",
+                    "type": "array",
+                    "items": {}
+                }
+            }
+        },
+        "routes.GraphQLRequest": {
+            "type": "object",
+            "required": [
+                "query"
+            ],
+            "properties": {
+                "operationName": {
+                    "description": "The operation to run, if the document has several.",
+                    "type": "string"
+                },
+                "query": {
+                    "description": "The GraphQL document.",
+                    "type": "string"
+                },
+                "variables": {
+                    "description": "The values of the variables of the operation.",
+                    "type": "object",
+                    "additionalProperties": true
+                }
+            }
+        },
+        "routes.GraphQLResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "description": "The selected fields; null if the request was rejected."
+                },
+                "errors": {
+                    "description": "The errors of the request or of its fields.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/routes.GraphQLError"
+                    }
+                }
+            }
+        },
+        "routes.ImportResponse": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "description": "The created examples.",
+                    "type": "integer"
+                },
+                "created_at": {
+                    "description": "Upload time.",
+                    "type": "string"
+                },
+                "error": {
+                    "description": "Why the import failed.",
+                    "type": "string"
+                },
+                "errors": {
+                    "description": "The errors of the rejected rows, by line.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/transfer.RowError"
+                    }
+                },
+                "failed": {
+                    "description": "The rejected rows.",
+                    "type": "integer"
+                },
+                "finished_at": {
+                    "description": "Time the import completed or failed.",
+                    "type": "string"
+                },
+                "format": {
+                    "description": "csv or ndjson.",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "The ID of the import; omitted for files imported in the request.",
+                    "type": "integer"
+                },
+                "rows": {
+                    "description": "The rows of the file.",
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "pending, running, completed or failed.",
+                    "type": "string"
+                }
+            }
+        },
+        "routes.JobListResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "description": "The jobs, most recently failed first.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/routes.JobResponse"
+                    }
+                },
+                "page": {
+                    "description": "The page number.",
+                    "type": "integer"
+                },
+                "page_size": {
+                    "description": "Jobs per page.",
+                    "type": "integer"
+                },
+                "total": {
+                    "description": "Number of dead jobs.",
+                    "type": "integer"
+                }
+            }
+        },
+        "routes.JobResponse": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "description": "Failed attempts so far.",
+                    "type": "integer"
+                },
+                "enqueued_at": {
+                    "description": "Enqueue time.",
+                    "type": "string"
+                },
+                "failed_at": {
+                    "description": "Time the job died.",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "The ID of the job.",
+                    "type": "string"
+                },
+                "last_error": {
+                    "description": "Error of the last failed attempt.",
+                    "type": "string"
+                },
+                "max_attempts": {
+                    "description": "Attempts before the job is dead.",
+                    "type": "integer"
+                },
+                "payload": {
+                    "description": "Arguments of the handler.",
+                    "type": "object"
+                },
+                "priority": {
+                    "description": "Higher priorities run first.",
+                    "type": "integer"
+                },
+                "run_at": {
+                    "description": "Earliest time of the next attempt.",
+                    "type": "string"
+                },
+                "state": {
+                    "description": "queued, scheduled, active or dead.",
+                    "type": "string"
+                },
+                "tenant": {
+                    "description": "Tenant that enqueued the job.",
+                    "type": "string"
+                },
+                "type": {
+                    "description": "The job type.",
+                    "type": "string"
+                },
+                "unique_key": {
+                    "description": "Key preventing duplicates while the job is pending.",
+                    "type": "string"
+                }
+            }
+        },
+        "routes.JobStatsResponse": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "description": "Jobs being processed.",
+                    "type": "integer"
+                },
+                "dead": {
+                    "description": "Jobs in the dead-letter queue.",
+                    "type": "integer"
+                },
+                "queued": {
+                    "description": "Jobs ready to run.",
+                    "type": "integer"
+                },
+                "scheduled": {
+                    "description": "Delayed jobs and jobs waiting for a retry.",
+                    "type": "integer"
+                }
+            }
+        },
+        "routes.LogLevelRequest": {
+            "type": "object",
+            "required": [
+                "level"
+            ],
+            "properties": {
+                "duration": {
+                    "description": "Optional duration after which the change is reverted (e.g., \"15m\").",
+                    "type": "string"
+                },
+                "level": {
+                    "description": "The new log level (debug, info, warn, error).",
+                    "type": "string"
+                },
+                "logger": {
+                    "description": "Optional named logger (e.g., \"gorm\"); empty changes the global level.",
+                    "type": "string"
+                }
+            }
+        },
+        "routes.LogLevelResponse": {
+            "type": "object",
+            "properties": {
+                "global": {
+                    "description": "The global log level.",
+                    "type": "string"
+                },
+                "loggers": {
+                    "description": "Per-logger level overrides.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "routes.SearchExamplesResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "description": "The matching examples, best matches first.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/routes.SearchHitResponse"
+                    }
+                },
+                "page": {
+                    "description": "The page number.",
+                    "type": "integer"
+                },
+                "page_size": {
+                    "description": "Examples per page.",
+                    "type": "integer"
+                },
+                "total": {
+                    "description": "Number of matching examples.",
+                    "type": "integer"
+                }
+            }
+        },
+        "routes.SearchHitResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "When the example was created.",
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "description": "When the example was soft deleted; omitted if it is not deleted.",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "The ID of the example.",
+                    "type": "integer"
+                },
+                "name": {
+                    "description": "The name of the example.",
+                    "type": "string"
+                },
+                "rank": {
+                    "description": "Relevance of the example; higher ranks match better.",
+                    "type": "number"
+                },
+                "snippet": {
+                    "description": "The name, HTML-escaped, with the matching words in \u003cmark\u003e elements.",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "description": "When the example was last updated.",
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Version of the example, incremented on every update (also sent as ETag).",
+                    "type": "integer"
+                }
+            }
+        },
+        "routes.UpdateExampleRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "description": "The new name of the example.",
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "version": {
+                    "description": "The version being updated, if the If-Match header is not sent.",
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
+        "routes.UpdateWebhookRequest": {
+            "type": "object",
+            "required": [
+                "events",
+                "url"
+            ],
+            "properties": {
+                "active": {
+                    "description": "Disable the subscription, or enable it again after it was disabled (resets its failures).",
+                    "type": "boolean"
+                },
+                "description": {
+                    "description": "Optional free text.",
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "events": {
+                    "description": "The event types sent to the endpoint, or \"*\" for all.",
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "url": {
+                    "description": "The endpoint receiving the events (POST), over HTTP or HTTPS.",
+                    "type": "string",
+                    "maxLength": 2048
+                }
+            }
+        },
+        "routes.WebhookAttemptResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "Time of the request.",
+                    "type": "string"
+                },
+                "duration_ms": {
+                    "description": "Duration of the request, in milliseconds.",
+                    "type": "integer"
+                },
+                "error": {
+                    "description": "Why the attempt failed.",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "The ID of the attempt.",
+                    "type": "integer"
+                },
+                "response": {
+                    "description": "Beginning of the response body.",
+                    "type": "string"
+                },
+                "status_code": {
+                    "description": "Response status; 0 if no response was received.",
+                    "type": "integer"
+                }
+            }
+        },
+        "routes.WebhookDeliveryListResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "description": "The deliveries, newest first.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/routes.WebhookDeliveryResponse"
+                    }
+                },
+                "page": {
+                    "description": "The page number.",
+                    "type": "integer"
+                },
+                "page_size": {
+                    "description": "Deliveries per page.",
+                    "type": "integer"
+                },
+                "total": {
+                    "description": "Number of matching deliveries.",
+                    "type": "integer"
+                }
+            }
+        },
+        "routes.WebhookDeliveryResponse": {
+            "type": "object",
+            "properties": {
+                "attempt_log": {
+                    "description": "The requests sent, oldest first (single delivery only).",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/routes.WebhookAttemptResponse"
+                    }
+                },
+                "attempts": {
+                    "description": "Attempts so far.",
+                    "type": "integer"
+                },
+                "created_at": {
+                    "description": "When the delivery was created.",
+                    "type": "string"
+                },
+                "delivered_at": {
+                    "description": "When the endpoint accepted the event.",
+                    "type": "string"
+                },
+                "event_id": {
+                    "description": "The ID of the event, identical for replays.",
+                    "type": "string"
+                },
+                "event_type": {
+                    "description": "The event type.",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "The ID of the delivery.",
+                    "type": "integer"
+                },
+                "last_error": {
+                    "description": "Error of the last failed attempt.",
+                    "type": "string"
+                },
+                "next_attempt_at": {
+                    "description": "Earliest time of the next attempt of a pending delivery.",
+                    "type": "string"
+                },
+                "payload": {
+                    "description": "The request body.",
+                    "type": "object"
+                },
+                "replay_of": {
+                    "description": "The delivery this one replays.",
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "pending, succeeded or failed.",
+                    "type": "string"
+                }
+            }
+        },
+        "routes.WebhookListResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "description": "The subscriptions.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/routes.WebhookResponse"
+                    }
+                }
+            }
+        },
+        "routes.WebhookResponse": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "description": "Whether events are sent.",
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "description": "When the subscription was created.",
+                    "type": "string"
+                },
+                "description": {
+                    "description": "Free text.",
+                    "type": "string"
+                },
+                "disabled_at": {
+                    "description": "When the subscription was disabled after repeated failures.",
+                    "type": "string"
+                },
+                "events": {
+                    "description": "The subscribed event types.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "failures": {
+                    "description": "Consecutive failed attempts.",
+                    "type": "integer"
+                },
+                "id": {
+                    "description": "The ID of the subscription.",
+                    "type": "integer"
+                },
+                "secret": {
+                    "description": "The signing key; only returned when the subscription is created.",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "description": "When the subscription was last updated.",
+                    "type": "string"
+                },
+                "url": {
+                    "description": "The endpoint receiving the events.",
+                    "type": "string"
+                }
+            }
+        },
+        "rpc.MethodMetrics": {
+            "type": "object",
+            "properties": {
+                "average_ms": {
+                    "description": "Average duration of the calls, in milliseconds",
+                    "type": "number"
+                },
+                "calls": {
+                    "description": "Completed calls",
+                    "type": "integer"
+                },
+                "codes": {
+                    "description": "Calls by status code (e.g., {\"OK\": 10, \"NotFound\": 2})",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "errors": {
+                    "description": "Calls that did not end with OK",
+                    "type": "integer"
+                },
+                "max_ms": {
+                    "description": "Longest call, in milliseconds",
+                    "type": "number"
+                },
+                "method": {
+                    "description": "Full method name (e.g., \"/gobo.v1.ExampleService/GetExample\")",
+                    "type": "string"
+                }
+            }
+        },
+        "transfer.RowError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Machine-readable reason (e.g., \"required\", \"malformed\").",
+                    "type": "string"
+                },
+                "field": {
+                    "description": "The invalid column, if the error concerns a single one.",
+                    "type": "string"
+                },
+                "message": {
+                    "description": "Human-readable description.",
+                    "type": "string"
+                },
+                "row": {
+                    "description": "The line of the row in the file (the header of a CSV file is line 1).",
+                    "type": "integer"
                 }
             }
         }
@@ -184,6 +3007,8 @@ var SwaggerInfo = &swag.Spec{
 	Description:      "A boilerplate application for building web services using Go and Fiber.",
 	InfoInstanceName: "swagger",
 	SwaggerTemplate:  docTemplate,
+	LeftDelim:        "{{",
+	RightDelim:       "}}",
 }
 
 func init() {
@@ -3,19 +3,46 @@
 package app
 
 import (
+	"os"
+	"strconv"
+
+	"gobo/internal/apperror"
+	"gobo/internal/middleware"
 	"gobo/internal/routes"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
 )
 
 // NewApp initializes and returns a new Fiber application instance.
-// This function sets up the application with all registered routes.
+// This function sets up the application with global middleware and all registered routes.
 //
 // Returns:
 // - *fiber.App: The initialized Fiber application instance ready to handle requests.
 func NewApp() *fiber.App {
 	// Create a new instance of Fiber.
-	app := fiber.New()
+	// Errors returned by handlers and middleware are rendered as RFC 7807 problem details.
+	app := fiber.New(fiber.Config{
+		ErrorHandler: apperror.Handler,
+		BodyLimit:    bodyLimit(),
+	})
+
+	// Report errors and performance data to Sentry and recover from panics.
+	// It is registered first so it wraps every other middleware and handler.
+	app.Use(middleware.SentryMiddleware())
+
+	// Assign every request an ID (X-Request-ID), reusing the client's if it sent one.
+	app.Use(requestid.New())
+
+	// Attribute database changes to the request's client (see the audit package).
+	app.Use(middleware.AuditMiddleware())
+
+	// Negotiate the response language from the Accept-Language header.
+	app.Use(middleware.LocaleMiddleware())
+
+	// Resolve the tenant of the request, scoping its data, cache keys and rate limits.
+	app.Use(middleware.TenantMiddleware(middleware.DefaultTenantConfig()))
+	app.Use(middleware.TenantRateLimitMiddleware(middleware.DefaultTenantRateLimitConfig()))
 
 	// Register application routes.
 	// The routes are defined and handled in the routes package.
@@ -24,3 +51,12 @@ func NewApp() *fiber.App {
 	// Return the initialized Fiber application instance.
 	return app
 }
+
+// bodyLimit returns the maximum size of request bodies: the BODY_LIMIT environment variable, in bytes, or the
+// default of Fiber (4 MiB). Imported files are limited by it as well.
+func bodyLimit() int {
+	if value, err := strconv.Atoi(os.Getenv("BODY_LIMIT")); err == nil && value > 0 {
+		return value
+	}
+	return fiber.DefaultBodyLimit
+}
@@ -0,0 +1,261 @@
+// Package apperror defines the application's typed errors and their HTTP representation.
+// Handlers and middleware return these errors, and the central Fiber error handler renders
+// them as RFC 7807 problem details (application/problem+json).
+package apperror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"gobo/internal/i18n"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// Machine-readable error codes returned in the "code" member of problem details.
+const (
+	CodeBadRequest   = "bad_request"
+	CodeNotFound     = "not_found"
+	CodeConflict     = "conflict"
+	CodeValidation   = "validation_failed"
+	CodeUnauthorized = "unauthorized"
+	CodeForbidden    = "forbidden"
+	CodeRateLimited  = "rate_limited"
+	CodeInternal     = "internal_error"
+)
+
+// FieldError describes a problem with a single request field.
+type FieldError struct {
+	Field   string    `json:"field"`   // The field name as sent by the client (e.g., "name").
+	Code    string    `json:"code"`    // Machine-readable reason (e.g., "required", "max").
+	Message string    `json:"message"` // Human-readable description in the default language.
+	Key     string    `json:"-"`       // Message key used to localize the message (e.g., "validation.required").
+	Args    i18n.Args `json:"-"`       // Placeholder values of the message.
+}
+
+// Error is an application error with an HTTP status and a machine-readable code.
+type Error struct {
+	Status int          // HTTP status code (e.g., 404)
+	Code   string       // Machine-readable error code (e.g., "not_found")
+	Detail string       // Human-readable explanation in the default language
+	Key    string       // Message key used to localize the detail (e.g., "error.not_found")
+	Args   i18n.Args    // Placeholder values of the message
+	Fields []FieldError // Field-level problems (validation errors)
+	Err    error        // Underlying cause, never exposed to clients
+
+	Extensions map[string]interface{} // Additional members of the problem details (e.g., "current")
+}
+
+// New creates an application error.
+//
+// Parameters:
+// - status (int): The HTTP status code.
+// - code (string): The machine-readable error code.
+// - detail (string): The human-readable explanation.
+//
+// Returns:
+// - *Error: The application error.
+func New(status int, code, detail string) *Error {
+	return &Error{Status: status, Code: code, Detail: detail}
+}
+
+// Error returns the error message, including the cause if there is one.
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Code, e.Detail, e.Err)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Detail)
+}
+
+// Unwrap returns the underlying cause.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// As allows errors.As to convert the error into a *fiber.Error,
+// so that the correct status is returned even without the central error handler.
+func (e *Error) As(target interface{}) bool {
+	if fiberErr, ok := target.(**fiber.Error); ok {
+		*fiberErr = fiber.NewError(e.Status, e.Detail)
+		return true
+	}
+	return false
+}
+
+// Title returns the short, human-readable summary of the error's status.
+func (e *Error) Title() string {
+	return http.StatusText(e.Status)
+}
+
+// WithCode returns a copy of the error with a more specific code (e.g., "example_not_found").
+func (e *Error) WithCode(code string) *Error {
+	clone := *e
+	clone.Code = code
+	return &clone
+}
+
+// WithKey returns a copy of the error whose detail is the localized message with the given key.
+// The detail is set to the message in the default language; responses are localized by Handler.
+func (e *Error) WithKey(key string, args ...i18n.Args) *Error {
+	clone := *e
+	clone.Key = key
+	clone.Args = nil
+	if len(args) > 0 {
+		clone.Args = args[0]
+	}
+	clone.Detail = i18n.T(i18n.DefaultLanguage, key, clone.Args)
+	return &clone
+}
+
+// WithExtension returns a copy of the error with an additional problem details member,
+// e.g. the current representation of a resource that could not be updated.
+// Members with the name of a standard member (e.g., "status") are ignored.
+func (e *Error) WithExtension(name string, value interface{}) *Error {
+	clone := *e
+	clone.Extensions = make(map[string]interface{}, len(e.Extensions)+1)
+	for key, existing := range e.Extensions {
+		clone.Extensions[key] = existing
+	}
+	clone.Extensions[name] = value
+	return &clone
+}
+
+// WithCause returns a copy of the error with the given underlying cause.
+func (e *Error) WithCause(err error) *Error {
+	clone := *e
+	clone.Err = err
+	return &clone
+}
+
+// BadRequest creates a 400 Bad Request error, e.g. for a malformed request body.
+func BadRequest(detail string) *Error {
+	return New(fiber.StatusBadRequest, CodeBadRequest, detail)
+}
+
+// NotFound creates a 404 Not Found error.
+func NotFound(detail string) *Error {
+	return New(fiber.StatusNotFound, CodeNotFound, detail)
+}
+
+// Conflict creates a 409 Conflict error, e.g. for a duplicate unique value.
+func Conflict(detail string) *Error {
+	return New(fiber.StatusConflict, CodeConflict, detail)
+}
+
+// Validation creates a 422 Unprocessable Entity error listing the invalid fields.
+func Validation(detail string, fields ...FieldError) *Error {
+	err := New(fiber.StatusUnprocessableEntity, CodeValidation, detail)
+	err.Fields = fields
+	return err
+}
+
+// Unauthorized creates a 401 Unauthorized error.
+func Unauthorized(detail string) *Error {
+	return New(fiber.StatusUnauthorized, CodeUnauthorized, detail)
+}
+
+// Forbidden creates a 403 Forbidden error.
+func Forbidden(detail string) *Error {
+	return New(fiber.StatusForbidden, CodeForbidden, detail)
+}
+
+// RateLimited creates a 429 Too Many Requests error.
+func RateLimited(detail string) *Error {
+	return New(fiber.StatusTooManyRequests, CodeRateLimited, detail)
+}
+
+// Internal creates a 500 Internal Server Error wrapping the given cause.
+// The cause is logged and reported, but never exposed to clients.
+func Internal(err error) *Error {
+	return New(fiber.StatusInternalServerError, CodeInternal, "").WithKey("error.internal").WithCause(err)
+}
+
+// PostgreSQL error codes mapped to application errors.
+const (
+	pgUniqueViolation     = "23505"
+	pgForeignKeyViolation = "23503"
+	pgNotNullViolation    = "23502"
+	pgCheckViolation      = "23514"
+	pgStringTooLong       = "22001"
+)
+
+// From converts any error into an application error:
+// - *Error values are returned as they are.
+// - gorm.ErrRecordNotFound becomes NotFound.
+// - Unique and foreign key violations become Conflict.
+// - Not-null, check and length violations become Validation.
+// - *fiber.Error values keep their status.
+// - Anything else becomes Internal.
+//
+// Parameters:
+// - err (error): The error to convert.
+//
+// Returns:
+// - *Error: The application error, or nil if err is nil.
+func From(err error) *Error {
+	if err == nil {
+		return nil
+	}
+
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return NotFound("").WithKey("error.not_found").WithCause(err)
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return Conflict("").WithKey("error.duplicate").WithCause(err)
+	case errors.Is(err, gorm.ErrForeignKeyViolated):
+		return Conflict("").WithKey("error.foreign_key").WithCause(err)
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case pgUniqueViolation:
+			return Conflict("").WithKey("error.duplicate").WithCause(err)
+		case pgForeignKeyViolation:
+			return Conflict("").WithKey("error.foreign_key").WithCause(err)
+		case pgNotNullViolation, pgCheckViolation, pgStringTooLong:
+			return Validation("").WithKey("error.invalid_values").WithCause(err)
+		}
+	}
+
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return New(fiberErr.Code, codeForStatus(fiberErr.Code), fiberErr.Message)
+	}
+
+	return Internal(err)
+}
+
+// codeForStatus returns the default error code for an HTTP status.
+func codeForStatus(status int) string {
+	switch status {
+	case fiber.StatusBadRequest:
+		return CodeBadRequest
+	case fiber.StatusUnauthorized:
+		return CodeUnauthorized
+	case fiber.StatusForbidden:
+		return CodeForbidden
+	case fiber.StatusNotFound:
+		return CodeNotFound
+	case fiber.StatusConflict:
+		return CodeConflict
+	case fiber.StatusUnprocessableEntity:
+		return CodeValidation
+	case fiber.StatusTooManyRequests:
+		return CodeRateLimited
+	}
+	if status >= fiber.StatusInternalServerError {
+		return CodeInternal
+	}
+	// Derive the code from the status text, e.g. 405 becomes "method_not_allowed".
+	return strings.ToLower(strings.ReplaceAll(http.StatusText(status), " ", "_"))
+}
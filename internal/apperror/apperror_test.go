@@ -0,0 +1,113 @@
+// Package apperror_test contains tests for the apperror package.
+// These tests validate the mapping of errors and their rendering as problem details.
+package apperror_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"gobo/internal/apperror"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// TestFrom verifies that common errors are mapped to the expected status and code.
+func TestFrom(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{"application error", apperror.NotFound("Example not found"), 404, apperror.CodeNotFound},
+		{"wrapped application error", fmt.Errorf("lookup: %w", apperror.Forbidden("No access")), 403, apperror.CodeForbidden},
+		{"record not found", fmt.Errorf("query: %w", gorm.ErrRecordNotFound), 404, apperror.CodeNotFound},
+		{"duplicated key", gorm.ErrDuplicatedKey, 409, apperror.CodeConflict},
+		{"unique violation", &pgconn.PgError{Code: "23505"}, 409, apperror.CodeConflict},
+		{"not-null violation", &pgconn.PgError{Code: "23502"}, 422, apperror.CodeValidation},
+		{"fiber error", fiber.ErrMethodNotAllowed, 405, "method_not_allowed"},
+		{"unknown error", errors.New("connection refused"), 500, apperror.CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			appErr := apperror.From(tt.err)
+			assert.Equal(t, tt.status, appErr.Status)
+			assert.Equal(t, tt.code, appErr.Code)
+		})
+	}
+
+	assert.Nil(t, apperror.From(nil))
+}
+
+// TestErrorAsFiberError verifies that application errors keep their status with Fiber's default error handler.
+func TestErrorAsFiberError(t *testing.T) {
+	var fiberErr *fiber.Error
+	assert.True(t, errors.As(apperror.Conflict("Duplicate name"), &fiberErr))
+	assert.Equal(t, fiber.StatusConflict, fiberErr.Code)
+	assert.Equal(t, "Duplicate name", fiberErr.Message)
+}
+
+// TestHandler verifies that the error handler renders problem details without exposing internal causes.
+func TestHandler(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Post("/examples", func(c *fiber.Ctx) error {
+		return apperror.Validation("The request contains invalid values.",
+			apperror.FieldError{Field: "name", Code: "required", Message: "name is required"},
+		)
+	})
+	app.Get("/examples", func(c *fiber.Ctx) error {
+		return errors.New("dial tcp 10.0.0.1:5432: connection refused")
+	})
+
+	// Validation errors include the field errors.
+	resp, err := app.Test(httptest.NewRequest("POST", "/examples?dry_run=true", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, 422, resp.StatusCode)
+	assert.Equal(t, apperror.MIMEProblemJSON, resp.Header.Get("Content-Type"))
+
+	var problem apperror.Problem
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&problem))
+	assert.Equal(t, "about:blank", problem.Type)
+	assert.Equal(t, "Unprocessable Entity", problem.Title)
+	assert.Equal(t, 422, problem.Status)
+	assert.Equal(t, "/examples?dry_run=true", problem.Instance)
+	assert.Equal(t, apperror.CodeValidation, problem.Code)
+	assert.Equal(t, []apperror.FieldError{{Field: "name", Code: "required", Message: "name is required"}}, problem.Errors)
+
+	// Unexpected errors become a generic 500 without the cause.
+	resp, err = app.Test(httptest.NewRequest("GET", "/examples", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, 500, resp.StatusCode)
+
+	problem = apperror.Problem{}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&problem))
+	assert.Equal(t, apperror.CodeInternal, problem.Code)
+	assert.NotContains(t, problem.Detail, "10.0.0.1")
+}
+
+// TestProblemExtensions verifies that extension members are rendered next to the standard members.
+func TestProblemExtensions(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Put("/examples/1", func(c *fiber.Ctx) error {
+		return apperror.Conflict("The example was modified.").
+			WithExtension("current", fiber.Map{"id": 1, "version": 2}).
+			WithExtension("status", "ignored")
+	})
+
+	resp, err := app.Test(httptest.NewRequest("PUT", "/examples/1", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, 409, resp.StatusCode)
+
+	var body map[string]interface{}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, float64(409), body["status"], "Expected standard members to take precedence")
+	assert.Equal(t, apperror.CodeConflict, body["code"])
+	assert.Equal(t, map[string]interface{}{"id": float64(1), "version": float64(2)}, body["current"])
+}
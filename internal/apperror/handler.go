@@ -0,0 +1,150 @@
+// Package apperror defines the application's typed errors and their HTTP representation.
+// This file renders errors as RFC 7807 problem details.
+package apperror
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"gobo/internal/i18n"
+	"gobo/internal/logger"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// MIMEProblemJSON is the media type of problem details responses.
+const MIMEProblemJSON = "application/problem+json"
+
+// Problem is the RFC 7807 problem details representation of an error.
+type Problem struct {
+	Type     string       `json:"type"`             // URI identifying the problem type ("about:blank" for plain HTTP errors).
+	Title    string       `json:"title"`            // Short summary of the problem type (the HTTP status text).
+	Status   int          `json:"status"`           // HTTP status code.
+	Detail   string       `json:"detail,omitempty"` // Explanation specific to this occurrence.
+	Instance string       `json:"instance"`         // The request path where the problem occurred.
+	Code     string       `json:"code"`             // Machine-readable error code (e.g., "not_found").
+	Errors   []FieldError `json:"errors,omitempty"` // Field-level problems (validation errors).
+
+	Extensions map[string]interface{} `json:"-"` // Additional members (RFC 7807 section 3.2), e.g. "current".
+}
+
+// standardMembers are the members of Problem, which extensions cannot replace.
+var standardMembers = map[string]bool{
+	"type": true, "title": true, "status": true, "detail": true, "instance": true, "code": true, "errors": true,
+}
+
+// MarshalJSON encodes the problem details with the extension members next to the standard ones.
+func (p Problem) MarshalJSON() ([]byte, error) {
+	type problem Problem // Without the MarshalJSON method
+	data, err := json.Marshal(problem(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return data, err
+	}
+
+	members := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &members); err != nil {
+		return nil, err
+	}
+	for name, value := range p.Extensions {
+		if standardMembers[name] {
+			continue
+		}
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		members[name] = raw
+	}
+	return json.Marshal(members)
+}
+
+// NewProblem builds the problem details of an application error for the current request.
+// The title, detail and field messages are localized to the request language (see Language).
+//
+// Parameters:
+// - c (*fiber.Ctx): The Fiber context of the request.
+// - err (*Error): The application error.
+//
+// Returns:
+// - Problem: The problem details.
+func NewProblem(c *fiber.Ctx, err *Error) Problem {
+	language := Language(c)
+
+	title := err.Title()
+	if key := "status." + strconv.Itoa(err.Status); i18n.Has(key) {
+		title = i18n.T(language, key)
+	}
+	detail := err.Detail
+	if err.Key != "" {
+		detail = i18n.T(language, err.Key, err.Args)
+	}
+
+	// Localize a copy so the error itself keeps the default language.
+	var fields []FieldError
+	if len(err.Fields) > 0 {
+		fields = make([]FieldError, len(err.Fields))
+		for i, field := range err.Fields {
+			if field.Key != "" {
+				field.Message = i18n.T(language, field.Key, field.Args)
+			}
+			fields[i] = field
+		}
+	}
+
+	return Problem{
+		Type:     "about:blank",
+		Title:    title,
+		Status:   err.Status,
+		Detail:   detail,
+		Instance: c.OriginalURL(),
+		Code:     err.Code,
+		Errors:   fields,
+
+		Extensions: err.Extensions,
+	}
+}
+
+// Language returns the language of the current request.
+// It is the language negotiated by middleware.LocaleMiddleware, or, if the middleware did not run,
+// the best match for the Accept-Language header.
+//
+// Parameters:
+// - c (*fiber.Ctx): The Fiber context of the request.
+//
+// Returns:
+// - string: The language code (e.g., "tr").
+func Language(c *fiber.Ctx) string {
+	if language := i18n.FromContext(c.UserContext()); language != "" {
+		return language
+	}
+	return i18n.Negotiate(c.Get(fiber.HeaderAcceptLanguage))
+}
+
+// Write sends the error as an application/problem+json response.
+//
+// Parameters:
+// - c (*fiber.Ctx): The Fiber context of the request.
+// - err (*Error): The application error.
+//
+// Returns:
+// - error: An error if the response cannot be written.
+func Write(c *fiber.Ctx, err *Error) error {
+	return c.Status(err.Status).JSON(NewProblem(c, err), MIMEProblemJSON)
+}
+
+// Handler is the central Fiber error handler (fiber.Config.ErrorHandler).
+// It converts any returned error with From and renders it as problem details.
+// Server errors are logged with their cause, which also reports them to Sentry.
+func Handler(c *fiber.Ctx, err error) error {
+	appErr := From(err)
+	if appErr.Status >= fiber.StatusInternalServerError {
+		logger.FromContext(c.UserContext()).Error("Request failed",
+			zap.Error(err),
+			zap.String("method", c.Method()),
+			zap.String("path", c.Path()),
+			zap.Int("status", appErr.Status),
+		)
+	}
+	return Write(c, appErr)
+}
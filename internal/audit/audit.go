@@ -0,0 +1,161 @@
+// Package audit records every create, update and delete of audited models in the audit_logs table.
+// The changes are captured by a GORM plugin (see Plugin); the actor, request ID and IP address are
+// taken from the statement's context (see NewContext), so queries must use db.WithContext.
+package audit
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Audited actions.
+const (
+	ActionCreate  = "create"  // The record was created
+	ActionUpdate  = "update"  // The record was updated
+	ActionDelete  = "delete"  // The record was deleted (soft or permanently)
+	ActionRestore = "restore" // The soft delete of the record was cleared
+)
+
+// Actor identifies who made a change.
+type Actor struct {
+	User      string // Authenticated username; empty for anonymous requests and background jobs
+	RequestID string // ID of the request that made the change (X-Request-ID)
+	IP        string // IP address of the client
+}
+
+// contextKey is the type of the context key under which the actor is stored.
+type contextKey struct{}
+
+// NewContext returns a copy of the context carrying the given actor.
+//
+// Parameters:
+// - ctx (context.Context): The parent context.
+// - actor (Actor): The actor making changes with this context.
+//
+// Returns:
+// - context.Context: The derived context.
+func NewContext(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, contextKey{}, actor)
+}
+
+// WithUser returns a copy of the context whose actor is the given user.
+// The request ID and IP address of the actor already in the context are kept.
+//
+// Parameters:
+// - ctx (context.Context): The parent context.
+// - user (string): The authenticated username.
+//
+// Returns:
+// - context.Context: The derived context.
+func WithUser(ctx context.Context, user string) context.Context {
+	actor := FromContext(ctx)
+	actor.User = user
+	return NewContext(ctx, actor)
+}
+
+// FromContext returns the actor stored in the context.
+//
+// Parameters:
+// - ctx (context.Context): The context, typically the statement's or request's user context.
+//
+// Returns:
+// - Actor: The actor, or a zero Actor if none is set.
+func FromContext(ctx context.Context) Actor {
+	if ctx == nil {
+		return Actor{}
+	}
+	actor, _ := ctx.Value(contextKey{}).(Actor)
+	return actor
+}
+
+// Entry represents the "audit_logs" table in the database.
+// Fields:
+// - ID: The primary key of the record.
+// - CreatedAt: When the change was made.
+// - Entity: The table of the changed record (e.g., "examples").
+// - EntityID: The primary key of the changed record.
+// - Action: create, update, delete or restore.
+// - TenantID: The tenant owning the changed record (see the tenant package).
+// - Actor, RequestID, IP: Who made the change (see Actor).
+// - Before, After: The changed columns before and after the change; nil for creates and permanent deletes respectively.
+type Entry struct {
+	ID        uint                   `gorm:"primaryKey"`                                             // Primary key for the record.
+	CreatedAt time.Time              `gorm:"index"`                                                  // Time of the change.
+	Entity    string                 `gorm:"type:varchar(100);not null;index:idx_audit_logs_entity"` // Table of the changed record.
+	EntityID  string                 `gorm:"type:varchar(100);not null;index:idx_audit_logs_entity"` // Primary key of the changed record.
+	Action    string                 `gorm:"type:varchar(20);not null"`                              // create, update, delete or restore.
+	TenantID  string                 `gorm:"type:varchar(63);not null;default:'default';index"`      // Tenant owning the changed record.
+	Actor     string                 `gorm:"type:varchar(100);index"`                                // Authenticated username.
+	RequestID string                 `gorm:"type:varchar(100)"`                                      // Request ID.
+	IP        string                 `gorm:"type:varchar(45)"`                                       // Client IP address.
+	Before    map[string]interface{} `gorm:"type:jsonb;serializer:json"`                             // Changed columns before the change.
+	After     map[string]interface{} `gorm:"type:jsonb;serializer:json"`                             // Changed columns after the change.
+}
+
+// TableName returns the table of the audit log.
+func (Entry) TableName() string {
+	return "audit_logs"
+}
+
+// Filter selects audit log entries. Zero values do not filter.
+type Filter struct {
+	Entity    string    // Table of the changed records (e.g., "examples")
+	EntityID  string    // Primary key of the changed record
+	Action    string    // create, update, delete or restore
+	Actor     string    // Username of the actor
+	RequestID string    // Request ID
+	From      time.Time // Earliest change (inclusive)
+	To        time.Time // Latest change (exclusive)
+	Page      int       // 1-based page number (defaults to 1)
+	PageSize  int       // Entries per page (defaults to 50)
+}
+
+// Query returns a page of the audit log entries matching the filter, newest first.
+//
+// Parameters:
+// - db (*gorm.DB): The GORM database connection instance.
+// - filter (Filter): The conditions and the page to return.
+//
+// Returns:
+// - []Entry: The entries of the requested page.
+// - int64: The total number of matching entries.
+// - error: Returns an error if the query fails.
+func Query(db *gorm.DB, filter Filter) ([]Entry, int64, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.PageSize < 1 {
+		filter.PageSize = 50
+	}
+
+	query := db.Model(&Entry{})
+	conditions := []struct{ column, value string }{
+		{"entity", filter.Entity},
+		{"entity_id", filter.EntityID},
+		{"action", filter.Action},
+		{"actor", filter.Actor},
+		{"request_id", filter.RequestID},
+	}
+	for _, condition := range conditions {
+		if condition.value != "" {
+			query = query.Where(condition.column+" = ?", condition.value)
+		}
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_at < ?", filter.To)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	entries := []Entry{}
+	err := query.Order("id DESC").Offset((filter.Page - 1) * filter.PageSize).Limit(filter.PageSize).Find(&entries).Error
+	return entries, total, err
+}
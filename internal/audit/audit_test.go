@@ -0,0 +1,91 @@
+// Package audit_test contains tests for the audit package.
+// These tests validate the actor context, change detection and the GORM plugin.
+package audit_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"gobo/internal/audit"
+	"gobo/internal/db"
+	"gobo/internal/models"
+	"gobo/internal/testhelpers"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm/schema"
+)
+
+// TestContext verifies that the actor is carried by the context and completed with the user.
+func TestContext(t *testing.T) {
+	assert.Equal(t, audit.Actor{}, audit.FromContext(context.Background()))
+
+	ctx := audit.NewContext(context.Background(), audit.Actor{RequestID: "req-1", IP: "10.0.0.1"})
+	ctx = audit.WithUser(ctx, "admin")
+	assert.Equal(t, audit.Actor{User: "admin", RequestID: "req-1", IP: "10.0.0.1"}, audit.FromContext(ctx))
+}
+
+// TestDiff verifies that only changed columns are returned, without GORM timestamps and with redacted values masked.
+func TestDiff(t *testing.T) {
+	s, err := schema.Parse(&models.User{}, &sync.Map{}, schema.NamingStrategy{})
+	assert.NoError(t, err)
+
+	before := map[string]interface{}{"id": 1, "username": "john", "password": "old", "email": "john@example.com", "updated_at": time.Unix(0, 0)}
+	after := map[string]interface{}{"id": 1, "username": "johnny", "password": "new", "email": "john@example.com", "updated_at": time.Unix(60, 0)}
+
+	changedBefore, changedAfter := audit.Diff(s, before, after)
+	assert.Equal(t, map[string]interface{}{"username": "john", "password": "[REDACTED]"}, changedBefore)
+	assert.Equal(t, map[string]interface{}{"username": "johnny", "password": "[REDACTED]"}, changedAfter)
+}
+
+// TestPlugin verifies that creates, updates, soft deletes, restores and permanent deletes are recorded.
+func TestPlugin(t *testing.T) {
+	testhelpers.SetupGormTestDB(t, &models.Example{}, &audit.Entry{})
+	defer testhelpers.TeardownGormTestDB(&models.Example{}, &audit.Entry{})
+	conn := testhelpers.OpenGormTestDB(t)
+	assert.NoError(t, conn.Use(audit.New(&models.Example{})))
+
+	ctx := audit.NewContext(context.Background(), audit.Actor{User: "admin", RequestID: "req-1", IP: "10.0.0.1"})
+	tx := conn.WithContext(ctx)
+
+	example := models.Example{Name: "Audited"}
+	assert.NoError(t, tx.Create(&example).Error)
+	assert.NoError(t, tx.Model(&example).Update("name", "Renamed").Error)
+	assert.NoError(t, tx.Delete(&models.Example{}, example.ID).Error)
+	_, err := models.Restore(tx, &models.Example{}, "id = ?", example.ID)
+	assert.NoError(t, err)
+	assert.NoError(t, tx.Unscoped().Delete(&example).Error)
+
+	entries, total, err := audit.Query(db.GormDB, audit.Filter{Entity: "examples"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), total)
+	if !assert.Len(t, entries, 5) {
+		return
+	}
+
+	// Entries are returned newest first.
+	actions := []string{}
+	for _, entry := range entries {
+		actions = append(actions, entry.Action)
+		assert.Equal(t, "admin", entry.Actor)
+		assert.Equal(t, "req-1", entry.RequestID)
+		assert.Equal(t, "10.0.0.1", entry.IP)
+	}
+	assert.Equal(t, []string{"delete", "restore", "delete", "update", "create"}, actions)
+
+	assert.Equal(t, "Audited", entries[4].After["name"])
+	assert.Nil(t, entries[4].Before)
+	assert.Equal(t, "Audited", entries[3].Before["name"])
+	assert.Equal(t, "Renamed", entries[3].After["name"])
+	assert.EqualValues(t, 1, entries[3].Before["version"])
+	assert.EqualValues(t, 2, entries[3].After["version"])
+	assert.Equal(t, "Renamed", entries[0].Before["name"])
+	assert.Nil(t, entries[0].After)
+
+	// Filters and pagination.
+	entries, total, err = audit.Query(db.GormDB, audit.Filter{Entity: "examples", Action: audit.ActionDelete, PageSize: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Len(t, entries, 1)
+}
@@ -0,0 +1,348 @@
+// Package audit records every create, update and delete of audited models in the audit_logs table.
+// This file implements the GORM plugin capturing the changes.
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"gobo/internal/tenant"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// redacted replaces the values of columns whose field is tagged with `log:"redact"` (e.g., passwords).
+const redacted = "[REDACTED]"
+
+// beforeKey is the statement instance key under which the rows are stored before an update or delete.
+const beforeKey = "audit:before"
+
+// Plugin is a GORM plugin writing an audit log entry for every created, updated and deleted
+// record of the audited models. The entries are written in the transaction of the change,
+// so a change is rolled back if its entry cannot be written.
+//
+// Changes are captured by reading the affected rows before and after the statement:
+// - Creates record all columns in After.
+// - Updates, soft deletes and restores record only the changed columns (timestamps maintained by GORM are ignored).
+// - Permanent deletes record all columns in Before.
+type Plugin struct {
+	models []interface{}
+	tables map[string]struct{}
+}
+
+// New creates the audit plugin for the given models.
+// Register it with db.Use after connecting to the database.
+//
+// Parameters:
+// - models (...interface{}): Pointers to the audited models (e.g., &models.Example{}).
+//
+// Returns:
+// - *Plugin: The plugin.
+func New(models ...interface{}) *Plugin {
+	return &Plugin{models: models}
+}
+
+// Name returns the name of the plugin.
+func (p *Plugin) Name() string {
+	return "audit"
+}
+
+// Initialize resolves the tables of the audited models and registers the callbacks.
+func (p *Plugin) Initialize(db *gorm.DB) error {
+	p.tables = make(map[string]struct{}, len(p.models))
+	for _, model := range p.models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return fmt.Errorf("audit: %w", err)
+		}
+		p.tables[stmt.Schema.Table] = struct{}{}
+	}
+
+	callbacks := db.Callback()
+	if err := callbacks.Create().After("gorm:create").Before("gorm:commit_or_rollback_transaction").
+		Register("audit:after_create", p.afterCreate); err != nil {
+		return err
+	}
+	if err := callbacks.Update().After("gorm:before_update").Before("gorm:update").
+		Register("audit:before_update", p.before); err != nil {
+		return err
+	}
+	if err := callbacks.Update().After("gorm:update").Before("gorm:commit_or_rollback_transaction").
+		Register("audit:after_update", p.afterChange); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().After("gorm:before_delete").Before("gorm:delete").
+		Register("audit:before_delete", p.before); err != nil {
+		return err
+	}
+	return callbacks.Delete().After("gorm:delete").Before("gorm:commit_or_rollback_transaction").
+		Register("audit:after_delete", p.afterChange)
+}
+
+// audited reports whether the statement changes an audited model.
+func (p *Plugin) audited(db *gorm.DB) bool {
+	if db.Error != nil || db.DryRun || db.Statement.Schema == nil || db.Statement.Schema.PrioritizedPrimaryField == nil {
+		return false
+	}
+	_, ok := p.tables[db.Statement.Schema.Table]
+	return ok
+}
+
+// afterCreate records the created records.
+func (p *Plugin) afterCreate(db *gorm.DB) {
+	if !p.audited(db) || db.Statement.RowsAffected == 0 {
+		return
+	}
+	keys := primaryKeys(db)
+	if len(keys) == 0 {
+		return
+	}
+	rows, err := snapshot(db, []clause.Expression{inKeys(db, keys)})
+	if err != nil {
+		_ = db.AddError(fmt.Errorf("audit: %w", err))
+		return
+	}
+
+	entries := make([]Entry, 0, len(rows))
+	for _, row := range rows {
+		entries = append(entries, newEntry(db, ActionCreate, row, nil, normalize(db.Statement.Schema, row)))
+	}
+	write(db, entries)
+}
+
+// before stores the rows matched by an update or delete, so that afterChange can compute the changes.
+func (p *Plugin) before(db *gorm.DB) {
+	if !p.audited(db) {
+		return
+	}
+	conditions := matchConditions(db)
+	if len(conditions) == 0 && !db.AllowGlobalUpdate {
+		return // GORM rejects the statement with ErrMissingWhereClause.
+	}
+	rows, err := snapshot(db, conditions)
+	if err != nil {
+		_ = db.AddError(fmt.Errorf("audit: %w", err))
+		return
+	}
+	db.InstanceSet(beforeKey, rows)
+}
+
+// afterChange records the changes of an update or delete.
+func (p *Plugin) afterChange(db *gorm.DB) {
+	value, ok := db.InstanceGet(beforeKey)
+	if !ok || !p.audited(db) || db.Statement.RowsAffected == 0 {
+		return
+	}
+	before := value.([]map[string]interface{})
+	if len(before) == 0 {
+		return
+	}
+
+	// Read the rows again (including soft-deleted ones) by primary key.
+	primary := db.Statement.Schema.PrioritizedPrimaryField.DBName
+	keys := make([]interface{}, 0, len(before))
+	for _, row := range before {
+		keys = append(keys, row[primary])
+	}
+	rows, err := snapshot(db, []clause.Expression{inKeys(db, keys)})
+	if err != nil {
+		_ = db.AddError(fmt.Errorf("audit: %w", err))
+		return
+	}
+	after := make(map[string]map[string]interface{}, len(rows))
+	for _, row := range rows {
+		after[fmt.Sprint(row[primary])] = row
+	}
+
+	entries := make([]Entry, 0, len(before))
+	for _, old := range before {
+		current, exists := after[fmt.Sprint(old[primary])]
+		if !exists {
+			// Permanently deleted.
+			entries = append(entries, newEntry(db, ActionDelete, old, normalize(db.Statement.Schema, old), nil))
+			continue
+		}
+		changedBefore, changedAfter := Diff(db.Statement.Schema, old, current)
+		if len(changedAfter) == 0 {
+			continue // Not matched by the statement, or nothing changed.
+		}
+		entries = append(entries, newEntry(db, action(db.Statement.Schema, old, current), old, changedBefore, changedAfter))
+	}
+	write(db, entries)
+}
+
+// action returns the action of an update: soft deletes and restores are updates of the DeletedAt column.
+func action(s *schema.Schema, before, after map[string]interface{}) string {
+	field := softDeleteField(s)
+	if field == nil {
+		return ActionUpdate
+	}
+	switch wasDeleted, isDeleted := before[field.DBName] != nil, after[field.DBName] != nil; {
+	case !wasDeleted && isDeleted:
+		return ActionDelete
+	case wasDeleted && !isDeleted:
+		return ActionRestore
+	default:
+		return ActionUpdate
+	}
+}
+
+// Diff returns the columns whose values differ between two rows of a model, before and after the change.
+// Columns maintained by GORM on update (e.g., updated_at) are ignored, and redacted columns are masked.
+//
+// Parameters:
+// - s (*schema.Schema): The schema of the model.
+// - before (map[string]interface{}): The row before the change, by column name.
+// - after (map[string]interface{}): The row after the change, by column name.
+//
+// Returns:
+// - map[string]interface{}: The changed columns with their previous values.
+// - map[string]interface{}: The changed columns with their new values.
+func Diff(s *schema.Schema, before, after map[string]interface{}) (map[string]interface{}, map[string]interface{}) {
+	changedBefore := map[string]interface{}{}
+	changedAfter := map[string]interface{}{}
+	for column, value := range after {
+		if field := s.LookUpField(column); field != nil && field.AutoUpdateTime > 0 {
+			continue
+		}
+		old, _ := json.Marshal(before[column])
+		current, _ := json.Marshal(value)
+		if string(old) == string(current) {
+			continue
+		}
+		changedBefore[column] = maskValue(s, column, before[column])
+		changedAfter[column] = maskValue(s, column, value)
+	}
+	return changedBefore, changedAfter
+}
+
+// normalize returns a copy of the row with redacted columns masked and bytes converted to strings.
+func normalize(s *schema.Schema, row map[string]interface{}) map[string]interface{} {
+	normalized := make(map[string]interface{}, len(row))
+	for column, value := range row {
+		normalized[column] = maskValue(s, column, value)
+	}
+	return normalized
+}
+
+// maskValue masks the value of a redacted column, and converts bytes to a string.
+func maskValue(s *schema.Schema, column string, value interface{}) interface{} {
+	if field := s.LookUpField(column); field != nil && field.Tag.Get("log") == "redact" {
+		return redacted
+	}
+	if bytes, ok := value.([]byte); ok {
+		return string(bytes)
+	}
+	return value
+}
+
+// newEntry creates an audit log entry for a row, with the actor of the statement's context.
+// The entry belongs to the tenant of the row, so that background jobs changing the records of
+// several tenants are attributed correctly.
+func newEntry(db *gorm.DB, action string, row, before, after map[string]interface{}) Entry {
+	actor := FromContext(db.Statement.Context)
+	tenantID, _ := row[tenant.Column].(string)
+	return Entry{
+		TenantID:  tenantID,
+		Entity:    db.Statement.Schema.Table,
+		EntityID:  fmt.Sprint(row[db.Statement.Schema.PrioritizedPrimaryField.DBName]),
+		Action:    action,
+		Actor:     actor.User,
+		RequestID: actor.RequestID,
+		IP:        actor.IP,
+		Before:    before,
+		After:     after,
+	}
+}
+
+// write inserts the entries in the statement's transaction; a failure fails the statement.
+func write(db *gorm.DB, entries []Entry) {
+	if len(entries) == 0 {
+		return
+	}
+	if err := session(db).Create(&entries).Error; err != nil {
+		_ = db.AddError(fmt.Errorf("audit: %w", err))
+	}
+}
+
+// snapshot reads the rows of the statement's model matching the conditions, including soft-deleted rows.
+// The model is set so that conditions on the primary key (e.g., db.Delete(&Example{}, 1)) can be resolved.
+func snapshot(db *gorm.DB, conditions []clause.Expression) ([]map[string]interface{}, error) {
+	rows := []map[string]interface{}{}
+	model := reflect.New(db.Statement.Schema.ModelType).Interface()
+	query := session(db).Unscoped().Model(model)
+	if len(conditions) > 0 {
+		query = query.Clauses(clause.Where{Exprs: conditions})
+	}
+	err := query.Find(&rows).Error
+	return rows, err
+}
+
+// session returns a new statement on the connection (and transaction) of the given statement.
+func session(db *gorm.DB) *gorm.DB {
+	return db.Session(&gorm.Session{NewDB: true, SkipHooks: true, Context: db.Statement.Context})
+}
+
+// matchConditions returns the conditions selecting the rows an update or delete will change:
+// its WHERE clause, the primary keys of the model values and, unless unscoped, the soft delete condition.
+func matchConditions(db *gorm.DB) []clause.Expression {
+	var conditions []clause.Expression
+	if c, ok := db.Statement.Clauses["WHERE"]; ok {
+		if where, ok := c.Expression.(clause.Where); ok {
+			conditions = append(conditions, where.Exprs...)
+		}
+	}
+	if keys := primaryKeys(db); len(keys) > 0 {
+		conditions = append(conditions, inKeys(db, keys))
+	}
+	if len(conditions) > 0 && !db.Statement.Unscoped {
+		if field := softDeleteField(db.Statement.Schema); field != nil {
+			conditions = append(conditions, clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: nil})
+		}
+	}
+	return conditions
+}
+
+// primaryKeys returns the non-zero primary keys of the statement's model values.
+func primaryKeys(db *gorm.DB) []interface{} {
+	field := db.Statement.Schema.PrioritizedPrimaryField
+	value := db.Statement.ReflectValue
+	var keys []interface{}
+	add := func(item reflect.Value) {
+		item = reflect.Indirect(item)
+		if item.Kind() != reflect.Struct || item.Type() != db.Statement.Schema.ModelType {
+			return
+		}
+		if key, zero := field.ValueOf(db.Statement.Context, item); !zero {
+			keys = append(keys, key)
+		}
+	}
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			add(value.Index(i))
+		}
+	case reflect.Struct:
+		add(value)
+	}
+	return keys
+}
+
+// inKeys returns the condition selecting rows by primary key.
+func inKeys(db *gorm.DB, keys []interface{}) clause.Expression {
+	column := clause.Column{Table: clause.CurrentTable, Name: db.Statement.Schema.PrioritizedPrimaryField.DBName}
+	return clause.IN{Column: column, Values: keys}
+}
+
+// softDeleteField returns the gorm.DeletedAt field of a schema, or nil if the model is not soft deletable.
+func softDeleteField(s *schema.Schema) *schema.Field {
+	for _, field := range s.Fields {
+		if field.FieldType == reflect.TypeOf(gorm.DeletedAt{}) {
+			return field
+		}
+	}
+	return nil
+}
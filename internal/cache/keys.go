@@ -0,0 +1,27 @@
+// Package cache provides utilities for interacting with a Redis server.
+// This file builds the keys of tenant-scoped values.
+package cache
+
+import (
+	"context"
+
+	"gobo/internal/tenant"
+)
+
+// TenantKey prefixes a key with the tenant of the context ("tenant:<id>:<key>"),
+// so that tenants sharing the Redis server never read each other's values.
+// Keys of contexts without a tenant (e.g., background jobs) are returned unchanged.
+//
+// Parameters:
+// - ctx (context.Context): The context carrying the tenant (see tenant.NewContext), typically c.UserContext().
+// - key (string): The key.
+//
+// Returns:
+// - string: The tenant-scoped key (e.g., "tenant:acme:idempotency:...").
+func TenantKey(ctx context.Context, key string) string {
+	id := tenant.FromContext(ctx)
+	if id == "" {
+		return key
+	}
+	return "tenant:" + id + ":" + key
+}
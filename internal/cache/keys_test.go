@@ -0,0 +1,17 @@
+package cache_test
+
+import (
+	"context"
+	"testing"
+
+	"gobo/internal/cache"
+	"gobo/internal/tenant"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTenantKey validates that keys are prefixed with the tenant of the context.
+func TestTenantKey(t *testing.T) {
+	assert.Equal(t, "tenant:acme:session", cache.TenantKey(tenant.NewContext(context.Background(), "acme"), "session"))
+	assert.Equal(t, "session", cache.TenantKey(context.Background(), "session"), "Expected keys without a tenant to be unchanged")
+}
@@ -9,7 +9,6 @@ import (
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 )
 
 // GormDB is the global GORM database instance used for interacting with the PostgreSQL database.
@@ -28,10 +27,13 @@ func ConnectGORM() {
 		panic("DATABASE_URL environment variable is not set")
 	}
 
-	// Open a GORM connection using the PostgreSQL driver and default logger.
+	// Open a GORM connection using the PostgreSQL driver.
+	// SQL statements are forwarded to the application's Zap logger.
 	var err error
 	GormDB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info), // Use the default logger in Info mode
+		Logger: NewGormLogger(DefaultGormLoggerConfig()),
+		// Translate driver errors into GORM errors (e.g., gorm.ErrDuplicatedKey) for apperror.From.
+		TranslateError: true,
 	})
 	if err != nil {
 		// Log a fatal error and terminate if the connection fails.
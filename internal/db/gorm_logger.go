@@ -0,0 +1,172 @@
+// Package db provides utilities for database connection and configuration using GORM.
+// This file bridges GORM's logger interface into the application's Zap logger.
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	applogger "gobo/internal/logger"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+	"gorm.io/gorm/utils"
+)
+
+// GormLoggerConfig defines how GORM log output is forwarded to Zap.
+type GormLoggerConfig struct {
+	SlowThreshold             time.Duration       // Queries slower than this are logged at WARN (0 disables detection)
+	LogLevel                  gormlogger.LogLevel // GORM log level (Silent, Error, Warn, Info)
+	IgnoreRecordNotFoundError bool                // Skip logging gorm.ErrRecordNotFound as an error
+	ParameterizedQueries      bool                // Log SQL with placeholders instead of inlined parameter values
+}
+
+// DefaultGormLoggerConfig returns the default GORM logger configuration.
+//
+// Defaults:
+// - SlowThreshold: 200ms, overridable with the DB_SLOW_QUERY_THRESHOLD environment variable
+// - LogLevel: gormlogger.Info (every statement is traced, at DEBUG level in Zap)
+// - IgnoreRecordNotFoundError: true (a missing record is not an application error)
+// - ParameterizedQueries: true (parameter values are redacted from the logged SQL)
+//
+// Returns:
+// - GormLoggerConfig: The default GORM logger configuration.
+func DefaultGormLoggerConfig() GormLoggerConfig {
+	config := GormLoggerConfig{
+		SlowThreshold:             200 * time.Millisecond,
+		LogLevel:                  gormlogger.Info,
+		IgnoreRecordNotFoundError: true,
+		ParameterizedQueries:      true,
+	}
+
+	// Allow the slow query threshold to be tuned without a code change.
+	if value := os.Getenv("DB_SLOW_QUERY_THRESHOLD"); value != "" {
+		if threshold, err := time.ParseDuration(value); err == nil {
+			config.SlowThreshold = threshold
+		}
+	}
+
+	return config
+}
+
+// GormLogger implements gorm's logger.Interface on top of the global Zap logger.
+// Statements are emitted as structured entries (sql, rows, duration, caller):
+// - Successful queries are logged at DEBUG.
+// - Queries slower than SlowThreshold are logged at WARN.
+// - Failed queries are logged at ERROR.
+type GormLogger struct {
+	config GormLoggerConfig
+}
+
+// NewGormLogger creates a GORM logger that writes to logger.Log.
+//
+// Parameters:
+// - config (GormLoggerConfig): The GORM logger configuration.
+//
+// Returns:
+// - *GormLogger: The GORM logger instance.
+func NewGormLogger(config GormLoggerConfig) *GormLogger {
+	return &GormLogger{config: config}
+}
+
+// zap returns the Zap logger used for GORM output.
+// The global logger is resolved on every call because the database may be connected
+// before logger.InitLogger runs; in that case a no-op logger is used.
+func (l *GormLogger) zap() *zap.Logger {
+	base := applogger.Log
+	if base == nil {
+		base = zap.L()
+	}
+	// The SQL call site is reported in the "caller" field, so Zap's own caller is disabled.
+	return base.Named("gorm").WithOptions(zap.WithCaller(false))
+}
+
+// LogMode returns a copy of the logger with the given GORM log level.
+func (l *GormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	clone := *l
+	clone.config.LogLevel = level
+	return &clone
+}
+
+// Info logs GORM informational messages.
+func (l *GormLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	if l.config.LogLevel >= gormlogger.Info {
+		l.zap().Info(fmt.Sprintf(msg, data...), zap.String("caller", utils.FileWithLineNum()))
+	}
+}
+
+// Warn logs GORM warning messages.
+func (l *GormLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	if l.config.LogLevel >= gormlogger.Warn {
+		l.zap().Warn(fmt.Sprintf(msg, data...), zap.String("caller", utils.FileWithLineNum()))
+	}
+}
+
+// Error logs GORM error messages.
+func (l *GormLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	if l.config.LogLevel >= gormlogger.Error {
+		l.zap().Error(fmt.Sprintf(msg, data...), zap.String("caller", utils.FileWithLineNum()))
+	}
+}
+
+// Trace logs a single SQL statement once it has been executed.
+func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if l.config.LogLevel <= gormlogger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	log := l.zap()
+
+	// Decide the severity first so fc (which renders the SQL) only runs when the entry is emitted.
+	var level zapcore.Level
+	var message string
+	switch {
+	case err != nil && l.config.LogLevel >= gormlogger.Error &&
+		(!errors.Is(err, gorm.ErrRecordNotFound) || !l.config.IgnoreRecordNotFoundError):
+		level, message = zapcore.ErrorLevel, "SQL query failed"
+	case l.config.SlowThreshold > 0 && elapsed > l.config.SlowThreshold && l.config.LogLevel >= gormlogger.Warn:
+		level, message = zapcore.WarnLevel, "Slow SQL query"
+	case l.config.LogLevel >= gormlogger.Info:
+		level, message = zapcore.DebugLevel, "SQL query"
+	default:
+		return
+	}
+
+	// Respect the level configured for the application logger.
+	checked := log.Check(level, message)
+	if checked == nil {
+		return
+	}
+
+	sql, rows := fc()
+	fields := []zap.Field{
+		zap.String("sql", sql),
+		zap.Duration("duration", elapsed),
+		zap.String("caller", utils.FileWithLineNum()),
+	}
+	if rows >= 0 {
+		fields = append(fields, zap.Int64("rows", rows))
+	}
+	if level == zapcore.WarnLevel {
+		fields = append(fields, zap.Duration("threshold", l.config.SlowThreshold))
+	}
+	if err != nil {
+		fields = append(fields, zap.Error(err))
+	}
+	checked.Write(fields...)
+}
+
+// ParamsFilter strips bound parameter values from logged SQL when ParameterizedQueries is enabled,
+// so credentials and personal data never reach the log sinks.
+func (l *GormLogger) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	if l.config.ParameterizedQueries {
+		return sql, nil
+	}
+	return sql, params
+}
@@ -0,0 +1,110 @@
+// Package db_test contains tests for the database connection and configuration.
+// These tests validate that GORM output is forwarded to the Zap logger.
+package db_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gobo/internal/db"
+	"gobo/internal/logger"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"gorm.io/gorm"
+)
+
+// observeLogs replaces the global logger with an in-memory observer at the given level.
+// The previous logger is restored when the test finishes.
+func observeLogs(t *testing.T, level zapcore.Level) *observer.ObservedLogs {
+	core, logs := observer.New(level)
+	previous := logger.Log
+	logger.Log = zap.New(core)
+	t.Cleanup(func() { logger.Log = previous })
+	return logs
+}
+
+// TestGormLogger_TraceStructuredFields verifies that a traced query is logged at DEBUG with structured fields.
+func TestGormLogger_TraceStructuredFields(t *testing.T) {
+	logs := observeLogs(t, zapcore.DebugLevel)
+	gormLogger := db.NewGormLogger(db.DefaultGormLoggerConfig())
+
+	gormLogger.Trace(context.Background(), time.Now(), func() (string, int64) {
+		return "SELECT * FROM examples", 2
+	}, nil)
+
+	entries := logs.All()
+	assert.Len(t, entries, 1, "Expected exactly one log entry")
+	assert.Equal(t, zapcore.DebugLevel, entries[0].Level)
+	fields := entries[0].ContextMap()
+	assert.Equal(t, "SELECT * FROM examples", fields["sql"])
+	assert.Equal(t, int64(2), fields["rows"])
+	assert.Contains(t, fields, "duration")
+	assert.Contains(t, fields, "caller")
+}
+
+// TestGormLogger_SlowQuery verifies that queries above the slow threshold are logged at WARN.
+func TestGormLogger_SlowQuery(t *testing.T) {
+	logs := observeLogs(t, zapcore.InfoLevel)
+	config := db.DefaultGormLoggerConfig()
+	config.SlowThreshold = 10 * time.Millisecond
+	gormLogger := db.NewGormLogger(config)
+
+	gormLogger.Trace(context.Background(), time.Now().Add(-time.Second), func() (string, int64) {
+		return "SELECT pg_sleep(1)", 1
+	}, nil)
+
+	entries := logs.FilterMessage("Slow SQL query").All()
+	assert.Len(t, entries, 1, "Expected the slow query to be logged")
+	assert.Equal(t, zapcore.WarnLevel, entries[0].Level)
+}
+
+// TestGormLogger_RespectsLogLevel verifies that DEBUG query traces are dropped when the logger runs at INFO.
+func TestGormLogger_RespectsLogLevel(t *testing.T) {
+	logs := observeLogs(t, zapcore.InfoLevel)
+	gormLogger := db.NewGormLogger(db.DefaultGormLoggerConfig())
+
+	called := false
+	gormLogger.Trace(context.Background(), time.Now(), func() (string, int64) {
+		called = true
+		return "SELECT 1", 1
+	}, nil)
+
+	assert.Equal(t, 0, logs.Len(), "Expected no log entries at INFO level")
+	assert.False(t, called, "SQL should not be rendered for a disabled level")
+}
+
+// TestGormLogger_Errors verifies error logging and the handling of gorm.ErrRecordNotFound.
+func TestGormLogger_Errors(t *testing.T) {
+	logs := observeLogs(t, zapcore.InfoLevel)
+	gormLogger := db.NewGormLogger(db.DefaultGormLoggerConfig())
+	fc := func() (string, int64) { return "INSERT INTO examples", 0 }
+
+	// A missing record is ignored by default.
+	gormLogger.Trace(context.Background(), time.Now(), fc, gorm.ErrRecordNotFound)
+	assert.Equal(t, 0, logs.Len(), "Expected ErrRecordNotFound to be ignored")
+
+	// Any other error is logged at ERROR.
+	gormLogger.Trace(context.Background(), time.Now(), fc, errors.New("duplicate key"))
+	entries := logs.All()
+	assert.Len(t, entries, 1)
+	assert.Equal(t, zapcore.ErrorLevel, entries[0].Level)
+	assert.Equal(t, "duplicate key", entries[0].ContextMap()["error"])
+}
+
+// TestGormLogger_ParamsFilter verifies that bound parameters are redacted from logged SQL.
+func TestGormLogger_ParamsFilter(t *testing.T) {
+	gormLogger := db.NewGormLogger(db.DefaultGormLoggerConfig())
+	sql, params := gormLogger.ParamsFilter(context.Background(), "SELECT * FROM users WHERE password = $1", "secret")
+	assert.Equal(t, "SELECT * FROM users WHERE password = $1", sql)
+	assert.Nil(t, params, "Expected parameters to be redacted")
+
+	config := db.DefaultGormLoggerConfig()
+	config.ParameterizedQueries = false
+	_, params = db.NewGormLogger(config).ParamsFilter(context.Background(), "SELECT $1", "value")
+	assert.Equal(t, []interface{}{"value"}, params)
+}
@@ -0,0 +1,123 @@
+// Package events publishes domain events to other services with the transactional outbox pattern.
+// Handlers publish events in the transaction of their change (see Publish); the events are stored in
+// the outbox table and a relay (see StartRelay) delivers them to Redis Streams, at least once.
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Event types.
+const (
+	ExampleCreated  = "example.created"  // An example was created; the payload is the example
+	ExampleUpdated  = "example.updated"  // An example was updated; the payload is the updated example
+	ExampleDeleted  = "example.deleted"  // An example was soft deleted; the payload is the deleted example
+	ExampleRestored = "example.restored" // A soft-deleted example was restored; the payload is the example
+)
+
+// Hook is called by Publish with the stored messages, in the transaction of the change
+// (e.g., to create the webhook deliveries of the events). Returning an error rolls the change back.
+type Hook func(tx *gorm.DB, messages []Message) error
+
+var (
+	hooksMu sync.RWMutex
+	hooks   []Hook
+)
+
+// AddHook registers a hook called every time events are published.
+//
+// Parameters:
+// - hook (Hook): The hook.
+func AddHook(hook Hook) {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+	hooks = append(hooks, hook)
+}
+
+// Event is a domain event: something that happened to an aggregate (e.g., an example was created).
+type Event struct {
+	Type          string      // Type of the event (e.g., ExampleCreated)
+	AggregateType string      // Type of the aggregate the event happened to (e.g., "example"); selects the stream
+	AggregateID   string      // ID of the aggregate; events of an aggregate are delivered in order
+	Payload       interface{} // Data of the event, encoded as JSON
+}
+
+// Message represents the "outbox" table: an event waiting to be delivered, or delivered recently.
+// Fields:
+// - ID: The primary key; events are delivered in ID order per aggregate.
+// - CreatedAt: When the event was published.
+// - EventID: The unique ID of the event, sent with it so that consumers can drop duplicates.
+// - TenantID: The tenant of the change (see the tenant package).
+// - Type, AggregateType, AggregateID, Payload: The event (see Event).
+// - Attempts, LastError, NextAttemptAt: The failed delivery attempts and when to retry.
+// - PublishedAt: When the event was delivered; NULL until then.
+// - FailedAt: When delivery was abandoned after the maximum number of attempts; NULL otherwise.
+type Message struct {
+	ID            uint            `gorm:"primaryKey"` // Primary key, in publication order.
+	CreatedAt     time.Time       // Publication time.
+	EventID       string          `gorm:"type:varchar(36);not null;uniqueIndex"`             // Unique event ID (UUID).
+	TenantID      string          `gorm:"type:varchar(63);not null;default:'default';index"` // Tenant of the change.
+	Type          string          `gorm:"type:varchar(100);not null"`                        // Event type.
+	AggregateType string          `gorm:"type:varchar(100);not null"`                        // Aggregate type.
+	AggregateID   string          `gorm:"type:varchar(100);not null"`                        // Aggregate ID.
+	Payload       json.RawMessage `gorm:"type:jsonb;not null"`                               // Event data (JSON).
+	Attempts      int             `gorm:"not null;default:0"`                                // Failed delivery attempts.
+	LastError     string          `gorm:"type:text"`                                         // Error of the last failed attempt.
+	NextAttemptAt *time.Time      // Earliest time of the next attempt after a failure.
+	PublishedAt   *time.Time      `gorm:"index"` // Delivery time; pending events have none.
+	FailedAt      *time.Time      // Time delivery was abandoned.
+}
+
+// TableName returns the table of the outbox.
+func (Message) TableName() string {
+	return "outbox"
+}
+
+// Publish stores events in the outbox, in the transaction of the change they describe:
+// if the transaction is rolled back, the events are never delivered. The hooks (see AddHook) are then called.
+// Use the request's transaction, e.g. db.GormDB.WithContext(c.UserContext()).Transaction(...),
+// so that the events belong to the tenant of the request.
+//
+// Parameters:
+// - tx (*gorm.DB): The transaction of the change.
+// - events (...Event): The events to publish.
+//
+// Returns:
+// - error: An error if an event cannot be encoded or stored, or a hook fails.
+func Publish(tx *gorm.DB, events ...Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	messages := make([]Message, 0, len(events))
+	for _, event := range events {
+		payload, err := json.Marshal(event.Payload)
+		if err != nil {
+			return fmt.Errorf("events: encoding %s: %w", event.Type, err)
+		}
+		messages = append(messages, Message{
+			EventID:       uuid.NewString(),
+			Type:          event.Type,
+			AggregateType: event.AggregateType,
+			AggregateID:   event.AggregateID,
+			Payload:       payload,
+		})
+	}
+	if err := tx.Create(&messages).Error; err != nil {
+		return err
+	}
+
+	hooksMu.RLock()
+	defer hooksMu.RUnlock()
+	for _, hook := range hooks {
+		if err := hook(tx, messages); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+// Package events_test contains tests for the events package.
+// These tests validate publishing events to the outbox and relaying them to Redis Streams.
+package events_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gobo/internal/db"
+	"gobo/internal/events"
+	"gobo/internal/testhelpers"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// newRelayConfig returns a relay configuration delivering to a new in-memory Redis server.
+func newRelayConfig(t *testing.T) (events.RelayConfig, *miniredis.Miniredis, *redis.Client) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	return events.RelayConfig{Client: client, MaxAttempts: 2, Backoff: time.Hour}, server, client
+}
+
+// streamIDs returns the aggregate IDs and types of the entries of a stream, in order.
+func streamIDs(t *testing.T, client *redis.Client, stream string) []string {
+	entries, err := client.XRange(context.Background(), stream, "-", "+").Result()
+	assert.NoError(t, err)
+	var ids []string
+	for _, entry := range entries {
+		ids = append(ids, entry.Values["aggregate_id"].(string)+":"+entry.Values["type"].(string))
+	}
+	return ids
+}
+
+// TestPublish verifies that events are stored with the transaction of the change, and discarded with it.
+func TestPublish(t *testing.T) {
+	testhelpers.SetupGormTestDB(t, &events.Message{})
+	defer testhelpers.TeardownGormTestDB(&events.Message{})
+
+	event := events.Event{Type: events.ExampleCreated, AggregateType: "example", AggregateID: "1", Payload: map[string]string{"name": "Example"}}
+	assert.NoError(t, db.GormDB.Transaction(func(tx *gorm.DB) error {
+		return events.Publish(tx, event)
+	}))
+	rollback := errors.New("rollback")
+	assert.ErrorIs(t, db.GormDB.Transaction(func(tx *gorm.DB) error {
+		assert.NoError(t, events.Publish(tx, event))
+		return rollback
+	}), rollback)
+
+	var messages []events.Message
+	assert.NoError(t, db.GormDB.Find(&messages).Error)
+	if assert.Len(t, messages, 1) {
+		assert.Equal(t, events.ExampleCreated, messages[0].Type)
+		assert.JSONEq(t, `{"name": "Example"}`, string(messages[0].Payload))
+		assert.Len(t, messages[0].EventID, 36)
+		assert.Nil(t, messages[0].PublishedAt)
+	}
+}
+
+// TestRelay verifies delivery to the stream of the aggregate type, in order per aggregate, with retries.
+func TestRelay(t *testing.T) {
+	testhelpers.SetupGormTestDB(t, &events.Message{})
+	defer testhelpers.TeardownGormTestDB(&events.Message{})
+	config, server, client := newRelayConfig(t)
+	ctx := context.Background()
+	var notified []string
+	config.Delivered = func(ctx context.Context, message events.Message) {
+		notified = append(notified, message.AggregateID+":"+message.Type)
+	}
+
+	publish := func(id, eventType string) {
+		event := events.Event{Type: eventType, AggregateType: "example", AggregateID: id, Payload: map[string]string{"id": id}}
+		assert.NoError(t, events.Publish(db.GormDB, event))
+	}
+	publish("1", "example.created")
+	publish("2", "example.created")
+	publish("1", "example.updated")
+
+	// Events are delivered in publication order.
+	delivered, err := events.Relay(ctx, db.GormDB, config)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, delivered)
+	assert.Equal(t, []string{"1:example.created", "2:example.created", "1:example.updated"}, streamIDs(t, client, "events:example"))
+	assert.Equal(t, streamIDs(t, client, "events:example"), notified, "Expected the delivered events to be notified")
+
+	// Nothing is delivered twice.
+	delivered, err = events.Relay(ctx, db.GormDB, config)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, delivered)
+
+	// A failed delivery is retried after the backoff, and holds back the later events of its aggregate.
+	publish("1", "example.deleted")
+	server.SetError("stream unavailable")
+	delivered, err = events.Relay(ctx, db.GormDB, config)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, delivered)
+	server.SetError("")
+	publish("1", "example.restored")
+	publish("2", "example.updated")
+	delivered, err = events.Relay(ctx, db.GormDB, config)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, delivered, "Expected only the event of the other aggregate to be delivered")
+	assert.Len(t, notified, 4, "Expected failed deliveries not to be notified")
+
+	var failed events.Message
+	assert.NoError(t, db.GormDB.Where("type = ?", "example.deleted").First(&failed).Error)
+	assert.Equal(t, 1, failed.Attempts)
+	assert.Equal(t, "stream unavailable", failed.LastError)
+	if assert.NotNil(t, failed.NextAttemptAt) {
+		assert.WithinDuration(t, time.Now().Add(time.Hour), *failed.NextAttemptAt, time.Minute)
+	}
+
+	// Once the retry is due, the aggregate's events are delivered in order.
+	assert.NoError(t, db.GormDB.Model(&failed).Update("next_attempt_at", time.Now().Add(-time.Second)).Error)
+	delivered, err = events.Relay(ctx, db.GormDB, config)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, delivered)
+	assert.Equal(t, []string{
+		"1:example.created", "2:example.created", "1:example.updated",
+		"2:example.updated", "1:example.deleted", "1:example.restored",
+	}, streamIDs(t, client, "events:example"))
+}
+
+// TestRelayAbandon verifies that delivery is abandoned after the maximum number of attempts.
+func TestRelayAbandon(t *testing.T) {
+	testhelpers.SetupGormTestDB(t, &events.Message{})
+	defer testhelpers.TeardownGormTestDB(&events.Message{})
+	config, server, _ := newRelayConfig(t)
+	config.Backoff = time.Nanosecond
+
+	assert.NoError(t, events.Publish(db.GormDB, events.Event{Type: "example.created", AggregateType: "example", AggregateID: "1"}))
+	server.SetError("stream unavailable")
+	for i := 0; i < 3; i++ {
+		time.Sleep(time.Millisecond)
+		_, err := events.Relay(context.Background(), db.GormDB, config)
+		assert.NoError(t, err)
+	}
+
+	var message events.Message
+	assert.NoError(t, db.GormDB.First(&message).Error)
+	assert.Equal(t, 2, message.Attempts, "Expected no attempt after the maximum")
+	assert.NotNil(t, message.FailedAt)
+	assert.Nil(t, message.PublishedAt)
+}
+
+// TestStartRelayLock verifies that the relay stops delivering once it lost its lock to another instance.
+func TestStartRelayLock(t *testing.T) {
+	testhelpers.SetupGormTestDB(t, &events.Message{})
+	defer testhelpers.TeardownGormTestDB(&events.Message{})
+	config, server, client := newRelayConfig(t)
+	config.Interval = 10 * time.Millisecond
+	config.BatchSize = 1
+	config.LockKey = "outbox:relay"
+	var taken atomic.Bool
+	config.Delivered = func(ctx context.Context, message events.Message) {
+		// Another instance takes the lock once, e.g. after it expired.
+		if taken.CompareAndSwap(false, true) {
+			server.Set(config.LockKey, "other")
+		}
+	}
+
+	for _, id := range []string{"1", "2", "3"} {
+		assert.NoError(t, events.Publish(db.GormDB, events.Event{Type: "example.created", AggregateType: "example", AggregateID: id}))
+	}
+	stop := events.StartRelay(db.GormDB, config)
+	defer stop()
+
+	assert.Eventually(t, func() bool {
+		return len(streamIDs(t, client, "events:example")) == 1
+	}, time.Second, 10*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	assert.Len(t, streamIDs(t, client, "events:example"), 1, "Expected no delivery without the lock")
+
+	// Once the lock is free again, the backlog is delivered.
+	server.Del(config.LockKey)
+	assert.Eventually(t, func() bool {
+		return len(streamIDs(t, client, "events:example")) == 3
+	}, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,313 @@
+// Package events publishes domain events to other services with the transactional outbox pattern.
+// This file implements the relay delivering the outbox to Redis Streams.
+package events
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strconv"
+	"time"
+
+	"gobo/internal/cache"
+	"gobo/internal/logger"
+	"gobo/internal/tenant"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// maxBackoff is the longest delay between two delivery attempts.
+const maxBackoff = time.Hour
+
+// releaseLock deletes the relay lock if it is still held by the given token.
+var releaseLock = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// extendLock extends the relay lock if it is still held by the given token.
+var extendLock = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+
+// RelayConfig defines how the outbox is delivered.
+type RelayConfig struct {
+	Interval     time.Duration         // Time between two polls of the outbox (defaults to 1 second)
+	BatchSize    int                   // Events read per poll (defaults to 100)
+	MaxAttempts  int                   // Attempts before the delivery of an event is abandoned (defaults to 10)
+	Backoff      time.Duration         // Delay before the first retry, doubled at every attempt up to 1 hour (defaults to 1 second)
+	Retention    time.Duration         // How long delivered events are kept in the outbox (defaults to 7 days)
+	StreamPrefix string                // Prefix of the streams, followed by the aggregate type (defaults to "events:")
+	MaxLen       int64                 // Approximate maximum length of a stream (defaults to 100000)
+	LockKey      string                // Redis key ensuring a single relay delivers at a time (defaults to "outbox:relay")
+	Client       redis.UniversalClient // Redis client; nil uses cache.RedisClient
+
+	// Delivered is called after an event is delivered, e.g. to push it to the connected clients (optional).
+	Delivered func(ctx context.Context, message Message)
+}
+
+// DefaultRelayConfig returns the default relay configuration.
+//
+// Defaults:
+// - Interval: 1 second, or the OUTBOX_RELAY_INTERVAL environment variable (e.g., "500ms")
+// - BatchSize: 100
+// - MaxAttempts: 10, or the OUTBOX_MAX_ATTEMPTS environment variable
+// - Backoff: 1 second
+// - Retention: 7 days, or the OUTBOX_RETENTION environment variable (e.g., "168h")
+// - StreamPrefix: "events:" (e.g., example events are added to the "events:example" stream)
+// - MaxLen: 100000
+// - LockKey: "outbox:relay"
+//
+// Returns:
+// - RelayConfig: The default relay configuration.
+func DefaultRelayConfig() RelayConfig {
+	config := RelayConfig{
+		Interval:     durationFromEnv("OUTBOX_RELAY_INTERVAL", time.Second),
+		BatchSize:    100,
+		MaxAttempts:  10,
+		Backoff:      time.Second,
+		Retention:    durationFromEnv("OUTBOX_RETENTION", 7*24*time.Hour),
+		StreamPrefix: "events:",
+		MaxLen:       100000,
+		LockKey:      "outbox:relay",
+	}
+	if value, err := strconv.Atoi(os.Getenv("OUTBOX_MAX_ATTEMPTS")); err == nil && value > 0 {
+		config.MaxAttempts = value
+	}
+	return config
+}
+
+// durationFromEnv returns the positive duration in the environment variable, or the fallback.
+func durationFromEnv(name string, fallback time.Duration) time.Duration {
+	if value := os.Getenv(name); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil && parsed > 0 {
+			return parsed
+		}
+	}
+	return fallback
+}
+
+// withDefaults returns the configuration with zero values replaced by the defaults.
+func (config RelayConfig) withDefaults() RelayConfig {
+	defaults := DefaultRelayConfig()
+	if config.Interval <= 0 {
+		config.Interval = defaults.Interval
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaults.BatchSize
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = defaults.MaxAttempts
+	}
+	if config.Backoff <= 0 {
+		config.Backoff = defaults.Backoff
+	}
+	if config.Retention <= 0 {
+		config.Retention = defaults.Retention
+	}
+	if config.StreamPrefix == "" {
+		config.StreamPrefix = defaults.StreamPrefix
+	}
+	if config.MaxLen <= 0 {
+		config.MaxLen = defaults.MaxLen
+	}
+	if config.LockKey == "" {
+		config.LockKey = defaults.LockKey
+	}
+	if config.Client == nil && cache.RedisClient != nil {
+		config.Client = cache.RedisClient
+	}
+	return config
+}
+
+// Relay delivers a batch of pending events from the outbox to Redis Streams.
+// Each event is added to the stream of its aggregate type, with the fields event_id, type,
+// aggregate_type, aggregate_id, tenant, payload (JSON) and occurred_at (RFC 3339).
+//
+// Delivery guarantees:
+// - At least once: an event is marked as delivered after it is added to the stream, so it is added
+// again if the relay stops in between. Consumers drop duplicates by event_id.
+// - In order per aggregate: an event is not delivered while an earlier event of its aggregate is pending.
+// - Failed deliveries are retried with exponential backoff; after MaxAttempts the event is abandoned,
+// so that the later events of its aggregate can be delivered.
+//
+// Parameters:
+// - ctx (context.Context): The context of the Redis commands and database statements.
+// - db (*gorm.DB): The GORM database connection instance.
+// - config (RelayConfig): The relay configuration; zero values use DefaultRelayConfig.
+//
+// Returns:
+// - int: The number of delivered events.
+// - error: An error if the outbox cannot be read or updated.
+func Relay(ctx context.Context, db *gorm.DB, config RelayConfig) (int, error) {
+	config = config.withDefaults()
+	if config.Client == nil {
+		return 0, errors.New("events: no Redis client")
+	}
+	tx := db.WithContext(ctx)
+	now := time.Now()
+
+	// Read the pending events, except those following an event of their aggregate that waits for a retry.
+	var messages []Message
+	err := tx.Where("published_at IS NULL AND failed_at IS NULL").
+		Where(`NOT EXISTS (SELECT 1 FROM outbox earlier WHERE earlier.aggregate_type = outbox.aggregate_type
+			AND earlier.aggregate_id = outbox.aggregate_id AND earlier.id < outbox.id
+			AND earlier.published_at IS NULL AND earlier.failed_at IS NULL AND earlier.next_attempt_at > ?)`, now).
+		Order("id").Limit(config.BatchSize).Find(&messages).Error
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	blocked := map[[2]string]bool{} // Aggregates with an undelivered event in this batch
+	for _, message := range messages {
+		aggregate := [2]string{message.AggregateType, message.AggregateID}
+		if blocked[aggregate] {
+			continue
+		}
+		if message.NextAttemptAt != nil && message.NextAttemptAt.After(now) {
+			blocked[aggregate] = true
+			continue
+		}
+
+		err := config.Client.XAdd(ctx, &redis.XAddArgs{
+			Stream: config.StreamPrefix + message.AggregateType,
+			MaxLen: config.MaxLen,
+			Approx: true,
+			Values: map[string]interface{}{
+				"event_id":       message.EventID,
+				"type":           message.Type,
+				"aggregate_type": message.AggregateType,
+				"aggregate_id":   message.AggregateID,
+				"tenant":         message.TenantID,
+				"payload":        string(message.Payload),
+				"occurred_at":    message.CreatedAt.UTC().Format(time.RFC3339Nano),
+			},
+		}).Err()
+		if err != nil {
+			if ctx.Err() != nil {
+				return delivered, ctx.Err()
+			}
+			blocked[aggregate] = true
+			if err := recordFailure(tx, message, err, config); err != nil {
+				return delivered, err
+			}
+			continue
+		}
+
+		if err := tx.Model(&Message{}).Where("id = ?", message.ID).Update("published_at", time.Now()).Error; err != nil {
+			return delivered, err
+		}
+		delivered++
+		if config.Delivered != nil {
+			config.Delivered(ctx, message)
+		}
+	}
+
+	// Remove the events delivered longer ago than the retention period.
+	err = tx.Where("published_at < ?", now.Add(-config.Retention)).Delete(&Message{}).Error
+	return delivered, err
+}
+
+// recordFailure schedules the next attempt of an event, or abandons it after the last attempt.
+func recordFailure(tx *gorm.DB, message Message, cause error, config RelayConfig) error {
+	attempts := message.Attempts + 1
+	updates := map[string]interface{}{"attempts": attempts, "last_error": cause.Error()}
+
+	if attempts >= config.MaxAttempts {
+		updates["failed_at"] = time.Now()
+		logger.FromContext(tx.Statement.Context).Named("outbox").Error("Abandoned event delivery",
+			zap.String("eventId", message.EventID),
+			zap.String("type", message.Type),
+			zap.Int("attempts", attempts),
+			zap.Error(cause),
+		)
+	} else {
+		backoff := config.Backoff << (attempts - 1)
+		if backoff > maxBackoff || backoff <= 0 {
+			backoff = maxBackoff
+		}
+		updates["next_attempt_at"] = time.Now().Add(backoff)
+	}
+	return tx.Model(&Message{}).Where("id = ?", message.ID).Updates(updates).Error
+}
+
+// StartRelay starts a background job delivering the outbox to Redis Streams (see Relay).
+// The job polls the outbox at every interval until it is empty, and runs until the returned function is called.
+// When several instances run, a Redis lock lets a single one deliver at a time, which keeps the order per aggregate.
+// The lock is extended after every batch; an instance that lost it stops delivering until it takes it again.
+//
+// Parameters:
+// - db (*gorm.DB): The GORM database connection instance.
+// - config (RelayConfig): The relay configuration; zero values use DefaultRelayConfig.
+//
+// Returns:
+// - func(): Stops the job and waits for a running delivery to finish.
+func StartRelay(db *gorm.DB, config RelayConfig) func() {
+	config = config.withDefaults()
+	// The outbox holds the events of every tenant.
+	ctx, cancel := context.WithCancel(tenant.Bypass(context.Background()))
+	done := make(chan struct{})
+	log := logger.FromContext(ctx).Named("outbox")
+
+	// The lock outlives a slow batch, but expires if the instance holding it dies. It is extended after every
+	// batch, so that it is held for as long as the outbox has a backlog.
+	lockTTL := max(30*time.Second, 10*config.Interval)
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(config.Interval)
+		defer ticker.Stop()
+
+		for {
+			if config.Client == nil {
+				log.Warn("Outbox relay stopped: Redis is not connected")
+				return
+			}
+			token := uuid.NewString()
+			locked, err := config.Client.SetNX(ctx, config.LockKey, token, lockTTL).Result()
+			if err != nil && ctx.Err() == nil {
+				log.Warn("Failed to acquire the outbox relay lock", zap.Error(err))
+			}
+			if locked {
+				for {
+					delivered, err := Relay(ctx, db, config)
+					if err != nil && ctx.Err() == nil {
+						log.Error("Failed to relay events", zap.Error(err))
+					}
+					if delivered > 0 {
+						log.Debug("Relayed events", zap.Int("count", delivered))
+					}
+					if err != nil || delivered < config.BatchSize {
+						break
+					}
+					extended, err := extendLock.Run(ctx, config.Client, []string{config.LockKey}, token, lockTTL.Milliseconds()).Int()
+					if err != nil || extended == 0 {
+						if ctx.Err() == nil {
+							log.Warn("Lost the outbox relay lock", zap.Error(err))
+						}
+						break
+					}
+				}
+				_ = releaseLock.Run(context.WithoutCancel(ctx), config.Client, []string{config.LockKey}, token).Err()
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
@@ -0,0 +1,139 @@
+package gql
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"gobo/internal/apperror"
+	"gobo/internal/audit"
+	"gobo/internal/i18n"
+	"gobo/internal/logger"
+	"gobo/internal/service"
+
+	"github.com/graphql-go/graphql"
+	"go.uber.org/zap"
+)
+
+// resolverError is an error of a field, returned in the "errors" of the response. Its extensions carry the
+// machine-readable code, the HTTP status the REST API returns for the same error and the invalid fields.
+type resolverError struct {
+	message    string
+	extensions map[string]interface{}
+}
+
+// Error returns the localized message.
+func (e *resolverError) Error() string {
+	return e.message
+}
+
+// Extensions returns the members of the "extensions" of the error (gqlerrors.ExtendedError).
+func (e *resolverError) Extensions() map[string]interface{} {
+	return e.extensions
+}
+
+// resolverErr converts an error of a resolver, as apperror.Handler renders the errors of the HTTP handlers as
+// problem details:
+//   - Version conflicts have the "version_conflict" code, with the current example in the "current" extension.
+//   - Any other error is converted with apperror.From; server errors are logged with their cause, which also
+//     reports them to Sentry, and their message does not expose it.
+//
+// Parameters:
+// - ctx (context.Context): The context of the request, carrying its language.
+// - err (error): The error to convert.
+//
+// Returns:
+// - error: The *resolverError.
+func resolverErr(ctx context.Context, err error) error {
+	var conflict *service.VersionConflictError
+	if errors.As(err, &conflict) {
+		err = apperror.Conflict("").
+			WithKey("error.version_conflict", i18n.Args{"version": conflict.Version, "current": conflict.Current.Version.Int64}).
+			WithCode("version_conflict").
+			WithExtension("current", service.NewExample(conflict.Current))
+	}
+
+	appErr := apperror.From(err)
+	if appErr.Status >= http.StatusInternalServerError {
+		logger.FromContext(ctx).Error("GraphQL resolver failed", zap.Error(err), zap.Int("status", appErr.Status))
+	}
+
+	language := i18n.FromContext(ctx)
+	if language == "" {
+		language = i18n.DefaultLanguage
+	}
+	message := appErr.Detail
+	if appErr.Key != "" {
+		message = i18n.T(language, appErr.Key, appErr.Args)
+	}
+
+	extensions := map[string]interface{}{}
+	for name, value := range appErr.Extensions {
+		extensions[name] = value
+	}
+	extensions["code"] = appErr.Code
+	extensions["status"] = appErr.Status
+	if len(appErr.Fields) > 0 {
+		// Localize a copy so the error itself keeps the default language.
+		fields := make([]apperror.FieldError, len(appErr.Fields))
+		for i, field := range appErr.Fields {
+			if field.Key != "" {
+				field.Message = i18n.T(language, field.Key, field.Args)
+			}
+			fields[i] = field
+		}
+		extensions["fields"] = fields
+	}
+	return &resolverError{message: message, extensions: extensions}
+}
+
+// resolve wraps a resolver, converting its errors with resolverErr.
+func resolve(fn graphql.FieldResolveFn) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		value, err := fn(p)
+		if err != nil {
+			return nil, resolverErr(p.Context, err)
+		}
+		return value, nil
+	}
+}
+
+// deferred returns the thunk of a batched field, converting its errors with resolverErr. graphql-go drops the
+// extensions of the errors returned by thunks but keeps those of the errors they panic with, so the errors are
+// raised as panics, which graphql-go recovers and reports as errors of the field.
+func deferred(ctx context.Context, fn func() (interface{}, error)) func() (interface{}, error) {
+	return func() (interface{}, error) {
+		value, err := fn()
+		if err != nil {
+			panic(resolverErr(ctx, err))
+		}
+		return value, nil
+	}
+}
+
+// authenticated returns an error unless the request is authenticated.
+// Fields reading the users or the audit log, the deleted examples and the mutations require authentication.
+func authenticated(ctx context.Context) error {
+	if audit.FromContext(ctx).User == "" {
+		return apperror.Unauthorized("").WithKey("error.unauthorized")
+	}
+	return nil
+}
+
+// parseID parses an ID argument.
+//
+// Parameters:
+// - value (interface{}): The value of the argument.
+//
+// Returns:
+// - uint: The ID.
+// - error: A bad request error (400) if the ID is not a positive integer.
+func parseID(value interface{}) (uint, error) {
+	s, _ := value.(string)
+	id, err := strconv.ParseUint(s, 10, 0)
+	if err != nil || id == 0 {
+		return 0, apperror.BadRequest("").WithKey("error.invalid_id").WithCode("invalid_id")
+	}
+	return uint(id), nil
+}
@@ -0,0 +1,164 @@
+// Package gql serves the application's GraphQL API at /graphql (see routes).
+// The schema (see schema.go) exposes the examples and users with paginated and filtered lists, and mutations to
+// create, update and delete them. The resolvers call the same service layer as the HTTP handlers and the gRPC
+// server, so all APIs apply the same validation, tenant scoping, audit log and domain events.
+// Records requested by several fields of a query (e.g., the history of every listed example) are loaded in
+// batches, one query per kind of record (see loader.go), and queries nested too deeply or selecting too many
+// records are rejected before they run (see limits.go).
+package gql
+
+import (
+	"context"
+	"os"
+	"strconv"
+
+	"gobo/internal/i18n"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/location"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+// Config defines the GraphQL API.
+type Config struct {
+	MaxDepth      int  // Maximum nesting of the selected fields (defaults to 10)
+	MaxComplexity int  // Maximum complexity of a query (defaults to 1000), see complexity
+	Playground    bool // Serve the GraphiQL playground at GET /graphql
+}
+
+// DefaultConfig returns the default GraphQL configuration.
+//
+// Defaults:
+// - MaxDepth: 10, or the GRAPHQL_MAX_DEPTH environment variable
+// - MaxComplexity: 1000, or the GRAPHQL_MAX_COMPLEXITY environment variable
+// - Playground: enabled if the APP_ENV environment variable is "development"
+//
+// Returns:
+// - Config: The default GraphQL configuration.
+func DefaultConfig() Config {
+	config := Config{
+		MaxDepth:      10,
+		MaxComplexity: 1000,
+		Playground:    os.Getenv("APP_ENV") == "development",
+	}
+	if value, err := strconv.Atoi(os.Getenv("GRAPHQL_MAX_DEPTH")); err == nil && value > 0 {
+		config.MaxDepth = value
+	}
+	if value, err := strconv.Atoi(os.Getenv("GRAPHQL_MAX_COMPLEXITY")); err == nil && value > 0 {
+		config.MaxComplexity = value
+	}
+	return config
+}
+
+// Request is a GraphQL request, as sent in the body of POST /graphql.
+type Request struct {
+	Query         string                 `json:"query" validate:"required"` // The GraphQL document.
+	OperationName string                 `json:"operationName"`             // The operation to run, if the document has several.
+	Variables     map[string]interface{} `json:"variables"`                 // The values of the variables of the operation.
+}
+
+// Executor runs GraphQL requests against the application's schema. Its methods are safe for concurrent use.
+type Executor struct {
+	config Config
+	schema graphql.Schema
+}
+
+// Default is the executor of the application's GraphQL API, created by the setup; nil until then.
+var Default *Executor
+
+// New creates an executor.
+//
+// Parameters:
+// - config (Config): The configuration; zero limits use the DefaultConfig values.
+//
+// Returns:
+// - *Executor: The executor.
+func New(config Config) *Executor {
+	if config.MaxDepth <= 0 {
+		config.MaxDepth = 10
+	}
+	if config.MaxComplexity <= 0 {
+		config.MaxComplexity = 1000
+	}
+	schema, err := newSchema()
+	if err != nil {
+		// The schema is static: an error is a programming error.
+		panic("Invalid GraphQL schema: " + err.Error())
+	}
+	return &Executor{config: config, schema: schema}
+}
+
+// Playground reports whether the GraphiQL playground is served.
+//
+// Returns:
+// - bool: True if the playground is enabled.
+func (e *Executor) Playground() bool {
+	return e.config.Playground
+}
+
+// Execute parses, validates and runs a request. The document is rejected before it runs if it exceeds the
+// depth or complexity limits. The records are loaded with loaders created for the request, so they are not
+// shared with other requests.
+//
+// Parameters:
+// - ctx (context.Context): The context of the request, carrying its tenant, user and language.
+// - req (Request): The request.
+//
+// Returns:
+// - *graphql.Result: The result; syntax, validation and limit errors are returned in its errors.
+func (e *Executor) Execute(ctx context.Context, req Request) *graphql.Result {
+	document, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{Body: []byte(req.Query), Name: "GraphQL request"}),
+	})
+	if err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+	if validation := graphql.ValidateDocument(&e.schema, document, nil); !validation.IsValid {
+		return &graphql.Result{Errors: validation.Errors}
+	}
+	if err := e.checkLimits(ctx, document, req.OperationName, req.Variables); err != nil {
+		return &graphql.Result{Errors: []gqlerrors.FormattedError{*err}}
+	}
+
+	return graphql.Execute(graphql.ExecuteParams{
+		Schema:        e.schema,
+		AST:           document,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       newLoaders().context(ctx),
+	})
+}
+
+// checkLimits measures the operation and returns an error if it exceeds a limit.
+// Operations that cannot be found are left to the executor, which reports them.
+func (e *Executor) checkLimits(ctx context.Context, document *ast.Document, operationName string, variables map[string]interface{}) *gqlerrors.FormattedError {
+	depth, complexity, ok := measure(document, operationName, variables)
+	if !ok {
+		return nil
+	}
+	language := i18n.FromContext(ctx)
+	if language == "" {
+		language = i18n.DefaultLanguage
+	}
+	switch {
+	case depth > e.config.MaxDepth:
+		return limitError(i18n.T(language, "error.query_too_deep", i18n.Args{"depth": depth, "max": e.config.MaxDepth}),
+			"query_too_deep")
+	case complexity > e.config.MaxComplexity:
+		return limitError(i18n.T(language, "error.query_too_complex", i18n.Args{"complexity": complexity, "max": e.config.MaxComplexity}),
+			"query_too_complex")
+	}
+	return nil
+}
+
+// limitError returns the error of a rejected operation, with its code in the extensions.
+func limitError(message, code string) *gqlerrors.FormattedError {
+	return &gqlerrors.FormattedError{
+		Message:    message,
+		Locations:  []location.SourceLocation{},
+		Extensions: map[string]interface{}{"code": code, "status": 400},
+	}
+}
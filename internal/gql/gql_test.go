@@ -0,0 +1,219 @@
+// Package gql contains tests for the queries and mutations of the GraphQL API, which use the test database.
+package gql
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"gobo/internal/audit"
+	"gobo/internal/db"
+	"gobo/internal/events"
+	"gobo/internal/models"
+	"gobo/internal/testhelpers"
+
+	"github.com/graphql-go/graphql"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// admin returns a context authenticated as the administrator, as the Basic authentication sets it.
+func admin() context.Context {
+	return audit.NewContext(context.Background(), audit.Actor{User: "admin"})
+}
+
+// execute runs a request with the default limits.
+func execute(ctx context.Context, query string, variables map[string]interface{}) *graphql.Result {
+	return New(Config{}).Execute(ctx, Request{Query: query, Variables: variables})
+}
+
+// data returns a member of the data of a result, following the path.
+func data(result *graphql.Result, path ...interface{}) interface{} {
+	value := result.Data
+	for _, key := range path {
+		switch key := key.(type) {
+		case string:
+			object, _ := value.(map[string]interface{})
+			value = object[key]
+		case int:
+			list, _ := value.([]interface{})
+			if key >= len(list) {
+				return nil
+			}
+			value = list[key]
+		}
+	}
+	return value
+}
+
+// code returns the code of the first error of a result.
+func code(result *graphql.Result) interface{} {
+	if len(result.Errors) == 0 {
+		return nil
+	}
+	return result.Errors[0].Extensions["code"]
+}
+
+// countQueries counts the queries of the table run until the end of the test.
+func countQueries(t *testing.T, table string) *int {
+	count := 0
+	name := "test:count_" + table
+	err := db.GormDB.Callback().Query().After("gorm:query").Register(name, func(tx *gorm.DB) {
+		// Subqueries are built with dry runs, and are not queries of their own.
+		if tx.Statement.Table == table && !tx.DryRun {
+			count++
+		}
+	})
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = db.GormDB.Callback().Query().Remove(name) })
+	return &count
+}
+
+// TestExamples validates the example queries and mutations.
+func TestExamples(t *testing.T) {
+	testhelpers.SetupGormTestDB(t, &models.Example{}, &events.Message{}, &audit.Entry{})
+	defer testhelpers.TeardownGormTestDB(&models.Example{}, &events.Message{}, &audit.Entry{})
+
+	// Mutations require authentication.
+	create := `mutation($name: String!) { createExample(input: {name: $name}) { id name version } }`
+	result := execute(context.Background(), create, map[string]interface{}{"name": "Alpha"})
+	assert.Equal(t, "unauthorized", code(result))
+	assert.Nil(t, result.Data)
+
+	ids := []string{}
+	for _, name := range []string{"Alpha", "Beta", "alphabet"} {
+		result = execute(admin(), create, map[string]interface{}{"name": name})
+		if !assert.Empty(t, result.Errors) {
+			return
+		}
+		assert.Equal(t, name, data(result, "createExample", "name"))
+		ids = append(ids, data(result, "createExample", "id").(string))
+	}
+	result = execute(admin(), create, map[string]interface{}{"name": " "})
+	assert.Equal(t, "validation_failed", code(result))
+	assert.Equal(t, 422, result.Errors[0].Extensions["status"])
+
+	// Lists are filtered and paginated; anonymous requests may read them.
+	result = execute(context.Background(), `{ examples(filter: {name: "ALP"}, pageSize: 1) { totalCount page pageSize items { name } } }`, nil)
+	assert.Empty(t, result.Errors)
+	assert.Equal(t, 2, data(result, "examples", "totalCount"))
+	assert.Equal(t, 1, data(result, "examples", "pageSize"))
+	assert.Equal(t, "Alpha", data(result, "examples", "items", 0, "name"))
+	result = execute(context.Background(), `{ examples(filter: {name: "alp"}, page: 2, pageSize: 1) { items { name } } }`, nil)
+	assert.Equal(t, "alphabet", data(result, "examples", "items", 0, "name"))
+	result = execute(context.Background(), `{ examples(pageSize: 500) { totalCount } }`, nil)
+	assert.Equal(t, "validation_failed", code(result))
+
+	// Updates require the current version.
+	update := `mutation($id: ID!, $version: Int!) { updateExample(id: $id, input: {name: "Gamma"}, version: $version) { name version } }`
+	result = execute(admin(), update, map[string]interface{}{"id": ids[1], "version": float64(1)})
+	assert.Empty(t, result.Errors)
+	assert.Equal(t, 2, data(result, "updateExample", "version"))
+	result = execute(admin(), update, map[string]interface{}{"id": ids[1], "version": float64(1)})
+	assert.Equal(t, "version_conflict", code(result))
+	if assert.NotEmpty(t, result.Errors) {
+		assert.Contains(t, fmt.Sprint(result.Errors[0].Extensions["current"]), "Gamma")
+	}
+	result = execute(admin(), update, map[string]interface{}{"id": ids[1], "version": float64(0)})
+	assert.Equal(t, "validation_failed", code(result))
+
+	// Deleted examples are not found, and are only listed for administrators.
+	result = execute(admin(), `mutation($id: ID!) { deleteExample(id: $id) }`, map[string]interface{}{"id": ids[0]})
+	assert.Equal(t, true, data(result, "deleteExample"))
+	result = execute(context.Background(), `query($id: ID!) { example(id: $id) { name } }`, map[string]interface{}{"id": ids[0]})
+	assert.Empty(t, result.Errors)
+	assert.Nil(t, data(result, "example"))
+	result = execute(context.Background(), `{ examples(filter: {includeDeleted: true}) { totalCount } }`, nil)
+	assert.Equal(t, "unauthorized", code(result))
+	result = execute(admin(), `{ examples(filter: {includeDeleted: true}) { totalCount items { deletedAt } } }`, nil)
+	assert.Equal(t, 3, data(result, "examples", "totalCount"))
+	assert.NotNil(t, data(result, "examples", "items", 0, "deletedAt"))
+
+	result = execute(context.Background(), `{ example(id: "abc") { name } }`, nil)
+	assert.Equal(t, "invalid_id", code(result))
+}
+
+// TestBatching validates that the records and histories requested by several fields are loaded with a single
+// query per table.
+func TestBatching(t *testing.T) {
+	testhelpers.SetupGormTestDB(t, &models.Example{}, &events.Message{}, &audit.Entry{})
+	defer testhelpers.TeardownGormTestDB(&models.Example{}, &events.Message{}, &audit.Entry{})
+
+	for i := 1; i <= 3; i++ {
+		example := models.Example{Name: fmt.Sprintf("Example %d", i)}
+		assert.NoError(t, db.GormDB.Create(&example).Error)
+		for _, action := range []string{audit.ActionCreate, audit.ActionUpdate} {
+			assert.NoError(t, db.GormDB.Create(&audit.Entry{
+				Entity: "examples", EntityID: fmt.Sprint(example.ID), Action: action, Actor: "admin",
+			}).Error)
+		}
+	}
+	exampleQueries := countQueries(t, "examples")
+	historyQueries := countQueries(t, "audit_logs")
+	historyRows := int64(0)
+	assert.NoError(t, db.GormDB.Callback().Query().After("gorm:query").Register("test:history_rows", func(tx *gorm.DB) {
+		if tx.Statement.Table == "audit_logs" {
+			historyRows += tx.Statement.RowsAffected
+		}
+	}))
+	defer func() { _ = db.GormDB.Callback().Query().Remove("test:history_rows") }()
+
+	// The history requires authentication.
+	query := `{ examples { items { name history(limit: 1) { action actor } } } }`
+	result := execute(context.Background(), query, nil)
+	assert.Equal(t, "unauthorized", code(result))
+
+	*historyQueries = 0
+	result = execute(admin(), query, nil)
+	assert.Empty(t, result.Errors)
+	assert.Len(t, data(result, "examples", "items"), 3)
+	assert.Equal(t, []interface{}{map[string]interface{}{"action": "update", "actor": "admin"}},
+		data(result, "examples", "items", 2, "history"))
+	assert.Equal(t, 1, *historyQueries)
+	assert.Equal(t, int64(3), historyRows, "Expected the limit to be applied by the query")
+
+	*exampleQueries = 0
+	result = execute(context.Background(), `{ a: example(id: 1) { name } b: example(id: 2) { name } c: example(id: 1) { id } }`, nil)
+	assert.Empty(t, result.Errors)
+	assert.Equal(t, "Example 2", data(result, "b", "name"))
+	assert.Equal(t, "1", data(result, "c", "id"))
+	assert.Equal(t, 1, *exampleQueries)
+}
+
+// TestUsers validates the user queries and mutations, which require authentication.
+func TestUsers(t *testing.T) {
+	testhelpers.SetupGormTestDB(t, &models.User{}, &audit.Entry{})
+	defer testhelpers.TeardownGormTestDB(&models.User{}, &audit.Entry{})
+
+	result := execute(context.Background(), `{ users { totalCount } }`, nil)
+	assert.Equal(t, "unauthorized", code(result))
+
+	create := `mutation($username: String!, $email: String!) {
+		createUser(input: {username: $username, email: $email, password: "correct horse"}) { id username }
+	}`
+	result = execute(admin(), create, map[string]interface{}{"username": "jane", "email": "jane@example.com"})
+	if !assert.Empty(t, result.Errors) {
+		return
+	}
+	id := data(result, "createUser", "id")
+	result = execute(admin(), create, map[string]interface{}{"username": "john", "email": "john@example.org"})
+	assert.Empty(t, result.Errors)
+	result = execute(admin(), create, map[string]interface{}{"username": "jane", "email": "other@example.com"})
+	assert.Equal(t, "conflict", code(result))
+
+	result = execute(admin(), `{ users(filter: {email: "example.com"}) { totalCount items { username } } }`, nil)
+	assert.Equal(t, 1, data(result, "users", "totalCount"))
+	assert.Equal(t, "jane", data(result, "users", "items", 0, "username"))
+
+	result = execute(admin(), `mutation($id: ID!) { updateUser(id: $id, input: {email: "jane@example.net"}) { email } }`,
+		map[string]interface{}{"id": id})
+	assert.Equal(t, "jane@example.net", data(result, "updateUser", "email"))
+
+	result = execute(admin(), `mutation($id: ID!) { deleteUser(id: $id) }`, map[string]interface{}{"id": id})
+	assert.Equal(t, true, data(result, "deleteUser"))
+	result = execute(admin(), `query($id: ID!) { user(id: $id) { username } }`, map[string]interface{}{"id": id})
+	assert.Empty(t, result.Errors)
+	assert.Nil(t, data(result, "user"))
+	result = execute(admin(), `mutation($id: ID!) { deleteUser(id: $id) }`, map[string]interface{}{"id": id})
+	assert.Equal(t, "not_found", code(result))
+}
@@ -0,0 +1,155 @@
+package gql
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// maxMeasure caps the list sizes and complexities while measuring, so that their products cannot overflow.
+const maxMeasure = math.MaxInt32
+
+// listArgument is the argument bounding the number of items returned by a list field.
+type listArgument struct {
+	name         string // Name of the argument (e.g., "pageSize")
+	defaultValue int    // Value of the argument when it is omitted
+}
+
+// listFields are the fields returning lists, by name: their selections are counted once per returned item.
+var listFields = map[string]listArgument{
+	"examples": {name: "pageSize", defaultValue: defaultPageSize},
+	"users":    {name: "pageSize", defaultValue: defaultPageSize},
+	"history":  {name: "limit", defaultValue: defaultHistoryLimit},
+}
+
+// measure returns the depth and the complexity of the operation of a validated document:
+//   - The depth is the largest number of nested fields; top-level fields have a depth of 1.
+//   - The complexity estimates the number of resolved fields: every field counts 1, and the selections of list
+//     fields (see listFields) count once per requested item.
+//
+// Fragments are expanded where they are spread, and measured once: documents spreading fragments in fragments
+// are measured in a time proportional to their size. Introspection fields (e.g., "__schema") are not counted, so
+// that tools can always load the schema.
+//
+// Parameters:
+// - document (*ast.Document): The validated document.
+// - operationName (string): The name of the operation to run; empty if the document has a single operation.
+// - variables (map[string]interface{}): The values of the variables, which may set list sizes.
+//
+// Returns:
+// - int: The depth of the operation.
+// - int: The complexity of the operation.
+// - bool: False if the operation is not found.
+func measure(document *ast.Document, operationName string, variables map[string]interface{}) (int, int, bool) {
+	var operation *ast.OperationDefinition
+	operations := 0
+	fragments := map[string]*ast.FragmentDefinition{}
+	for _, definition := range document.Definitions {
+		switch definition := definition.(type) {
+		case *ast.OperationDefinition:
+			operations++
+			if operationName == "" || (definition.Name != nil && definition.Name.Value == operationName) {
+				operation = definition
+			}
+		case *ast.FragmentDefinition:
+			fragments[definition.Name.Value] = definition
+		}
+	}
+	if operation == nil || (operationName == "" && operations > 1) {
+		return 0, 0, false
+	}
+
+	m := &measurer{fragments: fragments, variables: variables, measured: map[string]measurement{}}
+	depth, complexity := m.selectionSet(operation.SelectionSet)
+	return depth, complexity, true
+}
+
+// measurement is the depth and complexity of a fragment.
+type measurement struct {
+	depth, complexity int
+}
+
+// measurer walks the selections of an operation.
+type measurer struct {
+	fragments map[string]*ast.FragmentDefinition // Fragments of the document, by name
+	variables map[string]interface{}             // Values of the variables
+	measured  map[string]measurement             // Fragments already measured, by name
+}
+
+// selectionSet returns the depth and complexity of a selection set.
+func (m *measurer) selectionSet(set *ast.SelectionSet) (int, int) {
+	if set == nil {
+		return 0, 0
+	}
+	depth, complexity := 0, 0
+	for _, selection := range set.Selections {
+		var d, c int
+		switch selection := selection.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(selection.Name.Value, "__") {
+				continue
+			}
+			d, c = m.selectionSet(selection.SelectionSet)
+			d, c = d+1, min(1+c*m.items(selection), maxMeasure)
+		case *ast.InlineFragment:
+			d, c = m.selectionSet(selection.SelectionSet)
+		case *ast.FragmentSpread:
+			d, c = m.fragment(selection.Name.Value)
+		}
+		if d > depth {
+			depth = d
+		}
+		complexity = min(complexity+c, maxMeasure)
+	}
+	return depth, complexity
+}
+
+// fragment returns the depth and complexity of a fragment, measuring it the first time it is spread.
+// Validated documents have no fragment cycles; a fragment spread in itself counts 0.
+func (m *measurer) fragment(name string) (int, int) {
+	if measured, ok := m.measured[name]; ok {
+		return measured.depth, measured.complexity
+	}
+	fragment, ok := m.fragments[name]
+	if !ok {
+		return 0, 0
+	}
+	m.measured[name] = measurement{}
+	d, c := m.selectionSet(fragment.SelectionSet)
+	m.measured[name] = measurement{depth: d, complexity: c}
+	return d, c
+}
+
+// items returns the number of items a field may return: the value of its list argument, or 1.
+func (m *measurer) items(field *ast.Field) int {
+	list, ok := listFields[field.Name.Value]
+	if !ok {
+		return 1
+	}
+	for _, argument := range field.Arguments {
+		if argument.Name.Value != list.name {
+			continue
+		}
+		switch value := argument.Value.(type) {
+		case *ast.IntValue:
+			if n, err := strconv.Atoi(value.Value); err == nil && n > 0 {
+				return min(n, maxMeasure)
+			}
+		case *ast.Variable:
+			// Variables decoded from JSON are float64.
+			switch n := m.variables[value.Name.Value].(type) {
+			case float64:
+				if n > 0 {
+					return int(min(n, maxMeasure))
+				}
+			case int:
+				if n > 0 {
+					return min(n, maxMeasure)
+				}
+			}
+		}
+	}
+	return list.defaultValue
+}
@@ -0,0 +1,107 @@
+// Package gql contains tests for the depth and complexity limits, which reject queries before they run.
+package gql
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"gobo/internal/i18n"
+
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMeasure validates the depth and complexity of operations.
+func TestMeasure(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		operation  string
+		variables  map[string]interface{}
+		depth      int
+		complexity int
+	}{
+		{"scalar fields", `{ example(id: 1) { id name } }`, "", nil, 2, 3},
+		{"default list sizes", `{ examples { items { history { action } } } }`, "", nil, 4, 1 + 20*(1+1+10)},
+		{"list size arguments", `{ examples(pageSize: 5) { totalCount items { id } } }`, "", nil, 3, 1 + 5*(1+2)},
+		{"list size variables", `query($size: Int) { examples(pageSize: $size) { items { id } } }`, "", map[string]interface{}{"size": float64(2)}, 3, 1 + 2*2},
+		{"fragments", `{ example(id: 1) { ...fields ... on Example { version } } } fragment fields on Example { id name }`, "", nil, 2, 4},
+		{"introspection", `{ __schema { types { name } } example(id: 1) { __typename id } }`, "", nil, 2, 2},
+		{"named operation", `query A { example(id: 1) { id } } query B { examples { totalCount } }`, "B", nil, 2, 1 + 20},
+	}
+	for _, test := range tests {
+		document, err := parser.Parse(parser.ParseParams{Source: test.query})
+		if !assert.NoError(t, err, test.name) {
+			continue
+		}
+		depth, complexity, ok := measure(document, test.operation, test.variables)
+		assert.True(t, ok, test.name)
+		assert.Equal(t, test.depth, depth, test.name)
+		assert.Equal(t, test.complexity, complexity, test.name)
+	}
+
+	// Fragments spreading the next one twice are measured once each, instead of 2^n times.
+	query := `{ example(id: 1) { ...f0 } } fragment f40 on Example { id }`
+	for i := 0; i < 40; i++ {
+		query += fmt.Sprintf(" fragment f%d on Example { ...f%d ... on Example { ...f%d } }", i, i+1, i+1)
+	}
+	document, err := parser.Parse(parser.ParseParams{Source: query})
+	assert.NoError(t, err)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		depth, complexity, ok := measure(document, "", nil)
+		assert.True(t, ok)
+		assert.Equal(t, 2, depth)
+		assert.Equal(t, maxMeasure, complexity)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("measuring nested fragments did not finish")
+	}
+
+	// Ambiguous or missing operations are left to the executor.
+	document, _ = parser.Parse(parser.ParseParams{Source: `query A { example(id: 1) { id } } query B { example(id: 2) { id } }`})
+	_, _, ok := measure(document, "", nil)
+	assert.False(t, ok)
+	_, _, ok = measure(document, "C", nil)
+	assert.False(t, ok)
+}
+
+// TestLimits validates that queries exceeding the limits are rejected with a localized error, and that the
+// schema can be introspected.
+func TestLimits(t *testing.T) {
+	executor := New(Config{MaxDepth: 3, MaxComplexity: 100})
+	ctx := i18n.NewContext(context.Background(), "tr")
+
+	result := executor.Execute(ctx, Request{Query: `{ examples { items { history { action } } } }`})
+	if assert.Len(t, result.Errors, 1) {
+		assert.Equal(t, "query_too_deep", result.Errors[0].Extensions["code"])
+		assert.Equal(t, i18n.T("tr", "error.query_too_deep", i18n.Args{"depth": 4, "max": 3}), result.Errors[0].Message)
+	}
+	assert.Nil(t, result.Data)
+
+	result = executor.Execute(ctx, Request{
+		Query:     `query($size: Int) { examples(pageSize: $size) { items { id name } } }`,
+		Variables: map[string]interface{}{"size": float64(50)},
+	})
+	if assert.Len(t, result.Errors, 1) {
+		assert.Equal(t, "query_too_complex", result.Errors[0].Extensions["code"])
+	}
+
+	// Syntax and validation errors are returned before the limits are checked.
+	result = executor.Execute(ctx, Request{Query: `{ example(id: 1) {`})
+	assert.Len(t, result.Errors, 1)
+	result = executor.Execute(ctx, Request{Query: `{ example(id: 1) { password } }`})
+	if assert.Len(t, result.Errors, 1) {
+		assert.Contains(t, result.Errors[0].Message, "password")
+	}
+
+	// Introspection is not limited.
+	result = executor.Execute(ctx, Request{Query: `{ __schema { queryType { fields { name args { name type { name } } } } } }`})
+	assert.Empty(t, result.Errors)
+	assert.NotNil(t, result.Data)
+}
@@ -0,0 +1,141 @@
+package gql
+
+import (
+	"context"
+	"sync"
+
+	"gobo/internal/audit"
+	"gobo/internal/models"
+	"gobo/internal/service"
+)
+
+// loader loads records by key in batches, DataLoader style: load queues a key and returns a thunk, and the first
+// thunk called fetches all the queued keys at once. graphql-go calls the thunks of a level of the query after
+// resolving all its fields, so the records requested by the items of a list are fetched with a single query
+// instead of one per item. The records are cached for the request. Its methods are safe for concurrent use.
+type loader[K comparable, V any] struct {
+	fetch   func(ctx context.Context, keys []K) (map[K]V, error) // Fetches the records; missing keys are left out
+	mu      sync.Mutex
+	queued  []K         // Keys to fetch with the next batch
+	loaded  map[K]bool  // Fetched (or queued) keys
+	results map[K]V     // Fetched records, by key
+	errors  map[K]error // Errors of the batches that failed, by key
+	batches int         // Number of fetched batches
+}
+
+// newLoader creates a loader.
+//
+// Parameters:
+// - fetch (func(ctx context.Context, keys []K) (map[K]V, error)): Fetches the records of a batch of keys.
+//
+// Returns:
+// - *loader[K, V]: The loader.
+func newLoader[K comparable, V any](fetch func(ctx context.Context, keys []K) (map[K]V, error)) *loader[K, V] {
+	return &loader[K, V]{fetch: fetch, loaded: map[K]bool{}, results: map[K]V{}, errors: map[K]error{}}
+}
+
+// load queues a key, unless it was already loaded or queued, and returns the thunk returning its record.
+//
+// Parameters:
+// - ctx (context.Context): The context of the request, used to fetch the batch.
+// - key (K): The key of the record.
+//
+// Returns:
+// - func() (V, bool, error): Returns the record, whether it exists, and the error of its batch.
+func (l *loader[K, V]) load(ctx context.Context, key K) func() (V, bool, error) {
+	l.mu.Lock()
+	if !l.loaded[key] {
+		l.loaded[key] = true
+		l.queued = append(l.queued, key)
+	}
+	l.mu.Unlock()
+
+	return func() (V, bool, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if len(l.queued) > 0 {
+			l.dispatch(ctx)
+		}
+		value, ok := l.results[key]
+		return value, ok, l.errors[key]
+	}
+}
+
+// dispatch fetches the queued keys; the lock must be held.
+func (l *loader[K, V]) dispatch(ctx context.Context) {
+	keys := l.queued
+	l.queued = nil
+	l.batches++
+	results, err := l.fetch(ctx, keys)
+	for _, key := range keys {
+		if err != nil {
+			l.errors[key] = err
+		} else if value, ok := results[key]; ok {
+			l.results[key] = value
+		}
+	}
+}
+
+// record identifies an audited record, whose history is loaded.
+type record struct {
+	entity string // The table of the record (e.g., "examples")
+	id     string // The primary key of the record
+	limit  int    // The maximum number of entries loaded
+}
+
+// loaders are the loaders of a request.
+type loaders struct {
+	examples *loader[uint, models.Example]  // Examples by ID
+	users    *loader[uint, models.User]     // Users by ID
+	history  *loader[record, []audit.Entry] // Audit log entries of records, newest first
+}
+
+// loadersKey is the context key under which the loaders of the request are stored.
+type loadersKey struct{}
+
+// newLoaders creates the loaders of a request, fetching the records with the service layer.
+func newLoaders() *loaders {
+	return &loaders{
+		examples: newLoader(service.ExamplesByID),
+		users:    newLoader(service.UsersByID),
+		history:  newLoader(fetchHistory),
+	}
+}
+
+// context returns a copy of the context carrying the loaders.
+func (l *loaders) context(ctx context.Context) context.Context {
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+// loadersFrom returns the loaders of the request; the context of a request without loaders gets new ones,
+// which do not batch across fields.
+func loadersFrom(ctx context.Context) *loaders {
+	if l, ok := ctx.Value(loadersKey{}).(*loaders); ok {
+		return l
+	}
+	return newLoaders()
+}
+
+// fetchHistory fetches the history of records, with one query per table and limit.
+func fetchHistory(ctx context.Context, records []record) (map[record][]audit.Entry, error) {
+	type batch struct {
+		entity string
+		limit  int
+	}
+	ids := map[batch][]string{}
+	for _, r := range records {
+		key := batch{entity: r.entity, limit: r.limit}
+		ids[key] = append(ids[key], r.id)
+	}
+	history := make(map[record][]audit.Entry, len(records))
+	for key, entityIDs := range ids {
+		entries, err := service.History(ctx, key.entity, entityIDs, key.limit)
+		if err != nil {
+			return nil, err
+		}
+		for id, recordEntries := range entries {
+			history[record{entity: key.entity, id: id, limit: key.limit}] = recordEntries
+		}
+	}
+	return history, nil
+}
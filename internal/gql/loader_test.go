@@ -0,0 +1,67 @@
+// Package gql contains tests for the batching of the loaders.
+package gql
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestLoader validates that the keys queued before a thunk is called are fetched in a single batch, and that
+// the loaded records are cached.
+func TestLoader(t *testing.T) {
+	ctx := context.Background()
+	batches := [][]int{}
+	l := newLoader(func(ctx context.Context, keys []int) (map[int]string, error) {
+		batches = append(batches, keys)
+		records := map[int]string{}
+		for _, key := range keys {
+			if key != 404 {
+				records[key] = "record"
+			}
+		}
+		return records, nil
+	})
+
+	first := l.load(ctx, 1)
+	second := l.load(ctx, 2)
+	again := l.load(ctx, 1)
+	missing := l.load(ctx, 404)
+	assert.Empty(t, batches, "keys are only fetched when a thunk is called")
+
+	value, ok, err := second()
+	assert.Equal(t, "record", value)
+	assert.True(t, ok)
+	assert.NoError(t, err)
+	_, ok, _ = missing()
+	assert.False(t, ok)
+	_, ok, _ = first()
+	assert.True(t, ok)
+	_, ok, _ = again()
+	assert.True(t, ok)
+	assert.Equal(t, [][]int{{1, 2, 404}}, batches)
+
+	// Loaded keys are not fetched again; new keys are fetched with the next batch.
+	_, _, _ = l.load(ctx, 1)()
+	_, _, _ = l.load(ctx, 3)()
+	assert.Equal(t, [][]int{{1, 2, 404}, {3}}, batches)
+}
+
+// TestLoaderError validates that the error of a batch is returned for each of its keys.
+func TestLoaderError(t *testing.T) {
+	failure := errors.New("connection refused")
+	l := newLoader(func(ctx context.Context, keys []int) (map[int]string, error) {
+		return nil, failure
+	})
+	first := l.load(context.Background(), 1)
+	second := l.load(context.Background(), 2)
+
+	_, ok, err := first()
+	assert.False(t, ok)
+	assert.Equal(t, failure, err)
+	_, _, err = second()
+	assert.Equal(t, failure, err)
+	assert.Equal(t, 1, l.batches)
+}
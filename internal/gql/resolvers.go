@@ -0,0 +1,259 @@
+package gql
+
+import (
+	"strconv"
+
+	"gobo/internal/service"
+	"gobo/internal/validation"
+
+	"github.com/graphql-go/graphql"
+)
+
+// historyArgs are the arguments of the history fields.
+type historyArgs struct {
+	Limit int `json:"limit" validate:"min=1,max=100"`
+}
+
+// versionArgs are the arguments of the updateExample mutation besides its input.
+type versionArgs struct {
+	Version int64 `json:"version" validate:"min=1"`
+}
+
+// validateArgs validates arguments that the schema cannot express (e.g., ranges).
+//
+// Parameters:
+// - args (interface{}): The arguments, with validation tags named after the GraphQL arguments.
+//
+// Returns:
+// - error: A validation error (422) listing the invalid arguments.
+func validateArgs(args interface{}) error {
+	return validation.Struct(args)
+}
+
+// intArg returns an Int argument or input field; missing values are zero.
+func intArg(args map[string]interface{}, name string) int {
+	value, _ := args[name].(int)
+	return value
+}
+
+// stringArg returns a String argument or input field; missing values are empty.
+func stringArg(args map[string]interface{}, name string) string {
+	value, _ := args[name].(string)
+	return value
+}
+
+// boolArg returns a Boolean argument or input field; missing values are false.
+func boolArg(args map[string]interface{}, name string) bool {
+	value, _ := args[name].(bool)
+	return value
+}
+
+// objectArg returns an input object argument; missing values are empty.
+func objectArg(args map[string]interface{}, name string) map[string]interface{} {
+	value, _ := args[name].(map[string]interface{})
+	return value
+}
+
+// formatID formats an ID as the audit log stores it.
+func formatID(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+// pageOf returns the page requested by the arguments of a list field.
+func pageOf(args map[string]interface{}) service.Page {
+	return service.Page{Number: intArg(args, "page"), Size: intArg(args, "pageSize")}
+}
+
+// resolveExamples returns a page of the examples of the tenant; deleted examples require authentication.
+func resolveExamples(p graphql.ResolveParams) (interface{}, error) {
+	filterArgs := objectArg(p.Args, "filter")
+	filter := service.ExampleFilter{
+		Name:           stringArg(filterArgs, "name"),
+		IncludeDeleted: boolArg(filterArgs, "includeDeleted"),
+	}
+	if filter.IncludeDeleted {
+		if err := authenticated(p.Context); err != nil {
+			return nil, err
+		}
+	}
+
+	requested := pageOf(p.Args)
+	examples, total, err := service.FindExamples(p.Context, filter, requested)
+	if err != nil {
+		return nil, err
+	}
+	result := page[service.Example]{
+		Items:      make([]service.Example, len(examples)),
+		TotalCount: total,
+		Page:       requested.Number,
+		PageSize:   requested.Size,
+	}
+	for i, example := range examples {
+		result.Items[i] = service.NewExample(example)
+	}
+	return result, nil
+}
+
+// resolveExample returns an example of the tenant, loaded with the other examples of the query.
+func resolveExample(p graphql.ResolveParams) (interface{}, error) {
+	id, err := parseID(p.Args["id"])
+	if err != nil {
+		return nil, err
+	}
+	thunk := loadersFrom(p.Context).examples.load(p.Context, id)
+	return deferred(p.Context, func() (interface{}, error) {
+		example, ok, err := thunk()
+		if err != nil || !ok {
+			return nil, err
+		}
+		return service.NewExample(example), nil
+	}), nil
+}
+
+// resolveUsers returns a page of the users of the tenant.
+func resolveUsers(p graphql.ResolveParams) (interface{}, error) {
+	if err := authenticated(p.Context); err != nil {
+		return nil, err
+	}
+	filterArgs := objectArg(p.Args, "filter")
+	filter := service.UserFilter{
+		Username: stringArg(filterArgs, "username"),
+		Email:    stringArg(filterArgs, "email"),
+	}
+
+	requested := pageOf(p.Args)
+	users, total, err := service.FindUsers(p.Context, filter, requested)
+	if err != nil {
+		return nil, err
+	}
+	result := page[user]{
+		Items:      make([]user, len(users)),
+		TotalCount: total,
+		Page:       requested.Number,
+		PageSize:   requested.Size,
+	}
+	for i, u := range users {
+		result.Items[i] = newUser(u)
+	}
+	return result, nil
+}
+
+// resolveUser returns a user of the tenant, loaded with the other users of the query.
+func resolveUser(p graphql.ResolveParams) (interface{}, error) {
+	if err := authenticated(p.Context); err != nil {
+		return nil, err
+	}
+	id, err := parseID(p.Args["id"])
+	if err != nil {
+		return nil, err
+	}
+	thunk := loadersFrom(p.Context).users.load(p.Context, id)
+	return deferred(p.Context, func() (interface{}, error) {
+		u, ok, err := thunk()
+		if err != nil || !ok {
+			return nil, err
+		}
+		return newUser(u), nil
+	}), nil
+}
+
+// resolveCreateExample creates an example.
+func resolveCreateExample(p graphql.ResolveParams) (interface{}, error) {
+	if err := authenticated(p.Context); err != nil {
+		return nil, err
+	}
+	input := objectArg(p.Args, "input")
+	example, err := service.CreateExample(p.Context, service.ExampleInput{Name: stringArg(input, "name")})
+	if err != nil {
+		return nil, err
+	}
+	return service.NewExample(example), nil
+}
+
+// resolveUpdateExample renames an example if it still has the given version.
+func resolveUpdateExample(p graphql.ResolveParams) (interface{}, error) {
+	if err := authenticated(p.Context); err != nil {
+		return nil, err
+	}
+	id, err := parseID(p.Args["id"])
+	if err != nil {
+		return nil, err
+	}
+	args := versionArgs{Version: int64(intArg(p.Args, "version"))}
+	if err := validateArgs(args); err != nil {
+		return nil, err
+	}
+	input := objectArg(p.Args, "input")
+	example, err := service.UpdateExample(p.Context, id, service.ExampleInput{Name: stringArg(input, "name")}, args.Version)
+	if err != nil {
+		return nil, err
+	}
+	return service.NewExample(example), nil
+}
+
+// resolveDeleteExample soft deletes an example.
+func resolveDeleteExample(p graphql.ResolveParams) (interface{}, error) {
+	if err := authenticated(p.Context); err != nil {
+		return nil, err
+	}
+	id, err := parseID(p.Args["id"])
+	if err != nil {
+		return nil, err
+	}
+	if err := service.DeleteExample(p.Context, id); err != nil {
+		return nil, err
+	}
+	return true, nil
+}
+
+// resolveCreateUser creates a user.
+func resolveCreateUser(p graphql.ResolveParams) (interface{}, error) {
+	if err := authenticated(p.Context); err != nil {
+		return nil, err
+	}
+	input := objectArg(p.Args, "input")
+	u, err := service.CreateUser(p.Context, service.CreateUserInput{
+		Username: stringArg(input, "username"),
+		Email:    stringArg(input, "email"),
+		Password: stringArg(input, "password"),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newUser(u), nil
+}
+
+// resolveUpdateUser updates the email address and, optionally, the password of a user.
+func resolveUpdateUser(p graphql.ResolveParams) (interface{}, error) {
+	if err := authenticated(p.Context); err != nil {
+		return nil, err
+	}
+	id, err := parseID(p.Args["id"])
+	if err != nil {
+		return nil, err
+	}
+	input := objectArg(p.Args, "input")
+	u, err := service.UpdateUser(p.Context, id, service.UpdateUserInput{
+		Email:    stringArg(input, "email"),
+		Password: stringArg(input, "password"),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newUser(u), nil
+}
+
+// resolveDeleteUser deletes a user.
+func resolveDeleteUser(p graphql.ResolveParams) (interface{}, error) {
+	if err := authenticated(p.Context); err != nil {
+		return nil, err
+	}
+	id, err := parseID(p.Args["id"])
+	if err != nil {
+		return nil, err
+	}
+	if err := service.DeleteUser(p.Context, id); err != nil {
+		return nil, err
+	}
+	return true, nil
+}
@@ -0,0 +1,303 @@
+package gql
+
+import (
+	"time"
+
+	"gobo/internal/audit"
+	"gobo/internal/models"
+	"gobo/internal/service"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// Default sizes of the list fields.
+const (
+	defaultPageSize     = 20 // Items of a page of examples or users (the "pageSize" argument)
+	defaultHistoryLimit = 10 // Entries of the history of a record (the "limit" argument)
+)
+
+// page is a page of a list, with the total number of matching items.
+type page[T any] struct {
+	Items      []T   // The items of the page
+	TotalCount int64 // The number of matching items
+	Page       int   // The 1-based page number
+	PageSize   int   // The maximum number of items of the page
+}
+
+// user is the representation of a user; the password hash is never returned.
+type user struct {
+	ID        uint
+	Username  string
+	Email     string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Version   int64
+}
+
+// newUser converts a user model into its representation.
+func newUser(u models.User) user {
+	return user{
+		ID:        u.ID,
+		Username:  u.Username,
+		Email:     u.Email,
+		CreatedAt: u.CreatedAt,
+		UpdatedAt: u.UpdatedAt,
+		Version:   u.Version.Int64,
+	}
+}
+
+// jsonScalar is an arbitrary JSON value, returned as is (e.g., the changed columns of the audit log).
+var jsonScalar = graphql.NewScalar(graphql.ScalarConfig{
+	Name:        "JSON",
+	Description: "An arbitrary JSON value.",
+	Serialize:   func(value interface{}) interface{} { return value },
+	ParseValue:  func(value interface{}) interface{} { return value },
+	// JSON values are only returned, never accepted as arguments.
+	ParseLiteral: func(value ast.Value) interface{} { return nil },
+})
+
+// newSchema builds the schema of the GraphQL API.
+//
+// Returns:
+// - graphql.Schema: The schema.
+// - error: An error if the schema is invalid.
+func newSchema() (graphql.Schema, error) {
+	changeType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Change",
+		Description: "A change of a record, from the audit log.",
+		Fields: graphql.Fields{
+			"action":    &graphql.Field{Type: graphql.NewNonNull(graphql.String), Description: "create, update, delete or restore."},
+			"actor":     &graphql.Field{Type: graphql.String, Description: "The username of the user who made the change."},
+			"requestId": &graphql.Field{Type: graphql.String, Description: "The ID of the request that made the change."},
+			"ip":        &graphql.Field{Type: graphql.String, Description: "The IP address of the client."},
+			"createdAt": &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime), Description: "When the change was made."},
+			"before":    &graphql.Field{Type: jsonScalar, Description: "The changed columns before the change."},
+			"after":     &graphql.Field{Type: jsonScalar, Description: "The changed columns after the change."},
+		},
+	})
+
+	exampleType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Example",
+		Description: "An example.",
+		Fields: graphql.Fields{
+			"id":        &graphql.Field{Type: graphql.NewNonNull(graphql.ID), Description: "The ID of the example."},
+			"name":      &graphql.Field{Type: graphql.NewNonNull(graphql.String), Description: "The name of the example."},
+			"createdAt": &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime), Description: "When the example was created."},
+			"updatedAt": &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime), Description: "When the example was last updated."},
+			"deletedAt": &graphql.Field{Type: graphql.DateTime, Description: "When the example was soft deleted; null if it is not deleted."},
+			"version":   &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Description: "The version of the example, incremented on every update."},
+			"history": historyField(changeType, "examples", func(source interface{}) uint {
+				return source.(service.Example).ID
+			}),
+		},
+	})
+
+	userType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "User",
+		Description: "A user.",
+		Fields: graphql.Fields{
+			"id":        &graphql.Field{Type: graphql.NewNonNull(graphql.ID), Description: "The ID of the user."},
+			"username":  &graphql.Field{Type: graphql.NewNonNull(graphql.String), Description: "The username, unique within the tenant."},
+			"email":     &graphql.Field{Type: graphql.NewNonNull(graphql.String), Description: "The email address, unique within the tenant."},
+			"createdAt": &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime), Description: "When the user was created."},
+			"updatedAt": &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime), Description: "When the user was last updated."},
+			"version":   &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Description: "The version of the user, incremented on every update."},
+			"history": historyField(changeType, "users", func(source interface{}) uint {
+				return source.(user).ID
+			}),
+		},
+	})
+
+	exampleFilterType := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name:        "ExampleFilter",
+		Description: "Restricts the listed examples; omitted fields do not filter.",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"name":           &graphql.InputObjectFieldConfig{Type: graphql.String, Description: "Case-insensitive substring of the name."},
+			"includeDeleted": &graphql.InputObjectFieldConfig{Type: graphql.Boolean, Description: "Include the soft-deleted examples (requires authentication)."},
+		},
+	})
+	userFilterType := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name:        "UserFilter",
+		Description: "Restricts the listed users; omitted fields do not filter.",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"username": &graphql.InputObjectFieldConfig{Type: graphql.String, Description: "Case-insensitive substring of the username."},
+			"email":    &graphql.InputObjectFieldConfig{Type: graphql.String, Description: "Case-insensitive substring of the email address."},
+		},
+	})
+	exampleInputType := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name:        "ExampleInput",
+		Description: "The fields of a created or renamed example.",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"name": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String), Description: "The name of the example."},
+		},
+	})
+	createUserInputType := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name:        "CreateUserInput",
+		Description: "The fields of a created user.",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"username": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String), Description: "The username."},
+			"email":    &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String), Description: "The email address."},
+			"password": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String), Description: "The password, at least 8 characters long."},
+		},
+	})
+	updateUserInputType := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name:        "UpdateUserInput",
+		Description: "The fields of an updated user.",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"email":    &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String), Description: "The email address."},
+			"password": &graphql.InputObjectFieldConfig{Type: graphql.String, Description: "The new password; omitted keeps the current one."},
+		},
+	})
+
+	pageArgs := func(filterType *graphql.InputObject) graphql.FieldConfigArgument {
+		return graphql.FieldConfigArgument{
+			"filter":   &graphql.ArgumentConfig{Type: filterType, Description: "The conditions."},
+			"page":     &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 1, Description: "The 1-based page number."},
+			"pageSize": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultPageSize, Description: "The items per page (at most 100)."},
+		}
+	}
+	idArgs := graphql.FieldConfigArgument{
+		"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+	}
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"examples": &graphql.Field{
+				Type:        graphql.NewNonNull(pageType("ExamplePage", exampleType)),
+				Description: "A page of the examples of the tenant, by ID.",
+				Args:        pageArgs(exampleFilterType),
+				Resolve:     resolve(resolveExamples),
+			},
+			"example": &graphql.Field{
+				Type:        exampleType,
+				Description: "An example of the tenant; null if it does not exist or is deleted.",
+				Args:        idArgs,
+				Resolve:     resolve(resolveExample),
+			},
+			"users": &graphql.Field{
+				Type:        graphql.NewNonNull(pageType("UserPage", userType)),
+				Description: "A page of the users of the tenant, by ID (requires authentication).",
+				Args:        pageArgs(userFilterType),
+				Resolve:     resolve(resolveUsers),
+			},
+			"user": &graphql.Field{
+				Type:        userType,
+				Description: "A user of the tenant; null if it does not exist (requires authentication).",
+				Args:        idArgs,
+				Resolve:     resolve(resolveUser),
+			},
+		},
+	})
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createExample": &graphql.Field{
+				Type:        graphql.NewNonNull(exampleType),
+				Description: "Creates an example.",
+				Args: graphql.FieldConfigArgument{
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(exampleInputType)},
+				},
+				Resolve: resolve(resolveCreateExample),
+			},
+			"updateExample": &graphql.Field{
+				Type:        graphql.NewNonNull(exampleType),
+				Description: "Renames an example if it still has the given version.",
+				Args: graphql.FieldConfigArgument{
+					"id":      &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+					"input":   &graphql.ArgumentConfig{Type: graphql.NewNonNull(exampleInputType)},
+					"version": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int), Description: "The version being updated."},
+				},
+				Resolve: resolve(resolveUpdateExample),
+			},
+			"deleteExample": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.Boolean),
+				Description: "Soft deletes an example.",
+				Args:        idArgs,
+				Resolve:     resolve(resolveDeleteExample),
+			},
+			"createUser": &graphql.Field{
+				Type:        graphql.NewNonNull(userType),
+				Description: "Creates a user.",
+				Args: graphql.FieldConfigArgument{
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(createUserInputType)},
+				},
+				Resolve: resolve(resolveCreateUser),
+			},
+			"updateUser": &graphql.Field{
+				Type:        graphql.NewNonNull(userType),
+				Description: "Updates the email address and, optionally, the password of a user.",
+				Args: graphql.FieldConfigArgument{
+					"id":    &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(updateUserInputType)},
+				},
+				Resolve: resolve(resolveUpdateUser),
+			},
+			"deleteUser": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.Boolean),
+				Description: "Deletes a user.",
+				Args:        idArgs,
+				Resolve:     resolve(resolveDeleteUser),
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query, Mutation: mutation})
+}
+
+// pageType returns the type of a page of items.
+func pageType(name string, itemType *graphql.Object) *graphql.Object {
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name:        name,
+		Description: "A page of a list.",
+		Fields: graphql.Fields{
+			"items":      &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(itemType))), Description: "The items of the page."},
+			"totalCount": &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Description: "The number of matching items."},
+			"page":       &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Description: "The 1-based page number."},
+			"pageSize":   &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Description: "The maximum number of items of the page."},
+		},
+	})
+}
+
+// historyField returns the field listing the changes of a record, newest first (requires authentication).
+// The histories of all the records of a query are loaded together.
+//
+// Parameters:
+// - changeType (*graphql.Object): The Change type.
+// - entity (string): The table of the records (e.g., "examples").
+// - id (func(source interface{}) uint): Returns the ID of the record of the field.
+//
+// Returns:
+// - *graphql.Field: The field.
+func historyField(changeType *graphql.Object, entity string, id func(source interface{}) uint) *graphql.Field {
+	return &graphql.Field{
+		Type:        graphql.NewList(graphql.NewNonNull(changeType)),
+		Description: "The changes of the record, newest first (requires authentication).",
+		Args: graphql.FieldConfigArgument{
+			"limit": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultHistoryLimit, Description: "The maximum number of changes (at most 100)."},
+		},
+		Resolve: resolve(func(p graphql.ResolveParams) (interface{}, error) {
+			if err := authenticated(p.Context); err != nil {
+				return nil, err
+			}
+			args := historyArgs{Limit: intArg(p.Args, "limit")}
+			if err := validateArgs(args); err != nil {
+				return nil, err
+			}
+			key := record{entity: entity, id: formatID(id(p.Source)), limit: args.Limit}
+			thunk := loadersFrom(p.Context).history.load(p.Context, key)
+			return deferred(p.Context, func() (interface{}, error) {
+				entries, _, err := thunk()
+				if err != nil {
+					return nil, err
+				}
+				if entries == nil {
+					entries = []audit.Entry{}
+				}
+				return entries, nil
+			}), nil
+		}),
+	}
+}
@@ -0,0 +1,265 @@
+// Package i18n provides the message catalogs used to localize user-facing strings.
+// Messages are identified by keys (e.g., "error.invalid_request_body") and may contain
+// named placeholders such as {field}. A message can also define plural forms, selected
+// by the "count" argument according to the language's plural rules.
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Supported languages.
+const (
+	English = "en"
+	Turkish = "tr"
+)
+
+// DefaultLanguage is used when the client does not accept any supported language.
+// It is also the language of error details written to logs.
+const DefaultLanguage = English
+
+// Args holds the values of a message's named placeholders.
+type Args map[string]interface{}
+
+// message is a catalog entry: either a single text or one text per plural category.
+type message struct {
+	text   string
+	plural map[string]string // Plural category ("one", "other") to text
+}
+
+// UnmarshalJSON accepts either a string or an object of plural forms.
+func (m *message) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &m.text); err == nil {
+		return nil
+	}
+	return json.Unmarshal(data, &m.plural)
+}
+
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+var (
+	catalogs     map[string]map[string]message // Language to key to message
+	catalogsOnce sync.Once
+)
+
+// pluralRules return the plural category of a count for each language (CLDR cardinal rules).
+var pluralRules = map[string]func(n int) string{
+	English: func(n int) string {
+		if n == 1 {
+			return "one"
+		}
+		return "other"
+	},
+	Turkish: func(n int) string {
+		if n == 1 {
+			return "one"
+		}
+		return "other"
+	},
+}
+
+// pluralCategory returns the plural category of a count in a language. Languages without a rule (e.g., a locale
+// file added without one) use the rule of the default language.
+func pluralCategory(language string, count int) string {
+	rule, ok := pluralRules[language]
+	if !ok {
+		rule = pluralRules[DefaultLanguage]
+	}
+	return rule(count)
+}
+
+// loadCatalogs parses the embedded locale files (one file per language, e.g. locales/tr.json).
+func loadCatalogs() map[string]map[string]message {
+	catalogsOnce.Do(func() {
+		catalogs = map[string]map[string]message{}
+		files, err := localeFiles.ReadDir("locales")
+		if err != nil {
+			panic(fmt.Sprintf("i18n: failed to read locales: %v", err))
+		}
+		for _, file := range files {
+			content, err := localeFiles.ReadFile(path.Join("locales", file.Name()))
+			if err != nil {
+				panic(fmt.Sprintf("i18n: failed to read %s: %v", file.Name(), err))
+			}
+			var catalog map[string]message
+			if err := json.Unmarshal(content, &catalog); err != nil {
+				panic(fmt.Sprintf("i18n: failed to parse %s: %v", file.Name(), err))
+			}
+			catalogs[strings.TrimSuffix(file.Name(), path.Ext(file.Name()))] = catalog
+		}
+	})
+	return catalogs
+}
+
+// Languages returns the supported languages, sorted.
+//
+// Returns:
+// - []string: The language codes (e.g., ["en", "tr"]).
+func Languages() []string {
+	languages := make([]string, 0, len(loadCatalogs()))
+	for language := range loadCatalogs() {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// Has reports whether the default catalog defines the given key.
+func Has(key string) bool {
+	_, ok := loadCatalogs()[DefaultLanguage][key]
+	return ok
+}
+
+// T returns the message for the key in the given language with its placeholders replaced.
+// Missing translations fall back to the default language, and unknown keys are returned as they are.
+// If the message has plural forms, the "count" argument selects the form.
+//
+// Parameters:
+// - language (string): The language code (e.g., "tr").
+// - key (string): The message key (e.g., "error.rate_limited").
+// - args (Args): Optional placeholder values (e.g., Args{"count": 30}).
+//
+// Returns:
+// - string: The localized message.
+func T(language, key string, args ...Args) string {
+	var values Args
+	if len(args) > 0 {
+		values = args[0]
+	}
+
+	msg, ok := loadCatalogs()[language][key]
+	if !ok {
+		language = DefaultLanguage
+		if msg, ok = loadCatalogs()[DefaultLanguage][key]; !ok {
+			return key
+		}
+	}
+
+	text := msg.text
+	if msg.plural != nil {
+		category := "other"
+		if count, ok := toInt(values["count"]); ok {
+			category = pluralCategory(language, count)
+		}
+		if text, ok = msg.plural[category]; !ok {
+			text = msg.plural["other"]
+		}
+	}
+	return format(text, values)
+}
+
+// format replaces the {name} placeholders of a text with their values.
+func format(text string, args Args) string {
+	if len(args) == 0 || !strings.Contains(text, "{") {
+		return text
+	}
+	replacements := make([]string, 0, len(args)*2)
+	for name, value := range args {
+		replacements = append(replacements, "{"+name+"}", fmt.Sprint(value))
+	}
+	return strings.NewReplacer(replacements...).Replace(text)
+}
+
+// toInt converts a count argument to an int.
+func toInt(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case uint64:
+		return int(v), true
+	case string:
+		n, err := strconv.Atoi(v)
+		return n, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// Negotiate selects the best supported language for an Accept-Language header.
+// Languages are matched by their primary subtag ("tr-TR" matches "tr") in order of their quality values.
+//
+// Parameters:
+// - acceptLanguage (string): The Accept-Language header value (e.g., "tr-TR,tr;q=0.9,en;q=0.8").
+//
+// Returns:
+// - string: The selected language, or DefaultLanguage if none is supported.
+func Negotiate(acceptLanguage string) string {
+	type candidate struct {
+		language string
+		quality  float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" {
+			continue
+		}
+		quality := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		if quality <= 0 {
+			continue
+		}
+		primary, _, _ := strings.Cut(strings.ToLower(tag), "-")
+		candidates = append(candidates, candidate{language: primary, quality: quality})
+	}
+	// Keep the header order for equal qualities.
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].quality > candidates[j].quality })
+
+	for _, c := range candidates {
+		if c.language == "*" {
+			return DefaultLanguage
+		}
+		if _, ok := loadCatalogs()[c.language]; ok {
+			return c.language
+		}
+	}
+	return DefaultLanguage
+}
+
+// contextKey is the type of the context key under which the request language is stored.
+type contextKey struct{}
+
+// NewContext returns a copy of the context carrying the given language.
+//
+// Parameters:
+// - ctx (context.Context): The parent context.
+// - language (string): The negotiated language.
+//
+// Returns:
+// - context.Context: The derived context.
+func NewContext(ctx context.Context, language string) context.Context {
+	return context.WithValue(ctx, contextKey{}, language)
+}
+
+// FromContext returns the language stored in the context.
+//
+// Parameters:
+// - ctx (context.Context): The context, typically the request's user context.
+//
+// Returns:
+// - string: The language, or an empty string if none was negotiated.
+func FromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	language, _ := ctx.Value(contextKey{}).(string)
+	return language
+}
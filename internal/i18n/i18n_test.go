@@ -0,0 +1,65 @@
+// Package i18n_test contains tests for the i18n package.
+// These tests validate message lookup, pluralization and language negotiation.
+package i18n_test
+
+import (
+	"context"
+	"testing"
+
+	"gobo/internal/i18n"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestT verifies placeholders, fallbacks and unknown keys.
+func TestT(t *testing.T) {
+	assert.Equal(t, "name is required", i18n.T(i18n.English, "validation.required", i18n.Args{"field": "name"}))
+	assert.Equal(t, "name alanı zorunludur", i18n.T(i18n.Turkish, "validation.required", i18n.Args{"field": "name"}))
+
+	// Unsupported languages fall back to the default language.
+	assert.Equal(t, "Invalid request body", i18n.T("de", "error.invalid_request_body"))
+
+	// Unknown keys are returned as they are.
+	assert.Equal(t, "error.unknown", i18n.T(i18n.Turkish, "error.unknown"))
+}
+
+// TestT_Plural verifies that the count argument selects the plural form.
+func TestT_Plural(t *testing.T) {
+	assert.Equal(t, "Rate limit exceeded. Try again in 1 second.", i18n.T(i18n.English, "error.rate_limited_retry", i18n.Args{"count": 1}))
+	assert.Equal(t, "Rate limit exceeded. Try again in 30 seconds.", i18n.T(i18n.English, "error.rate_limited_retry", i18n.Args{"count": 30}))
+	assert.Equal(t, "name must be at most 100 characters long", i18n.T(i18n.English, "validation.max_length", i18n.Args{"field": "name", "count": "100"}))
+	assert.Equal(t, "İstek sınırı aşıldı. 30 saniye sonra tekrar deneyin.", i18n.T(i18n.Turkish, "error.rate_limited_retry", i18n.Args{"count": 30}))
+}
+
+// TestCatalogsComplete verifies that every supported language defines every key of the default catalog.
+func TestCatalogsComplete(t *testing.T) {
+	assert.Equal(t, []string{i18n.English, i18n.Turkish}, i18n.Languages())
+	for _, key := range []string{"status.404", "error.internal", "error.invalid_fields", "validation.oneof"} {
+		assert.True(t, i18n.Has(key), key)
+		assert.NotEqual(t, key, i18n.T(i18n.Turkish, key), key)
+	}
+}
+
+// TestNegotiate verifies Accept-Language negotiation.
+func TestNegotiate(t *testing.T) {
+	tests := map[string]string{
+		"":                          i18n.DefaultLanguage,
+		"tr":                        i18n.Turkish,
+		"tr-TR,tr;q=0.9,en;q=0.8":   i18n.Turkish,
+		"de-DE, en;q=0.5, tr;q=0.7": i18n.Turkish,
+		"fr, de":                    i18n.DefaultLanguage,
+		"EN-us":                     i18n.English,
+		"tr;q=0, en;q=0.1":          i18n.English,
+		"*":                         i18n.DefaultLanguage,
+		"en;q=invalid, tr;q=0.3":    i18n.Turkish,
+	}
+	for header, expected := range tests {
+		assert.Equal(t, expected, i18n.Negotiate(header), header)
+	}
+}
+
+// TestContext verifies that the language is carried by the context.
+func TestContext(t *testing.T) {
+	assert.Equal(t, "", i18n.FromContext(context.Background()))
+	assert.Equal(t, i18n.Turkish, i18n.FromContext(i18n.NewContext(context.Background(), i18n.Turkish)))
+}
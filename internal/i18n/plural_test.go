@@ -0,0 +1,16 @@
+// Package i18n contains tests for the internal helpers of the i18n package, such as the plural rules.
+package i18n
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestPluralCategory verifies that languages without a plural rule use the rule of the default language.
+func TestPluralCategory(t *testing.T) {
+	assert.Equal(t, "one", pluralCategory(Turkish, 1))
+	assert.Equal(t, "other", pluralCategory(Turkish, 2))
+	assert.Equal(t, "one", pluralCategory("de", 1))
+	assert.Equal(t, "other", pluralCategory("de", 0))
+}
@@ -0,0 +1,530 @@
+// Package jobs runs work outside the request path with a job queue stored in Redis.
+// Jobs are enqueued with Queue.Enqueue, processed by typed handlers (see Register) in a worker pool
+// (see Queue.Start), retried with exponential backoff and moved to a dead-letter queue after their last attempt.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"gobo/internal/cache"
+	"gobo/internal/tenant"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// Job priorities: jobs with a higher priority run first; jobs of equal priority run in enqueue order.
+const (
+	PriorityLow     = -5
+	PriorityDefault = 0
+	PriorityHigh    = 5
+	maxPriority     = 10 // Priorities are clamped to [-maxPriority, maxPriority]
+)
+
+// Job states.
+const (
+	StateQueued    = "queued"    // Ready to run
+	StateScheduled = "scheduled" // Waiting for its delay or retry backoff
+	StateActive    = "active"    // Being processed by a worker
+	StateDead      = "dead"      // Failed its last attempt (dead-letter queue)
+)
+
+// Errors returned by the queue.
+var (
+	ErrDuplicate   = errors.New("jobs: a job with the same unique key is pending")
+	ErrNotFound    = errors.New("jobs: job not found")
+	ErrUnavailable = errors.New("jobs: Redis is not connected")
+)
+
+// Default is the application's job queue, set up at startup.
+var Default *Queue
+
+// Actor is the actor of the changes made by jobs in the audit log.
+const Actor = "system:jobs"
+
+// Job is a unit of work stored in the queue.
+type Job struct {
+	ID          string          `json:"id"`                   // Unique ID (UUID)
+	Type        string          `json:"type"`                 // Type selecting the handler (e.g., "email.send")
+	Payload     json.RawMessage `json:"payload"`              // Arguments of the handler (JSON)
+	Priority    int             `json:"priority"`             // Higher priorities run first
+	Attempts    int             `json:"attempts"`             // Failed attempts so far
+	MaxAttempts int             `json:"max_attempts"`         // Attempts before the job is moved to the dead-letter queue
+	UniqueKey   string          `json:"unique_key,omitempty"` // Key preventing duplicates while the job is pending
+	Tenant      string          `json:"tenant,omitempty"`     // Tenant of the request that enqueued the job
+	EnqueuedAt  time.Time       `json:"enqueued_at"`          // Enqueue time
+	RunAt       time.Time       `json:"run_at"`               // Earliest time of the next attempt
+	LastError   string          `json:"last_error,omitempty"` // Error of the last failed attempt
+	FailedAt    *time.Time      `json:"failed_at,omitempty"`  // Time the job was moved to the dead-letter queue
+}
+
+// Options defines how a job is enqueued. Zero values use the defaults.
+type Options struct {
+	Delay       time.Duration // Time to wait before the first attempt
+	Priority    int           // Priority (e.g., PriorityHigh); defaults to PriorityDefault
+	MaxAttempts int           // Attempts before the job is dead; defaults to Config.MaxAttempts
+	UniqueKey   string        // If set, the job is rejected with ErrDuplicate while a job of the same type and key is pending
+}
+
+// Config defines the queue and its workers.
+type Config struct {
+	Prefix       string                // Prefix of the Redis keys (defaults to "jobs:")
+	Concurrency  int                   // Number of workers (defaults to 10)
+	MaxAttempts  int                   // Default attempts of a job (defaults to 5)
+	Backoff      time.Duration         // Delay before the first retry, doubled at every attempt up to 1 hour (defaults to 1 second)
+	Timeout      time.Duration         // Maximum duration of an attempt; longer attempts are canceled and retried (defaults to 5 minutes)
+	PollInterval time.Duration         // Time between two polls of an idle worker (defaults to 1 second)
+	UniqueTTL    time.Duration         // Maximum time a unique key is held (defaults to 24 hours)
+	DeadTTL      time.Duration         // How long dead jobs are kept (defaults to 7 days)
+	Client       redis.UniversalClient // Redis client; nil uses cache.RedisClient
+}
+
+// DefaultConfig returns the default queue configuration.
+//
+// Defaults:
+// - Prefix: "jobs:"
+// - Concurrency: 10, or the JOBS_CONCURRENCY environment variable
+// - MaxAttempts: 5, or the JOBS_MAX_ATTEMPTS environment variable
+// - Backoff: 1 second
+// - Timeout: 5 minutes, or the JOBS_TIMEOUT environment variable (e.g., "10m")
+// - PollInterval: 1 second
+// - UniqueTTL: 24 hours
+// - DeadTTL: 7 days, or the JOBS_DEAD_TTL environment variable (e.g., "168h")
+//
+// Returns:
+// - Config: The default queue configuration.
+func DefaultConfig() Config {
+	return Config{
+		Prefix:       "jobs:",
+		Concurrency:  intFromEnv("JOBS_CONCURRENCY", 10),
+		MaxAttempts:  intFromEnv("JOBS_MAX_ATTEMPTS", 5),
+		Backoff:      time.Second,
+		Timeout:      durationFromEnv("JOBS_TIMEOUT", 5*time.Minute),
+		PollInterval: time.Second,
+		UniqueTTL:    24 * time.Hour,
+		DeadTTL:      durationFromEnv("JOBS_DEAD_TTL", 7*24*time.Hour),
+	}
+}
+
+// intFromEnv returns the positive integer in the environment variable, or the fallback.
+func intFromEnv(name string, fallback int) int {
+	if value, err := strconv.Atoi(os.Getenv(name)); err == nil && value > 0 {
+		return value
+	}
+	return fallback
+}
+
+// durationFromEnv returns the positive duration in the environment variable, or the fallback.
+func durationFromEnv(name string, fallback time.Duration) time.Duration {
+	if value := os.Getenv(name); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil && parsed > 0 {
+			return parsed
+		}
+	}
+	return fallback
+}
+
+// HandlerFunc processes a job. Returning an error retries the job, unless it is wrapped with Permanent.
+type HandlerFunc func(ctx context.Context, job *Job) error
+
+// Queue is a job queue stored in Redis. Its methods are safe for concurrent use.
+//
+// Redis keys (with the configured prefix):
+// - job:<id>: the job (JSON).
+// - queue: sorted set of the jobs ready to run, by priority and enqueue time.
+// - scheduled: sorted set of the delayed jobs and jobs waiting for a retry, by run time.
+// - active: sorted set of the jobs being processed, by the deadline of the attempt.
+// - dead: sorted set of the dead jobs, by failure time.
+// - unique:<tenant>:<type>:<key>: the ID of the pending job holding a unique key.
+type Queue struct {
+	config   Config
+	mu       sync.RWMutex
+	handlers map[string]HandlerFunc
+}
+
+// New creates a job queue. Register the handlers, then start the workers with Start.
+//
+// Parameters:
+// - config (Config): The queue configuration; zero values use DefaultConfig.
+//
+// Returns:
+// - *Queue: The queue.
+func New(config Config) *Queue {
+	defaults := DefaultConfig()
+	if config.Prefix == "" {
+		config.Prefix = defaults.Prefix
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = defaults.Concurrency
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = defaults.MaxAttempts
+	}
+	if config.Backoff <= 0 {
+		config.Backoff = defaults.Backoff
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaults.Timeout
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = defaults.PollInterval
+	}
+	if config.UniqueTTL <= 0 {
+		config.UniqueTTL = defaults.UniqueTTL
+	}
+	if config.DeadTTL <= 0 {
+		config.DeadTTL = defaults.DeadTTL
+	}
+	return &Queue{config: config, handlers: map[string]HandlerFunc{}}
+}
+
+// client returns the Redis client of the queue.
+func (q *Queue) client() (redis.UniversalClient, error) {
+	if q.config.Client != nil {
+		return q.config.Client, nil
+	}
+	if cache.RedisClient != nil {
+		return cache.RedisClient, nil
+	}
+	return nil, ErrUnavailable
+}
+
+// key returns the Redis key with the queue's prefix.
+func (q *Queue) key(parts ...string) string {
+	key := q.config.Prefix
+	for i, part := range parts {
+		if i > 0 {
+			key += ":"
+		}
+		key += part
+	}
+	return key
+}
+
+// Handle registers the handler of a job type, replacing any previous handler.
+//
+// Parameters:
+// - jobType (string): The job type (e.g., "email.send").
+// - handler (HandlerFunc): The handler.
+func (q *Queue) Handle(jobType string, handler HandlerFunc) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.handlers[jobType] = handler
+}
+
+// handler returns the handler of a job type.
+func (q *Queue) handler(jobType string) (HandlerFunc, bool) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	handler, ok := q.handlers[jobType]
+	return handler, ok
+}
+
+// Register registers a typed handler: the payload of the jobs is decoded into T before calling it.
+// Jobs whose payload cannot be decoded fail permanently.
+//
+// Parameters:
+// - q (*Queue): The queue.
+// - jobType (string): The job type (e.g., "email.send").
+// - handler (func(context.Context, T) error): The handler.
+func Register[T any](q *Queue, jobType string, handler func(ctx context.Context, payload T) error) {
+	q.Handle(jobType, func(ctx context.Context, job *Job) error {
+		var payload T
+		if err := json.Unmarshal(job.Payload, &payload); err != nil {
+			return Permanent(fmt.Errorf("decoding payload: %w", err))
+		}
+		return handler(ctx, payload)
+	})
+}
+
+// permanentError marks an error that must not be retried.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps an error returned by a handler so that the job is not retried but moved to the dead-letter queue
+// (e.g., for invalid payloads).
+//
+// Parameters:
+// - err (error): The error.
+//
+// Returns:
+// - error: The wrapped error.
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// Enqueue adds a job to the queue. The tenant of the context is stored with the job and restored
+// when the job runs, so that its database queries are scoped to the same tenant. Jobs enqueued without a
+// tenant bypass the row-level security policies (see tenant.Bypass).
+//
+// Parameters:
+// - ctx (context.Context): The context, typically c.UserContext().
+// - jobType (string): The job type (e.g., "email.send").
+// - payload (interface{}): The arguments of the handler, encoded as JSON.
+// - options (Options): The delay, priority, attempts and unique key of the job.
+//
+// Returns:
+// - *Job: The enqueued job.
+// - error: ErrDuplicate if a job with the same unique key is pending, or an error if Redis fails.
+func (q *Queue) Enqueue(ctx context.Context, jobType string, payload interface{}, options Options) (*Job, error) {
+	client, err := q.client()
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("jobs: encoding %s: %w", jobType, err)
+	}
+	if options.MaxAttempts <= 0 {
+		options.MaxAttempts = q.config.MaxAttempts
+	}
+	now := time.Now()
+	job := &Job{
+		ID:          uuid.NewString(),
+		Type:        jobType,
+		Payload:     data,
+		Priority:    min(max(options.Priority, -maxPriority), maxPriority),
+		MaxAttempts: options.MaxAttempts,
+		UniqueKey:   options.UniqueKey,
+		Tenant:      tenant.FromContext(ctx),
+		EnqueuedAt:  now,
+		RunAt:       now.Add(options.Delay),
+	}
+
+	encoded, err := json.Marshal(job)
+	if err != nil {
+		return nil, err
+	}
+	if job.UniqueKey != "" {
+		claimed, err := client.SetNX(ctx, q.uniqueKey(job), job.ID, q.config.UniqueTTL).Result()
+		if err != nil {
+			return nil, err
+		}
+		if !claimed {
+			return nil, ErrDuplicate
+		}
+	}
+	_, err = client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, q.key("job", job.ID), encoded, 0)
+		if options.Delay > 0 {
+			pipe.ZAdd(ctx, q.key("scheduled"), redis.Z{Score: float64(job.RunAt.UnixMilli()), Member: job.ID})
+		} else {
+			pipe.ZAdd(ctx, q.key("queue"), redis.Z{Score: readyScore(job.Priority, now), Member: job.ID})
+		}
+		return nil
+	})
+	if err != nil {
+		// The job was not stored: release its unique key, so that it can be enqueued again.
+		if job.UniqueKey != "" {
+			client.Del(ctx, q.uniqueKey(job))
+		}
+		return nil, err
+	}
+	return job, nil
+}
+
+// readyScore orders the ready jobs by priority, then by time.
+func readyScore(priority int, at time.Time) float64 {
+	return float64(maxPriority-priority)*1e13 + float64(at.UnixMilli())
+}
+
+// uniqueKey returns the Redis key holding the unique key of a job.
+func (q *Queue) uniqueKey(job *Job) string {
+	return q.key("unique", job.Tenant, job.Type, job.UniqueKey)
+}
+
+// Stats holds the number of jobs in each state.
+type Stats struct {
+	Queued    int64 `json:"queued"`    // Jobs ready to run
+	Scheduled int64 `json:"scheduled"` // Delayed jobs and jobs waiting for a retry
+	Active    int64 `json:"active"`    // Jobs being processed
+	Dead      int64 `json:"dead"`      // Jobs in the dead-letter queue
+}
+
+// Stats returns the number of jobs in each state.
+//
+// Parameters:
+// - ctx (context.Context): The context of the Redis commands.
+//
+// Returns:
+// - Stats: The job counts.
+// - error: An error if Redis fails.
+func (q *Queue) Stats(ctx context.Context) (Stats, error) {
+	client, err := q.client()
+	if err != nil {
+		return Stats{}, err
+	}
+	var queued, scheduled, active, dead *redis.IntCmd
+	_, err = client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		queued = pipe.ZCard(ctx, q.key("queue"))
+		scheduled = pipe.ZCard(ctx, q.key("scheduled"))
+		active = pipe.ZCard(ctx, q.key("active"))
+		dead = pipe.ZCard(ctx, q.key("dead"))
+		return nil
+	})
+	if err != nil {
+		return Stats{}, err
+	}
+	return Stats{Queued: queued.Val(), Scheduled: scheduled.Val(), Active: active.Val(), Dead: dead.Val()}, nil
+}
+
+// Get returns a job and its state.
+//
+// Parameters:
+// - ctx (context.Context): The context of the Redis commands.
+// - id (string): The job ID.
+//
+// Returns:
+// - *Job: The job.
+// - string: The state of the job (e.g., StateDead).
+// - error: ErrNotFound if the job does not exist (e.g., it completed), or an error if Redis fails.
+func (q *Queue) Get(ctx context.Context, id string) (*Job, string, error) {
+	client, err := q.client()
+	if err != nil {
+		return nil, "", err
+	}
+	job, err := q.load(ctx, client, id)
+	if err != nil {
+		return nil, "", err
+	}
+	for _, state := range []string{StateActive, StateQueued, StateScheduled, StateDead} {
+		if err := client.ZScore(ctx, q.key(setOf(state)), id).Err(); err == nil {
+			return job, state, nil
+		} else if !errors.Is(err, redis.Nil) {
+			return nil, "", err
+		}
+	}
+	return job, StateQueued, nil // Being moved back to the queue (see Retry).
+}
+
+// setOf returns the sorted set holding the jobs of a state.
+func setOf(state string) string {
+	if state == StateQueued {
+		return "queue"
+	}
+	return state
+}
+
+// Dead returns a page of the dead-letter queue, most recently failed first.
+//
+// Parameters:
+// - ctx (context.Context): The context of the Redis commands.
+// - offset (int): The number of jobs to skip.
+// - limit (int): The maximum number of jobs to return.
+//
+// Returns:
+// - []Job: The dead jobs.
+// - int64: The number of dead jobs.
+// - error: An error if Redis fails.
+func (q *Queue) Dead(ctx context.Context, offset, limit int) ([]Job, int64, error) {
+	client, err := q.client()
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := client.ZCard(ctx, q.key("dead")).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+	ids, err := client.ZRevRange(ctx, q.key("dead"), int64(offset), int64(offset+limit-1)).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+	jobs := make([]Job, 0, len(ids))
+	for _, id := range ids {
+		job, err := q.load(ctx, client, id)
+		if errors.Is(err, ErrNotFound) {
+			continue // Expired.
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		jobs = append(jobs, *job)
+	}
+	return jobs, total, nil
+}
+
+// requeue moves a dead job back to the ready queue, and claims its unique key for the given time (0 if the job
+// has none). It returns 0 if the job is not dead, and -1 if the unique key is held by another job.
+var requeue = redis.NewScript(`
+if not redis.call("ZSCORE", KEYS[1], ARGV[1]) then
+	return 0
+end
+if ARGV[4] ~= "0" and not redis.call("SET", KEYS[4], ARGV[1], "NX", "PX", ARGV[4]) then
+	return -1
+end
+redis.call("ZREM", KEYS[1], ARGV[1])
+redis.call("SET", KEYS[3], ARGV[2])
+redis.call("ZADD", KEYS[2], ARGV[3], ARGV[1])
+return 1`)
+
+// Retry moves a dead job back to the queue, with its attempts reset.
+//
+// Parameters:
+// - ctx (context.Context): The context of the Redis commands.
+// - id (string): The job ID.
+//
+// Returns:
+// - *Job: The queued job.
+// - error: ErrNotFound if the job is not dead, ErrDuplicate if a job with the same unique key is pending,
+// or an error if Redis fails.
+func (q *Queue) Retry(ctx context.Context, id string) (*Job, error) {
+	client, err := q.client()
+	if err != nil {
+		return nil, err
+	}
+	job, err := q.load(ctx, client, id)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	job.Attempts = 0
+	job.FailedAt = nil
+	job.RunAt = now
+	encoded, err := json.Marshal(job)
+	if err != nil {
+		return nil, err
+	}
+	// The unique key was released when the job died: it is claimed again, once the job is known to be dead.
+	var ttl int64
+	if job.UniqueKey != "" {
+		ttl = q.config.UniqueTTL.Milliseconds()
+	}
+	keys := []string{q.key("dead"), q.key("queue"), q.key("job", id), q.uniqueKey(job)}
+	requeued, err := requeue.Run(ctx, client, keys, id, encoded, readyScore(job.Priority, now), ttl).Int()
+	switch {
+	case err != nil:
+		return nil, err
+	case requeued == 0:
+		return nil, ErrNotFound
+	case requeued < 0:
+		return nil, ErrDuplicate
+	}
+	return job, nil
+}
+
+// load reads a job.
+func (q *Queue) load(ctx context.Context, client redis.UniversalClient, id string) (*Job, error) {
+	data, err := client.Get(ctx, q.key("job", id)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var job Job
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
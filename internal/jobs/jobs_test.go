@@ -0,0 +1,266 @@
+// Package jobs_test contains tests for the jobs package.
+// These tests validate enqueuing jobs, processing them with workers, retries and the dead-letter queue.
+package jobs_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gobo/internal/audit"
+	"gobo/internal/jobs"
+	"gobo/internal/tenant"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+// newQueue returns a queue stored in a new in-memory Redis server, with fast polling.
+func newQueue(t *testing.T, config jobs.Config) (*jobs.Queue, *redis.Client) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	config.Client = client
+	config.PollInterval = 10 * time.Millisecond
+	return jobs.New(config), client
+}
+
+// start starts the workers of the queue until the end of the test.
+func start(t *testing.T, queue *jobs.Queue) {
+	stop := queue.Start()
+	t.Cleanup(func() { assert.NoError(t, stop(context.Background())) })
+}
+
+// email is the payload of the jobs of the tests.
+type email struct {
+	To string `json:"to"`
+}
+
+// TestEnqueue verifies the state of enqueued jobs and unique keys.
+func TestEnqueue(t *testing.T) {
+	queue, _ := newQueue(t, jobs.Config{MaxAttempts: 3})
+	ctx := tenant.NewContext(context.Background(), "acme")
+
+	job, err := queue.Enqueue(ctx, "email.send", email{To: "user@example.com"}, jobs.Options{})
+	assert.NoError(t, err)
+	assert.Len(t, job.ID, 36)
+	assert.Equal(t, "acme", job.Tenant)
+	assert.Equal(t, 3, job.MaxAttempts)
+	assert.JSONEq(t, `{"to": "user@example.com"}`, string(job.Payload))
+
+	delayed, err := queue.Enqueue(ctx, "email.send", email{}, jobs.Options{Delay: time.Hour})
+	assert.NoError(t, err)
+	stored, state, err := queue.Get(ctx, delayed.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, jobs.StateScheduled, state)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), stored.RunAt, time.Minute)
+
+	// A unique key is held while its job is pending, per tenant.
+	_, err = queue.Enqueue(ctx, "email.send", email{}, jobs.Options{UniqueKey: "welcome:1"})
+	assert.NoError(t, err)
+	_, err = queue.Enqueue(ctx, "email.send", email{}, jobs.Options{UniqueKey: "welcome:1"})
+	assert.ErrorIs(t, err, jobs.ErrDuplicate)
+	_, err = queue.Enqueue(tenant.NewContext(ctx, "globex"), "email.send", email{}, jobs.Options{UniqueKey: "welcome:1"})
+	assert.NoError(t, err)
+
+	stats, err := queue.Stats(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, jobs.Stats{Queued: 3, Scheduled: 1}, stats)
+
+	_, _, err = queue.Get(ctx, "missing")
+	assert.ErrorIs(t, err, jobs.ErrNotFound)
+
+	// Only dead jobs can be retried, whether or not they have a unique key.
+	pending, err := queue.Enqueue(ctx, "email.send", email{}, jobs.Options{UniqueKey: "welcome:2"})
+	assert.NoError(t, err)
+	_, err = queue.Retry(ctx, pending.ID)
+	assert.ErrorIs(t, err, jobs.ErrNotFound)
+	_, err = queue.Retry(ctx, job.ID)
+	assert.ErrorIs(t, err, jobs.ErrNotFound)
+}
+
+// TestEnqueueFailure verifies that the unique key of a job that could not be stored is released.
+func TestEnqueueFailure(t *testing.T) {
+	queue, client := newQueue(t, jobs.Config{})
+	ctx := context.Background()
+
+	// The queue holds a value of another type, so that adding the job to it fails.
+	assert.NoError(t, client.Set(ctx, "jobs:queue", "broken", 0).Err())
+	_, err := queue.Enqueue(ctx, "email.send", email{}, jobs.Options{UniqueKey: "welcome:1"})
+	assert.Error(t, err)
+
+	assert.NoError(t, client.Del(ctx, "jobs:queue").Err())
+	_, err = queue.Enqueue(ctx, "email.send", email{}, jobs.Options{UniqueKey: "welcome:1"})
+	assert.NoError(t, err)
+}
+
+// TestWorkers verifies that jobs run by priority, with the tenant and actor of the enqueuing request.
+func TestWorkers(t *testing.T) {
+	queue, client := newQueue(t, jobs.Config{Concurrency: 1})
+	ctx := tenant.NewContext(context.Background(), "acme")
+
+	ran := make(chan string, 3)
+	jobs.Register(queue, "email.send", func(ctx context.Context, payload email) error {
+		assert.Equal(t, "acme", tenant.FromContext(ctx))
+		assert.Equal(t, jobs.Actor, audit.FromContext(ctx).User)
+		ran <- payload.To
+		return nil
+	})
+
+	_, err := queue.Enqueue(ctx, "email.send", email{To: "low"}, jobs.Options{Priority: jobs.PriorityLow})
+	assert.NoError(t, err)
+	_, err = queue.Enqueue(ctx, "email.send", email{To: "default"}, jobs.Options{UniqueKey: "once"})
+	assert.NoError(t, err)
+	_, err = queue.Enqueue(ctx, "email.send", email{To: "high"}, jobs.Options{Priority: jobs.PriorityHigh})
+	assert.NoError(t, err)
+	start(t, queue)
+
+	for _, expected := range []string{"high", "default", "low"} {
+		select {
+		case to := <-ran:
+			assert.Equal(t, expected, to)
+		case <-time.After(5 * time.Second):
+			t.Fatal("Expected the job to run")
+		}
+	}
+
+	// Completed jobs are deleted and release their unique key.
+	assert.Eventually(t, func() bool {
+		stats, err := queue.Stats(ctx)
+		return err == nil && stats == jobs.Stats{}
+	}, 5*time.Second, 10*time.Millisecond)
+	keys, err := client.Keys(ctx, "*").Result()
+	assert.NoError(t, err)
+	assert.Empty(t, keys)
+}
+
+// TestRetries verifies that failed jobs are retried, moved to the dead-letter queue after their last
+// attempt, and can be retried from there.
+func TestRetries(t *testing.T) {
+	queue, _ := newQueue(t, jobs.Config{MaxAttempts: 3, Backoff: time.Millisecond})
+	ctx := context.Background()
+
+	var attempts atomic.Int32
+	queue.Handle("flaky", func(ctx context.Context, job *jobs.Job) error {
+		if attempts.Add(1) == 4 {
+			return nil
+		}
+		return errors.New("unavailable")
+	})
+	job, err := queue.Enqueue(ctx, "flaky", nil, jobs.Options{UniqueKey: "flaky"})
+	assert.NoError(t, err)
+	start(t, queue)
+
+	assert.Eventually(t, func() bool {
+		dead, total, err := queue.Dead(ctx, 0, 10)
+		return err == nil && total == 1 && len(dead) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	dead, state, err := queue.Get(ctx, job.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, jobs.StateDead, state)
+	assert.Equal(t, 3, dead.Attempts)
+	assert.Equal(t, "unavailable", dead.LastError)
+	assert.NotNil(t, dead.FailedAt)
+
+	// The unique key was released, and is claimed again by the retry.
+	retried, err := queue.Retry(ctx, job.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, retried.Attempts)
+	_, err = queue.Retry(ctx, job.ID)
+	assert.Error(t, err)
+	assert.Eventually(t, func() bool {
+		_, _, err := queue.Get(ctx, job.ID)
+		return errors.Is(err, jobs.ErrNotFound)
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.EqualValues(t, 4, attempts.Load())
+}
+
+// TestPermanent verifies that permanent errors, undecodable payloads and panics are handled.
+func TestPermanent(t *testing.T) {
+	queue, _ := newQueue(t, jobs.Config{MaxAttempts: 5, Backoff: time.Hour})
+	ctx := context.Background()
+
+	queue.Handle("invalid", func(ctx context.Context, job *jobs.Job) error {
+		return jobs.Permanent(errors.New("invalid recipient"))
+	})
+	jobs.Register(queue, "email.send", func(ctx context.Context, payload email) error { return nil })
+	queue.Handle("panic", func(ctx context.Context, job *jobs.Job) error { panic("boom") })
+
+	invalid, err := queue.Enqueue(ctx, "invalid", nil, jobs.Options{})
+	assert.NoError(t, err)
+	undecodable, err := queue.Enqueue(ctx, "email.send", []string{"not", "an", "email"}, jobs.Options{})
+	assert.NoError(t, err)
+	panicking, err := queue.Enqueue(ctx, "panic", nil, jobs.Options{})
+	assert.NoError(t, err)
+	start(t, queue)
+
+	assert.Eventually(t, func() bool {
+		stats, err := queue.Stats(ctx)
+		return err == nil && stats == jobs.Stats{Scheduled: 1, Dead: 2}
+	}, 5*time.Second, 10*time.Millisecond)
+
+	job, state, err := queue.Get(ctx, invalid.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, jobs.StateDead, state)
+	assert.Equal(t, 1, job.Attempts)
+	_, state, _ = queue.Get(ctx, undecodable.ID)
+	assert.Equal(t, jobs.StateDead, state)
+	job, state, _ = queue.Get(ctx, panicking.ID)
+	assert.Equal(t, jobs.StateScheduled, state, "Expected a panic to be retried")
+	assert.Equal(t, "panic: boom", job.LastError)
+}
+
+// TestStop verifies that stopping the workers waits for running jobs, and cancels them after the deadline.
+func TestStop(t *testing.T) {
+	queue, _ := newQueue(t, jobs.Config{Backoff: time.Hour})
+	ctx := context.Background()
+
+	started := make(chan struct{})
+	queue.Handle("slow", func(ctx context.Context, job *jobs.Job) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	job, err := queue.Enqueue(ctx, "slow", nil, jobs.Options{})
+	assert.NoError(t, err)
+	stop := queue.Start()
+	<-started
+
+	deadline, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, stop(deadline), context.DeadlineExceeded)
+
+	// The canceled job is retried later.
+	stored, state, err := queue.Get(ctx, job.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, jobs.StateScheduled, state)
+	assert.Equal(t, 1, stored.Attempts)
+}
+
+// TestMaintain verifies that jobs whose attempt exceeded its deadline are retried.
+func TestMaintain(t *testing.T) {
+	queue, client := newQueue(t, jobs.Config{Backoff: time.Millisecond})
+	ctx := context.Background()
+
+	job, err := queue.Enqueue(ctx, "email.send", email{}, jobs.Options{})
+	assert.NoError(t, err)
+	// Simulate a worker that died while running the job.
+	assert.NoError(t, client.ZRem(ctx, "jobs:queue", job.ID).Err())
+	assert.NoError(t, client.ZAdd(ctx, "jobs:active", redis.Z{Score: 0, Member: job.ID}).Err())
+
+	assert.NoError(t, queue.Maintain(ctx))
+	stored, state, err := queue.Get(ctx, job.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, jobs.StateScheduled, state)
+	assert.Equal(t, "job timed out", stored.LastError)
+
+	// Once due, the retry is moved back to the queue.
+	time.Sleep(5 * time.Millisecond)
+	assert.NoError(t, queue.Maintain(ctx))
+	_, state, err = queue.Get(ctx, job.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, jobs.StateQueued, state)
+}
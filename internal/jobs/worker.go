@@ -0,0 +1,326 @@
+// Package jobs runs work outside the request path with a job queue stored in Redis.
+// This file implements the worker pool processing the queue.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"gobo/internal/audit"
+	"gobo/internal/logger"
+	"gobo/internal/tenant"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+const (
+	maxBackoff = time.Hour       // Longest delay between two attempts
+	leaseGrace = time.Minute     // Time after the timeout of an attempt before the job is considered lost
+	batchSize  = 100             // Jobs promoted or reaped per poll
+	errTimeout = "job timed out" // Error of the attempts whose worker stopped without reporting
+)
+
+// dequeue moves the next ready job to the active set, with the deadline of its attempt.
+var dequeue = redis.NewScript(`
+local popped = redis.call("ZPOPMIN", KEYS[1])
+if #popped == 0 then
+	return false
+end
+redis.call("ZADD", KEYS[2], ARGV[1], popped[1])
+return popped[1]`)
+
+// promote moves the scheduled jobs that are due to the ready queue.
+var promote = redis.NewScript(`
+local ids = redis.call("ZRANGEBYSCORE", KEYS[1], "-inf", ARGV[1], "LIMIT", 0, ARGV[2])
+for _, id in ipairs(ids) do
+	redis.call("ZREM", KEYS[1], id)
+	local data = redis.call("GET", ARGV[3] .. id)
+	if data then
+		local job = cjson.decode(data)
+		redis.call("ZADD", KEYS[2], string.format("%.0f", (ARGV[4] - job.priority) * 1e13 + ARGV[1]), id)
+	end
+end
+return #ids`)
+
+// settle ends an attempt: the job leaves the active set, and is stored and added to the target set,
+// or deleted. Nothing happens if the job is no longer active, e.g. because its attempt timed out.
+var settle = redis.NewScript(`
+if redis.call("ZREM", KEYS[1], ARGV[1]) == 0 then
+	return 0
+end
+if ARGV[2] == "" then
+	redis.call("DEL", KEYS[2])
+elseif ARGV[4] ~= "0" then
+	redis.call("SET", KEYS[2], ARGV[2], "PX", ARGV[4])
+else
+	redis.call("SET", KEYS[2], ARGV[2])
+end
+if ARGV[3] ~= "" then
+	redis.call("ZADD", KEYS[3], ARGV[3], ARGV[1])
+end
+if ARGV[5] == "1" and redis.call("GET", KEYS[4]) == ARGV[1] then
+	redis.call("DEL", KEYS[4])
+end
+return 1`)
+
+// Start starts the workers processing the queue and runs until the returned function is called.
+// Each job runs with a context carrying the tenant that enqueued it and the Actor of the audit log,
+// and is canceled after the configured timeout.
+//
+// Returns:
+//   - func(context.Context) error: Stops the workers gracefully: no job is started anymore, and running jobs
+//     may finish until the context is done. Jobs still running then are canceled and retried later.
+//     Returns the context's error if jobs had to be canceled.
+func (q *Queue) Start() func(ctx context.Context) error {
+	pollCtx, stop := context.WithCancel(context.Background())
+	jobCtx, abort := context.WithCancel(context.Background())
+	log := logger.FromContext(pollCtx).Named("jobs")
+
+	client, err := q.client()
+	if err != nil {
+		log.Warn("Job workers not started: Redis is not connected")
+		stop()
+		abort()
+		return func(context.Context) error { return nil }
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < q.config.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			q.work(pollCtx, jobCtx, client, log)
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		q.maintain(pollCtx, log)
+	}()
+
+	return func(ctx context.Context) error {
+		stop()
+		done := make(chan struct{})
+		go func() {
+			wg.Wait()
+			close(done)
+		}()
+		defer abort()
+		select {
+		case <-done:
+			return nil
+		case <-ctx.Done():
+			abort()
+			<-done
+			return ctx.Err()
+		}
+	}
+}
+
+// work runs the jobs of the queue one at a time until pollCtx is done.
+func (q *Queue) work(pollCtx, jobCtx context.Context, client redis.UniversalClient, log *zap.Logger) {
+	for {
+		deadline := time.Now().Add(q.config.Timeout + leaseGrace).UnixMilli()
+		id, err := dequeue.Run(pollCtx, client, []string{q.key("queue"), q.key("active")}, deadline).Text()
+		switch {
+		case err == nil:
+			q.process(jobCtx, client, id, log)
+			continue
+		case errors.Is(err, redis.Nil), pollCtx.Err() != nil:
+		default:
+			log.Error("Failed to dequeue a job", zap.Error(err))
+		}
+
+		select {
+		case <-pollCtx.Done():
+			return
+		case <-time.After(q.config.PollInterval):
+		}
+	}
+}
+
+// maintain promotes the scheduled jobs that are due, retries the lost jobs and removes the expired
+// dead jobs at every poll interval until ctx is done.
+func (q *Queue) maintain(ctx context.Context, log *zap.Logger) {
+	ticker := time.NewTicker(q.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := q.Maintain(ctx); err != nil && ctx.Err() == nil {
+			log.Error("Failed to maintain the job queue", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Maintain runs the periodic tasks of the queue once; the workers run it at every poll interval:
+// - Scheduled jobs that are due are moved to the queue.
+// - Jobs whose attempt exceeded its deadline (e.g., because their instance died) fail and are retried.
+// - Dead jobs older than DeadTTL are removed.
+//
+// Parameters:
+// - ctx (context.Context): The context of the Redis commands.
+//
+// Returns:
+// - error: An error if Redis fails.
+func (q *Queue) Maintain(ctx context.Context) error {
+	client, err := q.client()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	ms := strconv.FormatInt(now.UnixMilli(), 10)
+	err = promote.Run(ctx, client, []string{q.key("scheduled"), q.key("queue")}, ms, batchSize, q.key("job", ""), maxPriority).Err()
+	if err != nil {
+		return err
+	}
+
+	lost, err := client.ZRangeByScore(ctx, q.key("active"), &redis.ZRangeBy{Min: "-inf", Max: ms, Count: batchSize}).Result()
+	if err != nil {
+		return err
+	}
+	log := logger.FromContext(ctx).Named("jobs")
+	for _, id := range lost {
+		job, err := q.load(ctx, client, id)
+		if errors.Is(err, ErrNotFound) {
+			client.ZRem(ctx, q.key("active"), id)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		q.fail(ctx, client, job, errors.New(errTimeout), log)
+	}
+
+	cutoff := now.Add(-q.config.DeadTTL).UnixMilli()
+	return client.ZRemRangeByScore(ctx, q.key("dead"), "-inf", strconv.FormatInt(cutoff, 10)).Err()
+}
+
+// process runs an attempt of a job and records its outcome.
+func (q *Queue) process(ctx context.Context, client redis.UniversalClient, id string, log *zap.Logger) {
+	// The outcome is recorded even if the workers are stopping.
+	settleCtx := context.WithoutCancel(ctx)
+	job, err := q.load(settleCtx, client, id)
+	if errors.Is(err, ErrNotFound) {
+		client.ZRem(settleCtx, q.key("active"), id)
+		return
+	}
+	if err != nil {
+		log.Error("Failed to load a job", zap.String("jobId", id), zap.Error(err))
+		return // Retried once its attempt times out.
+	}
+
+	start := time.Now()
+	if err := q.run(ctx, job); err != nil {
+		q.fail(settleCtx, client, job, err, log)
+		return
+	}
+	if _, err := q.settle(settleCtx, client, job, "", 0, 0); err != nil {
+		log.Error("Failed to complete a job", zap.String("jobId", job.ID), zap.Error(err))
+		return
+	}
+	log.Debug("Job completed",
+		zap.String("jobId", job.ID),
+		zap.String("type", job.Type),
+		zap.Duration("duration", time.Since(start)),
+	)
+}
+
+// run calls the handler of a job, converting panics to errors.
+func (q *Queue) run(ctx context.Context, job *Job) (err error) {
+	handler, ok := q.handler(job.Type)
+	if !ok {
+		// Retried, in case another instance (e.g., a newer version) handles the type.
+		return fmt.Errorf("no handler for job type %q", job.Type)
+	}
+
+	ctx = audit.NewContext(ctx, audit.Actor{User: Actor, RequestID: job.ID})
+	if job.Tenant != "" {
+		ctx = tenant.NewContext(ctx, job.Tenant)
+	} else {
+		// Jobs enqueued without a tenant (e.g., by scheduled tasks) work across tenants.
+		ctx = tenant.Bypass(ctx)
+	}
+	ctx, cancel := context.WithTimeout(ctx, q.config.Timeout)
+	defer cancel()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return handler(ctx, job)
+}
+
+// fail records a failed attempt: the job is retried after the backoff, or moved to the dead-letter
+// queue after its last attempt or a permanent error.
+func (q *Queue) fail(ctx context.Context, client redis.UniversalClient, job *Job, cause error, log *zap.Logger) {
+	now := time.Now()
+	job.Attempts++
+	job.LastError = cause.Error()
+	fields := []zap.Field{
+		zap.String("jobId", job.ID),
+		zap.String("type", job.Type),
+		zap.Int("attempts", job.Attempts),
+		zap.Error(cause),
+	}
+
+	var permanent *permanentError
+	if errors.As(cause, &permanent) || job.Attempts >= job.MaxAttempts {
+		job.FailedAt = &now
+		settled, err := q.settle(ctx, client, job, "dead", now.UnixMilli(), q.config.DeadTTL)
+		if err != nil {
+			log.Error("Failed to record a job failure", append(fields, zap.NamedError("redisError", err))...)
+		} else if settled {
+			log.Error("Job failed permanently", fields...)
+		}
+		return
+	}
+
+	backoff := q.config.Backoff << (job.Attempts - 1)
+	if backoff > maxBackoff || backoff <= 0 {
+		backoff = maxBackoff
+	}
+	job.RunAt = now.Add(backoff)
+	settled, err := q.settle(ctx, client, job, "scheduled", job.RunAt.UnixMilli(), 0)
+	if err != nil {
+		log.Error("Failed to record a job failure", append(fields, zap.NamedError("redisError", err))...)
+	} else if settled {
+		log.Warn("Job failed, retrying", append(fields, zap.Duration("backoff", backoff))...)
+	}
+}
+
+// settle records the outcome of an attempt: the job is added to the target set with the score and kept
+// for ttl (0 keeps it until it completes), or deleted if target is empty. The unique key of the job is
+// released unless it is retried. Returns false if the job was no longer active.
+func (q *Queue) settle(ctx context.Context, client redis.UniversalClient, job *Job, target string, score int64, ttl time.Duration) (bool, error) {
+	encoded, release := "", "1"
+	if target != "" {
+		data, err := json.Marshal(job)
+		if err != nil {
+			return false, err
+		}
+		encoded = string(data)
+	}
+	if target == "scheduled" || job.UniqueKey == "" {
+		release = "0"
+	}
+	scoreArg := ""
+	if target != "" {
+		scoreArg = strconv.FormatInt(score, 10)
+	}
+	keys := []string{q.key("active"), q.key("job", job.ID), q.key(setOf(target)), q.uniqueKey(job)}
+	settled, err := settle.Run(ctx, client, keys, job.ID, encoded, scoreArg, ttl.Milliseconds(), release).Int()
+	return settled == 1, err
+}
@@ -7,30 +7,44 @@ import (
 )
 
 // Config defines the configuration for the logger.
-// It includes options for log level, environment, output paths, and Sentry DSN for error tracking.
+// It includes options for log level, environment, output paths and sinks, file rotation, and Sentry DSN for error tracking.
 type Config struct {
-	Level       zapcore.Level `json:"level"`       // Log level (e.g., DEBUG, INFO, ERROR)
-	Environment string        `json:"environment"` // Logging environment: "development" or "production"
-	OutputPaths []string      `json:"outputPaths"` // Output paths for logs (e.g., stdout, file paths)
-	SentryDSN   string        `json:"sentryDSN"`   // Sentry DSN for error tracking
+	Level                  zapcore.Level    `json:"level"`                  // Log level (e.g., DEBUG, INFO, ERROR)
+	Environment            string           `json:"environment"`            // Logging environment: "development" or "production"
+	OutputPaths            []string         `json:"outputPaths"`            // Output paths for logs (e.g., stdout, file paths)
+	SentryDSN              string           `json:"sentryDSN"`              // Sentry DSN for error tracking
+	SentryTracesSampleRate float64          `json:"sentryTracesSampleRate"` // Share of requests traced as Sentry transactions (0 disables tracing)
+	Sampling               *SamplingConfig  `json:"sampling"`               // Sampling of repeated low-severity entries (nil disables sampling)
+	Sinks                  []SinkConfig     `json:"sinks"`                  // Log destinations with their own level and encoding (overrides OutputPaths)
+	Rotation               RotationConfig   `json:"rotation"`               // Rotation of file outputs (used for OutputPaths and file sinks without rotation settings)
+	Redaction              *RedactionConfig `json:"redaction"`              // Masking of sensitive data (nil uses DefaultRedactionConfig)
 }
 
 // DefaultConfig returns the default configuration for the logger.
 // This default configuration is used if no custom configuration is provided.
-// 
+//
 // Defaults:
 // - Level: zapcore.InfoLevel (logs informational messages and above)
 // - Environment: "production" (optimized for production use)
 // - OutputPaths: Writes logs to both the terminal (stdout) and a log file (logs/app.log)
 // - SentryDSN: Empty by default (Sentry integration disabled)
+// - SentryTracesSampleRate: 0 (performance tracing disabled)
+// - Sampling: DefaultSamplingConfig() (repeated INFO and DEBUG entries are sampled)
+// - Sinks: None (derived from OutputPaths)
+// - Rotation: DefaultRotationConfig() (100 MB files, rotated daily, kept for 30 days, compressed)
+// - Redaction: DefaultRedactionConfig() (passwords, tokens, DSNs, auth headers, emails and card numbers are masked)
 //
 // Returns:
 // - Config: The default logger configuration.
 func DefaultConfig() Config {
 	return Config{
-		Level:       zapcore.InfoLevel,                   // Default to INFO level logging
-		Environment: "production",                       // Default to production environment
-		OutputPaths: []string{"stdout", "logs/app.log"}, // Log to both stdout and a file
-		SentryDSN:   "",                                 // No Sentry DSN by default
+		Level:                  zapcore.InfoLevel,                  // Default to INFO level logging
+		Environment:            "production",                       // Default to production environment
+		OutputPaths:            []string{"stdout", "logs/app.log"}, // Log to both stdout and a file
+		SentryDSN:              "",                                 // No Sentry DSN by default
+		SentryTracesSampleRate: 0,                                  // No performance tracing by default
+		Sampling:               DefaultSamplingConfig(),            // Sample high-volume low-severity logs
+		Rotation:               DefaultRotationConfig(),            // Rotate and clean up log files
+		Redaction:              DefaultRedactionConfig(),           // Mask sensitive data
 	}
 }
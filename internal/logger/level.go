@@ -0,0 +1,209 @@
+// Package logger provides utilities for configuring and initializing the application's logger.
+// This file implements runtime log level control, globally and per named logger.
+package logger
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// levels holds the runtime log levels of the global logger.
+var levels = newLevelRegistry(zapcore.InfoLevel)
+
+// levelRegistry stores the global log level and per-logger overrides.
+// Overrides apply to a named logger (see zap.Logger.Named) and to all of its children,
+// e.g. an override for "gorm" also applies to "gorm.migrator".
+type levelRegistry struct {
+	mu         sync.RWMutex
+	global     zap.AtomicLevel
+	configured zapcore.Level
+	overrides  map[string]zapcore.Level
+	reverts    map[string]*revert
+}
+
+// revert is a pending revert of a temporary level change, with the level the logger had before it.
+type revert struct {
+	timer       *time.Timer
+	previous    zapcore.Level // Level before the first of the pending changes
+	hadOverride bool          // Whether the named logger had an override before the first of the pending changes
+}
+
+// newLevelRegistry creates a registry with the given configured global level.
+func newLevelRegistry(level zapcore.Level) *levelRegistry {
+	return &levelRegistry{
+		global:     zap.NewAtomicLevelAt(level),
+		configured: level,
+		overrides:  map[string]zapcore.Level{},
+		reverts:    map[string]*revert{},
+	}
+}
+
+// reset restores the configured level and removes all overrides and pending reverts.
+func (r *levelRegistry) reset(level zapcore.Level) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, pending := range r.reverts {
+		pending.timer.Stop()
+	}
+	r.configured = level
+	r.global.SetLevel(level)
+	r.overrides = map[string]zapcore.Level{}
+	r.reverts = map[string]*revert{}
+}
+
+// levelFor returns the effective level of the named logger.
+func (r *levelRegistry) levelFor(name string) zapcore.Level {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for name != "" {
+		if level, ok := r.overrides[name]; ok {
+			return level
+		}
+		index := strings.LastIndex(name, ".")
+		if index < 0 {
+			break
+		}
+		name = name[:index]
+	}
+	return r.global.Level()
+}
+
+// minLevel returns the lowest level enabled by any logger.
+func (r *levelRegistry) minLevel() zapcore.Level {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	level := r.global.Level()
+	for _, override := range r.overrides {
+		if override < level {
+			level = override
+		}
+	}
+	return level
+}
+
+// set changes the level of the named logger ("" for the global level).
+// If revertAfter is positive, the change is undone after that duration. Temporary changes made while another
+// one is pending restore the level from before the first of them, so that they never become permanent.
+func (r *levelRegistry) set(name string, level zapcore.Level, revertAfter time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous, hadOverride := r.overrides[name]
+	if name == "" {
+		previous, hadOverride = r.global.Level(), true
+	}
+	// A new change replaces any pending revert of the same logger, and keeps the level it restores.
+	if pending, ok := r.reverts[name]; ok {
+		pending.timer.Stop()
+		delete(r.reverts, name)
+		previous, hadOverride = pending.previous, pending.hadOverride
+	}
+
+	if name == "" {
+		r.global.SetLevel(level)
+	} else {
+		r.overrides[name] = level
+	}
+
+	if revertAfter <= 0 {
+		return
+	}
+	pending := &revert{previous: previous, hadOverride: hadOverride}
+	pending.timer = time.AfterFunc(revertAfter, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		// Ignore the revert if the logger was changed again in the meantime.
+		if r.reverts[name] != pending {
+			return
+		}
+		delete(r.reverts, name)
+		switch {
+		case name == "":
+			r.global.SetLevel(previous)
+		case hadOverride:
+			r.overrides[name] = previous
+		default:
+			delete(r.overrides, name)
+		}
+	})
+	r.reverts[name] = pending
+}
+
+// unset removes the override of the named logger, or restores the configured global level for "".
+func (r *levelRegistry) unset(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if pending, ok := r.reverts[name]; ok {
+		pending.timer.Stop()
+		delete(r.reverts, name)
+	}
+	if name == "" {
+		r.global.SetLevel(r.configured)
+		return
+	}
+	delete(r.overrides, name)
+}
+
+// SetLevel changes the log level at runtime.
+//
+// Parameters:
+// - name (string): The named logger to change (e.g., "gorm"), or "" for the global level.
+// - level (zapcore.Level): The new log level.
+// - revertAfter (time.Duration): If positive, the previous level is restored after this duration.
+func SetLevel(name string, level zapcore.Level, revertAfter time.Duration) {
+	levels.set(name, level, revertAfter)
+}
+
+// ResetLevel removes the runtime override of a named logger,
+// or restores the configured global level when name is "".
+//
+// Parameters:
+// - name (string): The named logger to reset, or "" for the global level.
+func ResetLevel(name string) {
+	levels.unset(name)
+}
+
+// GlobalLevel returns the current global log level.
+func GlobalLevel() zapcore.Level {
+	return levels.global.Level()
+}
+
+// LoggerLevels returns a snapshot of the per-logger level overrides.
+func LoggerLevels() map[string]zapcore.Level {
+	levels.mu.RLock()
+	defer levels.mu.RUnlock()
+	snapshot := make(map[string]zapcore.Level, len(levels.overrides))
+	for name, level := range levels.overrides {
+		snapshot[name] = level
+	}
+	return snapshot
+}
+
+// levelCore is a zapcore.Core that filters entries using the runtime level registry.
+type levelCore struct {
+	zapcore.Core
+	registry *levelRegistry
+}
+
+// Enabled reports whether any logger is enabled at the given level.
+// The exact decision for a named logger is made in Check.
+func (c *levelCore) Enabled(level zapcore.Level) bool {
+	return level >= c.registry.minLevel()
+}
+
+// With returns a copy of the core with the given fields.
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelCore{Core: c.Core.With(fields), registry: c.registry}
+}
+
+// Check applies the effective level of the entry's logger before delegating to the wrapped core.
+func (c *levelCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if entry.Level < c.registry.levelFor(entry.LoggerName) {
+		return checked
+	}
+	return c.Core.Check(entry, checked)
+}
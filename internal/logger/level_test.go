@@ -0,0 +1,129 @@
+// Package logger_test contains tests for the logger package.
+// These tests validate runtime log level control and log sampling.
+package logger_test
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"gobo/internal/logger"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+)
+
+// initTestLogger initializes the logger with output to a temporary file and returns its path.
+func initTestLogger(t *testing.T, config logger.Config) string {
+	tempFile, err := os.CreateTemp("", "testlog")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	t.Cleanup(func() { os.Remove(tempFile.Name()) })
+
+	config.Environment = "production"
+	config.OutputPaths = []string{tempFile.Name()}
+	logger.InitLogger(config)
+	return tempFile.Name()
+}
+
+// readLog returns the content of the log file.
+func readLog(t *testing.T, path string) []byte {
+	_ = logger.Log.Sync()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read temp file: %v", err)
+	}
+	return content
+}
+
+// TestSetLevel_Global verifies that the global level can be changed and reset at runtime.
+func TestSetLevel_Global(t *testing.T) {
+	path := initTestLogger(t, logger.Config{Level: zapcore.InfoLevel})
+
+	logger.Log.Debug("hidden debug message")
+	logger.SetLevel("", zapcore.DebugLevel, 0)
+	logger.Log.Debug("visible debug message")
+	logger.ResetLevel("")
+	logger.Log.Debug("hidden again")
+
+	content := readLog(t, path)
+	assert.False(t, bytes.Contains(content, []byte("hidden debug message")))
+	assert.True(t, bytes.Contains(content, []byte("visible debug message")))
+	assert.False(t, bytes.Contains(content, []byte("hidden again")))
+	assert.Equal(t, zapcore.InfoLevel, logger.GlobalLevel())
+}
+
+// TestSetLevel_NamedLogger verifies that an override only applies to the named logger and its children.
+func TestSetLevel_NamedLogger(t *testing.T) {
+	path := initTestLogger(t, logger.Config{Level: zapcore.InfoLevel})
+
+	logger.SetLevel("gorm", zapcore.DebugLevel, 0)
+	defer logger.ResetLevel("gorm")
+
+	logger.Log.Named("gorm").Debug("gorm debug message")
+	logger.Log.Named("gorm").Named("migrator").Debug("child debug message")
+	logger.Log.Named("http").Debug("http debug message")
+
+	content := readLog(t, path)
+	assert.True(t, bytes.Contains(content, []byte("gorm debug message")))
+	assert.True(t, bytes.Contains(content, []byte("child debug message")))
+	assert.False(t, bytes.Contains(content, []byte("http debug message")))
+	assert.Equal(t, map[string]zapcore.Level{"gorm": zapcore.DebugLevel}, logger.LoggerLevels())
+}
+
+// TestSetLevel_Revert verifies that a temporary level change is reverted automatically.
+func TestSetLevel_Revert(t *testing.T) {
+	initTestLogger(t, logger.Config{Level: zapcore.WarnLevel})
+
+	logger.SetLevel("", zapcore.DebugLevel, 50*time.Millisecond)
+	logger.SetLevel("cache", zapcore.ErrorLevel, 50*time.Millisecond)
+	assert.Equal(t, zapcore.DebugLevel, logger.GlobalLevel())
+
+	assert.Eventually(t, func() bool {
+		return logger.GlobalLevel() == zapcore.WarnLevel && len(logger.LoggerLevels()) == 0
+	}, time.Second, 10*time.Millisecond, "Expected the level changes to be reverted")
+}
+
+// TestSetLevel_RevertRepeated verifies that repeated temporary changes restore the level from before the first one.
+func TestSetLevel_RevertRepeated(t *testing.T) {
+	initTestLogger(t, logger.Config{Level: zapcore.WarnLevel})
+	logger.SetLevel("gorm", zapcore.InfoLevel, 0)
+	defer logger.ResetLevel("gorm")
+
+	logger.SetLevel("", zapcore.DebugLevel, time.Hour)
+	logger.SetLevel("", zapcore.DebugLevel, 50*time.Millisecond)
+	logger.SetLevel("gorm", zapcore.DebugLevel, time.Hour)
+	logger.SetLevel("gorm", zapcore.ErrorLevel, 50*time.Millisecond)
+	logger.SetLevel("cache", zapcore.DebugLevel, time.Hour)
+	logger.SetLevel("cache", zapcore.DebugLevel, 50*time.Millisecond)
+	assert.Equal(t, zapcore.DebugLevel, logger.GlobalLevel())
+
+	assert.Eventually(t, func() bool {
+		return logger.GlobalLevel() == zapcore.WarnLevel &&
+			assert.ObjectsAreEqual(map[string]zapcore.Level{"gorm": zapcore.InfoLevel}, logger.LoggerLevels())
+	}, time.Second, 10*time.Millisecond, "Expected the levels from before the first changes")
+}
+
+// TestSampling verifies that repeated INFO entries are sampled while warnings are always logged.
+func TestSampling(t *testing.T) {
+	path := initTestLogger(t, logger.Config{
+		Level: zapcore.InfoLevel,
+		Sampling: &logger.SamplingConfig{
+			Tick:       time.Minute,
+			Initial:    2,
+			Thereafter: 1000,
+			MaxLevel:   zapcore.InfoLevel,
+		},
+	})
+
+	for i := 0; i < 10; i++ {
+		logger.Log.Info("repeated info message")
+		logger.Log.Warn("repeated warn message")
+	}
+
+	content := readLog(t, path)
+	assert.Equal(t, 2, bytes.Count(content, []byte("repeated info message")), "Expected INFO entries to be sampled")
+	assert.Equal(t, 10, bytes.Count(content, []byte("repeated warn message")), "Expected WARN entries not to be sampled")
+}
@@ -0,0 +1,114 @@
+// Package logger provides utilities for configuring and initializing the application's logger.
+// This file implements a logfmt encoder (key=value pairs) for Zap.
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// logfmtPool provides the output buffers of the logfmt encoder.
+var logfmtPool = buffer.NewPool()
+
+// logfmtEncoder encodes entries as logfmt lines, e.g.:
+//
+//	ts=2024-01-01T00:00:00Z level=info msg="Server started" port=3000
+//
+// Fields are accumulated by an embedded JSON encoder, so every Zap field type is supported;
+// nested objects and arrays are rendered as compact JSON values.
+type logfmtEncoder struct {
+	zapcore.Encoder
+}
+
+// newLogfmtEncoder creates a logfmt encoder with the given encoder configuration.
+func newLogfmtEncoder(config zapcore.EncoderConfig) zapcore.Encoder {
+	// Colors and a custom line ending would corrupt the key=value output.
+	config.EncodeLevel = zapcore.LowercaseLevelEncoder
+	config.LineEnding = ""
+	return &logfmtEncoder{Encoder: zapcore.NewJSONEncoder(config)}
+}
+
+// Clone returns a copy of the encoder.
+func (e *logfmtEncoder) Clone() zapcore.Encoder {
+	return &logfmtEncoder{Encoder: e.Encoder.Clone()}
+}
+
+// EncodeEntry encodes the entry and fields as a single logfmt line.
+func (e *logfmtEncoder) EncodeEntry(entry zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	encoded, err := e.Encoder.EncodeEntry(entry, fields)
+	if err != nil {
+		return nil, err
+	}
+	defer encoded.Free()
+
+	// Walk the top-level JSON object in order and write each member as key=value.
+	decoder := json.NewDecoder(bytes.NewReader(encoded.Bytes()))
+	decoder.UseNumber()
+	if _, err := decoder.Token(); err != nil { // Opening brace.
+		return nil, err
+	}
+
+	line := logfmtPool.Get()
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			line.Free()
+			return nil, err
+		}
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			line.Free()
+			return nil, err
+		}
+
+		if line.Len() > 0 {
+			line.AppendByte(' ')
+		}
+		line.AppendString(logfmtKey(token.(string)))
+		line.AppendByte('=')
+		line.AppendString(logfmtValue(value))
+	}
+	line.AppendString(zapcore.DefaultLineEnding)
+	return line, nil
+}
+
+// logfmtKey replaces characters that are not allowed in logfmt keys.
+func logfmtKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r == '=' || r == '"' || r == unicode.ReplacementChar {
+			return '_'
+		}
+		return r
+	}, key)
+}
+
+// logfmtValue converts a JSON value into its logfmt representation.
+func logfmtValue(value json.RawMessage) string {
+	var text string
+	if len(value) > 0 && value[0] == '"' {
+		if err := json.Unmarshal(value, &text); err != nil {
+			text = string(value)
+		}
+	} else {
+		// Numbers, booleans, null, objects and arrays keep their compact JSON form.
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, value); err != nil {
+			return strconv.Quote(string(value))
+		}
+		text = compact.String()
+		if text[0] != '{' && text[0] != '[' {
+			return text
+		}
+	}
+
+	if text == "" || strings.ContainsAny(text, " =\"\\") || strings.IndexFunc(text, unicode.IsControl) >= 0 {
+		return strconv.Quote(text)
+	}
+	return text
+}
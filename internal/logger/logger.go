@@ -3,7 +3,9 @@
 package logger
 
 import (
+	"io"
 	"log"
+	"os"
 	"time"
 
 	"github.com/getsentry/sentry-go"
@@ -14,6 +16,12 @@ import (
 // Log is the global logger instance used throughout the application.
 var Log *zap.Logger
 
+// sinkCloser releases the files and connections opened for the current logger.
+var sinkCloser io.Closer
+
+// flushTimeout is the maximum time to wait for pending Sentry events to be sent.
+const flushTimeout = 2 * time.Second
+
 // InitLogger initializes the Zap logger with the specified configuration and Sentry integration.
 // It supports different configurations for "development" and "production" environments.
 // If the initialization fails or an invalid environment is provided, the application terminates.
@@ -24,57 +32,112 @@ var Log *zap.Logger
 // Behavior:
 // - For the "development" environment, a human-readable logging format is used.
 // - For the "production" environment, a JSON logging format is used.
-// - If a Sentry DSN is provided, errors and higher-severity logs are sent to Sentry.
+// - Each sink may override the level and the format (json, console, logfmt); file outputs are rotated.
+// - Sensitive fields, headers and patterns are redacted before reaching any sink or Sentry.
+// - If a Sentry DSN is provided, errors and higher-severity logs are sent to Sentry with their fields and stack trace.
+// - If a Sentry DSN is provided, info and warning logs are recorded as Sentry breadcrumbs.
+// - If sampling is configured, repeated low-severity entries are sampled.
+// - The log level can be changed at runtime with SetLevel.
 func InitLogger(config Config) {
-	// Initialize Sentry if a DSN is provided.
-	if config.SentryDSN != "" {
-		err := sentry.Init(sentry.ClientOptions{
-			Dsn:         config.SentryDSN,
-			Environment: config.Environment,
-		})
-		if err != nil {
-			log.Fatalf("Failed to initialize Sentry: %v", err)
-		}
-		// Ensure Sentry flushes events before exiting the application.
-		defer sentry.Flush(2 * time.Second)
-	}
-
-	var zapConfig zap.Config
+	var encoderConfig zapcore.EncoderConfig
+	var defaultEncoding string
+	var options []zap.Option
 
 	// Configure the logger based on the specified environment.
 	switch config.Environment {
 	case "development":
-		zapConfig = zap.NewDevelopmentConfig() // Use development-friendly settings.
+		// Use development-friendly settings.
+		encoderConfig = zap.NewDevelopmentEncoderConfig()
+		defaultEncoding = EncodingConsole
+		options = append(options, zap.Development(), zap.AddStacktrace(zapcore.WarnLevel))
 	case "production":
-		zapConfig = zap.NewProductionConfig() // Use production-friendly settings.
+		// Use production-friendly settings.
+		encoderConfig = zap.NewProductionEncoderConfig()
+		defaultEncoding = EncodingJSON
+		options = append(options, zap.AddStacktrace(zapcore.ErrorLevel))
 	default:
 		// Terminate the application if an invalid environment is provided.
 		panic("Invalid environment: " + config.Environment)
 	}
+	options = append(options, zap.AddCaller(), zap.ErrorOutput(zapcore.Lock(os.Stderr)))
 
-	// Set the log level (e.g., DEBUG, INFO, ERROR) based on the configuration.
-	zapConfig.Level = zap.NewAtomicLevelAt(config.Level)
+	// Open the configured sinks (e.g., stdout, rotated files, syslog), each with its own level and encoding.
+	core, closer, err := openSinks(config, encoderConfig, defaultEncoding)
+	if err != nil {
+		// Log a fatal error and terminate the application if logger initialization fails.
+		log.Fatalf("Failed to initialize logger: %v", err)
+	}
 
-	// Configure the output paths for the logger (e.g., stdout, files).
-	zapConfig.OutputPaths = config.OutputPaths
+	// Release the sinks of a previous initialization.
+	if sinkCloser != nil {
+		_ = sinkCloser.Close()
+	}
+	sinkCloser = closer
 
-	// Build the logger instance using the configured settings.
-	var err error
-	Log, err = zapConfig.Build(zap.Hooks(sentryHook))
+	// Sample repeated low-severity entries if configured.
+	if config.Sampling != nil {
+		core = newSampledCore(core, config.Sampling)
+	}
+
+	// Compile the redaction rules applied to every entry and Sentry event.
+	redactionConfig := config.Redaction
+	if redactionConfig == nil {
+		redactionConfig = DefaultRedactionConfig()
+	}
+	redactor, err := newRedactor(redactionConfig)
 	if err != nil {
-		// Log a fatal error and terminate the application if logger initialization fails.
 		log.Fatalf("Failed to initialize logger: %v", err)
 	}
+	setRedactor(redactor)
+
+	// Initialize Sentry if a DSN is provided.
+	// A Sentry failure is not fatal: the application keeps logging locally.
+	var sentryErr error
+	if config.SentryDSN != "" {
+		sentryErr = sentry.Init(sentry.ClientOptions{
+			Dsn:              config.SentryDSN,
+			Environment:      config.Environment,
+			AttachStacktrace: true,
+			EnableTracing:    config.SentryTracesSampleRate > 0,
+			TracesSampleRate: config.SentryTracesSampleRate,
+			// Redact events that do not come from the logger (e.g., recovered panics).
+			BeforeSend: func(event *sentry.Event, hint *sentry.EventHint) *sentry.Event {
+				return currentRedactor().redactEvent(event)
+			},
+			BeforeBreadcrumb: func(breadcrumb *sentry.Breadcrumb, hint *sentry.BreadcrumbHint) *sentry.Breadcrumb {
+				return currentRedactor().redactBreadcrumb(breadcrumb)
+			},
+		})
+		if sentryErr == nil {
+			// Tee every entry into Sentry: INFO and WARN become breadcrumbs, ERROR and above become events.
+			core = zapcore.NewTee(core, newSentryCore(zapcore.InfoLevel, zapcore.ErrorLevel))
+		}
+	}
+
+	// Mask sensitive data before anything reaches a sink or Sentry.
+	core = &redactCore{Core: core, redactor: redactor}
+
+	// Set the log level (e.g., DEBUG, INFO, ERROR) based on the configuration.
+	// The level is enforced on top of all other cores by the runtime level registry (see SetLevel).
+	levels.reset(config.Level)
+	core = &levelCore{Core: core, registry: levels}
+
+	// Build the logger instance using the configured settings.
+	Log = zap.New(core, options...)
+
+	if sentryErr != nil {
+		Log.Warn("Failed to initialize Sentry, error reporting is disabled", zap.Error(sentryErr))
+	}
 
 	// Log a message indicating successful logger initialization.
 	Log.Info("Logger initialized successfully")
 }
 
-// sentryHook is a Zap hook that sends error-level and above logs to Sentry.
-func sentryHook(entry zapcore.Entry) error {
-	if entry.Level >= zapcore.ErrorLevel {
-		// Capture the error-level log in Sentry.
-		sentry.CaptureMessage(entry.Message)
+// Sync flushes any buffered log entries and pending Sentry events.
+// It should be deferred by the application entry point so nothing is lost on shutdown.
+func Sync() {
+	if Log != nil {
+		_ = Log.Sync()
 	}
-	return nil
+	sentry.Flush(flushTimeout)
 }
@@ -0,0 +1,464 @@
+// Package logger provides utilities for configuring and initializing the application's logger.
+// This file implements the redaction of sensitive data before it reaches a sink or Sentry.
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Built-in patterns for values that are masked wherever they appear.
+// If a pattern has a group named "secret", only that group is masked.
+const (
+	PatternEmail          = `[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`
+	PatternBearerToken    = `(?i)\b(?:bearer|basic)\s+(?P<secret>[A-Za-z0-9\-._~+/]+=*)`
+	PatternJWT            = `\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`
+	PatternURLCredentials = `[A-Za-z][A-Za-z0-9+.-]*://[^:/@\s]+:(?P<secret>[^@\s]+)@`
+	PatternCardNumber     = `\b(?:\d[ -]?){12,18}\d\b`
+)
+
+// RedactionConfig defines which data is masked in log entries and Sentry events.
+type RedactionConfig struct {
+	Fields   []string `json:"fields"`   // Field and map key names whose values are masked (case and separator insensitive)
+	Headers  []string `json:"headers"`  // HTTP header names whose values are masked
+	Patterns []string `json:"patterns"` // Regular expressions masked in every string value and message
+	Mask     string   `json:"mask"`     // Replacement text (defaults to "[REDACTED]")
+}
+
+// DefaultRedactionConfig returns the default redaction configuration.
+//
+// Defaults:
+// - Fields: passwords, secrets, tokens, API keys and database DSNs (e.g., password, token, database_url)
+// - Headers: Authorization, Proxy-Authorization, Cookie, Set-Cookie and X-Api-Key
+// - Patterns: emails, bearer/basic credentials, JWTs, credentials in URLs and card numbers
+// - Mask: "[REDACTED]"
+//
+// Returns:
+// - *RedactionConfig: The default redaction configuration.
+func DefaultRedactionConfig() *RedactionConfig {
+	return &RedactionConfig{
+		Fields: []string{
+			"password", "passwd", "secret", "token", "access_token", "refresh_token",
+			"api_key", "apikey", "private_key", "credentials", "dsn", "database_url", "redis_url",
+		},
+		Headers: []string{
+			"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key",
+		},
+		Patterns: []string{
+			PatternURLCredentials, PatternBearerToken, PatternJWT, PatternEmail, PatternCardNumber,
+		},
+		Mask: "[REDACTED]",
+	}
+}
+
+// redactor masks sensitive keys, struct fields and patterns.
+type redactor struct {
+	keys     map[string]struct{}
+	patterns []redactionPattern
+	mask     string
+}
+
+// redactionPattern is a compiled pattern with an optional validation of its matches.
+type redactionPattern struct {
+	regexp   *regexp.Regexp
+	secret   int               // Index of the "secret" group, or -1 to mask the whole match
+	validate func(string) bool // Optional check that a match really is sensitive
+}
+
+// activeRedactor is the redactor used by the global logger and Sentry.
+var (
+	activeRedactorMu sync.RWMutex
+	activeRedactor   = mustRedactor(DefaultRedactionConfig())
+)
+
+// newRedactor compiles a redaction configuration.
+func newRedactor(config *RedactionConfig) (*redactor, error) {
+	r := &redactor{keys: map[string]struct{}{}, mask: config.Mask}
+	if r.mask == "" {
+		r.mask = "[REDACTED]"
+	}
+	for _, name := range append(append([]string{}, config.Fields...), config.Headers...) {
+		r.keys[normalizeKey(name)] = struct{}{}
+	}
+	for _, expression := range config.Patterns {
+		compiled, err := regexp.Compile(expression)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", expression, err)
+		}
+		pattern := redactionPattern{regexp: compiled, secret: compiled.SubexpIndex("secret")}
+		if expression == PatternCardNumber {
+			pattern.validate = luhnValid
+		}
+		r.patterns = append(r.patterns, pattern)
+	}
+	return r, nil
+}
+
+// mustRedactor compiles a redaction configuration known to be valid.
+func mustRedactor(config *RedactionConfig) *redactor {
+	r, err := newRedactor(config)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// currentRedactor returns the redactor of the global logger.
+func currentRedactor() *redactor {
+	activeRedactorMu.RLock()
+	defer activeRedactorMu.RUnlock()
+	return activeRedactor
+}
+
+// setRedactor replaces the redactor of the global logger.
+func setRedactor(r *redactor) {
+	activeRedactorMu.Lock()
+	defer activeRedactorMu.Unlock()
+	activeRedactor = r
+}
+
+// RedactString masks the configured patterns (e.g., emails, tokens) in a string.
+func RedactString(value string) string {
+	return currentRedactor().redactString(value)
+}
+
+// Redact returns a copy of the value with sensitive data masked:
+// values under sensitive keys, struct fields tagged `log:"redact"` and configured patterns.
+// Structs are converted to maps keyed by their JSON names.
+func Redact(value interface{}) interface{} {
+	return currentRedactor().redactValue(reflect.ValueOf(value), 0)
+}
+
+// normalizeKey lowercases a key and removes separators, so "X-Api-Key", "api_key" and "apiKey" match.
+func normalizeKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '-', '_', ' ', '.':
+			return -1
+		}
+		return r
+	}, strings.ToLower(key))
+}
+
+// isSensitiveKey reports whether values under the key must be masked.
+func (r *redactor) isSensitiveKey(key string) bool {
+	_, ok := r.keys[normalizeKey(key)]
+	return ok
+}
+
+// redactString masks every pattern match in the string.
+func (r *redactor) redactString(value string) string {
+	for _, pattern := range r.patterns {
+		value = pattern.regexp.ReplaceAllStringFunc(value, func(match string) string {
+			if pattern.validate != nil && !pattern.validate(match) {
+				return match
+			}
+			if pattern.secret < 0 {
+				return r.mask
+			}
+			// Only mask the secret group, keeping the surrounding context readable.
+			groups := pattern.regexp.FindStringSubmatchIndex(match)
+			start, end := groups[2*pattern.secret], groups[2*pattern.secret+1]
+			if start < 0 {
+				return r.mask
+			}
+			return match[:start] + r.mask + match[end:]
+		})
+	}
+	return value
+}
+
+// maxRedactDepth limits the recursion into nested values.
+const maxRedactDepth = 16
+
+// redactValue returns a redacted, encoder-friendly copy of a value.
+func (r *redactor) redactValue(value reflect.Value, depth int) interface{} {
+	if !value.IsValid() {
+		return nil
+	}
+	if depth > maxRedactDepth {
+		return "..."
+	}
+	if (value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface) && value.IsNil() {
+		return nil
+	}
+	if !value.CanInterface() {
+		return fmt.Sprint(value)
+	}
+
+	// Keep well-known value types as they are.
+	switch typed := value.Interface().(type) {
+	case time.Time, time.Duration:
+		return typed
+	case error:
+		return r.redactString(typed.Error())
+	case json.Marshaler:
+		return r.redactJSON(typed)
+	}
+
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return r.redactValue(value.Elem(), depth+1)
+	case reflect.String:
+		return r.redactString(value.String())
+	case reflect.Struct:
+		return r.redactStruct(value, depth)
+	case reflect.Map:
+		result := make(map[string]interface{}, value.Len())
+		iterator := value.MapRange()
+		for iterator.Next() {
+			key := fmt.Sprint(iterator.Key().Interface())
+			if r.isSensitiveKey(key) {
+				result[key] = r.mask
+				continue
+			}
+			result[key] = r.redactValue(iterator.Value(), depth+1)
+		}
+		return result
+	case reflect.Slice, reflect.Array:
+		if value.Kind() == reflect.Slice && value.Type().Elem().Kind() == reflect.Uint8 {
+			return r.redactString(string(value.Bytes()))
+		}
+		result := make([]interface{}, value.Len())
+		for i := 0; i < value.Len(); i++ {
+			result[i] = r.redactValue(value.Index(i), depth+1)
+		}
+		return result
+	default:
+		return value.Interface()
+	}
+}
+
+// redactStruct converts a struct into a map keyed by JSON names, masking tagged and sensitive fields.
+func (r *redactor) redactStruct(value reflect.Value, depth int) map[string]interface{} {
+	result := map[string]interface{}{}
+	structType := value.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := field.Name
+		if tag, ok := field.Tag.Lookup("json"); ok {
+			tagName := strings.Split(tag, ",")[0]
+			if tagName == "-" {
+				continue
+			}
+			if tagName != "" {
+				name = tagName
+			}
+		}
+
+		// Promote the fields of embedded structs, as encoding/json does.
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && field.Tag.Get("json") == "" {
+			for key, nested := range r.redactStruct(value.Field(i), depth+1) {
+				result[key] = nested
+			}
+			continue
+		}
+
+		if field.Tag.Get("log") == "redact" || r.isSensitiveKey(name) {
+			result[name] = r.mask
+			continue
+		}
+		result[name] = r.redactValue(value.Field(i), depth+1)
+	}
+	return result
+}
+
+// redactJSON redacts a value through its JSON representation.
+func (r *redactor) redactJSON(marshaler json.Marshaler) interface{} {
+	encoded, err := marshaler.MarshalJSON()
+	if err != nil {
+		return r.mask
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		return r.redactString(string(encoded))
+	}
+	return r.redactValue(reflect.ValueOf(decoded), 1)
+}
+
+// redactField returns a copy of the field with sensitive data masked.
+func (r *redactor) redactField(field zapcore.Field) zapcore.Field {
+	switch field.Type {
+	case zapcore.SkipType, zapcore.NamespaceType:
+		return field
+	}
+
+	if r.isSensitiveKey(field.Key) {
+		return zap.String(field.Key, r.mask)
+	}
+
+	switch field.Type {
+	case zapcore.StringType:
+		field.String = r.redactString(field.String)
+	case zapcore.ByteStringType:
+		return zap.String(field.Key, r.redactString(string(field.Interface.([]byte))))
+	case zapcore.ErrorType:
+		if err, ok := field.Interface.(error); ok {
+			if message := r.redactString(err.Error()); message != err.Error() {
+				// The original error (and its chain) would expose the unredacted message.
+				return zap.Error(&redactedError{message: message})
+			}
+		}
+	case zapcore.StringerType:
+		return zap.String(field.Key, r.redactString(stringerValue(field.Interface)))
+	case zapcore.ReflectType:
+		return zap.Any(field.Key, r.redactValue(reflect.ValueOf(field.Interface), 0))
+	case zapcore.ObjectMarshalerType, zapcore.InlineMarshalerType, zapcore.ArrayMarshalerType:
+		// Encode the value into a map first, then redact the resulting structure.
+		encoder := zapcore.NewMapObjectEncoder()
+		field.AddTo(encoder)
+		if field.Type == zapcore.InlineMarshalerType {
+			return zap.Inline(redactedObject(r.redactValue(reflect.ValueOf(encoder.Fields), 0).(map[string]interface{})))
+		}
+		return zap.Any(field.Key, r.redactValue(reflect.ValueOf(encoder.Fields[field.Key]), 0))
+	}
+	return field
+}
+
+// redactFields redacts a list of fields.
+func (r *redactor) redactFields(fields []zapcore.Field) []zapcore.Field {
+	redacted := make([]zapcore.Field, len(fields))
+	for i, field := range fields {
+		redacted[i] = r.redactField(field)
+	}
+	return redacted
+}
+
+// stringerValue calls String on a fmt.Stringer, tolerating nil receivers.
+func stringerValue(value interface{}) (text string) {
+	defer func() {
+		if recover() != nil {
+			text = "<nil>"
+		}
+	}()
+	return value.(fmt.Stringer).String()
+}
+
+// redactedError replaces an error whose message contained sensitive data.
+type redactedError struct {
+	message string
+}
+
+// Error returns the redacted error message.
+func (e *redactedError) Error() string {
+	return e.message
+}
+
+// redactedObject adds the members of a redacted map to an encoder.
+type redactedObject map[string]interface{}
+
+// MarshalLogObject implements zapcore.ObjectMarshaler.
+func (o redactedObject) MarshalLogObject(encoder zapcore.ObjectEncoder) error {
+	for key, value := range o {
+		zap.Any(key, value).AddTo(encoder)
+	}
+	return nil
+}
+
+// redactCore is a zapcore.Core that redacts entries before passing them to the wrapped core.
+type redactCore struct {
+	zapcore.Core
+	redactor *redactor
+}
+
+// With redacts the fields and adds them to the wrapped core.
+func (c *redactCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactCore{Core: c.Core.With(c.redactor.redactFields(fields)), redactor: c.redactor}
+}
+
+// Check adds the core to the checked entry if the wrapped core is enabled for the level.
+func (c *redactCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+// Write redacts the message and fields, then writes them to the cores of the wrapped core that accept the entry.
+func (c *redactCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	entry.Message = c.redactor.redactString(entry.Message)
+	if inner := c.Core.Check(entry, nil); inner != nil {
+		inner.Write(c.redactor.redactFields(fields)...)
+	}
+	return nil
+}
+
+// redactEvent masks sensitive data in a Sentry event before it is sent.
+func (r *redactor) redactEvent(event *sentry.Event) *sentry.Event {
+	event.Message = r.redactString(event.Message)
+	for i := range event.Exception {
+		event.Exception[i].Value = r.redactString(event.Exception[i].Value)
+	}
+	for key, value := range event.Extra {
+		if r.isSensitiveKey(key) {
+			event.Extra[key] = r.mask
+			continue
+		}
+		event.Extra[key] = r.redactValue(reflect.ValueOf(value), 0)
+	}
+	for _, breadcrumb := range event.Breadcrumbs {
+		r.redactBreadcrumb(breadcrumb)
+	}
+	if event.Request != nil {
+		for name := range event.Request.Headers {
+			if r.isSensitiveKey(name) {
+				event.Request.Headers[name] = r.mask
+			} else {
+				event.Request.Headers[name] = r.redactString(event.Request.Headers[name])
+			}
+		}
+		if event.Request.Cookies != "" {
+			event.Request.Cookies = r.mask
+		}
+		event.Request.URL = r.redactString(event.Request.URL)
+		event.Request.QueryString = r.redactString(event.Request.QueryString)
+		event.Request.Data = r.redactString(event.Request.Data)
+	}
+	return event
+}
+
+// redactBreadcrumb masks sensitive data in a Sentry breadcrumb.
+func (r *redactor) redactBreadcrumb(breadcrumb *sentry.Breadcrumb) *sentry.Breadcrumb {
+	breadcrumb.Message = r.redactString(breadcrumb.Message)
+	if breadcrumb.Data != nil {
+		if redacted, ok := r.redactValue(reflect.ValueOf(breadcrumb.Data), 0).(map[string]interface{}); ok {
+			breadcrumb.Data = redacted
+		}
+	}
+	return breadcrumb
+}
+
+// luhnValid reports whether the digits of a candidate card number pass the Luhn checksum.
+func luhnValid(candidate string) bool {
+	sum, count, double := 0, 0, false
+	for i := len(candidate) - 1; i >= 0; i-- {
+		c := candidate[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		digit := int(c - '0')
+		if double {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		count++
+		double = !double
+	}
+	return count >= 13 && count <= 19 && sum%10 == 0
+}
@@ -0,0 +1,71 @@
+// Package logger provides utilities for configuring and initializing the application's logger.
+// This file implements log sampling for high-volume, low-severity entries.
+package logger
+
+import (
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// SamplingConfig defines how repeated log entries are sampled.
+// Within each Tick, the first Initial entries with the same level and message are logged,
+// then only every Thereafter-th entry. Entries above MaxLevel are never sampled.
+type SamplingConfig struct {
+	Tick       time.Duration `json:"tick"`       // Sampling interval (e.g., 1s)
+	Initial    int           `json:"initial"`    // Entries logged per tick before sampling starts
+	Thereafter int           `json:"thereafter"` // Log every Nth entry after Initial is reached
+	MaxLevel   zapcore.Level `json:"maxLevel"`   // Highest level subject to sampling (e.g., INFO)
+}
+
+// DefaultSamplingConfig returns the default sampling configuration.
+//
+// Defaults:
+// - Tick: 1 second
+// - Initial: 100 entries per message and tick
+// - Thereafter: every 100th entry
+// - MaxLevel: zapcore.InfoLevel (warnings and errors are always logged)
+//
+// Returns:
+// - *SamplingConfig: The default sampling configuration.
+func DefaultSamplingConfig() *SamplingConfig {
+	return &SamplingConfig{
+		Tick:       time.Second,
+		Initial:    100,
+		Thereafter: 100,
+		MaxLevel:   zapcore.InfoLevel,
+	}
+}
+
+// sampledCore is a zapcore.Core that samples entries up to maxLevel and passes the rest through.
+type sampledCore struct {
+	zapcore.Core
+	sampler  zapcore.Core
+	maxLevel zapcore.Level
+}
+
+// newSampledCore wraps a core with the given sampling configuration.
+func newSampledCore(core zapcore.Core, config *SamplingConfig) zapcore.Core {
+	return &sampledCore{
+		Core:     core,
+		sampler:  zapcore.NewSamplerWithOptions(core, config.Tick, config.Initial, config.Thereafter),
+		maxLevel: config.MaxLevel,
+	}
+}
+
+// With returns a copy of the core with the given fields.
+func (c *sampledCore) With(fields []zapcore.Field) zapcore.Core {
+	return &sampledCore{
+		Core:     c.Core.With(fields),
+		sampler:  c.sampler.With(fields),
+		maxLevel: c.maxLevel,
+	}
+}
+
+// Check routes low-severity entries through the sampler.
+func (c *sampledCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if entry.Level <= c.maxLevel {
+		return c.sampler.Check(entry, checked)
+	}
+	return c.Core.Check(entry, checked)
+}
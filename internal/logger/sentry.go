@@ -0,0 +1,209 @@
+// Package logger provides utilities for configuring and initializing the application's logger.
+// This file implements a Zap core that forwards log entries to Sentry.
+package logger
+
+import (
+	"context"
+	"strings"
+
+	"github.com/getsentry/sentry-go"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// sentryHubKey is the field key used to attach a request-scoped Sentry hub to a logger.
+const sentryHubKey = "sentry_hub"
+
+// SentryHub returns a Zap field that routes entries of the logger it is attached to
+// through the given Sentry hub instead of the global one.
+// The field is skipped by all regular encoders, so it never appears in log output.
+//
+// Parameters:
+// - hub (*sentry.Hub): The hub to report events and breadcrumbs to.
+//
+// Returns:
+// - zap.Field: The field to pass to Logger.With.
+func SentryHub(hub *sentry.Hub) zap.Field {
+	return zap.Field{Key: sentryHubKey, Type: zapcore.SkipType, Interface: hub}
+}
+
+// FromContext returns a logger bound to the Sentry hub stored in the context, if any.
+// Request handlers should use it with c.UserContext() so that their log entries become
+// breadcrumbs and events of the request's Sentry scope.
+//
+// Parameters:
+// - ctx (context.Context): The context carrying the request-scoped Sentry hub.
+//
+// Returns:
+// - *zap.Logger: The request-scoped logger, or the global logger if no hub is set.
+func FromContext(ctx context.Context) *zap.Logger {
+	base := Log
+	if base == nil {
+		base = zap.L()
+	}
+	if ctx != nil && sentry.HasHubOnContext(ctx) {
+		return base.With(SentryHub(sentry.GetHubFromContext(ctx)))
+	}
+	return base
+}
+
+// sentryCore is a zapcore.Core that reports entries to Sentry:
+// - Entries at or above eventLevel are captured as Sentry events, with all fields as extras and a stack trace.
+// - Entries at or above breadcrumbLevel (and below eventLevel) are recorded as breadcrumbs.
+type sentryCore struct {
+	breadcrumbLevel zapcore.Level
+	eventLevel      zapcore.Level
+	hub             *sentry.Hub
+	fields          []zapcore.Field
+}
+
+// newSentryCore creates a Sentry core reporting through the global hub.
+func newSentryCore(breadcrumbLevel, eventLevel zapcore.Level) *sentryCore {
+	return &sentryCore{
+		breadcrumbLevel: breadcrumbLevel,
+		eventLevel:      eventLevel,
+	}
+}
+
+// Enabled reports whether the entry level produces a breadcrumb or an event.
+func (c *sentryCore) Enabled(level zapcore.Level) bool {
+	return level >= c.breadcrumbLevel
+}
+
+// With returns a copy of the core with the given fields added to every entry.
+func (c *sentryCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.fields = append(make([]zapcore.Field, 0, len(c.fields)+len(fields)), c.fields...)
+	for _, field := range fields {
+		if hub, ok := field.Interface.(*sentry.Hub); ok && field.Key == sentryHubKey {
+			clone.hub = hub
+			continue
+		}
+		clone.fields = append(clone.fields, field)
+	}
+	return &clone
+}
+
+// Check adds the core to the checked entry if the level is enabled.
+func (c *sentryCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+// Write sends the entry to Sentry as an event or a breadcrumb depending on its level.
+func (c *sentryCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	hub := c.hub
+	if hub == nil {
+		hub = sentry.CurrentHub()
+	}
+
+	// Encode all fields into a map so they can be attached to the event or breadcrumb.
+	encoder := zapcore.NewMapObjectEncoder()
+	var entryErr error
+	all := make([]zapcore.Field, 0, len(c.fields)+len(fields))
+	all = append(append(all, c.fields...), fields...)
+	for _, field := range all {
+		if field.Key == sentryHubKey {
+			if fieldHub, ok := field.Interface.(*sentry.Hub); ok {
+				hub = fieldHub
+			}
+			continue
+		}
+		if err, ok := field.Interface.(error); ok && field.Type == zapcore.ErrorType && entryErr == nil {
+			entryErr = err
+		}
+		field.AddTo(encoder)
+	}
+
+	if entry.Level < c.eventLevel {
+		hub.AddBreadcrumb(&sentry.Breadcrumb{
+			Type:      "default",
+			Category:  breadcrumbCategory(entry),
+			Message:   entry.Message,
+			Data:      encoder.Fields,
+			Level:     sentryLevel(entry.Level),
+			Timestamp: entry.Time,
+		}, nil)
+		return nil
+	}
+
+	client := hub.Client()
+	if client == nil {
+		return nil
+	}
+
+	var event *sentry.Event
+	if entryErr != nil {
+		// Keep the error chain (and any stack trace it carries) as the exception.
+		event = client.EventFromException(entryErr, sentryLevel(entry.Level))
+		event.Message = entry.Message
+	} else {
+		event = client.EventFromMessage(entry.Message, sentryLevel(entry.Level))
+	}
+
+	// Attach the stack trace of the log call when the error did not provide one.
+	stacktrace := callerStacktrace()
+	if len(event.Exception) > 0 {
+		last := &event.Exception[len(event.Exception)-1]
+		if last.Stacktrace == nil {
+			last.Stacktrace = stacktrace
+		}
+	} else {
+		event.Threads = []sentry.Thread{{Stacktrace: stacktrace, Current: true, Crashed: false}}
+	}
+
+	event.Logger = entry.LoggerName
+	event.Timestamp = entry.Time
+	event.Extra = encoder.Fields
+	hub.CaptureEvent(event)
+	return nil
+}
+
+// Sync flushes buffered Sentry events.
+func (c *sentryCore) Sync() error {
+	sentry.Flush(flushTimeout)
+	return nil
+}
+
+// callerStacktrace captures the current stack trace without the logging frames.
+func callerStacktrace() *sentry.Stacktrace {
+	stacktrace := sentry.NewStacktrace()
+	if stacktrace == nil {
+		return nil
+	}
+	frames := make([]sentry.Frame, 0, len(stacktrace.Frames))
+	for _, frame := range stacktrace.Frames {
+		if strings.HasPrefix(frame.Module, "go.uber.org/zap") || frame.Module == "gobo/internal/logger" {
+			continue
+		}
+		frames = append(frames, frame)
+	}
+	stacktrace.Frames = frames
+	return stacktrace
+}
+
+// breadcrumbCategory returns the breadcrumb category for a log entry.
+func breadcrumbCategory(entry zapcore.Entry) string {
+	if entry.LoggerName != "" {
+		return entry.LoggerName
+	}
+	return "log"
+}
+
+// sentryLevel maps a Zap level to the corresponding Sentry level.
+func sentryLevel(level zapcore.Level) sentry.Level {
+	switch level {
+	case zapcore.DebugLevel:
+		return sentry.LevelDebug
+	case zapcore.InfoLevel:
+		return sentry.LevelInfo
+	case zapcore.WarnLevel:
+		return sentry.LevelWarning
+	case zapcore.ErrorLevel:
+		return sentry.LevelError
+	default:
+		return sentry.LevelFatal
+	}
+}
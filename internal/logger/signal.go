@@ -0,0 +1,56 @@
+//go:build !windows
+
+// Package logger provides utilities for configuring and initializing the application's logger.
+// This file implements log level control through Unix signals.
+package logger
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// HandleSignals changes the global log level when the process receives a signal:
+// - SIGUSR1 switches to DEBUG level, reverting automatically after debugDuration.
+// - SIGUSR2 restores the configured level immediately.
+// It returns a function that stops listening for the signals.
+//
+// Parameters:
+// - debugDuration (time.Duration): How long DEBUG level stays active after SIGUSR1.
+//
+// Returns:
+// - func(): A function that stops the signal handler.
+func HandleSignals(debugDuration time.Duration) func() {
+	signals := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(signals, syscall.SIGUSR1, syscall.SIGUSR2)
+
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				switch sig {
+				case syscall.SIGUSR1:
+					SetLevel("", zapcore.DebugLevel, debugDuration)
+					FromContext(context.Background()).Info("Log level changed by signal",
+						zap.Stringer("level", zapcore.DebugLevel), zap.Duration("revertAfter", debugDuration))
+				case syscall.SIGUSR2:
+					ResetLevel("")
+					FromContext(context.Background()).Info("Log level restored by signal", zap.Stringer("level", GlobalLevel()))
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
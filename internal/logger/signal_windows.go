@@ -0,0 +1,13 @@
+//go:build windows
+
+// Package logger provides utilities for configuring and initializing the application's logger.
+// This file provides a no-op signal handler, as Windows has no SIGUSR signals.
+package logger
+
+import "time"
+
+// HandleSignals is a no-op on Windows, which does not support SIGUSR1 and SIGUSR2.
+// Use the admin endpoint to change the log level at runtime instead.
+func HandleSignals(debugDuration time.Duration) func() {
+	return func() {}
+}
@@ -0,0 +1,364 @@
+// Package logger provides utilities for configuring and initializing the application's logger.
+// This file implements the log sinks (stdout, rotated files, syslog and TCP) and their encoders.
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// Supported sink types.
+const (
+	SinkStdout = "stdout" // Standard output
+	SinkStderr = "stderr" // Standard error
+	SinkFile   = "file"   // A file with size and age based rotation
+	SinkSyslog = "syslog" // The local or a remote syslog daemon
+	SinkTCP    = "tcp"    // A raw TCP (or UDP) log collector
+)
+
+// Supported encodings.
+const (
+	EncodingJSON    = "json"    // One JSON object per line
+	EncodingConsole = "console" // Human-readable, tab separated
+	EncodingLogfmt  = "logfmt"  // key=value pairs
+)
+
+// SinkConfig defines a single log destination with its own level and encoding.
+type SinkConfig struct {
+	Type     string          `json:"type"`     // Sink type: stdout, stderr, file, syslog or tcp
+	Level    string          `json:"level"`    // Minimum level for this sink (e.g., "warn"); empty accepts every enabled entry
+	Encoding string          `json:"encoding"` // Encoding: json, console or logfmt; empty uses the environment default
+	Path     string          `json:"path"`     // File path (file sinks)
+	Network  string          `json:"network"`  // Network for syslog and tcp sinks (e.g., "tcp", "udp"); empty uses local syslog
+	Address  string          `json:"address"`  // Remote address for syslog and tcp sinks (e.g., "logs.example.com:514")
+	Tag      string          `json:"tag"`      // Syslog tag (defaults to "gobo")
+	Rotation *RotationConfig `json:"rotation"` // Rotation settings (file sinks); nil uses Config.Rotation
+}
+
+// RotationConfig defines when log files are rotated and how long old files are kept.
+type RotationConfig struct {
+	MaxSizeMB  int           `json:"maxSizeMB"`  // Rotate when the file reaches this size in megabytes
+	MaxAgeDays int           `json:"maxAgeDays"` // Delete rotated files older than this many days (0 keeps them)
+	MaxBackups int           `json:"maxBackups"` // Maximum number of rotated files to keep (0 keeps all)
+	Compress   bool          `json:"compress"`   // Compress rotated files with gzip
+	Interval   time.Duration `json:"interval"`   // Also rotate at this interval regardless of size (0 disables)
+}
+
+// DefaultRotationConfig returns the default rotation configuration.
+//
+// Defaults:
+// - MaxSizeMB: 100
+// - MaxAgeDays: 30
+// - MaxBackups: 10
+// - Compress: true
+// - Interval: 24 hours (daily rotation)
+//
+// Returns:
+// - RotationConfig: The default rotation configuration.
+func DefaultRotationConfig() RotationConfig {
+	return RotationConfig{
+		MaxSizeMB:  100,
+		MaxAgeDays: 30,
+		MaxBackups: 10,
+		Compress:   true,
+		Interval:   24 * time.Hour,
+	}
+}
+
+// sinks returns the sinks of the configuration.
+// If no sinks are configured, one sink per entry of OutputPaths is derived:
+// "stdout" and "stderr" map to the standard streams, anything else to a rotated file.
+func (config Config) sinks() []SinkConfig {
+	if len(config.Sinks) > 0 {
+		return config.Sinks
+	}
+	sinks := make([]SinkConfig, 0, len(config.OutputPaths))
+	for _, path := range config.OutputPaths {
+		switch path {
+		case SinkStdout, SinkStderr:
+			sinks = append(sinks, SinkConfig{Type: path})
+		default:
+			sinks = append(sinks, SinkConfig{Type: SinkFile, Path: path})
+		}
+	}
+	return sinks
+}
+
+// openSinks builds one core per sink and combines them.
+// The returned closer releases files and network connections held by the sinks.
+func openSinks(config Config, encoderConfig zapcore.EncoderConfig, defaultEncoding string) (zapcore.Core, io.Closer, error) {
+	var cores []zapcore.Core
+	var closers multiCloser
+	for _, sink := range config.sinks() {
+		encoder, err := newEncoder(sink.Encoding, defaultEncoding, encoderConfig)
+		if err != nil {
+			_ = closers.Close()
+			return nil, nil, err
+		}
+
+		writer, closer, err := openSinkWriter(sink, config.Rotation)
+		if err != nil {
+			_ = closers.Close()
+			return nil, nil, fmt.Errorf("failed to open %s sink: %w", sink.Type, err)
+		}
+		if closer != nil {
+			closers = append(closers, closer)
+		}
+
+		// The global level is applied by the level registry; sinks may only raise it.
+		enabler := zapcore.LevelEnabler(zapcore.DebugLevel)
+		if sink.Level != "" {
+			level, err := zapcore.ParseLevel(sink.Level)
+			if err != nil {
+				_ = closers.Close()
+				return nil, nil, err
+			}
+			enabler = level
+		}
+
+		cores = append(cores, zapcore.NewCore(encoder, writer, enabler))
+	}
+	return zapcore.NewTee(cores...), closers, nil
+}
+
+// newEncoder creates the encoder for the given encoding name.
+func newEncoder(encoding, defaultEncoding string, config zapcore.EncoderConfig) (zapcore.Encoder, error) {
+	if encoding == "" {
+		encoding = defaultEncoding
+	}
+	switch encoding {
+	case EncodingJSON:
+		return zapcore.NewJSONEncoder(config), nil
+	case EncodingConsole:
+		return zapcore.NewConsoleEncoder(config), nil
+	case EncodingLogfmt:
+		return newLogfmtEncoder(config), nil
+	default:
+		return nil, fmt.Errorf("unknown log encoding: %q", encoding)
+	}
+}
+
+// openSinkWriter opens the destination of a sink.
+func openSinkWriter(sink SinkConfig, defaultRotation RotationConfig) (zapcore.WriteSyncer, io.Closer, error) {
+	switch sink.Type {
+	case SinkStdout:
+		return zapcore.Lock(os.Stdout), nil, nil
+	case SinkStderr:
+		return zapcore.Lock(os.Stderr), nil, nil
+	case SinkFile:
+		rotation := defaultRotation
+		if sink.Rotation != nil {
+			rotation = *sink.Rotation
+		}
+		file, err := newRotatingFile(sink.Path, rotation)
+		if err != nil {
+			return nil, nil, err
+		}
+		return zapcore.AddSync(file), file, nil
+	case SinkSyslog:
+		tag := sink.Tag
+		if tag == "" {
+			tag = "gobo"
+		}
+		writer, err := newSyslogWriter(sink.Network, sink.Address, tag)
+		if err != nil {
+			return nil, nil, err
+		}
+		return zapcore.AddSync(writer), writer, nil
+	case SinkTCP:
+		if sink.Address == "" {
+			return nil, nil, errors.New("address is required")
+		}
+		network := sink.Network
+		if network == "" {
+			network = "tcp"
+		}
+		writer := newNetworkWriter(network, sink.Address)
+		return zapcore.AddSync(writer), writer, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown sink type: %q", sink.Type)
+	}
+}
+
+// rotatingFile is a log file rotated by size (and optionally by interval) with retention.
+type rotatingFile struct {
+	*lumberjack.Logger
+	stop chan struct{}
+	once sync.Once
+}
+
+// newRotatingFile creates the log directory if needed and opens a rotating file.
+func newRotatingFile(path string, rotation RotationConfig) (*rotatingFile, error) {
+	if path == "" {
+		return nil, errors.New("path is required")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+
+	file := &rotatingFile{
+		Logger: &lumberjack.Logger{
+			Filename:   path,
+			MaxSize:    rotation.MaxSizeMB,
+			MaxAge:     rotation.MaxAgeDays,
+			MaxBackups: rotation.MaxBackups,
+			Compress:   rotation.Compress,
+			LocalTime:  true,
+		},
+		stop: make(chan struct{}),
+	}
+
+	// Rotate periodically in addition to the size limit.
+	if rotation.Interval > 0 {
+		go func() {
+			ticker := time.NewTicker(rotation.Interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					_ = file.Rotate()
+				case <-file.stop:
+					return
+				}
+			}
+		}()
+	}
+	return file, nil
+}
+
+// Close stops the periodic rotation and closes the file.
+func (f *rotatingFile) Close() error {
+	f.once.Do(func() { close(f.stop) })
+	return f.Logger.Close()
+}
+
+// Delays between two connection attempts of a network sink, doubled after each failure.
+const (
+	minReconnectDelay = 100 * time.Millisecond
+	maxReconnectDelay = 30 * time.Second
+)
+
+// networkWriter writes log lines to a TCP or UDP endpoint, reconnecting on failure.
+// Entries written while the endpoint is unreachable are dropped rather than blocking the application:
+// the connection is made again in the background, with backoff.
+type networkWriter struct {
+	mu           sync.Mutex
+	network      string
+	address      string
+	conn         net.Conn
+	reconnecting bool          // Whether a background reconnection is running
+	stop         chan struct{} // Closed by Close, to stop reconnecting
+	once         sync.Once
+}
+
+// newNetworkWriter creates a writer to the endpoint. The first connection is made right away, so that the
+// entries written at startup are not dropped; if it fails, the writer reconnects in the background.
+func newNetworkWriter(network, address string) *networkWriter {
+	w := &networkWriter{network: network, address: address, stop: make(chan struct{})}
+	conn, err := net.DialTimeout(network, address, time.Second)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err != nil {
+		w.reconnect()
+	} else {
+		w.conn = conn
+	}
+	return w
+}
+
+// Write sends the log line. Lines written while disconnected are dropped; a failed write drops the
+// connection and starts reconnecting.
+func (w *networkWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn == nil {
+		w.reconnect()
+		return len(p), nil
+	}
+	_ = w.conn.SetWriteDeadline(time.Now().Add(time.Second))
+	n, err := w.conn.Write(p)
+	if err != nil {
+		_ = w.conn.Close()
+		w.conn = nil
+		w.reconnect()
+		return n, fmt.Errorf("failed to write to %s://%s: %w", w.network, w.address, err)
+	}
+	return n, nil
+}
+
+// reconnect starts connecting again in the background, unless it is already. The caller holds the mutex.
+func (w *networkWriter) reconnect() {
+	if w.reconnecting {
+		return
+	}
+	select {
+	case <-w.stop:
+		return
+	default:
+	}
+	w.reconnecting = true
+	go func() {
+		delay := minReconnectDelay
+		for {
+			select {
+			case <-w.stop:
+				w.mu.Lock()
+				w.reconnecting = false
+				w.mu.Unlock()
+				return
+			case <-time.After(delay):
+			}
+			conn, err := net.DialTimeout(w.network, w.address, time.Second)
+			if err != nil {
+				delay = min(delay*2, maxReconnectDelay)
+				continue
+			}
+			w.mu.Lock()
+			select {
+			case <-w.stop:
+				_ = conn.Close()
+			default:
+				w.conn = conn
+			}
+			w.reconnecting = false
+			w.mu.Unlock()
+			return
+		}
+	}()
+}
+
+// Close stops reconnecting and closes the connection.
+func (w *networkWriter) Close() error {
+	w.once.Do(func() { close(w.stop) })
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+// multiCloser closes several resources, returning the first error.
+type multiCloser []io.Closer
+
+// Close closes all resources.
+func (m multiCloser) Close() error {
+	var first error
+	for _, closer := range m {
+		if err := closer.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
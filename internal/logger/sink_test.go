@@ -0,0 +1,195 @@
+// Package logger_test contains tests for the logger package.
+// These tests validate the log sinks, their encodings and file rotation.
+package logger_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gobo/internal/logger"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// TestSinks_LevelsAndEncodings verifies that each sink applies its own level and encoding,
+// and that missing log directories are created.
+func TestSinks_LevelsAndEncodings(t *testing.T) {
+	dir := t.TempDir()
+	jsonPath := filepath.Join(dir, "nested", "errors.log")
+	logfmtPath := filepath.Join(dir, "app.logfmt")
+
+	logger.InitLogger(logger.Config{
+		Level:       zapcore.DebugLevel,
+		Environment: "production",
+		Sinks: []logger.SinkConfig{
+			{Type: logger.SinkFile, Path: jsonPath, Level: "warn", Encoding: logger.EncodingJSON},
+			{Type: logger.SinkFile, Path: logfmtPath, Encoding: logger.EncodingLogfmt},
+		},
+	})
+
+	logger.Log.Debug("debug entry", zap.String("user", "john doe"))
+	logger.Log.Warn("warn entry", zap.Int("attempt", 3))
+	_ = logger.Log.Sync()
+
+	// The JSON sink only receives WARN and above.
+	content, err := os.ReadFile(jsonPath)
+	assert.NoError(t, err, "Expected the nested log directory to be created")
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	assert.Len(t, lines, 1)
+	var entry map[string]interface{}
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &entry))
+	assert.Equal(t, "warn entry", entry["msg"])
+	assert.Equal(t, float64(3), entry["attempt"])
+
+	// The logfmt sink receives every entry as key=value pairs.
+	content, err = os.ReadFile(logfmtPath)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), `level=debug`)
+	assert.Contains(t, string(content), `msg="debug entry"`)
+	assert.Contains(t, string(content), `user="john doe"`)
+	assert.Contains(t, string(content), `msg="warn entry"`)
+	assert.Contains(t, string(content), `attempt=3`)
+}
+
+// TestSinks_OutputPathsCreateDirectory verifies that file output paths work when the directory does not exist.
+func TestSinks_OutputPathsCreateDirectory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "app.log")
+
+	config := logger.DefaultConfig()
+	config.OutputPaths = []string{path}
+	logger.InitLogger(config)
+	logger.Log.Info("file entry")
+	_ = logger.Log.Sync()
+
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "file entry")
+}
+
+// TestSinks_Rotation verifies that a file sink is rotated and old files are compressed.
+func TestSinks_Rotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	logger.InitLogger(logger.Config{
+		Level:       zapcore.InfoLevel,
+		Environment: "production",
+		Sinks: []logger.SinkConfig{{
+			Type: logger.SinkFile,
+			Path: path,
+			Rotation: &logger.RotationConfig{
+				MaxSizeMB:  1,
+				MaxBackups: 1,
+				Compress:   true,
+			},
+		}},
+	})
+
+	// Write a little more than 1 MB to trigger a rotation.
+	payload := strings.Repeat("x", 1024)
+	for i := 0; i < 1100; i++ {
+		logger.Log.Info("rotation entry", zap.String("payload", payload))
+	}
+
+	assert.Eventually(t, func() bool {
+		matches, _ := filepath.Glob(filepath.Join(dir, "app-*.log.gz"))
+		return len(matches) == 1
+	}, 5*time.Second, 50*time.Millisecond, "Expected one compressed backup file")
+}
+
+// TestSinks_TCP verifies that entries are sent to a TCP collector.
+func TestSinks_TCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		line, _ := bufio.NewReader(conn).ReadString('\n')
+		received <- line
+	}()
+
+	logger.InitLogger(logger.Config{
+		Level:       zapcore.InfoLevel,
+		Environment: "production",
+		Sinks: []logger.SinkConfig{
+			{Type: logger.SinkTCP, Address: listener.Addr().String(), Encoding: logger.EncodingLogfmt, Level: "info"},
+		},
+	})
+
+	select {
+	case line := <-received:
+		assert.Contains(t, line, `msg="Logger initialized successfully"`)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected an entry on the TCP sink")
+	}
+}
+
+// TestSinks_TCPReconnect verifies that entries are dropped without blocking while the TCP collector is down,
+// and sent again once it is back.
+func TestSinks_TCPReconnect(t *testing.T) {
+	// Reserve an address, and close it until the collector starts.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	logger.InitLogger(logger.Config{
+		Level:       zapcore.InfoLevel,
+		Environment: "production",
+		Sinks: []logger.SinkConfig{
+			{Type: logger.SinkTCP, Address: address, Encoding: logger.EncodingLogfmt, Level: "info"},
+		},
+	})
+	start := time.Now()
+	for i := 0; i < 100; i++ {
+		logger.Log.Info("dropped message")
+	}
+	assert.Less(t, time.Since(start), 500*time.Millisecond, "Expected the entries to be dropped without blocking")
+
+	listener, err = net.Listen("tcp", address)
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		line, _ := bufio.NewReader(conn).ReadString('\n')
+		received <- line
+	}()
+
+	deadline := time.After(5 * time.Second)
+	for {
+		logger.Log.Info("delivered message")
+		select {
+		case line := <-received:
+			assert.Contains(t, line, `msg="delivered message"`)
+			return
+		case <-deadline:
+			t.Fatal("Expected an entry once the TCP collector is back")
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
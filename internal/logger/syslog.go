@@ -0,0 +1,17 @@
+//go:build !windows
+
+// Package logger provides utilities for configuring and initializing the application's logger.
+// This file implements the syslog sink.
+package logger
+
+import (
+	"io"
+	"log/syslog"
+)
+
+// newSyslogWriter connects to the syslog daemon.
+// An empty network connects to the local daemon; otherwise network and address select a remote one.
+// Entries are sent with the INFO priority; the level is part of the encoded line.
+func newSyslogWriter(network, address, tag string) (io.WriteCloser, error) {
+	return syslog.Dial(network, address, syslog.LOG_INFO|syslog.LOG_USER, tag)
+}
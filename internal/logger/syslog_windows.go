@@ -0,0 +1,15 @@
+//go:build windows
+
+// Package logger provides utilities for configuring and initializing the application's logger.
+// This file reports that the syslog sink is unavailable on Windows.
+package logger
+
+import (
+	"errors"
+	"io"
+)
+
+// newSyslogWriter always fails, as syslog is not available on Windows.
+func newSyslogWriter(network, address, tag string) (io.WriteCloser, error) {
+	return nil, errors.New("syslog is not supported on windows")
+}
@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"gobo/internal/audit"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AuditMiddleware stores the request ID and client IP address as the audit actor in the user context
+// (see audit.NewContext). Authentication middleware adds the username once the user is known.
+// Database changes made with db.GormDB.WithContext(c.UserContext()) are attributed to this actor.
+// Register it after the request ID middleware.
+func AuditMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.SetUserContext(audit.NewContext(c.UserContext(), audit.Actor{
+			User:      userFromLocals(c),
+			RequestID: c.GetRespHeader(fiber.HeaderXRequestID),
+			IP:        c.IP(),
+		}))
+		return c.Next()
+	}
+}
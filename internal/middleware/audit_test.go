@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"gobo/internal/audit"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestAuditMiddleware tests that the audit actor carries the request ID, client IP and authenticated user.
+func TestAuditMiddleware(t *testing.T) {
+	var actor audit.Actor
+	app := fiber.New()
+	app.Use(requestid.New(), AuditMiddleware())
+	app.Get("/protected", BasicAuthMiddleware("admin", "password"), func(c *fiber.Ctx) error {
+		actor = audit.FromContext(c.UserContext())
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+
+	req := httptest.NewRequest("GET", "/protected", nil)
+	req.Header.Set(fiber.HeaderXRequestID, "req-1")
+	req.SetBasicAuth("admin", "password")
+	resp, err := app.Test(req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 204, resp.StatusCode)
+	assert.Equal(t, audit.Actor{User: "admin", RequestID: "req-1", IP: "0.0.0.0"}, actor)
+}
@@ -4,45 +4,85 @@ import (
 	"encoding/base64"
 	"strings"
 
+	"gobo/internal/apperror"
+	"gobo/internal/audit"
+
 	"github.com/gofiber/fiber/v2"
 )
 
+// UsernameLocalKey is the Fiber locals key under which the authenticated username is stored.
+const UsernameLocalKey = "username"
+
+// Credentials of the administrator, the user of the Basic authentication of the routes (see routes.Register).
+const (
+	AdminUsername = "admin"
+	AdminPassword = "password"
+)
+
 // BasicAuthMiddleware provides basic authentication for routes.
-func BasicAuthMiddleware(username, password string) fiber.Handler {
+// On success, the username is stored in the locals under UsernameLocalKey;
+// otherwise an apperror.Unauthorized error is returned to the error handler.
+func BasicAuthMiddleware(expectedUsername, expectedPassword string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		// Get the Authorization header
-		authHeader := c.Get("Authorization")
-		if authHeader == "" || !strings.HasPrefix(authHeader, "Basic ") {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Unauthorized",
-			})
-		}
-
-		// Decode the Base64 encoded credentials
-		encodedCredentials := strings.TrimPrefix(authHeader, "Basic ")
-		decodedCredentials, err := base64.StdEncoding.DecodeString(encodedCredentials)
+		username, password, err := basicCredentials(c)
 		if err != nil {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Invalid authorization header",
-			})
-		}
-
-		// Split the decoded credentials into username and password
-		credentials := strings.SplitN(string(decodedCredentials), ":", 2)
-		if len(credentials) != 2 {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Invalid authorization header format",
-			})
+			return err
 		}
 
 		// Validate the credentials
-		if credentials[0] != username || credentials[1] != password {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Invalid credentials",
-			})
+		if username != expectedUsername || password != expectedPassword {
+			return apperror.Unauthorized("").WithKey("error.invalid_credentials")
 		}
 
+		// Expose the authenticated user to the handlers, error reporting and the audit log
+		c.Locals(UsernameLocalKey, username)
+		c.SetUserContext(audit.WithUser(c.UserContext(), username))
+
 		// Allow the request to proceed
 		return c.Next()
 	}
 }
+
+// BasicAuthIf requires basic authentication only for requests matching the condition,
+// e.g. requests asking for data reserved to administrators; other requests proceed anonymously.
+//
+// Parameters:
+// - condition (func(*fiber.Ctx) bool): Reports whether the request must be authenticated.
+// - username (string): The expected username.
+// - password (string): The expected password.
+//
+// Returns:
+// - fiber.Handler: The conditional authentication middleware.
+func BasicAuthIf(condition func(c *fiber.Ctx) bool, username, password string) fiber.Handler {
+	auth := BasicAuthMiddleware(username, password)
+	return func(c *fiber.Ctx) error {
+		if !condition(c) {
+			return c.Next()
+		}
+		return auth(c)
+	}
+}
+
+// basicCredentials returns the username and password of the Basic Authorization header of the request.
+// A missing or malformed header returns an apperror.Unauthorized error.
+func basicCredentials(c *fiber.Ctx) (string, string, error) {
+	// Get the Authorization header
+	authHeader := c.Get("Authorization")
+	if authHeader == "" || !strings.HasPrefix(authHeader, "Basic ") {
+		return "", "", apperror.Unauthorized("").WithKey("error.unauthorized")
+	}
+
+	// Decode the Base64 encoded credentials
+	encodedCredentials := strings.TrimPrefix(authHeader, "Basic ")
+	decodedCredentials, err := base64.StdEncoding.DecodeString(encodedCredentials)
+	if err != nil {
+		return "", "", apperror.Unauthorized("").WithKey("error.invalid_authorization_header")
+	}
+
+	// Split the decoded credentials into username and password
+	credentials := strings.SplitN(string(decodedCredentials), ":", 2)
+	if len(credentials) != 2 {
+		return "", "", apperror.Unauthorized("").WithKey("error.invalid_authorization_header_format")
+	}
+	return credentials[0], credentials[1], nil
+}
@@ -87,3 +87,29 @@ func TestBasicAuthMiddlewareMissingHeader(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, 401, resp.StatusCode)
 }
+
+// TestBasicAuthIf tests that authentication is only required for matching requests.
+func TestBasicAuthIf(t *testing.T) {
+	// Create a new Fiber app with a route requiring authentication for ?private=true
+	app := fiber.New()
+	private := func(c *fiber.Ctx) bool { return c.QueryBool("private") }
+	app.Get("/items", BasicAuthIf(private, "admin", "password"), func(c *fiber.Ctx) error {
+		return c.SendString("Items")
+	})
+
+	// Public requests proceed without credentials
+	resp, err := app.Test(httptest.NewRequest("GET", "/items", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	// Private requests require credentials
+	resp, err = app.Test(httptest.NewRequest("GET", "/items?private=true", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, 401, resp.StatusCode)
+
+	req := httptest.NewRequest("GET", "/items?private=true", nil)
+	req.SetBasicAuth("admin", "password")
+	resp, err = app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+}
@@ -0,0 +1,291 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"time"
+
+	"gobo/internal/apperror"
+	"gobo/internal/cache"
+	"gobo/internal/i18n"
+	"gobo/internal/logger"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// Idempotency headers and record states.
+const (
+	HeaderIdempotencyKey      = "Idempotency-Key"     // Client-generated key identifying a request (e.g., a UUID)
+	HeaderIdempotentReplayed  = "Idempotent-Replayed" // Set to "true" on responses replayed from the store
+	maxIdempotencyKeyLength   = 255                   // Longer keys are rejected
+	idempotencyStateInFlight  = "in_flight"           // The first request is still being processed
+	idempotencyStateCompleted = "completed"           // The response of the first request is stored
+)
+
+// IdempotencyConfig defines how idempotent requests are stored.
+type IdempotencyConfig struct {
+	TTL         time.Duration         // How long responses are replayed (defaults to 24 hours)
+	LockTimeout time.Duration         // How long an in-flight request holds its key without refreshing it (defaults to 1 minute)
+	KeyPrefix   string                // Prefix of the Redis keys, after the tenant prefix (defaults to "idempotency:")
+	Required    bool                  // Reject requests without an Idempotency-Key header
+	Client      redis.UniversalClient // Redis client; nil uses cache.RedisClient
+}
+
+// DefaultIdempotencyConfig returns the default idempotency configuration.
+//
+// Defaults:
+// - TTL: 24 hours, or the IDEMPOTENCY_TTL environment variable (e.g., "12h")
+// - LockTimeout: 1 minute
+// - KeyPrefix: "idempotency:"
+// - Required: false
+//
+// Returns:
+// - IdempotencyConfig: The default idempotency configuration.
+func DefaultIdempotencyConfig() IdempotencyConfig {
+	ttl := 24 * time.Hour
+	if value := os.Getenv("IDEMPOTENCY_TTL"); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil && parsed > 0 {
+			ttl = parsed
+		}
+	}
+	return IdempotencyConfig{
+		TTL:         ttl,
+		LockTimeout: time.Minute,
+		KeyPrefix:   "idempotency:",
+	}
+}
+
+// idempotencyRecord is the stored state of an idempotent request.
+type idempotencyRecord struct {
+	State       string            `json:"state"`             // in_flight or completed
+	Token       string            `json:"token,omitempty"`   // Random token of the request holding the key, while in flight
+	Fingerprint string            `json:"fingerprint"`       // Hash of the request payload and response language
+	Status      int               `json:"status,omitempty"`  // Response status code
+	Headers     map[string]string `json:"headers,omitempty"` // Response headers
+	Body        []byte            `json:"body,omitempty"`    // Response body
+}
+
+// The claim of an in-flight request is only refreshed, released or replaced by its response while the
+// request still holds it (i.e., while the stored claim is the one it wrote).
+var (
+	refreshIdempotencyClaim = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+	releaseIdempotencyClaim = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+	completeIdempotencyClaim = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	redis.call("SET", KEYS[1], ARGV[2], "PX", ARGV[3])
+	return 1
+end
+return 0`)
+)
+
+// IdempotencyMiddleware makes requests carrying an Idempotency-Key header safe to retry.
+// The first response for a key, tenant, user and route is stored in Redis and replayed for repeated requests.
+// - A request with the same key while the first one is still running is rejected with 409 Conflict; the first
+// request refreshes its claim of the key until its response is stored, however long it runs.
+// - A request reusing a key with a different payload or response language (see LocaleMiddleware) is rejected
+// with 422 Unprocessable Entity.
+// - Server errors (5xx) are not stored, so the request can be retried with the same key.
+// If Redis is unavailable, requests are processed without idempotency protection.
+// Register it after authentication, so that keys are scoped to the authenticated user.
+func IdempotencyMiddleware(config IdempotencyConfig) fiber.Handler {
+	defaults := DefaultIdempotencyConfig()
+	if config.TTL <= 0 {
+		config.TTL = defaults.TTL
+	}
+	if config.LockTimeout <= 0 {
+		config.LockTimeout = defaults.LockTimeout
+	}
+	if config.KeyPrefix == "" {
+		config.KeyPrefix = defaults.KeyPrefix
+	}
+
+	return func(c *fiber.Ctx) error {
+		key := c.Get(HeaderIdempotencyKey)
+		if key == "" {
+			if config.Required {
+				return apperror.BadRequest("").WithKey("error.idempotency_key_missing").WithCode("idempotency_key_missing")
+			}
+			return c.Next()
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			return apperror.BadRequest("").WithKey("error.idempotency_key_invalid").WithCode("invalid_idempotency_key")
+		}
+
+		client := config.Client
+		if client == nil {
+			if cache.RedisClient == nil {
+				return c.Next()
+			}
+			client = cache.RedisClient
+		}
+
+		ctx := c.UserContext()
+		log := logger.FromContext(ctx).Named("idempotency")
+		storeKey := cache.TenantKey(ctx, config.KeyPrefix+hash(userFromLocals(c), c.Method(), c.Path(), key))
+		fingerprint := hash(string(c.Request().Header.ContentType()), string(c.Body()), i18n.FromContext(ctx))
+
+		// Claim the key; only the first request runs the handler.
+		inFlight, _ := json.Marshal(idempotencyRecord{
+			State:       idempotencyStateInFlight,
+			Token:       uuid.NewString(),
+			Fingerprint: fingerprint,
+		})
+		claimed, err := client.SetNX(ctx, storeKey, inFlight, config.LockTimeout).Result()
+		if err != nil {
+			log.Warn("Idempotency store unavailable, processing request without protection", zap.Error(err))
+			return c.Next()
+		}
+
+		if !claimed {
+			record, err := loadIdempotencyRecord(ctx, client, storeKey)
+			if err != nil {
+				log.Warn("Failed to read idempotency record, processing request without protection", zap.Error(err))
+				return c.Next()
+			}
+			switch {
+			case record == nil:
+				// The record expired between the two commands; let the client retry.
+				return apperror.Conflict("").WithKey("error.idempotency_key_in_progress").WithCode("idempotency_key_in_progress")
+			case record.Fingerprint != fingerprint:
+				return apperror.New(fiber.StatusUnprocessableEntity, "idempotency_key_reused", "").WithKey("error.idempotency_key_reused")
+			case record.State != idempotencyStateCompleted:
+				return apperror.Conflict("").WithKey("error.idempotency_key_in_progress").WithCode("idempotency_key_in_progress")
+			default:
+				return replay(c, record)
+			}
+		}
+
+		// Hold the key while the handler runs, and render errors here so the final response can be stored.
+		storeCtx := context.WithoutCancel(ctx)
+		stopRefreshing := refreshIdempotencyKey(storeCtx, client, storeKey, inFlight, config.LockTimeout)
+		err = c.Next()
+		stopRefreshing()
+		if err != nil {
+			if handlerErr := c.App().ErrorHandler(c, err); handlerErr != nil {
+				_ = releaseIdempotencyClaim.Run(storeCtx, client, []string{storeKey}, inFlight).Err()
+				return handlerErr
+			}
+		}
+
+		// Release the key on server errors so that the request can be retried.
+		status := c.Response().StatusCode()
+		if status >= fiber.StatusInternalServerError {
+			if err := releaseIdempotencyClaim.Run(storeCtx, client, []string{storeKey}, inFlight).Err(); err != nil {
+				log.Warn("Failed to release idempotency key", zap.Error(err))
+			}
+			return nil
+		}
+
+		completed, err := json.Marshal(idempotencyRecord{
+			State:       idempotencyStateCompleted,
+			Fingerprint: fingerprint,
+			Status:      status,
+			Headers:     responseHeaders(c),
+			Body:        c.Response().Body(),
+		})
+		if err == nil {
+			err = completeIdempotencyClaim.Run(storeCtx, client, []string{storeKey}, inFlight, completed,
+				config.TTL.Milliseconds()).Err()
+		}
+		if err != nil {
+			log.Warn("Failed to store idempotent response", zap.Error(err))
+		}
+		return nil
+	}
+}
+
+// refreshIdempotencyKey extends the claim of an in-flight request every third of the lock timeout, so that it
+// holds the key until its response is stored. The returned function stops refreshing.
+func refreshIdempotencyKey(ctx context.Context, client redis.UniversalClient, key string, claim []byte, timeout time.Duration) func() {
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(timeout / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				err := refreshIdempotencyClaim.Run(ctx, client, []string{key}, claim, timeout.Milliseconds()).Err()
+				if err != nil {
+					logger.FromContext(ctx).Named("idempotency").Warn("Failed to refresh idempotency key", zap.Error(err))
+				}
+			}
+		}
+	}()
+	return func() {
+		close(stop)
+		<-done
+	}
+}
+
+// loadIdempotencyRecord reads a stored record; it returns nil if the key does not exist.
+func loadIdempotencyRecord(ctx context.Context, client redis.UniversalClient, key string) (*idempotencyRecord, error) {
+	data, err := client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var record idempotencyRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// replay writes a stored response.
+func replay(c *fiber.Ctx, record *idempotencyRecord) error {
+	for name, value := range record.Headers {
+		c.Set(name, value)
+	}
+	c.Set(HeaderIdempotentReplayed, "true")
+	return c.Status(record.Status).Send(record.Body)
+}
+
+// responseHeaders returns the response headers worth replaying.
+func responseHeaders(c *fiber.Ctx) map[string]string {
+	headers := map[string]string{}
+	c.Response().Header.VisitAll(func(key, value []byte) {
+		switch name := string(key); name {
+		case fiber.HeaderContentLength, fiber.HeaderDate, fiber.HeaderConnection, fiber.HeaderTransferEncoding, fiber.HeaderSetCookie:
+		default:
+			headers[name] = string(value)
+		}
+	})
+	return headers
+}
+
+// userFromLocals returns the authenticated username, or an empty string for anonymous requests.
+func userFromLocals(c *fiber.Ctx) string {
+	username, _ := c.Locals(UsernameLocalKey).(string)
+	return username
+}
+
+// hash returns the hex-encoded SHA-256 of the NUL-separated parts.
+func hash(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,235 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gobo/internal/apperror"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+// newIdempotencyTestApp creates an app with a counting POST route protected by the IdempotencyMiddleware.
+// If hold is not nil, the handler calls it before responding (e.g., to block the request).
+func newIdempotencyTestApp(t *testing.T, hold func()) (*fiber.App, *miniredis.Miniredis, *int32) {
+	return newIdempotencyTestAppWithConfig(t, IdempotencyConfig{TTL: time.Hour}, hold)
+}
+
+// newIdempotencyTestAppWithConfig creates the app of newIdempotencyTestApp with the given configuration.
+func newIdempotencyTestAppWithConfig(t *testing.T, config IdempotencyConfig, hold func()) (*fiber.App, *miniredis.Miniredis, *int32) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	config.Client = client
+
+	var calls int32
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Post("/examples",
+		LocaleMiddleware(),
+		BasicAuthMiddleware("admin", "password"),
+		IdempotencyMiddleware(config),
+		func(c *fiber.Ctx) error {
+			if hold != nil {
+				hold()
+			}
+			n := atomic.AddInt32(&calls, 1)
+			if strings.Contains(string(c.Body()), "fail") {
+				return fmt.Errorf("database unavailable")
+			}
+			c.Set(fiber.HeaderLocation, fmt.Sprintf("/examples/%d", n))
+			return c.Status(fiber.StatusCreated).JSON(fiber.Map{"id": n})
+		},
+	)
+	return app, server, &calls
+}
+
+// postIdempotent performs an authenticated POST request with the given idempotency key and body.
+func postIdempotent(t *testing.T, app *fiber.App, key, body string) (*http.Response, string) {
+	return postIdempotentIn(t, app, "", key, body)
+}
+
+// postIdempotentIn performs the request of postIdempotent, accepting the given language.
+func postIdempotentIn(t *testing.T, app *fiber.App, language, key, body string) (*http.Response, string) {
+	req := httptest.NewRequest("POST", "/examples", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderIdempotencyKey, key)
+	if language != "" {
+		req.Header.Set(fiber.HeaderAcceptLanguage, language)
+	}
+	req.SetBasicAuth("admin", "password")
+	resp, err := app.Test(req, -1)
+	assert.NoError(t, err)
+	content, _ := io.ReadAll(resp.Body)
+	return resp, string(content)
+}
+
+// TestIdempotencyMiddlewareReplay tests that a repeated request replays the first response.
+func TestIdempotencyMiddlewareReplay(t *testing.T) {
+	app, server, calls := newIdempotencyTestApp(t, nil)
+
+	// Perform the first request
+	resp, body := postIdempotent(t, app, "key-1", `{"name": "Example"}`)
+	assert.Equal(t, 201, resp.StatusCode)
+	assert.Empty(t, resp.Header.Get(HeaderIdempotentReplayed))
+
+	// Repeat the request with the same key
+	replayed, replayedBody := postIdempotent(t, app, "key-1", `{"name": "Example"}`)
+	assert.Equal(t, 201, replayed.StatusCode)
+	assert.Equal(t, body, replayedBody)
+	assert.Equal(t, "/examples/1", replayed.Header.Get(fiber.HeaderLocation))
+	assert.Equal(t, fiber.MIMEApplicationJSON, replayed.Header.Get(fiber.HeaderContentType))
+	assert.Equal(t, "true", replayed.Header.Get(HeaderIdempotentReplayed))
+	assert.Equal(t, int32(1), atomic.LoadInt32(calls), "Expected the handler to run once")
+
+	// A different key creates a new resource
+	resp, _ = postIdempotent(t, app, "key-2", `{"name": "Example"}`)
+	assert.Equal(t, 201, resp.StatusCode)
+	assert.Equal(t, int32(2), atomic.LoadInt32(calls))
+
+	// Stored responses expire after the TTL
+	server.FastForward(2 * time.Hour)
+	resp, _ = postIdempotent(t, app, "key-1", `{"name": "Example"}`)
+	assert.Empty(t, resp.Header.Get(HeaderIdempotentReplayed))
+	assert.Equal(t, int32(3), atomic.LoadInt32(calls))
+}
+
+// TestIdempotencyMiddlewareDifferentPayload tests that reusing a key with another payload is rejected.
+func TestIdempotencyMiddlewareDifferentPayload(t *testing.T) {
+	app, _, calls := newIdempotencyTestApp(t, nil)
+
+	postIdempotent(t, app, "key-1", `{"name": "Example"}`)
+	resp, body := postIdempotent(t, app, "key-1", `{"name": "Other"}`)
+
+	assert.Equal(t, 422, resp.StatusCode)
+	var problem apperror.Problem
+	assert.NoError(t, json.Unmarshal([]byte(body), &problem))
+	assert.Equal(t, "idempotency_key_reused", problem.Code)
+	assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+}
+
+// TestIdempotencyMiddlewareInFlight tests that a concurrent duplicate is rejected while the first request runs.
+func TestIdempotencyMiddlewareInFlight(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	app, _, calls := newIdempotencyTestApp(t, func() {
+		close(started)
+		<-release
+	})
+
+	// Start the first request, which blocks in the handler
+	done := make(chan int)
+	go func() {
+		resp, _ := postIdempotent(t, app, "key-1", `{"name": "Example"}`)
+		done <- resp.StatusCode
+	}()
+	<-started
+
+	// Send the duplicate while the first request holds the key
+	resp, body := postIdempotent(t, app, "key-1", `{"name": "Example"}`)
+	assert.Equal(t, 409, resp.StatusCode)
+	assert.Contains(t, body, "idempotency_key_in_progress")
+
+	close(release)
+	assert.Equal(t, 201, <-done)
+	assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+}
+
+// TestIdempotencyMiddlewareDifferentLanguage tests that a key is not replayed in another language.
+func TestIdempotencyMiddlewareDifferentLanguage(t *testing.T) {
+	app, _, calls := newIdempotencyTestApp(t, nil)
+
+	postIdempotentIn(t, app, "en", "key-1", `{"name": "Example"}`)
+	resp, _ := postIdempotentIn(t, app, "tr", "key-1", `{"name": "Example"}`)
+	assert.Equal(t, 422, resp.StatusCode)
+	replayed, _ := postIdempotentIn(t, app, "en", "key-1", `{"name": "Example"}`)
+	assert.Equal(t, "true", replayed.Header.Get(HeaderIdempotentReplayed))
+	assert.Equal(t, "en", replayed.Header.Get(fiber.HeaderContentLanguage))
+	assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+}
+
+// TestIdempotencyMiddlewareSlowRequest tests that a request running longer than the lock timeout keeps its key.
+func TestIdempotencyMiddlewareSlowRequest(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var holding atomic.Bool
+	config := IdempotencyConfig{TTL: time.Hour, LockTimeout: 150 * time.Millisecond}
+	app, server, calls := newIdempotencyTestAppWithConfig(t, config, func() {
+		// Only the first request is held.
+		if holding.CompareAndSwap(false, true) {
+			close(started)
+			<-release
+		}
+	})
+
+	done := make(chan int)
+	go func() {
+		resp, _ := postIdempotent(t, app, "key-1", `{"name": "Example"}`)
+		done <- resp.StatusCode
+	}()
+	<-started
+
+	// The claim would have expired several times without being refreshed.
+	for i := 0; i < 5; i++ {
+		time.Sleep(100 * time.Millisecond)
+		server.FastForward(100 * time.Millisecond)
+	}
+	resp, _ := postIdempotent(t, app, "key-1", `{"name": "Example"}`)
+	assert.Equal(t, 409, resp.StatusCode)
+
+	close(release)
+	assert.Equal(t, 201, <-done)
+	assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+}
+
+// TestIdempotencyMiddlewareReleaseOwnClaim tests that a failed request does not release a claim it no longer holds.
+func TestIdempotencyMiddlewareReleaseOwnClaim(t *testing.T) {
+	var server *miniredis.Miniredis
+	app, server, _ := newIdempotencyTestApp(t, func() {
+		// Another request claimed the key in the meantime (e.g., after the claim expired).
+		for _, key := range server.Keys() {
+			assert.NoError(t, server.Set(key, `{"state": "in_flight", "token": "other"}`))
+		}
+	})
+
+	resp, _ := postIdempotent(t, app, "key-1", `{"name": "fail"}`)
+	assert.Equal(t, 500, resp.StatusCode)
+	assert.Len(t, server.Keys(), 1, "Expected the claim of the other request to be kept")
+}
+
+// TestIdempotencyMiddlewareServerError tests that server errors are not stored and the key can be retried.
+func TestIdempotencyMiddlewareServerError(t *testing.T) {
+	app, server, calls := newIdempotencyTestApp(t, nil)
+
+	resp, _ := postIdempotent(t, app, "key-1", `{"name": "fail"}`)
+	assert.Equal(t, 500, resp.StatusCode)
+	assert.Empty(t, server.Keys(), "Expected the key to be released")
+
+	resp, _ = postIdempotent(t, app, "key-1", `{"name": "fail"}`)
+	assert.Equal(t, 500, resp.StatusCode)
+	assert.Equal(t, int32(2), atomic.LoadInt32(calls))
+}
+
+// TestIdempotencyMiddlewareWithoutKey tests that requests without a key are processed normally.
+func TestIdempotencyMiddlewareWithoutKey(t *testing.T) {
+	app, server, calls := newIdempotencyTestApp(t, nil)
+
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest("POST", "/examples", strings.NewReader(`{}`))
+		req.SetBasicAuth("admin", "password")
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, 201, resp.StatusCode)
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(calls))
+	assert.Empty(t, server.Keys())
+}
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"gobo/internal/i18n"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// LanguageLocalKey is the Fiber locals key under which the negotiated language is stored.
+const LanguageLocalKey = "language"
+
+// LocaleMiddleware negotiates the response language from the Accept-Language header.
+// The language is stored in the locals under LanguageLocalKey and in the user context
+// (see i18n.FromContext), and is announced with the Content-Language header.
+// Responses vary by Accept-Language so that caches keep one copy per language.
+func LocaleMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		language := i18n.Negotiate(c.Get(fiber.HeaderAcceptLanguage))
+
+		c.Locals(LanguageLocalKey, language)
+		c.SetUserContext(i18n.NewContext(c.UserContext(), language))
+		c.Set(fiber.HeaderContentLanguage, language)
+		c.Vary(fiber.HeaderAcceptLanguage)
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gobo/internal/apperror"
+	"gobo/internal/i18n"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestLocaleMiddleware tests that the negotiated language is exposed to handlers and announced in the response.
+func TestLocaleMiddleware(t *testing.T) {
+	// Create a new Fiber app with the LocaleMiddleware
+	app := fiber.New()
+	app.Use(LocaleMiddleware())
+
+	// Register a test route returning the negotiated language
+	app.Get("/language", func(c *fiber.Ctx) error {
+		assert.Equal(t, c.Locals(LanguageLocalKey), i18n.FromContext(c.UserContext()))
+		return c.SendString(i18n.FromContext(c.UserContext()))
+	})
+
+	// Perform the request with a Turkish preference
+	req := httptest.NewRequest("GET", "/language", nil)
+	req.Header.Set("Accept-Language", "tr-TR,tr;q=0.9,en;q=0.8")
+	resp, err := app.Test(req)
+
+	// Assert the response
+	assert.NoError(t, err)
+	assert.Equal(t, "tr", resp.Header.Get("Content-Language"))
+	assert.Contains(t, resp.Header.Get("Vary"), "Accept-Language")
+}
+
+// TestLocaleMiddlewareLocalizesErrors tests that middleware errors are rendered in the negotiated language.
+func TestLocaleMiddlewareLocalizesErrors(t *testing.T) {
+	// Create a new Fiber app with the central error handler, LocaleMiddleware and a strict rate limit
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Use(LocaleMiddleware())
+	app.Get("/limited", RateLimitMiddleware(1, time.Minute), func(c *fiber.Ctx) error {
+		return c.SendString("Request allowed")
+	})
+
+	// Exceed the limit with a Turkish preference
+	var resp *http.Response
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest("GET", "/limited", nil)
+		req.Header.Set("Accept-Language", "tr")
+		var err error
+		resp, err = app.Test(req)
+		assert.NoError(t, err)
+	}
+
+	// Assert the localized problem details
+	assert.Equal(t, 429, resp.StatusCode)
+	var problem apperror.Problem
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&problem))
+	assert.Equal(t, "Çok Fazla İstek", problem.Title)
+	assert.Regexp(t, `^İstek sınırı aşıldı\. \d+ saniye sonra tekrar deneyin\.$`, problem.Detail)
+	assert.Equal(t, apperror.CodeRateLimited, problem.Code)
+}
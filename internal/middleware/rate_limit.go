@@ -1,26 +1,113 @@
 package middleware
 
 import (
+	"os"
+	"strconv"
+	"strings"
 	"time"
 
+	"gobo/internal/apperror"
+	"gobo/internal/cache"
+	"gobo/internal/i18n"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/limiter"
 )
 
 // RateLimitMiddleware creates a rate limiter middleware for Fiber with dynamic parameters.
+// Clients are limited per tenant, so the requests of one tenant never count against another's.
 func RateLimitMiddleware(maxRequests int, expiration time.Duration) fiber.Handler {
 	return limiter.New(limiter.Config{
-		Max:        maxRequests,       // Maximum number of requests per duration
-		Expiration: expiration,        // Time duration for the limit
+		Max:        maxRequests, // Maximum number of requests per duration
+		Expiration: expiration,  // Time duration for the limit
 		KeyGenerator: func(c *fiber.Ctx) string {
-			// Use client IP as the key
-			return c.IP()
-		},
-		LimitReached: func(c *fiber.Ctx) error {
-			// Response when rate limit is exceeded
-			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
-				"error": "Rate limit exceeded. Try again later.",
-			})
+			// Use the tenant and client IP as the key
+			return cache.TenantKey(c.UserContext(), c.IP())
 		},
+		LimitReached: rateLimitReached,
 	})
 }
+
+// TenantRateLimitConfig defines the request quotas of the tenants.
+type TenantRateLimitConfig struct {
+	Max        int            // Requests per window of a tenant without its own limit (defaults to 1000)
+	Limits     map[string]int // Requests per window of specific tenants (e.g., {"acme": 5000})
+	Expiration time.Duration  // Length of the window (defaults to 1 minute)
+}
+
+// DefaultTenantRateLimitConfig returns the default tenant rate limit configuration.
+//
+// Defaults:
+// - Max: 1000, or the TENANT_RATE_LIMIT environment variable
+// - Limits: the TENANT_RATE_LIMITS environment variable (e.g., "acme=5000,globex=200")
+// - Expiration: 1 minute, or the TENANT_RATE_LIMIT_WINDOW environment variable (e.g., "1h")
+//
+// Returns:
+// - TenantRateLimitConfig: The default tenant rate limit configuration.
+func DefaultTenantRateLimitConfig() TenantRateLimitConfig {
+	config := TenantRateLimitConfig{
+		Max:        1000,
+		Limits:     map[string]int{},
+		Expiration: time.Minute,
+	}
+	if value, err := strconv.Atoi(os.Getenv("TENANT_RATE_LIMIT")); err == nil && value > 0 {
+		config.Max = value
+	}
+	if value, err := time.ParseDuration(os.Getenv("TENANT_RATE_LIMIT_WINDOW")); err == nil && value > 0 {
+		config.Expiration = value
+	}
+	for _, limit := range strings.Split(os.Getenv("TENANT_RATE_LIMITS"), ",") {
+		id, value, found := strings.Cut(strings.TrimSpace(limit), "=")
+		if requests, err := strconv.Atoi(value); found && err == nil && requests > 0 {
+			config.Limits[id] = requests
+		}
+	}
+	return config
+}
+
+// TenantRateLimitMiddleware limits the requests of each tenant, whatever the client, to its quota.
+// Register it after TenantMiddleware; requests without a tenant are not limited.
+func TenantRateLimitMiddleware(config TenantRateLimitConfig) fiber.Handler {
+	defaults := DefaultTenantRateLimitConfig()
+	if config.Max <= 0 {
+		config.Max = defaults.Max
+	}
+	if config.Expiration <= 0 {
+		config.Expiration = defaults.Expiration
+	}
+
+	// The limiter has a single maximum, so each tenant with its own limit gets its own limiter.
+	newLimiter := func(requests int) fiber.Handler {
+		return limiter.New(limiter.Config{
+			Max:          requests,
+			Expiration:   config.Expiration,
+			KeyGenerator: func(c *fiber.Ctx) string { return "tenant:" + tenantFromLocals(c) },
+			LimitReached: rateLimitReached,
+		})
+	}
+	limiters := make(map[string]fiber.Handler, len(config.Limits))
+	for id, requests := range config.Limits {
+		limiters[id] = newLimiter(requests)
+	}
+	fallback := newLimiter(config.Max)
+
+	return func(c *fiber.Ctx) error {
+		id := tenantFromLocals(c)
+		if id == "" {
+			return c.Next()
+		}
+		if handler, ok := limiters[id]; ok {
+			return handler(c)
+		}
+		return fallback(c)
+	}
+}
+
+// rateLimitReached is the response when a rate limit is exceeded.
+func rateLimitReached(c *fiber.Ctx) error {
+	// The limiter sets Retry-After (in seconds) before calling this handler.
+	if retryAfter, err := strconv.Atoi(c.GetRespHeader(fiber.HeaderRetryAfter)); err == nil {
+		return apperror.RateLimited("").WithKey("error.rate_limited_retry", i18n.Args{"count": retryAfter})
+	}
+	return apperror.RateLimited("").WithKey("error.rate_limited")
+}
@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"gobo/internal/apperror"
+	"gobo/internal/logger"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp/fasthttpadaptor"
+	"go.uber.org/zap"
+)
+
+// SentryHubLocalKey is the Fiber locals key under which the request-scoped Sentry hub is stored.
+const SentryHubLocalKey = "sentry_hub"
+
+// SentryMiddleware integrates requests with Sentry and recovers from panics.
+// For every request it:
+// - Clones the Sentry hub and attaches the request data to its scope.
+// - Stores the hub in the locals and in the user context (see logger.FromContext).
+// - Starts a performance transaction named after the matched route.
+// - Recovers panics, reports them with the request and the authenticated user, and responds with 500.
+// - Passes handler errors to the application's error handler, so the transaction records the final status.
+// It should be registered before any other middleware.
+func SentryMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		// Give each request its own scope so data never leaks between requests.
+		hub := sentry.CurrentHub().Clone()
+		if request := httpRequest(c); request != nil {
+			hub.Scope().SetRequest(request)
+		}
+		ctx := sentry.SetHubOnContext(c.UserContext(), hub)
+
+		// Start a transaction, continuing the trace of the caller if one was propagated.
+		transaction := sentry.StartTransaction(ctx,
+			fmt.Sprintf("%s %s", c.Method(), c.Path()),
+			sentry.WithOpName("http.server"),
+			sentry.ContinueFromHeaders(c.Get(sentry.SentryTraceHeader), c.Get(sentry.SentryBaggageHeader)),
+			sentry.WithTransactionSource(sentry.SourceURL),
+		)
+		c.SetUserContext(transaction.Context())
+		c.Locals(SentryHubLocalKey, hub)
+
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				setSentryUser(c, hub)
+				hub.RecoverWithContext(transaction.Context(), recovered)
+				logger.FromContext(c.UserContext()).WithOptions(zap.WithCaller(false)).Warn(
+					"Recovered from panic",
+					zap.Any("panic", recovered),
+					zap.String("method", c.Method()),
+					zap.String("path", c.Path()),
+				)
+				// The panic has already been reported, so the problem is written without logging it again.
+				err = apperror.Write(c, apperror.Internal(fmt.Errorf("panic: %v", recovered)))
+			}
+			finishTransaction(c, transaction)
+		}()
+
+		// Render errors here rather than after the middleware returns; server errors are
+		// reported to Sentry by the error handler when it logs them (see apperror.Handler).
+		if err = c.Next(); err != nil {
+			setSentryUser(c, hub)
+			err = c.App().ErrorHandler(c, err)
+		}
+		return err
+	}
+}
+
+// finishTransaction names the transaction after the matched route and records the response status.
+func finishTransaction(c *fiber.Ctx, transaction *sentry.Span) {
+	if route := c.Route(); route != nil && route.Path != "" {
+		transaction.Name = fmt.Sprintf("%s %s", c.Method(), route.Path)
+		transaction.Source = sentry.SourceRoute
+	}
+	status := c.Response().StatusCode()
+	transaction.Status = sentry.HTTPtoSpanStatus(status)
+	transaction.SetData("http.response.status_code", status)
+	transaction.Finish()
+}
+
+// setSentryUser attaches the authenticated user, if any, to the request scope.
+func setSentryUser(c *fiber.Ctx, hub *sentry.Hub) {
+	user := sentry.User{IPAddress: c.IP()}
+	if username, ok := c.Locals(UsernameLocalKey).(string); ok {
+		user.Username = username
+	}
+	hub.Scope().SetUser(user)
+}
+
+// httpRequest converts the Fiber request into a net/http request for the Sentry scope.
+// Sensitive headers such as Authorization are stripped by the Sentry SDK when the event is sent.
+func httpRequest(c *fiber.Ctx) *http.Request {
+	var request http.Request
+	if err := fasthttpadaptor.ConvertRequest(c.Context(), &request, true); err != nil {
+		return nil
+	}
+	return &request
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSentryMiddlewareRecoversPanic tests that a panicking handler results in a 500 response.
+func TestSentryMiddlewareRecoversPanic(t *testing.T) {
+	// Create a new Fiber app with the SentryMiddleware
+	app := fiber.New()
+	app.Use(SentryMiddleware())
+
+	// Register a test route that panics
+	app.Get("/panic", func(c *fiber.Ctx) error {
+		panic("something went wrong")
+	})
+
+	// Perform the request
+	req := httptest.NewRequest("GET", "/panic", nil)
+	resp, err := app.Test(req)
+
+	// Assert the response
+	assert.NoError(t, err)
+	assert.Equal(t, 500, resp.StatusCode)
+
+	var body map[string]interface{}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, "application/problem+json", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "internal_error", body["code"])
+	assert.NotContains(t, body["detail"], "something went wrong", "The panic value must not be exposed")
+}
+
+// TestSentryMiddlewareRequestScope tests that the request-scoped hub is available to handlers
+// and that the authenticated user is attached to it on panic.
+func TestSentryMiddlewareRequestScope(t *testing.T) {
+	// Create a new Fiber app with the SentryMiddleware and BasicAuthMiddleware
+	app := fiber.New()
+	app.Use(SentryMiddleware())
+
+	var hub *sentry.Hub
+	app.Get("/protected", BasicAuthMiddleware("admin", "password"), func(c *fiber.Ctx) error {
+		hub, _ = c.Locals(SentryHubLocalKey).(*sentry.Hub)
+		assert.True(t, sentry.HasHubOnContext(c.UserContext()), "Expected the hub on the user context")
+		panic("boom")
+	})
+
+	// Perform the request with valid credentials
+	credentials := base64.StdEncoding.EncodeToString([]byte("admin:password"))
+	req := httptest.NewRequest("GET", "/protected", nil)
+	req.Header.Set("Authorization", "Basic "+credentials)
+	resp, err := app.Test(req)
+
+	// Assert the response and the scope
+	assert.NoError(t, err)
+	assert.Equal(t, 500, resp.StatusCode)
+	if assert.NotNil(t, hub, "Expected the hub in the locals") {
+		assert.NotSame(t, sentry.CurrentHub(), hub, "Expected a request-scoped hub")
+	}
+}
@@ -0,0 +1,205 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net"
+	"os"
+	"strings"
+
+	"gobo/internal/apperror"
+	"gobo/internal/i18n"
+	"gobo/internal/tenant"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Tenant locals keys.
+const (
+	TenantLocalKey = "tenant" // The resolved tenant ID
+	ClaimsLocalKey = "claims" // Verified token claims (map[string]interface{}), stored by token authentication
+)
+
+// AnyTenant grants a TenantUser every tenant accepted by the configuration.
+const AnyTenant = "*"
+
+// TenantConfig defines how the tenant of a request is resolved.
+type TenantConfig struct {
+	Header  string                // Header naming the tenant (defaults to "X-Tenant-ID"); "-" disables it
+	Domain  string                // Base domain of the tenant subdomains (e.g., "example.com" resolves "acme.example.com" to "acme"); empty disables them
+	Claim   string                // Token claim naming the tenant (defaults to "tenant")
+	Default string                // Tenant of requests that do not name one; empty rejects them
+	Allowed []string              // Known tenants; empty allows any valid tenant ID
+	Users   map[string]TenantUser // Users of the Basic authentication bound to tenants, by username (defaults to the admin user, bound to every tenant)
+}
+
+// TenantUser is a user of the Basic authentication and the tenants it may select.
+type TenantUser struct {
+	Password string   // The password of the user
+	Tenants  []string // The tenants the user may select; AnyTenant grants all of them
+}
+
+// allows reports whether the user may select the tenant.
+func (u TenantUser) allows(id string) bool {
+	for _, granted := range u.Tenants {
+		if granted == AnyTenant || granted == id {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultTenantConfig returns the default tenant configuration.
+//
+// Defaults:
+// - Header: "X-Tenant-ID", or the TENANT_HEADER environment variable
+// - Domain: the TENANT_DOMAIN environment variable (e.g., "example.com")
+// - Claim: "tenant"
+// - Default: tenant.DefaultID, or the TENANT_DEFAULT environment variable (set it empty to require a tenant)
+// - Allowed: the comma-separated TENANTS environment variable (e.g., "acme,globex")
+// - Users: the administrator of the routes (AdminUsername and AdminPassword), bound to every tenant
+//
+// Returns:
+// - TenantConfig: The default tenant configuration.
+func DefaultTenantConfig() TenantConfig {
+	config := TenantConfig{
+		Header:  "X-Tenant-ID",
+		Domain:  strings.ToLower(os.Getenv("TENANT_DOMAIN")),
+		Claim:   "tenant",
+		Default: tenant.DefaultID,
+		Users:   map[string]TenantUser{AdminUsername: {Password: AdminPassword, Tenants: []string{AnyTenant}}},
+	}
+	if value := os.Getenv("TENANT_HEADER"); value != "" {
+		config.Header = value
+	}
+	if value, ok := os.LookupEnv("TENANT_DEFAULT"); ok {
+		config.Default = value
+	}
+	for _, id := range strings.Split(os.Getenv("TENANTS"), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			config.Allowed = append(config.Allowed, id)
+		}
+	}
+	return config
+}
+
+// TenantMiddleware resolves the tenant of the request from the token claim, the subdomain or the header.
+// - The tenant is stored in the locals under TenantLocalKey and in the user context (see tenant.NewContext),
+// so that database queries with db.GormDB.WithContext(c.UserContext()) are scoped to it.
+// - Sources naming different tenants are rejected with 403 Forbidden (e.g., a header naming another tenant than the token).
+// - Invalid or unknown tenants are rejected with 400 Bad Request; so are requests naming none, unless a default is configured.
+// - The claim of a verified token binds the request to its tenant. Any client can send the subdomain or the
+// header, so they only select a tenant: anonymous requests belong to the default tenant, and selecting another
+// one requires the Basic credentials of a user bound to it (see TenantConfig.Users), even on routes that do not
+// require authentication. Other requests are rejected with 401 Unauthorized, and users selecting a tenant they
+// are not bound to with 403 Forbidden.
+// Token claims are only available once the token is verified: with token authentication, register the
+// middleware again after it, so that the claim is checked against the other sources.
+func TenantMiddleware(config TenantConfig) fiber.Handler {
+	defaults := DefaultTenantConfig()
+	if config.Header == "" {
+		config.Header = defaults.Header
+	}
+	if config.Claim == "" {
+		config.Claim = defaults.Claim
+	}
+	if config.Users == nil {
+		config.Users = defaults.Users
+	}
+	config.Domain = strings.TrimPrefix(config.Domain, ".")
+	allowed := make(map[string]bool, len(config.Allowed))
+	for _, id := range config.Allowed {
+		allowed[id] = true
+	}
+
+	return func(c *fiber.Ctx) error {
+		id, claimed := "", claimTenant(c, config.Claim)
+		for _, candidate := range []string{claimed, subdomainTenant(c, config.Domain), headerTenant(c, config.Header)} {
+			switch {
+			case candidate == "":
+			case id == "":
+				id = candidate
+			case candidate != id:
+				return apperror.Forbidden("").WithKey("error.tenant_mismatch").WithCode("tenant_mismatch")
+			}
+		}
+		if id == "" {
+			id = config.Default
+		}
+
+		switch {
+		case id == "":
+			return apperror.BadRequest("").WithKey("error.tenant_required").WithCode("tenant_required")
+		case !tenant.Valid(id):
+			return apperror.BadRequest("").WithKey("error.invalid_tenant").WithCode("invalid_tenant")
+		case len(allowed) > 0 && !allowed[id] && id != config.Default:
+			return apperror.BadRequest("").WithKey("error.unknown_tenant", i18n.Args{"tenant": id}).WithCode("unknown_tenant")
+		}
+		if id != config.Default && id != claimed {
+			if err := authorizeTenant(c, config.Users, id); err != nil {
+				return err
+			}
+		}
+
+		c.Locals(TenantLocalKey, id)
+		c.SetUserContext(tenant.NewContext(c.UserContext(), id))
+		if hub, ok := c.Locals(SentryHubLocalKey).(*sentry.Hub); ok {
+			hub.Scope().SetTag("tenant", id)
+		}
+		return c.Next()
+	}
+}
+
+// claimTenant returns the tenant named by the verified token claims, if any.
+func claimTenant(c *fiber.Ctx, claim string) string {
+	claims, _ := c.Locals(ClaimsLocalKey).(map[string]interface{})
+	id, _ := claims[claim].(string)
+	return strings.ToLower(id)
+}
+
+// authorizeTenant checks that the Basic credentials of the request are those of a user bound to the tenant.
+func authorizeTenant(c *fiber.Ctx, users map[string]TenantUser, id string) error {
+	username, password, err := basicCredentials(c)
+	if err != nil {
+		return apperror.Unauthorized("").WithKey("error.tenant_unauthorized").WithCode("tenant_unauthorized")
+	}
+	user, ok := users[username]
+	if !ok || subtle.ConstantTimeCompare([]byte(password), []byte(user.Password)) != 1 {
+		return apperror.Unauthorized("").WithKey("error.invalid_credentials")
+	}
+	if !user.allows(id) {
+		return apperror.Forbidden("").WithKey("error.tenant_forbidden", i18n.Args{"tenant": id}).WithCode("tenant_forbidden")
+	}
+	return nil
+}
+
+// subdomainTenant returns the tenant named by the subdomain of the base domain, if any
+// (e.g., "acme" for "acme.example.com"; nested subdomains are not tenants).
+func subdomainTenant(c *fiber.Ctx, domain string) string {
+	if domain == "" {
+		return ""
+	}
+	host := c.Hostname()
+	if name, _, err := net.SplitHostPort(host); err == nil {
+		host = name
+	}
+	subdomain, found := strings.CutSuffix(strings.ToLower(host), "."+domain)
+	if !found || strings.Contains(subdomain, ".") {
+		return ""
+	}
+	return subdomain
+}
+
+// headerTenant returns the tenant named by the header, if any.
+func headerTenant(c *fiber.Ctx, header string) string {
+	if header == "-" {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSpace(c.Get(header)))
+}
+
+// tenantFromLocals returns the tenant of the request, or an empty string if it was not resolved.
+func tenantFromLocals(c *fiber.Ctx) string {
+	id, _ := c.Locals(TenantLocalKey).(string)
+	return id
+}
@@ -0,0 +1,177 @@
+package middleware
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gobo/internal/apperror"
+	"gobo/internal/tenant"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTenantTestApp creates an app responding with the tenant of the request context.
+func newTenantTestApp(config TenantConfig) *fiber.App {
+	return newTenantTestAppWithClaims(config, nil)
+}
+
+// newTenantTestAppWithClaims creates the app of newTenantTestApp, storing the claims in the locals before the
+// middleware runs (as token authentication would).
+func newTenantTestAppWithClaims(config TenantConfig, claims map[string]interface{}) *fiber.App {
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	if claims != nil {
+		app.Use(func(c *fiber.Ctx) error {
+			c.Locals(ClaimsLocalKey, claims)
+			return c.Next()
+		})
+	}
+	app.Use(TenantMiddleware(config))
+	app.Get("/tenant", func(c *fiber.Ctx) error {
+		return c.SendString(tenant.FromContext(c.UserContext()))
+	})
+	return app
+}
+
+// getTenant performs a request with the given host and tenant header, authenticated as admin, and returns the
+// status and body.
+func getTenant(t *testing.T, app *fiber.App, host, header string) (int, string) {
+	return getTenantAs(t, app, host, header, "admin", "password")
+}
+
+// getTenantAs performs a request with the given host, tenant header and Basic credentials (none if the
+// username is empty), and returns the status and body.
+func getTenantAs(t *testing.T, app *fiber.App, host, header, username, password string) (int, string) {
+	req := httptest.NewRequest("GET", "http://"+host+"/tenant", nil)
+	if header != "" {
+		req.Header.Set("X-Tenant-ID", header)
+	}
+	if username != "" {
+		req.SetBasicAuth(username, password)
+	}
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != fiber.StatusOK {
+		var problem apperror.Problem
+		assert.NoError(t, json.Unmarshal(body, &problem))
+		return resp.StatusCode, problem.Code
+	}
+	return resp.StatusCode, string(body)
+}
+
+// TestTenantMiddleware tests that the tenant is resolved from the subdomain, header and token claim.
+func TestTenantMiddleware(t *testing.T) {
+	app := newTenantTestApp(TenantConfig{Domain: "example.com", Default: "default", Allowed: []string{"acme", "globex"}})
+
+	tests := []struct {
+		name, host, header string
+		status             int
+		result             string // Tenant, or problem code
+	}{
+		{"Default", "example.com", "", 200, "default"},
+		{"Header", "example.com", "Acme", 200, "acme"},
+		{"Subdomain", "acme.example.com:3000", "", 200, "acme"},
+		{"SubdomainAndHeader", "acme.example.com", "acme", 200, "acme"},
+		{"Mismatch", "acme.example.com", "globex", 403, "tenant_mismatch"},
+		{"NestedSubdomain", "api.acme.example.com", "", 200, "default"},
+		{"Invalid", "example.com", "acme_corp", 400, "invalid_tenant"},
+		{"Unknown", "initech.example.com", "", 400, "unknown_tenant"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, result := getTenant(t, app, tt.host, tt.header)
+			assert.Equal(t, tt.status, status)
+			assert.Equal(t, tt.result, result)
+		})
+	}
+
+	// Without a default, requests must name a tenant.
+	app = newTenantTestApp(TenantConfig{Header: "X-Tenant-ID"})
+	status, code := getTenant(t, app, "example.com", "")
+	assert.Equal(t, 400, status)
+	assert.Equal(t, "tenant_required", code)
+
+	// The token claim binds the request to its tenant, and must agree with the other sources.
+	app = newTenantTestAppWithClaims(TenantConfig{}, map[string]interface{}{"tenant": "acme"})
+	status, result := getTenantAs(t, app, "example.com", "", "", "")
+	assert.Equal(t, 200, status)
+	assert.Equal(t, "acme", result)
+	status, result = getTenantAs(t, app, "example.com", "acme", "", "")
+	assert.Equal(t, 200, status)
+	assert.Equal(t, "acme", result)
+	status, result = getTenant(t, app, "example.com", "globex")
+	assert.Equal(t, 403, status)
+	assert.Equal(t, "tenant_mismatch", result)
+}
+
+// TestTenantMiddlewareBinding tests that tenants other than the default can only be selected by their users.
+func TestTenantMiddlewareBinding(t *testing.T) {
+	app := newTenantTestApp(TenantConfig{Domain: "example.com", Default: "default", Users: map[string]TenantUser{
+		"admin": {Password: "password", Tenants: []string{AnyTenant}},
+		"alice": {Password: "secret", Tenants: []string{"acme"}},
+	}})
+
+	tests := []struct {
+		name, host, header, username, password string
+		status                                 int
+		result                                 string // Tenant, or problem code
+	}{
+		{"AnonymousDefault", "example.com", "", "", "", 200, "default"},
+		{"AnonymousHeader", "example.com", "acme", "", "", 401, "tenant_unauthorized"},
+		{"AnonymousSubdomain", "acme.example.com", "", "", "", 401, "tenant_unauthorized"},
+		{"InvalidCredentials", "example.com", "acme", "alice", "wrong", 401, "unauthorized"},
+		{"UnknownUser", "example.com", "acme", "mallory", "secret", 401, "unauthorized"},
+		{"BoundUser", "example.com", "acme", "alice", "secret", 200, "acme"},
+		{"OtherTenant", "globex.example.com", "", "alice", "secret", 403, "tenant_forbidden"},
+		{"AnyTenant", "example.com", "globex", "admin", "password", 200, "globex"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, result := getTenantAs(t, app, tt.host, tt.header, tt.username, tt.password)
+			assert.Equal(t, tt.status, status)
+			assert.Equal(t, tt.result, result)
+		})
+	}
+}
+
+// TestTenantRateLimitMiddleware tests that each tenant has its own quota.
+func TestTenantRateLimitMiddleware(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Use(TenantMiddleware(TenantConfig{}))
+	app.Use(TenantRateLimitMiddleware(TenantRateLimitConfig{Max: 2, Limits: map[string]int{"acme": 3}, Expiration: time.Minute}))
+	app.Get("/tenant", func(c *fiber.Ctx) error {
+		return c.SendString(tenantFromLocals(c))
+	})
+
+	statuses := func(id string, n int) []int {
+		var result []int
+		for i := 0; i < n; i++ {
+			status, _ := getTenant(t, app, "example.com", id)
+			result = append(result, status)
+		}
+		return result
+	}
+	assert.Equal(t, []int{200, 200, 429}, statuses("globex", 3))
+	assert.Equal(t, []int{200, 200, 200, 429}, statuses("acme", 4), "Expected acme to have its own limit")
+	assert.Equal(t, []int{200, 200}, statuses("initech", 2), "Expected other tenants not to be limited by globex")
+}
+
+// TestRateLimitMiddlewarePerTenant tests that clients are limited separately in each tenant.
+func TestRateLimitMiddlewarePerTenant(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Use(TenantMiddleware(TenantConfig{}))
+	app.Get("/tenant", RateLimitMiddleware(1, time.Minute), func(c *fiber.Ctx) error {
+		return c.SendString(tenantFromLocals(c))
+	})
+
+	status, _ := getTenant(t, app, "example.com", "acme")
+	assert.Equal(t, 200, status)
+	status, _ = getTenant(t, app, "example.com", "acme")
+	assert.Equal(t, 429, status)
+	status, _ = getTenant(t, app, "example.com", "globex")
+	assert.Equal(t, 200, status)
+}
@@ -0,0 +1,55 @@
+// Package models contains the application's database models and related functionality.
+// This file defines the Base model shared by all models.
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/plugin/optimisticlock"
+)
+
+// Base contains the columns shared by all models: the primary key, timestamps and soft delete.
+// Embed it in a model to get these columns; GORM maintains them automatically.
+// Fields:
+// - ID: The primary key of the record.
+// - CreatedAt: Set when the record is created.
+// - UpdatedAt: Set when the record is created or updated.
+// - DeletedAt: Set when the record is soft deleted; NULL otherwise.
+// - Version: Incremented on every update, for optimistic locking.
+//
+// Soft delete behavior:
+// - db.Delete sets DeletedAt instead of removing the row.
+// - Queries exclude soft-deleted rows unless db.Unscoped() is used.
+// - Soft-deleted rows are removed permanently by the purge.soft_deleted scheduled task (see registerTasks in cmd/main.go).
+//
+// Optimistic locking behavior:
+// - Updates of a model whose Version is set add "WHERE version = ?" and increment the version.
+// - If the record was changed in the meantime, no row is updated (RowsAffected is 0).
+// - Updates of a model without a Version (e.g., db.Model(&Example{}).Where(...)) are not checked.
+type Base struct {
+	ID        uint                   `gorm:"primaryKey"` // Primary key for the record.
+	CreatedAt time.Time              // Creation time, set by GORM.
+	UpdatedAt time.Time              // Last update time, set by GORM.
+	DeletedAt gorm.DeletedAt         `gorm:"index" swaggertype:"string" format:"date-time"` // Soft delete time; indexed because every query filters on it.
+	Version   optimisticlock.Version `gorm:"not null;default:1" swaggertype:"integer"`      // Version of the record, starting at 1.
+}
+
+// Restore clears the soft delete of the records matching the given conditions.
+//
+// Parameters:
+// - db (*gorm.DB): The GORM database connection instance.
+// - model (interface{}): A pointer to the model (e.g., &Example{}).
+// - conds (...interface{}): Conditions selecting the records (e.g., an ID).
+//
+// Returns:
+// - int64: The number of restored records.
+// - error: Returns an error if the update fails.
+func Restore(db *gorm.DB, model interface{}, conds ...interface{}) (int64, error) {
+	query := db.Unscoped().Model(model).Where("deleted_at IS NOT NULL")
+	if len(conds) > 0 {
+		query = query.Where(conds[0], conds[1:]...)
+	}
+	result := query.Update("deleted_at", nil)
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,120 @@
+// Package models contains the application's database models and related functionality.
+// This file includes tests for the timestamps, soft delete, restore and purge of models.
+package models
+
+import (
+	"gobo/internal/db"
+	"gobo/internal/testhelpers"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSoftDeleteAndRestore validates that deleted records are hidden and can be restored.
+func TestSoftDeleteAndRestore(t *testing.T) {
+	// Set up the test database with Example model
+	testhelpers.SetupGormTestDB(t, &Example{})
+	defer testhelpers.TeardownGormTestDB(&Example{})
+
+	// Timestamps are set on creation
+	example := Example{Name: "Deleted Example"}
+	assert.NoError(t, db.GormDB.Create(&example).Error)
+	assert.False(t, example.CreatedAt.IsZero(), "Expected CreatedAt to be set")
+	assert.False(t, example.UpdatedAt.IsZero(), "Expected UpdatedAt to be set")
+
+	// Soft deleted records are excluded from queries, but remain in the table
+	assert.NoError(t, db.GormDB.Delete(&example).Error)
+	var count int64
+	db.GormDB.Model(&Example{}).Count(&count)
+	assert.Equal(t, int64(0), count, "Expected the deleted example to be hidden")
+	db.GormDB.Unscoped().Model(&Example{}).Count(&count)
+	assert.Equal(t, int64(1), count, "Expected the deleted example to remain in the table")
+
+	// Restore clears DeletedAt
+	restored, err := Restore(db.GormDB, &Example{}, "id = ?", example.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), restored)
+	db.GormDB.Model(&Example{}).Count(&count)
+	assert.Equal(t, int64(1), count, "Expected the restored example to be visible")
+
+	// Records that are not deleted are not restored again
+	restored, err = Restore(db.GormDB, &Example{}, "id = ?", example.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), restored)
+}
+
+// TestPurgeSoftDeleted validates that only records deleted before the cutoff are removed permanently.
+func TestPurgeSoftDeleted(t *testing.T) {
+	// Set up the test database with Example model
+	testhelpers.SetupGormTestDB(t, &Example{})
+	defer testhelpers.TeardownGormTestDB(&Example{})
+
+	// Create an active, a recently deleted and a long deleted example
+	active := Example{Name: "Active"}
+	recent := Example{Name: "Recently Deleted"}
+	old := Example{Name: "Deleted Long Ago"}
+	db.GormDB.Create(&[]*Example{&active, &recent, &old})
+	db.GormDB.Delete(&recent)
+	db.GormDB.Delete(&old)
+	db.GormDB.Unscoped().Model(&old).Update("deleted_at", time.Now().Add(-48*time.Hour))
+
+	// Purge records deleted more than a day ago
+	purged, err := PurgeSoftDeleted(db.GormDB, time.Now().Add(-24*time.Hour), &Example{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+
+	var remaining []Example
+	db.GormDB.Unscoped().Order("id").Find(&remaining)
+	if assert.Len(t, remaining, 2) {
+		assert.Equal(t, "Active", remaining[0].Name)
+		assert.Equal(t, "Recently Deleted", remaining[1].Name)
+	}
+}
+
+// TestDefaultPurgeConfig validates the purge defaults and their environment overrides.
+func TestDefaultPurgeConfig(t *testing.T) {
+	t.Setenv("SOFT_DELETE_RETENTION", "")
+	t.Setenv("SOFT_DELETE_PURGE_INTERVAL", "")
+	config := DefaultPurgeConfig()
+	assert.Equal(t, 30*24*time.Hour, config.Retention)
+	assert.Equal(t, time.Hour, config.Interval)
+
+	t.Setenv("SOFT_DELETE_RETENTION", "72h")
+	t.Setenv("SOFT_DELETE_PURGE_INTERVAL", "invalid")
+	config = DefaultPurgeConfig()
+	assert.Equal(t, 72*time.Hour, config.Retention)
+	assert.Equal(t, time.Hour, config.Interval, "Expected invalid values to be ignored")
+}
+
+// TestOptimisticLocking validates that updates with a stale version do not change the record.
+func TestOptimisticLocking(t *testing.T) {
+	// Set up the test database with Example model
+	testhelpers.SetupGormTestDB(t, &Example{})
+	defer testhelpers.TeardownGormTestDB(&Example{})
+
+	// New records start at version 1
+	example := Example{Name: "Locked Example"}
+	assert.NoError(t, db.GormDB.Create(&example).Error)
+	assert.Equal(t, int64(1), example.Version.Int64)
+
+	// Two clients load the same version
+	var first, second Example
+	db.GormDB.First(&first, example.ID)
+	db.GormDB.First(&second, example.ID)
+
+	// The first update increments the version
+	result := db.GormDB.Model(&first).Update("name", "First")
+	assert.NoError(t, result.Error)
+	assert.Equal(t, int64(1), result.RowsAffected)
+
+	// The second update is based on a stale version and changes nothing
+	result = db.GormDB.Model(&second).Update("name", "Second")
+	assert.NoError(t, result.Error)
+	assert.Equal(t, int64(0), result.RowsAffected, "Expected the stale update to be rejected")
+
+	var current Example
+	db.GormDB.First(&current, example.ID)
+	assert.Equal(t, "First", current.Name)
+	assert.Equal(t, int64(2), current.Version.Int64)
+}
@@ -6,10 +6,12 @@ import "gorm.io/gorm"
 
 // Example represents the "examples" table in the database.
 // Fields:
-// - ID: The primary key of the record.
+// - Base: The primary key, timestamps and soft delete (see Base).
+// - TenantOwned: The tenant owning the example (see TenantOwned).
 // - Name: A required string field with a maximum length of 100 characters.
 type Example struct {
-	ID   uint   `gorm:"primaryKey"`                    // Primary key for the record.
+	Base        // Primary key, timestamps and soft delete.
+	TenantOwned // Tenant owning the example.
 	Name string `gorm:"type:varchar(100);not null"`    // Name field, required with a max length of 100 characters.
 }
 
@@ -0,0 +1,66 @@
+// Package models contains the application's database models and related functionality.
+// This file defines the permanent deletion of soft-deleted records, run by the purge.soft_deleted scheduled task.
+package models
+
+import (
+	"os"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PurgeActor is the actor of the permanent deletes in the audit log.
+const PurgeActor = "system:purge"
+
+// PurgeConfig defines how long soft-deleted records are kept and how often they are purged.
+type PurgeConfig struct {
+	Retention time.Duration // Records soft deleted longer ago than this are deleted permanently
+	Interval  time.Duration // Time between two purge runs
+}
+
+// DefaultPurgeConfig returns the default purge configuration.
+//
+// Defaults:
+// - Retention: 30 days, or the SOFT_DELETE_RETENTION environment variable (e.g., "720h")
+// - Interval: 1 hour, or the SOFT_DELETE_PURGE_INTERVAL environment variable (e.g., "1h")
+//
+// Returns:
+// - PurgeConfig: The default purge configuration.
+func DefaultPurgeConfig() PurgeConfig {
+	return PurgeConfig{
+		Retention: durationFromEnv("SOFT_DELETE_RETENTION", 30*24*time.Hour),
+		Interval:  durationFromEnv("SOFT_DELETE_PURGE_INTERVAL", time.Hour),
+	}
+}
+
+// durationFromEnv returns the positive duration in the environment variable, or the fallback.
+func durationFromEnv(name string, fallback time.Duration) time.Duration {
+	if value := os.Getenv(name); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil && parsed > 0 {
+			return parsed
+		}
+	}
+	return fallback
+}
+
+// PurgeSoftDeleted permanently deletes the records of the given models that were soft deleted before the cutoff.
+//
+// Parameters:
+// - db (*gorm.DB): The GORM database connection instance.
+// - cutoff (time.Time): Records with an earlier DeletedAt are deleted.
+// - models (...interface{}): Pointers to the models to purge (e.g., &Example{}).
+//
+// Returns:
+// - int64: The number of deleted records.
+// - error: Returns the first error; the remaining models are not purged.
+func PurgeSoftDeleted(db *gorm.DB, cutoff time.Time, models ...interface{}) (int64, error) {
+	var purged int64
+	for _, model := range models {
+		result := db.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).Delete(model)
+		if result.Error != nil {
+			return purged, result.Error
+		}
+		purged += result.RowsAffected
+	}
+	return purged, nil
+}
@@ -0,0 +1,12 @@
+// Package models contains the application's database models and related functionality.
+// This file defines the TenantOwned model embedded by tenant-owned models.
+package models
+
+// TenantOwned contains the tenant column of the records owned by a tenant (see the tenant package).
+// Embed it in a model, and register the model with the tenant plugin, so that every query and insert
+// is scoped to the tenant of the request.
+// Fields:
+// - TenantID: The tenant owning the record; records created before multi-tenancy belong to the "default" tenant.
+type TenantOwned struct {
+	TenantID string `gorm:"type:varchar(63);not null;default:'default';index"` // Tenant owning the record, set by the tenant plugin.
+}
@@ -6,15 +6,17 @@ import "gorm.io/gorm"
 
 // User represents the "users" table in the database.
 // Fields:
-// - ID: The primary key of the record.
-// - Username: A required string field with a maximum length of 100 characters, must be unique.
-// - Password: A required string field for storing the user's password.
-// - Email: A required string field with a maximum length of 100 characters, must be unique.
+// - Base: The primary key, timestamps and soft delete (see Base).
+// - TenantID: The tenant owning the user (as in TenantOwned, but part of the unique indexes).
+// - Username: A required string field with a maximum length of 100 characters, must be unique within the tenant.
+// - Password: A required string field for storing the user's password (masked in logs and error reports).
+// - Email: A required string field with a maximum length of 100 characters, must be unique within the tenant.
 type User struct {
-	ID       uint   `gorm:"primaryKey"`                    // Primary key for the record.
-	Username string `gorm:"type:varchar(100);unique;not null"` // Username field, unique and required with max length of 100 characters.
-	Password string `gorm:"type:varchar(100);not null"`      // Password field, required with max length of 100 characters.
-	Email    string `gorm:"type:varchar(100);unique;not null"` // Email field, unique and required with max length of 100 characters.
+	Base            // Primary key, timestamps and soft delete.
+	TenantID string `gorm:"type:varchar(63);not null;default:'default';uniqueIndex:idx_users_tenant_username,priority:1;uniqueIndex:idx_users_tenant_email,priority:1"` // Tenant owning the user, set by the tenant plugin.
+	Username string `gorm:"type:varchar(100);uniqueIndex:idx_users_tenant_username,priority:2;not null"`                                                                // Username field, unique per tenant and required with max length of 100 characters.
+	Password string `gorm:"type:varchar(100);not null" log:"redact"`                                                                                                    // Password field, required with max length of 100 characters. Never logged.
+	Email    string `gorm:"type:varchar(100);uniqueIndex:idx_users_tenant_email,priority:2;not null"`                                                                   // Email field, unique per tenant and required with max length of 100 characters.
 }
 
 // AutoMigrateUsers ensures the "users" table schema is up to date.
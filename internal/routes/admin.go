@@ -0,0 +1,195 @@
+// Package routes defines and registers all HTTP routes for the application.
+// This file contains the administrative endpoints.
+package routes
+
+import (
+	"time"
+
+	"gobo/internal/apperror"
+	"gobo/internal/logger"
+	"gobo/internal/middleware"
+	"gobo/internal/rpc"
+	"gobo/internal/service"
+	"gobo/internal/validation"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Request struct for changing the log level at runtime
+type LogLevelRequest struct {
+	Level    string `json:"level" validate:"required"` // The new log level (debug, info, warn, error).
+	Logger   string `json:"logger"`                    // Optional named logger (e.g., "gorm"); empty changes the global level.
+	Duration string `json:"duration"`                  // Optional duration after which the change is reverted (e.g., "15m").
+}
+
+// Response struct for the current log levels
+type LogLevelResponse struct {
+	Global  string            `json:"global"`  // The global log level.
+	Loggers map[string]string `json:"loggers"` // Per-logger level overrides.
+}
+
+// Response struct for the gRPC call metrics
+type GRPCMetricsResponse struct {
+	Methods []rpc.MethodMetrics `json:"methods"` // Metrics of each called method, by method name.
+}
+
+// registerAdmin registers the administrative routes under /admin.
+// All admin routes are protected by Basic Authentication.
+//
+// Parameters:
+// - app (*fiber.App): The Fiber application instance to which routes are registered.
+func registerAdmin(app *fiber.App) {
+	admin := app.Group("/admin", middleware.BasicAuthMiddleware(middleware.AdminUsername, middleware.AdminPassword))
+
+	// Inspect and change log levels at runtime.
+	// GET, PUT, DELETE /admin/log-level
+	admin.Get("/log-level", getLogLevelHandler)
+	admin.Put("/log-level", setLogLevelHandler)
+	admin.Delete("/log-level", resetLogLevelHandler)
+
+	// Restore soft-deleted records.
+	// POST /admin/examples/:id/restore
+	admin.Post("/examples/:id/restore", restoreExampleHandler)
+
+	// Query the audit log of data changes.
+	// GET /admin/audit
+	admin.Get("/audit", getAuditLogHandler)
+
+	// Inspect background jobs and retry dead ones.
+	// GET /admin/jobs, GET /admin/jobs/dead, GET /admin/jobs/:id, POST /admin/jobs/dead/:id/retry
+	admin.Get("/jobs", getJobStatsHandler)
+	admin.Get("/jobs/dead", getDeadJobsHandler)
+	admin.Get("/jobs/:id", getJobHandler)
+	admin.Post("/jobs/dead/:id/retry", retryJobHandler)
+
+	// Inspect the calls of the gRPC API.
+	// GET /admin/grpc/metrics
+	admin.Get("/grpc/metrics", getGRPCMetricsHandler)
+}
+
+// getLogLevelHandler returns the current log levels.
+// @Summary      Get Log Levels
+// @Description  Returns the global log level and the per-logger overrides.
+// @Tags         admin
+// @Produce      json
+// @Security     BasicAuth
+// @Success      200 {object} LogLevelResponse
+// @Router       /admin/log-level [get]
+func getLogLevelHandler(c *fiber.Ctx) error {
+	return c.JSON(currentLogLevels())
+}
+
+// setLogLevelHandler changes the global or a named logger's level, optionally for a limited time.
+// @Summary      Set Log Level
+// @Description  Changes the log level globally or for a named logger. If a duration is given, the change is reverted automatically.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BasicAuth
+// @Param        request body      LogLevelRequest true "Log Level Request"
+// @Success      200 {object} LogLevelResponse
+// @Failure      400 {object} apperror.Problem
+// @Failure      422 {object} apperror.Problem
+// @Router       /admin/log-level [put]
+func setLogLevelHandler(c *fiber.Ctx) error {
+	var body LogLevelRequest
+
+	// Parse and validate the request body.
+	if err := validation.BindAndValidate(c, &body); err != nil {
+		return err
+	}
+
+	// Parse the requested level (e.g., "debug").
+	level, err := zapcore.ParseLevel(body.Level)
+	if err != nil {
+		return apperror.BadRequest("").WithKey("error.invalid_log_level").WithCode("invalid_log_level")
+	}
+
+	// Parse the optional revert duration.
+	var duration time.Duration
+	if body.Duration != "" {
+		duration, err = time.ParseDuration(body.Duration)
+		if err != nil || duration < 0 {
+			return apperror.BadRequest("").WithKey("error.invalid_duration").WithCode("invalid_duration")
+		}
+	}
+
+	logger.SetLevel(body.Logger, level, duration)
+	logger.FromContext(c.UserContext()).Info("Log level changed",
+		zap.String("logger", body.Logger),
+		zap.Stringer("level", level),
+		zap.Duration("revertAfter", duration),
+	)
+
+	return c.JSON(currentLogLevels())
+}
+
+// resetLogLevelHandler removes a runtime log level change.
+// @Summary      Reset Log Level
+// @Description  Removes the override of a named logger, or restores the configured global level if no logger is given.
+// @Tags         admin
+// @Produce      json
+// @Security     BasicAuth
+// @Param        logger query     string false "Named logger to reset"
+// @Success      200 {object} LogLevelResponse
+// @Router       /admin/log-level [delete]
+func resetLogLevelHandler(c *fiber.Ctx) error {
+	logger.ResetLevel(c.Query("logger"))
+	return c.JSON(currentLogLevels())
+}
+
+// restoreExampleHandler restores a soft-deleted example.
+// @Summary      Restore Example
+// @Description  Restores a soft-deleted example that has not been purged yet.
+// @Tags         admin
+// @Produce      json
+// @Security     BasicAuth
+// @Param        id path int true "Example ID"
+// @Success      200 {object} ExampleResponse
+// @Failure      400 {object} apperror.Problem
+// @Failure      404 {object} apperror.Problem
+// @Failure      409 {object} apperror.Problem "The example is not deleted"
+// @Failure      500 {object} apperror.Problem
+// @Router       /admin/examples/{id}/restore [post]
+func restoreExampleHandler(c *fiber.Ctx) error {
+	var params ExampleIDRequest
+	if err := validation.BindAndValidate(c, &params); err != nil {
+		return err
+	}
+
+	// 404 if the example never existed or was purged, 409 if it is not deleted.
+	restored, err := service.RestoreExample(c.UserContext(), params.ID)
+	if err != nil {
+		return apperror.From(err)
+	}
+
+	logger.FromContext(c.UserContext()).Info("Example restored", zap.Uint("id", restored.ID))
+	return c.JSON(newExampleResponse(restored))
+}
+
+// currentLogLevels builds the log level response from the logger registry.
+func currentLogLevels() LogLevelResponse {
+	response := LogLevelResponse{
+		Global:  logger.GlobalLevel().String(),
+		Loggers: map[string]string{},
+	}
+	for name, level := range logger.LoggerLevels() {
+		response.Loggers[name] = level.String()
+	}
+	return response
+}
+
+// getGRPCMetricsHandler returns the metrics of the gRPC API since startup.
+// @Summary      Get gRPC Metrics
+// @Description  Returns the number of calls of each gRPC method by status code, and their average and longest durations.
+// @Tags         admin
+// @Produce      json
+// @Security     BasicAuth
+// @Success      200 {object} GRPCMetricsResponse
+// @Failure      401 {object} apperror.Problem
+// @Router       /admin/grpc/metrics [get]
+func getGRPCMetricsHandler(c *fiber.Ctx) error {
+	return c.JSON(GRPCMetricsResponse{Methods: rpc.DefaultMetrics.Snapshot()})
+}
@@ -0,0 +1,130 @@
+// Package routes contains tests for the application's API endpoints.
+// These tests validate the administrative endpoints, which do not require a database.
+package routes
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gobo/internal/apperror"
+	"gobo/internal/logger"
+	"gobo/internal/rpc"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+)
+
+// TestSetLogLevel validates the PUT /admin/log-level endpoint.
+// It ensures that a named logger's level can be changed and inspected.
+func TestSetLogLevel(t *testing.T) {
+	defer logger.ResetLevel("gorm")
+
+	// Create a new Fiber app instance and register routes.
+	app := fiber.New()
+	Register(app)
+
+	// Change the level of the "gorm" logger with Basic Authentication.
+	body := `{"level": "debug", "logger": "gorm", "duration": "1m"}`
+	req := httptest.NewRequest("PUT", "/admin/log-level", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.SetBasicAuth("admin", "password")
+	resp, err := app.Test(req)
+
+	// Assert the response status code is 200 OK.
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	// Parse the response body and verify the override.
+	var response LogLevelResponse
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	assert.NoError(t, err)
+	assert.Equal(t, "debug", response.Loggers["gorm"])
+	assert.Equal(t, zapcore.DebugLevel, logger.LoggerLevels()["gorm"])
+}
+
+// TestSetLogLevelInvalid validates that an invalid level is rejected with 400 Bad Request.
+func TestSetLogLevelInvalid(t *testing.T) {
+	// Create a new Fiber app instance and register routes.
+	app := fiber.New()
+	Register(app)
+
+	// Send an unknown level.
+	body := `{"level": "verbose"}`
+	req := httptest.NewRequest("PUT", "/admin/log-level", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.SetBasicAuth("admin", "password")
+	resp, err := app.Test(req)
+
+	// Assert the response status code is 400 Bad Request.
+	assert.NoError(t, err)
+	assert.Equal(t, 400, resp.StatusCode)
+}
+
+// TestLogLevelUnauthorized validates that the admin endpoints require authentication.
+func TestLogLevelUnauthorized(t *testing.T) {
+	// Create a new Fiber app instance and register routes.
+	app := fiber.New()
+	Register(app)
+
+	// Perform the GET request without Basic Authentication.
+	req := httptest.NewRequest("GET", "/admin/log-level", nil)
+	resp, err := app.Test(req)
+
+	// Assert the response status code is 401 Unauthorized.
+	assert.NoError(t, err)
+	assert.Equal(t, 401, resp.StatusCode)
+}
+
+// TestGetAuditLogInvalidFilters validates that invalid audit log filters are rejected.
+func TestGetAuditLogInvalidFilters(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	Register(app)
+
+	req := httptest.NewRequest("GET", "/admin/audit?action=rename&from=yesterday&page_size=500", nil)
+	req.SetBasicAuth("admin", "password")
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, 422, resp.StatusCode)
+
+	var problem apperror.Problem
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&problem))
+	fields := []string{}
+	for _, field := range problem.Errors {
+		fields = append(fields, field.Field)
+	}
+	assert.ElementsMatch(t, []string{"action", "from", "page_size"}, fields)
+}
+
+// TestGetGRPCMetrics validates that the GET /admin/grpc/metrics endpoint returns the recorded gRPC calls.
+func TestGetGRPCMetrics(t *testing.T) {
+	previous := rpc.DefaultMetrics
+	rpc.DefaultMetrics = rpc.NewMetrics()
+	defer func() { rpc.DefaultMetrics = previous }()
+	rpc.DefaultMetrics.Record("/gobo.v1.ExampleService/GetExample", "OK", 2*time.Millisecond)
+	rpc.DefaultMetrics.Record("/gobo.v1.ExampleService/GetExample", "NotFound", 4*time.Millisecond)
+
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	Register(app)
+
+	req := httptest.NewRequest("GET", "/admin/grpc/metrics", nil)
+	req.SetBasicAuth("admin", "password")
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	var response GRPCMetricsResponse
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	if assert.Len(t, response.Methods, 1) {
+		metrics := response.Methods[0]
+		assert.Equal(t, "/gobo.v1.ExampleService/GetExample", metrics.Method)
+		assert.Equal(t, int64(2), metrics.Calls)
+		assert.Equal(t, int64(1), metrics.Errors)
+		assert.Equal(t, map[string]int64{"OK": 1, "NotFound": 1}, metrics.Codes)
+		assert.InDelta(t, 3.0, metrics.AverageMs, 0.001)
+		assert.InDelta(t, 4.0, metrics.MaxMs, 0.001)
+	}
+}
@@ -0,0 +1,123 @@
+// Package routes defines and registers all HTTP routes for the application.
+// This file contains the audit log endpoint.
+package routes
+
+import (
+	"time"
+
+	"gobo/internal/apperror"
+	"gobo/internal/audit"
+	"gobo/internal/db"
+	"gobo/internal/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Request struct for querying the audit log
+type AuditLogRequest struct {
+	Entity    string `query:"entity"`                                                         // Table of the changed records (e.g., "examples").
+	ID        string `query:"id"`                                                             // Primary key of the changed record.
+	Action    string `query:"action" validate:"omitempty,oneof=create update delete restore"` // The action.
+	Actor     string `query:"actor"`                                                          // Username of the actor.
+	RequestID string `query:"request_id"`                                                     // Request ID (X-Request-ID).
+	From      string `query:"from" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`   // Earliest change (RFC 3339, inclusive).
+	To        string `query:"to" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`     // Latest change (RFC 3339, exclusive).
+	Page      int    `query:"page" validate:"omitempty,min=1"`                                // Page number (defaults to 1).
+	PageSize  int    `query:"page_size" validate:"omitempty,min=1,max=100"`                   // Entries per page (defaults to 50).
+}
+
+// Response struct for an audit log entry
+type AuditLogResponse struct {
+	ID        uint                   `json:"id"`               // The ID of the entry.
+	Entity    string                 `json:"entity"`           // Table of the changed record.
+	EntityID  string                 `json:"entity_id"`        // Primary key of the changed record.
+	Action    string                 `json:"action"`           // create, update, delete or restore.
+	Actor     string                 `json:"actor"`            // Username of the actor; empty for anonymous requests.
+	RequestID string                 `json:"request_id"`       // Request ID.
+	IP        string                 `json:"ip"`               // Client IP address.
+	Before    map[string]interface{} `json:"before,omitempty"` // Changed columns before the change.
+	After     map[string]interface{} `json:"after,omitempty"`  // Changed columns after the change.
+	CreatedAt time.Time              `json:"created_at"`       // Time of the change.
+}
+
+// Response struct for a page of audit log entries
+type AuditLogListResponse struct {
+	Data     []AuditLogResponse `json:"data"`      // The entries, newest first.
+	Page     int                `json:"page"`      // The page number.
+	PageSize int                `json:"page_size"` // Entries per page.
+	Total    int64              `json:"total"`     // Number of matching entries.
+}
+
+// getAuditLogHandler returns the audit log entries matching the filters, newest first.
+// @Summary      Query Audit Log
+// @Description  Returns who created, updated, deleted or restored records, with the changed columns before and after each change.
+// @Tags         admin
+// @Produce      json
+// @Security     BasicAuth
+// @Param        entity     query string false "Table of the changed records (e.g., examples)"
+// @Param        id         query string false "Primary key of the changed record"
+// @Param        action     query string false "Action" Enums(create, update, delete, restore)
+// @Param        actor      query string false "Username of the actor"
+// @Param        request_id query string false "Request ID"
+// @Param        from       query string false "Earliest change (RFC 3339, inclusive)"
+// @Param        to         query string false "Latest change (RFC 3339, exclusive)"
+// @Param        page       query int    false "Page number" minimum(1) default(1)
+// @Param        page_size  query int    false "Entries per page" minimum(1) maximum(100) default(50)
+// @Success      200 {object} AuditLogListResponse
+// @Failure      400 {object} apperror.Problem
+// @Failure      401 {object} apperror.Problem
+// @Failure      422 {object} apperror.Problem
+// @Failure      500 {object} apperror.Problem
+// @Router       /admin/audit [get]
+func getAuditLogHandler(c *fiber.Ctx) error {
+	var params AuditLogRequest
+	if err := validation.BindAndValidate(c, &params); err != nil {
+		return err
+	}
+
+	filter := audit.Filter{
+		Entity:    params.Entity,
+		EntityID:  params.ID,
+		Action:    params.Action,
+		Actor:     params.Actor,
+		RequestID: params.RequestID,
+		Page:      params.Page,
+		PageSize:  params.PageSize,
+	}
+	// The formats were validated, so parsing cannot fail.
+	filter.From, _ = time.Parse(time.RFC3339, params.From)
+	filter.To, _ = time.Parse(time.RFC3339, params.To)
+	if filter.Page == 0 {
+		filter.Page = 1
+	}
+	if filter.PageSize == 0 {
+		filter.PageSize = 50
+	}
+
+	entries, total, err := audit.Query(db.GormDB.WithContext(c.UserContext()), filter)
+	if err != nil {
+		return apperror.From(err)
+	}
+
+	response := AuditLogListResponse{
+		Data:     make([]AuditLogResponse, 0, len(entries)),
+		Page:     filter.Page,
+		PageSize: filter.PageSize,
+		Total:    total,
+	}
+	for _, entry := range entries {
+		response.Data = append(response.Data, AuditLogResponse{
+			ID:        entry.ID,
+			Entity:    entry.Entity,
+			EntityID:  entry.EntityID,
+			Action:    entry.Action,
+			Actor:     entry.Actor,
+			RequestID: entry.RequestID,
+			IP:        entry.IP,
+			Before:    entry.Before,
+			After:     entry.After,
+			CreatedAt: entry.CreatedAt,
+		})
+	}
+	return c.JSON(response)
+}
@@ -0,0 +1,164 @@
+// Package routes defines and registers all HTTP routes for the application.
+// This file contains the bulk endpoint creating, updating and deleting examples in a single request.
+package routes
+
+import (
+	"errors"
+	"os"
+	"strconv"
+
+	"gobo/internal/apperror"
+	"gobo/internal/i18n"
+	"gobo/internal/logger"
+	"gobo/internal/service"
+	"gobo/internal/validation"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// BulkConfig limits the bulk requests.
+type BulkConfig struct {
+	MaxOperations int // Maximum operations of a request (defaults to 1000)
+	MaxBytes      int // Maximum size of the request body, in bytes (defaults to 1 MiB)
+	BatchSize     int // Examples inserted per statement (defaults to service.DefaultBulkBatchSize)
+}
+
+// DefaultBulkConfig returns the default bulk configuration.
+//
+// Defaults:
+// - MaxOperations: 1000, or the BULK_MAX_OPERATIONS environment variable
+// - MaxBytes: 1048576 (1 MiB), or the BULK_MAX_BYTES environment variable
+// - BatchSize: 100, or the BULK_BATCH_SIZE environment variable
+//
+// Returns:
+// - BulkConfig: The default bulk configuration.
+func DefaultBulkConfig() BulkConfig {
+	config := BulkConfig{
+		MaxOperations: 1000,
+		MaxBytes:      1 << 20,
+		BatchSize:     service.DefaultBulkBatchSize,
+	}
+	if value, err := strconv.Atoi(os.Getenv("BULK_MAX_OPERATIONS")); err == nil && value > 0 {
+		config.MaxOperations = value
+	}
+	if value, err := strconv.Atoi(os.Getenv("BULK_MAX_BYTES")); err == nil && value > 0 {
+		config.MaxBytes = value
+	}
+	if value, err := strconv.Atoi(os.Getenv("BULK_BATCH_SIZE")); err == nil && value > 0 {
+		config.BatchSize = value
+	}
+	return config
+}
+
+// BulkOperationRequest is an operation of a bulk request: a create (name), an update (id, name and version)
+// or a delete (id).
+type BulkOperationRequest = service.BulkOperation
+
+// Request struct for bulk operations on examples
+type BulkExamplesRequest struct {
+	Mode       string                 `json:"mode" validate:"omitempty,oneof=all_or_nothing best_effort"` // all_or_nothing (default) or best_effort.
+	Operations []BulkOperationRequest `json:"operations" validate:"required,min=1"`                       // The operations, validated one by one.
+}
+
+// BulkExampleResult is the outcome of an operation of a bulk request.
+type BulkExampleResult struct {
+	Index   int               `json:"index"`             // The index of the operation in the request.
+	Action  string            `json:"action"`            // The action of the operation.
+	Status  int               `json:"status"`            // 201 (created), 200 (updated), 204 (deleted), or the status of the error.
+	Example *ExampleResponse  `json:"example,omitempty"` // The created or updated example.
+	Error   *apperror.Problem `json:"error,omitempty"`   // Why the operation failed; 424 if it was rolled back because another one failed.
+}
+
+// BulkExamplesResponse contains the outcome of each operation of a bulk request.
+type BulkExamplesResponse struct {
+	Mode      string              `json:"mode"`      // The mode of the request.
+	Succeeded int                 `json:"succeeded"` // The number of applied operations.
+	Failed    int                 `json:"failed"`    // The number of operations that were not applied.
+	Results   []BulkExampleResult `json:"results"`   // The outcome of each operation, in the order of the request.
+}
+
+// bulkSuccessStatus are the statuses of the applied operations, by action.
+var bulkSuccessStatus = map[string]int{
+	service.BulkCreate: fiber.StatusCreated,
+	service.BulkUpdate: fiber.StatusOK,
+	service.BulkDelete: fiber.StatusNoContent,
+}
+
+// bulkExamplesHandler returns the handler applying bulk operations on examples.
+// @Summary      Bulk Create, Update and Delete Examples
+// @Description  Applies a list of operations on examples: {"action": "create", "name": "..."}, {"action": "update", "id": 1, "name": "...", "version": 2} or {"action": "delete", "id": 1}. The created examples are inserted in batches before the updates and deletes are applied in order. In the "all_or_nothing" mode (default), nothing is applied if an operation is invalid or fails: the other operations fail with 424. In the "best_effort" mode, the valid operations are applied even if others fail. The response is 207 Multi-Status, with the status and the example or the error of each operation. Requests may contain at most 1000 operations and 1 MiB.
+// @Tags         examples
+// @Accept       json
+// @Produce      json
+// @Security     BasicAuth
+// @Param        request body BulkExamplesRequest true "Mode and operations"
+// @Param        Idempotency-Key header string false "Unique key making the request safe to retry"
+// @Success      207 {object} BulkExamplesResponse
+// @Failure      400 {object} apperror.Problem
+// @Failure      401 {object} apperror.Problem
+// @Failure      413 {object} apperror.Problem "Too many operations or request body too large"
+// @Failure      422 {object} apperror.Problem
+// @Failure      429 {object} apperror.Problem
+// @Router       /v2/examples/bulk [post]
+func bulkExamplesHandler(config BulkConfig) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if len(c.Body()) > config.MaxBytes {
+			return apperror.New(fiber.StatusRequestEntityTooLarge, "payload_too_large", "").
+				WithKey("error.payload_too_large", i18n.Args{"max": config.MaxBytes})
+		}
+		var body BulkExamplesRequest
+		if err := validation.BindAndValidate(c, &body); err != nil {
+			return err
+		}
+		if len(body.Operations) > config.MaxOperations {
+			return apperror.New(fiber.StatusRequestEntityTooLarge, "too_many_operations", "").
+				WithKey("error.bulk_too_many_operations", i18n.Args{"max": config.MaxOperations})
+		}
+		if body.Mode == "" {
+			body.Mode = service.BulkAllOrNothing
+		}
+
+		results := service.BulkExamples(c.UserContext(), body.Operations, body.Mode, config.BatchSize)
+		response := BulkExamplesResponse{Mode: body.Mode, Results: make([]BulkExampleResult, len(results))}
+		for i, result := range results {
+			item := BulkExampleResult{Index: i, Action: result.Action}
+			if result.Err != nil {
+				appErr := bulkError(c, result.Err)
+				problem := apperror.NewProblem(c, appErr)
+				item.Status, item.Error = appErr.Status, &problem
+				response.Failed++
+			} else {
+				item.Status = bulkSuccessStatus[result.Action]
+				if result.Action != service.BulkDelete {
+					example := newExampleResponse(result.Example)
+					item.Example = &example
+				}
+				response.Succeeded++
+			}
+			response.Results[i] = item
+		}
+		return c.Status(fiber.StatusMultiStatus).JSON(response)
+	}
+}
+
+// bulkError converts the error of an operation as the single-example endpoints do: version conflicts carry the
+// current example. Server errors are logged with their cause.
+func bulkError(c *fiber.Ctx, err error) *apperror.Error {
+	var conflict *service.VersionConflictError
+	if errors.As(err, &conflict) {
+		return apperror.Conflict("").
+			WithKey("error.version_conflict", i18n.Args{"version": conflict.Version, "current": conflict.Current.Version.Int64}).
+			WithCode("version_conflict").
+			WithExtension("current", newExampleResponse(conflict.Current))
+	}
+	appErr := apperror.From(err)
+	if appErr.Status >= fiber.StatusInternalServerError {
+		logger.FromContext(c.UserContext()).Error("Bulk operation failed",
+			zap.Error(err),
+			zap.String("path", c.Path()),
+			zap.Int("status", appErr.Status),
+		)
+	}
+	return appErr
+}
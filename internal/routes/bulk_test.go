@@ -0,0 +1,115 @@
+// Package routes contains tests for the bulk endpoint of the examples.
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gobo/internal/apperror"
+	"gobo/internal/db"
+	"gobo/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// postBulk sends a bulk request to the handler with the given configuration.
+func postBulk(t *testing.T, config BulkConfig, body string) *http.Response {
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Post("/examples/bulk", bulkExamplesHandler(config))
+	req := httptest.NewRequest(http.MethodPost, "/examples/bulk", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req, -1)
+	assert.NoError(t, err)
+	return resp
+}
+
+// TestBulkExamplesLimits validates that oversized and invalid bulk requests are rejected before reaching the database.
+func TestBulkExamplesLimits(t *testing.T) {
+	config := BulkConfig{MaxOperations: 2, MaxBytes: 200, BatchSize: 10}
+
+	resp := postBulk(t, config, fmt.Sprintf(`{"operations": [{"action": "create", "name": "%s"}]}`, strings.Repeat("a", 200)))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+
+	resp = postBulk(t, config, `{"operations": [{"action": "delete", "id": 1}, {"action": "delete", "id": 2}, {"action": "delete", "id": 3}]}`)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+	var problem apperror.Problem
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&problem))
+	assert.Equal(t, "too_many_operations", problem.Code)
+
+	resp = postBulk(t, config, `{"operations": []}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+	resp = postBulk(t, config, `{"mode": "sometimes", "operations": [{"action": "delete", "id": 1}]}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+
+	// Invalid operations of an all-or-nothing request roll it back without reaching the database.
+	resp = postBulk(t, config, `{"operations": [{"action": "create", "name": "Alpha"}, {"action": "update", "id": 1}]}`)
+	assert.Equal(t, http.StatusMultiStatus, resp.StatusCode)
+	var response BulkExamplesResponse
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	assert.Equal(t, "all_or_nothing", response.Mode)
+	assert.Equal(t, 0, response.Succeeded)
+	assert.Equal(t, 2, response.Failed)
+	assert.Equal(t, http.StatusFailedDependency, response.Results[0].Status)
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Results[1].Status)
+}
+
+// TestBulkExamples validates that the operations of bulk requests are applied in both modes.
+func TestBulkExamples(t *testing.T) {
+	setupGormTestDB(t)
+	defer teardownTestDB()
+
+	existing := models.Example{Name: "Existing"}
+	assert.NoError(t, db.GormDB.Create(&existing).Error)
+	config := BulkConfig{MaxOperations: 10, MaxBytes: 1 << 20, BatchSize: 2}
+	decode := func(resp *http.Response) BulkExamplesResponse {
+		var response BulkExamplesResponse
+		assert.Equal(t, http.StatusMultiStatus, resp.StatusCode)
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		return response
+	}
+
+	// A failing operation rolls back the whole all-or-nothing request.
+	response := decode(postBulk(t, config, fmt.Sprintf(`{"operations": [
+		{"action": "create", "name": "Alpha"},
+		{"action": "update", "id": %d, "name": "Renamed", "version": 5}
+	]}`, existing.ID)))
+	assert.Equal(t, 2, response.Failed)
+	assert.Equal(t, http.StatusFailedDependency, response.Results[0].Status)
+	assert.Equal(t, http.StatusConflict, response.Results[1].Status)
+	var count int64
+	db.GormDB.Model(&models.Example{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+
+	// Best-effort requests apply the valid operations.
+	response = decode(postBulk(t, config, fmt.Sprintf(`{"mode": "best_effort", "operations": [
+		{"action": "create", "name": "Alpha"},
+		{"action": "create", "name": "Beta"},
+		{"action": "create", "name": "Gamma"},
+		{"action": "update", "id": %d, "name": "Renamed", "version": 5},
+		{"action": "delete", "id": 999}
+	]}`, existing.ID)))
+	assert.Equal(t, 3, response.Succeeded)
+	assert.Equal(t, 2, response.Failed)
+	assert.Equal(t, http.StatusCreated, response.Results[2].Status)
+	if assert.NotNil(t, response.Results[2].Example) {
+		assert.Equal(t, "Gamma", response.Results[2].Example.Name)
+	}
+	assert.Equal(t, http.StatusConflict, response.Results[3].Status)
+	assert.Equal(t, http.StatusNotFound, response.Results[4].Status)
+
+	// All-or-nothing requests apply every operation when none fails.
+	response = decode(postBulk(t, config, fmt.Sprintf(`{"operations": [
+		{"action": "create", "name": "Delta"},
+		{"action": "update", "id": %d, "name": "Renamed", "version": 1},
+		{"action": "delete", "id": %d}
+	]}`, existing.ID, response.Results[0].Example.ID)))
+	assert.Equal(t, 3, response.Succeeded)
+	assert.Equal(t, []int{201, 200, 204}, []int{response.Results[0].Status, response.Results[1].Status, response.Results[2].Status})
+	db.GormDB.Model(&models.Example{}).Count(&count)
+	assert.Equal(t, int64(4), count)
+}
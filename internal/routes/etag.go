@@ -0,0 +1,47 @@
+// Package routes defines and registers all HTTP routes for the application.
+// This file contains the entity tag helpers for conditional requests (If-Match).
+package routes
+
+import (
+	"strconv"
+	"strings"
+
+	"gobo/internal/apperror"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// etag returns the entity tag of a resource version (e.g., `"3"`).
+func etag(version int64) string {
+	return strconv.Quote(strconv.FormatInt(version, 10))
+}
+
+// ifMatchVersion returns the resource version required by the If-Match header.
+// A weak tag (W/"3") is accepted like a strong one, and "*" matches any version (0).
+//
+// Parameters:
+// - c (*fiber.Ctx): The Fiber context of the request.
+//
+// Returns:
+// - int64: The required version, or 0 for "*".
+// - bool: Whether the header is present.
+// - error: An *apperror.Error (400) if the header is not a single entity tag of a version.
+func ifMatchVersion(c *fiber.Ctx) (int64, bool, error) {
+	header := strings.TrimSpace(c.Get(fiber.HeaderIfMatch))
+	if header == "" {
+		return 0, false, nil
+	}
+	if header == "*" {
+		return 0, true, nil
+	}
+
+	tag := strings.TrimPrefix(header, "W/")
+	unquoted, err := strconv.Unquote(tag)
+	if err == nil {
+		var version int64
+		if version, err = strconv.ParseInt(unquoted, 10, 64); err == nil && version > 0 {
+			return version, true, nil
+		}
+	}
+	return 0, true, apperror.BadRequest("").WithKey("error.invalid_if_match").WithCode("invalid_if_match")
+}
@@ -0,0 +1,67 @@
+// Package routes contains tests for the application's API endpoints.
+// These tests validate the If-Match handling of conditional requests, which does not require a database.
+package routes
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gobo/internal/apperror"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestIfMatchVersion validates the parsing of If-Match headers.
+func TestIfMatchVersion(t *testing.T) {
+	tests := []struct {
+		header      string
+		version     int64
+		conditional bool
+		invalid     bool
+	}{
+		{header: "", version: 0, conditional: false},
+		{header: `"3"`, version: 3, conditional: true},
+		{header: `W/"3"`, version: 3, conditional: true},
+		{header: `*`, version: 0, conditional: true},
+		{header: `3`, conditional: true, invalid: true},
+		{header: `"0"`, conditional: true, invalid: true},
+		{header: `"1", "2"`, conditional: true, invalid: true},
+	}
+
+	for _, test := range tests {
+		var (
+			version     int64
+			conditional bool
+			err         error
+		)
+		app := fiber.New()
+		app.Put("/", func(c *fiber.Ctx) error {
+			version, conditional, err = ifMatchVersion(c)
+			return nil
+		})
+		req := httptest.NewRequest("PUT", "/", nil)
+		req.Header.Set(fiber.HeaderIfMatch, test.header)
+		_, testErr := app.Test(req)
+		assert.NoError(t, testErr)
+
+		assert.Equal(t, test.version, version, test.header)
+		assert.Equal(t, test.conditional, conditional, test.header)
+		assert.Equal(t, test.invalid, err != nil, test.header)
+	}
+}
+
+// TestUpdateExamplePreconditionRequired validates that updates without a version are rejected before reaching the database.
+func TestUpdateExamplePreconditionRequired(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	Register(app)
+
+	req := httptest.NewRequest("PUT", "/v2/examples/1", strings.NewReader(`{"name": "Updated"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.SetBasicAuth("admin", "password")
+	resp, err := app.Test(req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 428, resp.StatusCode)
+}
@@ -0,0 +1,98 @@
+// Package routes defines and registers all HTTP routes for the application.
+// This file contains the adapters serving the v1 representation of the example endpoints.
+package routes
+
+import (
+	"encoding/json"
+
+	"gobo/internal/apperror"
+	"gobo/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/plugin/optimisticlock"
+)
+
+// Response structs for Swagger (v1)
+type CreateExampleResponse struct {
+	Message string `json:"message"`
+	ID      int    `json:"id"`
+}
+
+// v1ListExamples adapts the list of examples to v1, which returns a bare array of models.
+// @Summary      Get All Examples
+// @Description  Retrieves all examples from the database.
+// @Tags         examples
+// @Accept       json
+// @Produce      json
+// @Param        include_deleted query bool false "Include soft-deleted examples (requires admin credentials)"
+// @Success      200 {array} models.Example
+// @Failure      401 {object} apperror.Problem
+// @Failure      500 {object} apperror.Problem
+// @Router       /v1/examples [get]
+func v1ListExamples(next fiber.Handler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var response ExampleListResponse
+		if ok, err := adaptJSON(c, next, fiber.StatusOK, &response); !ok || err != nil {
+			return err
+		}
+
+		examples := make([]models.Example, 0, len(response.Data))
+		for _, example := range response.Data {
+			model := models.Example{Name: example.Name}
+			model.ID, model.CreatedAt, model.UpdatedAt = example.ID, example.CreatedAt, example.UpdatedAt
+			model.Version = optimisticlock.Version{Int64: example.Version, Valid: true}
+			if example.DeletedAt != nil {
+				model.DeletedAt = gorm.DeletedAt{Time: *example.DeletedAt, Valid: true}
+			}
+			examples = append(examples, model)
+		}
+		return c.JSON(examples)
+	}
+}
+
+// v1CreateExample adapts the created example to v1, which returns a message and the ID.
+// @Summary      Create Example
+// @Description  Creates a new example in the database.
+// @Tags         examples
+// @Accept       json
+// @Produce      json
+// @Security     BasicAuth
+// @Param        request body      CreateExampleRequest true "Example Request"
+// @Param        Idempotency-Key header string false "Unique key making the request safe to retry"
+// @Success      201 {object} CreateExampleResponse
+// @Failure      400 {object} apperror.Problem
+// @Failure      401 {object} apperror.Problem
+// @Failure      409 {object} apperror.Problem "Duplicate name or request with the same Idempotency-Key in progress"
+// @Failure      422 {object} apperror.Problem "Invalid fields or Idempotency-Key reused with a different payload"
+// @Failure      429 {object} apperror.Problem
+// @Failure      500 {object} apperror.Problem
+// @Router       /v1/examples [post]
+func v1CreateExample(next fiber.Handler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var example ExampleResponse
+		if ok, err := adaptJSON(c, next, fiber.StatusCreated, &example); !ok || err != nil {
+			return err
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(CreateExampleResponse{
+			Message: "Example created successfully",
+			ID:      int(example.ID),
+		})
+	}
+}
+
+// adaptJSON runs the handler and decodes its response into out if it has the expected status.
+// It returns false if the response must be left as it is (e.g., an error response).
+func adaptJSON(c *fiber.Ctx, next fiber.Handler, status int, out interface{}) (bool, error) {
+	if err := next(c); err != nil {
+		return false, err
+	}
+	if c.Response().StatusCode() != status {
+		return false, nil
+	}
+	if err := json.Unmarshal(c.Response().Body(), out); err != nil {
+		return false, apperror.Internal(err)
+	}
+	return true, nil
+}
@@ -0,0 +1,73 @@
+// Package routes defines and registers all HTTP routes for the application.
+// This file contains the GraphQL endpoint and its playground.
+package routes
+
+import (
+	"gobo/internal/apperror"
+	"gobo/internal/gql"
+	"gobo/internal/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GraphQLRequest is a GraphQL request.
+type GraphQLRequest = gql.Request
+
+// GraphQLError is an error of a GraphQL response.
+type GraphQLError struct {
+	Message    string                 `json:"message"`              // The localized message.
+	Path       []interface{}          `json:"path,omitempty"`       // The path of the field that failed (e.g., ["example", "history"]).
+	Extensions map[string]interface{} `json:"extensions,omitempty"` // The machine-readable "code", the HTTP "status" and the invalid "fields".
+}
+
+// GraphQLResponse is the result of a GraphQL request.
+type GraphQLResponse struct {
+	Data   interface{}    `json:"data"`             // The selected fields; null if the request was rejected.
+	Errors []GraphQLError `json:"errors,omitempty"` // The errors of the request or of its fields.
+}
+
+// hasAuthorization reports whether the request sends credentials.
+func hasAuthorization(c *fiber.Ctx) bool {
+	return c.Get(fiber.HeaderAuthorization) != ""
+}
+
+// graphqlHandler runs a GraphQL request.
+// @Summary      GraphQL API
+// @Description  Runs a GraphQL query or mutation over the examples and users of the tenant. Queries list them with pagination ("page", "pageSize") and filters, or read them by ID with their change history; mutations create, update and delete them. Anonymous requests may only read the examples that are not deleted: the users, the history, the deleted examples and the mutations require the Basic credentials. Errors are returned in "errors" with a status of 200, with their machine-readable code in the extensions. Queries nested more than 10 levels deep or with a complexity above 1000 (each field counts 1; the fields of lists count once per requested item) are rejected before they run.
+// @Tags         graphql
+// @Accept       json
+// @Produce      json
+// @Security     BasicAuth
+// @Param        request body GraphQLRequest true "Query, operation name and variables"
+// @Success      200 {object} GraphQLResponse
+// @Failure      400 {object} apperror.Problem
+// @Failure      401 {object} apperror.Problem
+// @Failure      422 {object} apperror.Problem
+// @Failure      503 {object} apperror.Problem
+// @Router       /graphql [post]
+func graphqlHandler(c *fiber.Ctx) error {
+	var req GraphQLRequest
+	if err := validation.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+	if gql.Default == nil {
+		return apperror.New(fiber.StatusServiceUnavailable, "graphql_unavailable", "").WithKey("error.graphql_unavailable")
+	}
+	return c.JSON(gql.Default.Execute(c.UserContext(), req))
+}
+
+// graphqlPlaygroundHandler serves the GraphiQL playground in development.
+// @Summary      GraphQL Playground
+// @Description  Serves the GraphiQL playground, sending its queries to POST /graphql. Only available when APP_ENV is "development".
+// @Tags         graphql
+// @Produce      text/html
+// @Success      200 {string} string "GraphiQL page"
+// @Failure      404 {object} apperror.Problem
+// @Router       /graphql [get]
+func graphqlPlaygroundHandler(c *fiber.Ctx) error {
+	if gql.Default == nil || !gql.Default.Playground() {
+		return apperror.NotFound("").WithKey("error.not_found")
+	}
+	c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	return c.SendString(gql.PlaygroundHTML)
+}
@@ -0,0 +1,61 @@
+// Package routes contains tests for the GraphQL endpoint and its playground.
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gobo/internal/apperror"
+	"gobo/internal/gql"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestGraphQL validates that GraphQL requests are validated, authenticated when they send credentials, and
+// answered with their result.
+func TestGraphQL(t *testing.T) {
+	previous := gql.Default
+	gql.Default = gql.New(gql.Config{})
+	defer func() { gql.Default = previous }()
+
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	Register(app)
+	post := func(body, authorization string) *http.Response {
+		req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		resp, _ := app.Test(req, -1)
+		return resp
+	}
+
+	resp := post(`{"variables": {}}`, "")
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+	resp = post(`{"query": "{ __typename }"}`, "Basic d3Jvbmc6d3Jvbmc=") // wrong:wrong
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	// Errors of the query are returned in the result.
+	resp = post(`{"query": "{ __typename }"}`, "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	var result GraphQLResponse
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	assert.Equal(t, map[string]interface{}{"__typename": "Query"}, result.Data)
+	resp = post(`{"query": "{ unknown }"}`, "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	result = GraphQLResponse{}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	assert.Len(t, result.Errors, 1)
+
+	// The playground is disabled by default.
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/graphql", nil), -1)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	gql.Default = gql.New(gql.Config{Playground: true})
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/graphql", nil), -1)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get("Content-Type"), "text/html")
+}
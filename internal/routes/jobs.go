@@ -0,0 +1,212 @@
+// Package routes defines and registers all HTTP routes for the application.
+// This file contains the background job endpoints.
+package routes
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"gobo/internal/apperror"
+	"gobo/internal/jobs"
+	"gobo/internal/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Response struct for the job counts
+type JobStatsResponse struct {
+	Queued    int64 `json:"queued"`    // Jobs ready to run.
+	Scheduled int64 `json:"scheduled"` // Delayed jobs and jobs waiting for a retry.
+	Active    int64 `json:"active"`    // Jobs being processed.
+	Dead      int64 `json:"dead"`      // Jobs in the dead-letter queue.
+}
+
+// Response struct for a job
+type JobResponse struct {
+	ID          string          `json:"id"`                           // The ID of the job.
+	Type        string          `json:"type"`                         // The job type.
+	State       string          `json:"state"`                        // queued, scheduled, active or dead.
+	Payload     json.RawMessage `json:"payload" swaggertype:"object"` // Arguments of the handler.
+	Priority    int             `json:"priority"`                     // Higher priorities run first.
+	Attempts    int             `json:"attempts"`                     // Failed attempts so far.
+	MaxAttempts int             `json:"max_attempts"`                 // Attempts before the job is dead.
+	UniqueKey   string          `json:"unique_key,omitempty"`         // Key preventing duplicates while the job is pending.
+	Tenant      string          `json:"tenant,omitempty"`             // Tenant that enqueued the job.
+	EnqueuedAt  time.Time       `json:"enqueued_at"`                  // Enqueue time.
+	RunAt       time.Time       `json:"run_at"`                       // Earliest time of the next attempt.
+	LastError   string          `json:"last_error,omitempty"`         // Error of the last failed attempt.
+	FailedAt    *time.Time      `json:"failed_at,omitempty"`          // Time the job died.
+}
+
+// Request struct for listing dead jobs
+type DeadJobsRequest struct {
+	Page     int `query:"page" validate:"omitempty,min=1"`              // Page number (defaults to 1).
+	PageSize int `query:"page_size" validate:"omitempty,min=1,max=100"` // Jobs per page (defaults to 50).
+}
+
+// Response struct for a page of dead jobs
+type JobListResponse struct {
+	Data     []JobResponse `json:"data"`      // The jobs, most recently failed first.
+	Page     int           `json:"page"`      // The page number.
+	PageSize int           `json:"page_size"` // Jobs per page.
+	Total    int64         `json:"total"`     // Number of dead jobs.
+}
+
+// newJobResponse converts a job to its API representation.
+func newJobResponse(job jobs.Job, state string) JobResponse {
+	return JobResponse{
+		ID:          job.ID,
+		Type:        job.Type,
+		State:       state,
+		Payload:     job.Payload,
+		Priority:    job.Priority,
+		Attempts:    job.Attempts,
+		MaxAttempts: job.MaxAttempts,
+		UniqueKey:   job.UniqueKey,
+		Tenant:      job.Tenant,
+		EnqueuedAt:  job.EnqueuedAt,
+		RunAt:       job.RunAt,
+		LastError:   job.LastError,
+		FailedAt:    job.FailedAt,
+	}
+}
+
+// jobError converts an error of the job queue to an API error.
+func jobError(err error) error {
+	switch {
+	case errors.Is(err, jobs.ErrNotFound):
+		return apperror.NotFound("").WithKey("error.job_not_found").WithCode("job_not_found")
+	case errors.Is(err, jobs.ErrDuplicate):
+		return apperror.Conflict("").WithKey("error.job_duplicate").WithCode("job_duplicate")
+	case errors.Is(err, jobs.ErrUnavailable):
+		return apperror.New(fiber.StatusServiceUnavailable, "jobs_unavailable", "").WithKey("error.jobs_unavailable")
+	default:
+		return apperror.From(err)
+	}
+}
+
+// jobQueue returns the application's job queue.
+func jobQueue() (*jobs.Queue, error) {
+	if jobs.Default == nil {
+		return nil, jobs.ErrUnavailable
+	}
+	return jobs.Default, nil
+}
+
+// getJobStatsHandler returns the number of jobs in each state.
+// @Summary      Get Job Counts
+// @Description  Returns the number of queued, scheduled, active and dead background jobs.
+// @Tags         admin
+// @Produce      json
+// @Security     BasicAuth
+// @Success      200 {object} JobStatsResponse
+// @Failure      401 {object} apperror.Problem
+// @Failure      503 {object} apperror.Problem
+// @Router       /admin/jobs [get]
+func getJobStatsHandler(c *fiber.Ctx) error {
+	queue, err := jobQueue()
+	if err != nil {
+		return jobError(err)
+	}
+	stats, err := queue.Stats(c.UserContext())
+	if err != nil {
+		return jobError(err)
+	}
+	return c.JSON(JobStatsResponse(stats))
+}
+
+// getDeadJobsHandler returns the jobs of the dead-letter queue, most recently failed first.
+// @Summary      List Dead Jobs
+// @Description  Returns the background jobs that failed their last attempt, most recently failed first.
+// @Tags         admin
+// @Produce      json
+// @Security     BasicAuth
+// @Param        page       query int false "Page number" minimum(1) default(1)
+// @Param        page_size  query int false "Jobs per page" minimum(1) maximum(100) default(50)
+// @Success      200 {object} JobListResponse
+// @Failure      401 {object} apperror.Problem
+// @Failure      422 {object} apperror.Problem
+// @Failure      503 {object} apperror.Problem
+// @Router       /admin/jobs/dead [get]
+func getDeadJobsHandler(c *fiber.Ctx) error {
+	var params DeadJobsRequest
+	if err := validation.BindAndValidate(c, &params); err != nil {
+		return err
+	}
+	if params.Page == 0 {
+		params.Page = 1
+	}
+	if params.PageSize == 0 {
+		params.PageSize = 50
+	}
+
+	queue, err := jobQueue()
+	if err != nil {
+		return jobError(err)
+	}
+	dead, total, err := queue.Dead(c.UserContext(), (params.Page-1)*params.PageSize, params.PageSize)
+	if err != nil {
+		return jobError(err)
+	}
+
+	response := JobListResponse{
+		Data:     make([]JobResponse, 0, len(dead)),
+		Page:     params.Page,
+		PageSize: params.PageSize,
+		Total:    total,
+	}
+	for _, job := range dead {
+		response.Data = append(response.Data, newJobResponse(job, jobs.StateDead))
+	}
+	return c.JSON(response)
+}
+
+// getJobHandler returns a pending or dead job.
+// @Summary      Get Job
+// @Description  Returns a background job and its state. Completed jobs are not kept.
+// @Tags         admin
+// @Produce      json
+// @Security     BasicAuth
+// @Param        id  path string true "Job ID"
+// @Success      200 {object} JobResponse
+// @Failure      401 {object} apperror.Problem
+// @Failure      404 {object} apperror.Problem
+// @Failure      503 {object} apperror.Problem
+// @Router       /admin/jobs/{id} [get]
+func getJobHandler(c *fiber.Ctx) error {
+	queue, err := jobQueue()
+	if err != nil {
+		return jobError(err)
+	}
+	job, state, err := queue.Get(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return jobError(err)
+	}
+	return c.JSON(newJobResponse(*job, state))
+}
+
+// retryJobHandler moves a dead job back to the queue, with its attempts reset.
+// @Summary      Retry Dead Job
+// @Description  Moves a job of the dead-letter queue back to the queue, with its attempts reset.
+// @Tags         admin
+// @Produce      json
+// @Security     BasicAuth
+// @Param        id  path string true "Job ID"
+// @Success      200 {object} JobResponse
+// @Failure      401 {object} apperror.Problem
+// @Failure      404 {object} apperror.Problem
+// @Failure      409 {object} apperror.Problem
+// @Failure      503 {object} apperror.Problem
+// @Router       /admin/jobs/dead/{id}/retry [post]
+func retryJobHandler(c *fiber.Ctx) error {
+	queue, err := jobQueue()
+	if err != nil {
+		return jobError(err)
+	}
+	job, err := queue.Retry(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return jobError(err)
+	}
+	return c.JSON(newJobResponse(*job, jobs.StateQueued))
+}
@@ -0,0 +1,94 @@
+// Package routes contains tests for the application's API endpoints.
+// These tests validate the background job endpoints, which use an in-memory Redis server.
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gobo/internal/jobs"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+// setupJobQueue replaces the application's job queue with one stored in an in-memory Redis server.
+func setupJobQueue(t *testing.T) *jobs.Queue {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	previous := jobs.Default
+	jobs.Default = jobs.New(jobs.Config{Client: client, MaxAttempts: 1, PollInterval: 10 * time.Millisecond})
+	t.Cleanup(func() {
+		jobs.Default = previous
+		_ = client.Close()
+	})
+	return jobs.Default
+}
+
+// TestJobAdmin validates inspecting the dead-letter queue and retrying a dead job.
+func TestJobAdmin(t *testing.T) {
+	queue := setupJobQueue(t)
+	ctx := context.Background()
+	queue.Handle("email.send", func(ctx context.Context, job *jobs.Job) error {
+		return errors.New("smtp unavailable")
+	})
+	job, err := queue.Enqueue(ctx, "email.send", map[string]string{"to": "user@example.com"}, jobs.Options{})
+	assert.NoError(t, err)
+	stop := queue.Start()
+	assert.Eventually(t, func() bool {
+		stats, err := queue.Stats(ctx)
+		return err == nil && stats.Dead == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.NoError(t, stop(ctx))
+
+	// Create a new Fiber app instance and register routes.
+	app := fiber.New()
+	Register(app)
+	get := func(path string, response interface{}) int {
+		req := httptest.NewRequest("GET", path, nil)
+		req.SetBasicAuth("admin", "password")
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		if resp.StatusCode == 200 {
+			assert.NoError(t, json.NewDecoder(resp.Body).Decode(response))
+		}
+		return resp.StatusCode
+	}
+
+	var stats JobStatsResponse
+	assert.Equal(t, 200, get("/admin/jobs", &stats))
+	assert.Equal(t, JobStatsResponse{Dead: 1}, stats)
+
+	var dead JobListResponse
+	assert.Equal(t, 200, get("/admin/jobs/dead?page_size=10", &dead))
+	assert.EqualValues(t, 1, dead.Total)
+	if assert.Len(t, dead.Data, 1) {
+		assert.Equal(t, job.ID, dead.Data[0].ID)
+		assert.Equal(t, "smtp unavailable", dead.Data[0].LastError)
+		assert.JSONEq(t, `{"to": "user@example.com"}`, string(dead.Data[0].Payload))
+	}
+
+	// Retry the dead job.
+	req := httptest.NewRequest("POST", "/admin/jobs/dead/"+job.ID+"/retry", nil)
+	req.SetBasicAuth("admin", "password")
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	var retried JobResponse
+	assert.Equal(t, 200, get("/admin/jobs/"+job.ID, &retried))
+	assert.Equal(t, jobs.StateQueued, retried.State)
+	assert.Equal(t, 0, retried.Attempts)
+
+	// The job is no longer dead, and unknown jobs are not found.
+	resp, err = app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, 404, resp.StatusCode)
+	assert.Equal(t, 404, get("/admin/jobs/unknown", nil))
+}
@@ -3,30 +3,78 @@
 package routes
 
 import (
-	"gobo/internal/db"
+	"errors"
+	"time"
+
+	"gobo/internal/apperror"
+	"gobo/internal/i18n"
 	"gobo/internal/middleware"
 	"gobo/internal/models"
+	"gobo/internal/service"
+	"gobo/internal/transfer"
+	"gobo/internal/validation"
+	"gobo/internal/versioning"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/swagger"
 )
 
+// API versions.
+const (
+	V1 = "v1" // The original API, kept for existing clients
+	V2 = "v2" // The current API
+)
+
+// apiVersions defines the versions of the API.
+// Unversioned paths (e.g., /examples) are served by v1 and are deprecated.
+var apiVersions = versioning.Config{
+	Versions: []versioning.Version{{Name: V1}, {Name: V2}},
+	Default:  V1,
+	Unversioned: &versioning.Deprecation{
+		Date:   time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC),
+		Sunset: time.Date(2027, time.April, 30, 0, 0, 0, 0, time.UTC),
+	},
+}
+
 // Response structs for Swagger
-type ErrorResponse struct {
-	Error string `json:"error"`
+// Errors are documented as apperror.Problem (application/problem+json).
+// ExampleResponse is the representation of an example, shared with the gRPC API and the example events.
+type ExampleResponse = service.Example
+
+type ExampleListResponse struct {
+	Data []ExampleResponse `json:"data"` // The examples.
+}
+
+// Request struct for listing examples
+type ListExamplesRequest struct {
+	IncludeDeleted bool `query:"include_deleted"` // Include soft-deleted examples (admin only).
 }
 
-type CreateExampleResponse struct {
-	Message string `json:"message"`
-	ID      int    `json:"id"`
+// Request struct for endpoints addressing a single example
+type ExampleIDRequest struct {
+	ID uint `params:"id" validate:"required"` // The ID of the example.
 }
 
 // Request struct for creating an example
 type CreateExampleRequest struct {
-	Name string `json:"name"` // The name of the example to be created.
+	Name string `json:"name" validate:"required,notblank,max=100"` // The name of the example to be created.
 }
 
+// Request struct for updating an example
+type UpdateExampleRequest struct {
+	Name    string `json:"name" validate:"required,notblank,max=100"` // The new name of the example.
+	Version *int64 `json:"version" validate:"omitempty,min=1"`        // The version being updated, if the If-Match header is not sent.
+}
 
+// newExampleResponse converts an example model into its API representation.
+func newExampleResponse(example models.Example) ExampleResponse {
+	return service.NewExample(example)
+}
+
+// includesDeleted reports whether the request asks for soft-deleted records.
+func includesDeleted(c *fiber.Ctx) bool {
+	return c.QueryBool("include_deleted")
+}
 
 // Register registers all routes for the application.
 // It maps HTTP endpoints to their corresponding handlers and integrates them with the database.
@@ -34,27 +82,160 @@ type CreateExampleRequest struct {
 // Parameters:
 // - app (*fiber.App): The Fiber application instance to which routes are registered.
 func Register(app *fiber.App) {
-	// Serve the Swagger documentation at the /swagger endpoint.
-	app.Get("/swagger/*", swagger.HandlerDefault) // Default path: /swagger/index.html
+	// Mount the version groups (/v1, /v2) and route unversioned paths to a version.
+	versions := versioning.New(app, apiVersions)
 
+	// Serve the Swagger documentation of each version at /swagger/<version>/index.html,
+	// and the complete documentation at the /swagger endpoint.
+	versions.RegisterSwagger()
+	for _, version := range apiVersions.Versions {
+		app.Get("/swagger/"+version.Name+"/*", swagger.New(swagger.Config{
+			InstanceName: versioning.SwaggerInstance(version.Name),
+		}))
+	}
+	app.Get("/swagger/*", swagger.HandlerDefault) // Default path: /swagger/index.html
 
 	// Root endpoint: Responds with a simple "Hello, World!" message.
 	// GET /
 	app.Get("/", rootHandler)
 
 	// Retrieve all examples from the database.
-	// GET /examples
-	app.Get("/examples", getAllExamplesHandler)
-
-	// Group for protected POST routes
-	protected := app.Group(
-		"/examples",
-		middleware.BasicAuthMiddleware("admin", "password"), // Basic Authentication
-		middleware.RateLimitMiddleware(10, 1),               // Rate Limiting | x requests per y seconds
+	// Soft-deleted examples are only listed for administrators (?include_deleted=true).
+	// GET /v1/examples, /v2/examples
+	versions.Handle(fiber.MethodGet, "/examples",
+		[]versioning.Binding{versioning.In(V1, v1ListExamples), versioning.In(V2)},
+		middleware.BasicAuthIf(includesDeleted, middleware.AdminUsername, middleware.AdminPassword), // Basic Authentication for deleted examples
+		getAllExamplesHandler,
 	)
+
 	// Create a new example in the database.
-	// POST /examples
-	protected.Post("/", createExampleHandler)
+	// POST /v1/examples, /v2/examples
+	versions.Handle(fiber.MethodPost, "/examples",
+		[]versioning.Binding{versioning.In(V1, v1CreateExample), versioning.In(V2)},
+		middleware.BasicAuthMiddleware(middleware.AdminUsername, middleware.AdminPassword), // Basic Authentication
+		middleware.RateLimitMiddleware(10, 1),                                              // Rate Limiting | x requests per y seconds
+		middleware.IdempotencyMiddleware(middleware.DefaultIdempotencyConfig()),            // Safe retries with Idempotency-Key
+		createExampleHandler,
+	)
+
+	// Create, update and delete examples in a single request; it counts as one request for the rate limit.
+	// POST /v2/examples/bulk
+	versions.Handle(fiber.MethodPost, "/examples/bulk",
+		[]versioning.Binding{versioning.In(V2)},
+		middleware.BasicAuthMiddleware(middleware.AdminUsername, middleware.AdminPassword), // Basic Authentication
+		middleware.RateLimitMiddleware(10, 1),                                              // Rate Limiting | x requests per y seconds
+		middleware.IdempotencyMiddleware(middleware.DefaultIdempotencyConfig()),            // Safe retries with Idempotency-Key
+		bulkExamplesHandler(DefaultBulkConfig()),
+	)
+
+	// Export the examples as a CSV, NDJSON or XLSX file, streamed page by page (registered before /examples/:id).
+	// GET /v2/examples/export
+	versions.Handle(fiber.MethodGet, "/examples/export",
+		[]versioning.Binding{versioning.In(V2)},
+		middleware.BasicAuthMiddleware(middleware.AdminUsername, middleware.AdminPassword), // Basic Authentication
+		exportExamplesHandler(transfer.DefaultConfig()),
+	)
+
+	// Import examples from a CSV or NDJSON file; large files are imported by a background job.
+	// POST /v2/examples/import, GET /v2/examples/imports/:id
+	versions.Handle(fiber.MethodPost, "/examples/import",
+		[]versioning.Binding{versioning.In(V2)},
+		middleware.BasicAuthMiddleware(middleware.AdminUsername, middleware.AdminPassword), // Basic Authentication
+		middleware.RateLimitMiddleware(10, 1),                                              // Rate Limiting | x requests per y seconds
+		importExamplesHandler(DefaultImportConfig()),
+	)
+	versions.Handle(fiber.MethodGet, "/examples/imports/:id",
+		[]versioning.Binding{versioning.In(V2)},
+		middleware.BasicAuthMiddleware(middleware.AdminUsername, middleware.AdminPassword), // Basic Authentication
+		getImportHandler,
+	)
+
+	// Search the examples by name, best matches first (registered before /examples/:id).
+	// GET /v2/examples/search
+	versions.Handle(fiber.MethodGet, "/examples/search",
+		[]versioning.Binding{versioning.In(V2)},
+		searchExamplesHandler,
+	)
+
+	// Stream the changes of the examples of the tenant with Server-Sent Events (registered before /examples/:id).
+	// GET /v2/examples/stream
+	versions.Handle(fiber.MethodGet, "/examples/stream",
+		[]versioning.Binding{versioning.In(V2)},
+		middleware.BasicAuthMiddleware(middleware.AdminUsername, middleware.AdminPassword), // Basic Authentication
+		streamExamplesHandler,
+	)
+
+	// Retrieve a single example; its version is returned in the ETag header.
+	// GET /v2/examples/:id
+	versions.Handle(fiber.MethodGet, "/examples/:id",
+		[]versioning.Binding{versioning.In(V2)},
+		getExampleHandler,
+	)
+
+	// Update an example; the version must match (If-Match header or "version" field).
+	// PUT /v2/examples/:id
+	versions.Handle(fiber.MethodPut, "/examples/:id",
+		[]versioning.Binding{versioning.In(V2)},
+		middleware.BasicAuthMiddleware(middleware.AdminUsername, middleware.AdminPassword), // Basic Authentication
+		updateExampleHandler,
+	)
+
+	// Soft delete an example; it can be restored by an administrator until it is purged.
+	// DELETE /v2/examples/:id
+	versions.Handle(fiber.MethodDelete, "/examples/:id",
+		[]versioning.Binding{versioning.In(V2)},
+		middleware.BasicAuthMiddleware(middleware.AdminUsername, middleware.AdminPassword), // Basic Authentication
+		deleteExampleHandler,
+	)
+
+	// Manage the webhook subscriptions of the tenant, inspect their deliveries and replay them.
+	// GET, POST /v2/webhooks; GET, PUT, DELETE /v2/webhooks/:id
+	// GET /v2/webhooks/:id/deliveries, GET /v2/webhooks/:id/deliveries/:delivery_id
+	// POST /v2/webhooks/:id/deliveries/:delivery_id/replay
+	webhookRoutes := []struct {
+		method, path string
+		handler      fiber.Handler
+	}{
+		{fiber.MethodGet, "/webhooks", getWebhooksHandler},
+		{fiber.MethodPost, "/webhooks", createWebhookHandler},
+		{fiber.MethodGet, "/webhooks/:id", getWebhookHandler},
+		{fiber.MethodPut, "/webhooks/:id", updateWebhookHandler},
+		{fiber.MethodDelete, "/webhooks/:id", deleteWebhookHandler},
+		{fiber.MethodGet, "/webhooks/:id/deliveries", getWebhookDeliveriesHandler},
+		{fiber.MethodGet, "/webhooks/:id/deliveries/:delivery_id", getWebhookDeliveryHandler},
+		{fiber.MethodPost, "/webhooks/:id/deliveries/:delivery_id/replay", replayWebhookDeliveryHandler},
+	}
+	for _, route := range webhookRoutes {
+		versions.Handle(route.method, route.path,
+			[]versioning.Binding{versioning.In(V2)},
+			middleware.BasicAuthMiddleware(middleware.AdminUsername, middleware.AdminPassword), // Basic Authentication
+			route.handler,
+		)
+	}
+
+	// Exchange messages on the channels of the tenant over a WebSocket connection, authenticated at upgrade time.
+	// GET /v2/ws
+	versions.Handle(fiber.MethodGet, "/ws",
+		[]versioning.Binding{versioning.In(V2)},
+		middleware.BasicAuthMiddleware(middleware.AdminUsername, middleware.AdminPassword), // Basic Authentication
+		websocketHandler,
+	)
+
+	// Run GraphQL queries and mutations; credentials are only checked if they are sent, since anonymous
+	// requests may read the examples.
+	// POST /graphql
+	app.Post("/graphql",
+		middleware.BasicAuthIf(hasAuthorization, middleware.AdminUsername, middleware.AdminPassword), // Basic Authentication, if credentials are sent
+		graphqlHandler,
+	)
+
+	// Serve the GraphiQL playground in development.
+	// GET /graphql
+	app.Get("/graphql", graphqlPlaygroundHandler)
+
+	// Administrative endpoints (e.g., runtime log level control).
+	// /admin/*
+	registerAdmin(app)
 }
 
 // rootHandler handles the root endpoint.
@@ -70,60 +251,189 @@ func rootHandler(c *fiber.Ctx) error {
 }
 
 // getAllExamplesHandler retrieves all examples from the database and returns them as JSON.
+// It implements the current version; v1 responses are adapted by v1ListExamples.
 // @Summary      Get All Examples
 // @Description  Retrieves all examples from the database.
 // @Tags         examples
 // @Accept       json
 // @Produce      json
-// @Success      200 {array} models.Example
-// @Failure      500 {object} ErrorResponse
-// @Router       /examples [get]
+// @Param        include_deleted query bool false "Include soft-deleted examples (requires admin credentials)"
+// @Success      200 {object} ExampleListResponse
+// @Failure      400 {object} apperror.Problem
+// @Failure      401 {object} apperror.Problem
+// @Failure      500 {object} apperror.Problem
+// @Router       /v2/examples [get]
 func getAllExamplesHandler(c *fiber.Ctx) error {
-	var examples []models.Example // Slice to hold the retrieved examples.
+	var params ListExamplesRequest
+	if err := validation.BindAndValidate(c, &params); err != nil {
+		return err
+	}
 
-	// Query the database for all examples.
-	if result := db.GormDB.Find(&examples); result.Error != nil {
-		// Return a 500 status code if there is an error during the query.
-		return c.Status(500).JSON(ErrorResponse{Error: "Failed to fetch examples"})
+	// Soft-deleted examples are excluded unless requested (authorized by BasicAuthIf).
+	examples, err := service.ListExamples(c.UserContext(), params.IncludeDeleted)
+	if err != nil {
+		// Let the error handler map the database error (500 for unexpected errors).
+		return apperror.From(err)
 	}
 
 	// Return the examples as a JSON response.
-	return c.JSON(examples)
+	response := ExampleListResponse{Data: make([]ExampleResponse, 0, len(examples))}
+	for _, example := range examples {
+		response.Data = append(response.Data, newExampleResponse(example))
+	}
+	return c.JSON(response)
 }
 
 // createExampleHandler handles the creation of a new example in the database.
+// It implements the current version; v1 responses are adapted by v1CreateExample.
 // @Summary      Create Example
-// @Description  Creates a new example in the database.
+// @Description  Creates a new example in the database and returns it.
 // @Tags         examples
 // @Accept       json
 // @Produce      json
+// @Security     BasicAuth
 // @Param        request body      CreateExampleRequest true "Example Request"
-// @Success      201 {object} CreateExampleResponse
-// @Failure      400 {object} ErrorResponse
-// @Failure      500 {object} ErrorResponse
-// @Router       /examples [post]
+// @Param        Idempotency-Key header string false "Unique key making the request safe to retry"
+// @Success      201 {object} ExampleResponse
+// @Failure      400 {object} apperror.Problem
+// @Failure      401 {object} apperror.Problem
+// @Failure      409 {object} apperror.Problem "Duplicate name or request with the same Idempotency-Key in progress"
+// @Failure      422 {object} apperror.Problem "Invalid fields or Idempotency-Key reused with a different payload"
+// @Failure      429 {object} apperror.Problem
+// @Failure      500 {object} apperror.Problem
+// @Router       /v2/examples [post]
 func createExampleHandler(c *fiber.Ctx) error {
 	var body CreateExampleRequest
 
-	// Parse the JSON request body into the request struct.
-	if err := c.BodyParser(&body); err != nil {
-		// Return a 400 status code if the request body is invalid.
-		return c.Status(400).JSON(ErrorResponse{Error: "Invalid request body"})
+	// Parse and validate the request body (400 if malformed, 422 if invalid).
+	if err := validation.BindAndValidate(c, &body); err != nil {
+		return err
 	}
 
-	// Create a new example record using the parsed data.
-	example := models.Example{Name: body.Name}
-	if result := db.GormDB.Create(&example); result.Error != nil {
-		// Return a 500 status code if there is an error during record creation.
-		return c.Status(500).JSON(ErrorResponse{Error: "Failed to create example"})
+	// Create the example and announce it to other services.
+	example, err := service.CreateExample(c.UserContext(), service.ExampleInput{Name: body.Name})
+	if err != nil {
+		// Map the database error, e.g. a unique violation to 409 Conflict.
+		return apperror.From(err)
 	}
 
-	// Convert example.ID from uint to int
-	id := int(example.ID)
+	// Return a 201 status code and the newly created example.
+	return c.Status(201).JSON(newExampleResponse(example))
+}
+
+// getExampleHandler retrieves a single example.
+// @Summary      Get Example
+// @Description  Retrieves an example. The ETag header contains its version, for use with If-Match when updating it.
+// @Tags         examples
+// @Produce      json
+// @Param        id path int true "Example ID"
+// @Success      200 {object} ExampleResponse
+// @Header       200 {string} ETag "Version of the example"
+// @Failure      400 {object} apperror.Problem
+// @Failure      404 {object} apperror.Problem
+// @Failure      500 {object} apperror.Problem
+// @Router       /v2/examples/{id} [get]
+func getExampleHandler(c *fiber.Ctx) error {
+	var params ExampleIDRequest
+	if err := validation.BindAndValidate(c, &params); err != nil {
+		return err
+	}
+
+	example, err := service.GetExample(c.UserContext(), params.ID)
+	if err != nil {
+		return apperror.From(err) // 404 if the example does not exist or is deleted
+	}
+
+	c.Set(fiber.HeaderETag, etag(example.Version.Int64))
+	return c.JSON(newExampleResponse(example))
+}
+
+// updateExampleHandler updates an example using optimistic locking.
+// The version being updated is taken from the If-Match header, or from the "version" field if the header is not sent.
+// If the example was modified in the meantime, the update is rejected with 409 Conflict and the current representation.
+// @Summary      Update Example
+// @Description  Updates an example if its version matches the If-Match header (or the "version" field). On a version mismatch, the problem details contain the current example in the "current" member.
+// @Tags         examples
+// @Accept       json
+// @Produce      json
+// @Security     BasicAuth
+// @Param        id       path   int                  true  "Example ID"
+// @Param        If-Match header string               false "ETag of the version being updated, as returned by Get Example"
+// @Param        request  body   UpdateExampleRequest true  "Update Example Request"
+// @Success      200 {object} ExampleResponse
+// @Header       200 {string} ETag "New version of the example"
+// @Failure      400 {object} apperror.Problem
+// @Failure      401 {object} apperror.Problem
+// @Failure      404 {object} apperror.Problem
+// @Failure      409 {object} apperror.Problem "The example was modified by another request"
+// @Failure      422 {object} apperror.Problem
+// @Failure      428 {object} apperror.Problem "Neither If-Match nor version was sent"
+// @Failure      500 {object} apperror.Problem
+// @Router       /v2/examples/{id} [put]
+func updateExampleHandler(c *fiber.Ctx) error {
+	var params ExampleIDRequest
+	if err := validation.BindAndValidate(c, &params); err != nil {
+		return err
+	}
+	var body UpdateExampleRequest
+	if err := validation.BindAndValidate(c, &body); err != nil {
+		return err
+	}
+
+	// The If-Match header takes precedence over the version in the body.
+	version, conditional, err := ifMatchVersion(c)
+	if err != nil {
+		return err
+	}
+	if !conditional && body.Version != nil {
+		version, conditional = *body.Version, true
+	}
+	if !conditional {
+		return apperror.New(fiber.StatusPreconditionRequired, "precondition_required", "").WithKey("error.precondition_required")
+	}
+
+	// Update the example if it still has the expected version; the version is incremented.
+	example, err := service.UpdateExample(c.UserContext(), params.ID, service.ExampleInput{Name: body.Name}, version)
+	var conflict *service.VersionConflictError
+	if errors.As(err, &conflict) {
+		// 409 if the example has another version.
+		c.Set(fiber.HeaderETag, etag(conflict.Current.Version.Int64))
+		return apperror.Conflict("").
+			WithKey("error.version_conflict", i18n.Args{"version": version, "current": conflict.Current.Version.Int64}).
+			WithCode("version_conflict").
+			WithExtension("current", newExampleResponse(conflict.Current))
+	}
+	if err != nil {
+		return apperror.From(err)
+	}
+
+	c.Set(fiber.HeaderETag, etag(example.Version.Int64))
+	return c.JSON(newExampleResponse(example))
+}
+
+// deleteExampleHandler soft deletes an example.
+// @Summary      Delete Example
+// @Description  Soft deletes an example. Deleted examples are hidden from the list, can be restored by an administrator and are purged after the retention period.
+// @Tags         examples
+// @Produce      json
+// @Security     BasicAuth
+// @Param        id path int true "Example ID"
+// @Success      204
+// @Failure      400 {object} apperror.Problem
+// @Failure      401 {object} apperror.Problem
+// @Failure      404 {object} apperror.Problem
+// @Failure      500 {object} apperror.Problem
+// @Router       /v2/examples/{id} [delete]
+func deleteExampleHandler(c *fiber.Ctx) error {
+	var params ExampleIDRequest
+	if err := validation.BindAndValidate(c, &params); err != nil {
+		return err
+	}
+
+	// Set deleted_at; already deleted examples are not found.
+	if err := service.DeleteExample(c.UserContext(), params.ID); err != nil {
+		return apperror.From(err)
+	}
 
-	// Return a 201 status code and the ID of the newly created example.
-	return c.Status(201).JSON(CreateExampleResponse{
-		Message: "Example created successfully",
-		ID:      id,
-	})
+	return c.SendStatus(fiber.StatusNoContent)
 }
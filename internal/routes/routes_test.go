@@ -6,12 +6,19 @@ package routes
 import (
 	"encoding/json"
 	"log"
+	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
 
+	"gobo/internal/apperror"
 	"gobo/internal/db"
+	"gobo/internal/events"
+	"gobo/internal/middleware"
 	"gobo/internal/models"
+	"gobo/internal/tenant"
+	"gobo/internal/testhelpers"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/joho/godotenv"
@@ -43,16 +50,22 @@ func setupGormTestDB(t *testing.T) {
 		t.Fatalf("[Error] Error during migrations: %v", err)
 	}
 
+	// Run database migrations for the outbox, written when examples are created.
+	if err := db.GormDB.AutoMigrate(&events.Message{}); err != nil {
+		t.Fatalf("[Error] Error during migrations: %v", err)
+	}
+
 	log.Println("[Setup] Test database setup completed successfully.")
 }
 
 // teardownTestDB cleans up the test database after each test.
-// It drops the `examples` table to ensure a clean state for subsequent tests.
+// It drops the `examples` and `outbox` tables to ensure a clean state for subsequent tests.
 func teardownTestDB() {
 	log.Println("[Teardown] Dropping test tables...")
 
-	// Drop the `examples` table.
+	// Drop the `examples` and `outbox` tables.
 	db.GormDB.Exec("DROP TABLE IF EXISTS examples")
+	db.GormDB.Exec("DROP TABLE IF EXISTS outbox")
 
 	log.Println("[Teardown] Test database cleaned up.")
 }
@@ -143,6 +156,44 @@ func TestCreateExample(t *testing.T) {
 	log.Println("[Test] Example saved successfully to the database.")
 }
 
+// TestCreateExampleV2 validates the POST /v2/examples endpoint.
+// It ensures that the created example is returned in the v2 representation.
+func TestCreateExampleV2(t *testing.T) {
+	// Set up the test database.
+	setupGormTestDB(t)
+	defer teardownTestDB()
+
+	// Create a new Fiber app instance and register routes.
+	app := fiber.New()
+	Register(app)
+
+	// Perform the POST request to the /v2/examples endpoint with Basic Authentication.
+	req := httptest.NewRequest("POST", "/v2/examples", strings.NewReader(`{"name": "Versioned Example"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.SetBasicAuth("admin", "password")
+	resp, err := app.Test(req)
+
+	// Assert the response status code is 201 Created and the version header.
+	assert.NoError(t, err)
+	assert.Equal(t, 201, resp.StatusCode)
+	assert.Equal(t, "v2", resp.Header.Get("API-Version"))
+
+	// Assert that the created example is returned.
+	var response ExampleResponse
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	assert.NoError(t, err)
+	assert.Equal(t, "Versioned Example", response.Name)
+	assert.NotZero(t, response.ID)
+
+	// Assert that the example.created event is stored in the outbox.
+	var message events.Message
+	assert.NoError(t, db.GormDB.Where("type = ?", events.ExampleCreated).First(&message).Error)
+	assert.Equal(t, strconv.Itoa(int(response.ID)), message.AggregateID)
+	assert.Contains(t, string(message.Payload), "Versioned Example")
+
+	log.Println("[Test] POST /v2/examples response validated successfully.")
+}
+
 // TestCreateExampleUnauthorized validates the POST /examples endpoint without authentication.
 // It ensures that the API returns a 401 Unauthorized status code for missing credentials.
 func TestCreateExampleUnauthorized(t *testing.T) {
@@ -150,8 +201,8 @@ func TestCreateExampleUnauthorized(t *testing.T) {
 	setupGormTestDB(t)
 	defer teardownTestDB()
 
-	// Create a new Fiber app instance and register routes.
-	app := fiber.New()
+	// Create a new Fiber app instance with the central error handler and register routes.
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
 	Register(app)
 
 	// Define a request body for creating a new example.
@@ -171,8 +222,241 @@ func TestCreateExampleUnauthorized(t *testing.T) {
 	err = json.NewDecoder(resp.Body).Decode(&response)
 	assert.NoError(t, err)
 
-	// Assert the error message.
-	assert.Equal(t, "Unauthorized", response["error"])
+	// Assert the problem details.
+	assert.Equal(t, "application/problem+json", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "unauthorized", response["code"])
+	assert.Equal(t, float64(401), response["status"])
 
 	log.Println("[Test] POST /examples unauthorized access validated successfully.")
 }
+
+// TestCreateExampleValidation validates that invalid names are rejected before reaching the database.
+// It ensures that the API returns 422 Unprocessable Entity with the invalid field.
+func TestCreateExampleValidation(t *testing.T) {
+	// Create a new Fiber app instance with the central error handler and register routes.
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	Register(app)
+
+	for _, body := range []string{`{"name": ""}`, `{"name": "` + strings.Repeat("x", 101) + `"}`} {
+		// Perform the POST request to the /examples endpoint with Basic Authentication.
+		req := httptest.NewRequest("POST", "/examples", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.SetBasicAuth("admin", "password")
+		resp, err := app.Test(req)
+
+		// Assert the response status code is 422 Unprocessable Entity.
+		assert.NoError(t, err)
+		assert.Equal(t, 422, resp.StatusCode)
+
+		// Assert that the name field is reported.
+		var problem apperror.Problem
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&problem))
+		if assert.Len(t, problem.Errors, 1) {
+			assert.Equal(t, "name", problem.Errors[0].Field)
+		}
+	}
+
+	log.Println("[Test] POST /examples validation validated successfully.")
+}
+
+// TestGetDeletedExamplesUnauthorized validates that only administrators can list soft-deleted examples.
+func TestGetDeletedExamplesUnauthorized(t *testing.T) {
+	// Create a new Fiber app instance with the central error handler and register routes.
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	Register(app)
+
+	// Perform the GET request with include_deleted but without Basic Authentication.
+	req := httptest.NewRequest("GET", "/v2/examples?include_deleted=true", nil)
+	resp, err := app.Test(req)
+
+	// Assert the response status code is 401 Unauthorized.
+	assert.NoError(t, err)
+	assert.Equal(t, 401, resp.StatusCode)
+
+	log.Println("[Test] GET /v2/examples?include_deleted=true unauthorized access validated successfully.")
+}
+
+// TestDeleteAndRestoreExample validates the soft delete and restore endpoints.
+// It ensures that deleted examples are hidden from the list unless requested by an administrator.
+func TestDeleteAndRestoreExample(t *testing.T) {
+	// Set up the test database.
+	setupGormTestDB(t)
+	defer teardownTestDB()
+
+	// Add a test example to the database.
+	testExample := models.Example{Name: "Deleted Example"}
+	if result := db.GormDB.Create(&testExample); result.Error != nil {
+		t.Fatalf("[Error] Failed to add test example: %v", result.Error)
+	}
+	path := "/v2/examples/" + strconv.Itoa(int(testExample.ID))
+
+	// Create a new Fiber app instance with the central error handler and register routes.
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	Register(app)
+
+	// list performs an authenticated GET request and returns the listed examples.
+	list := func(query string) []ExampleResponse {
+		req := httptest.NewRequest("GET", "/v2/examples"+query, nil)
+		req.SetBasicAuth("admin", "password")
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		var response ExampleListResponse
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		return response.Data
+	}
+
+	// Soft delete the example.
+	req := httptest.NewRequest("DELETE", path, nil)
+	req.SetBasicAuth("admin", "password")
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, 204, resp.StatusCode)
+
+	// Deleting it again returns 404 Not Found.
+	req = httptest.NewRequest("DELETE", path, nil)
+	req.SetBasicAuth("admin", "password")
+	resp, err = app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, 404, resp.StatusCode)
+
+	// The example is only listed with include_deleted.
+	assert.Empty(t, list(""))
+	deleted := list("?include_deleted=true")
+	if assert.Len(t, deleted, 1) {
+		assert.NotNil(t, deleted[0].DeletedAt)
+	}
+
+	// Restore the example.
+	req = httptest.NewRequest("POST", "/admin/examples/"+strconv.Itoa(int(testExample.ID))+"/restore", nil)
+	req.SetBasicAuth("admin", "password")
+	resp, err = app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	var restored ExampleResponse
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&restored))
+	assert.Nil(t, restored.DeletedAt)
+	assert.Len(t, list(""), 1)
+
+	// Restoring an example that is not deleted returns 409 Conflict.
+	req = httptest.NewRequest("POST", "/admin/examples/"+strconv.Itoa(int(testExample.ID))+"/restore", nil)
+	req.SetBasicAuth("admin", "password")
+	resp, err = app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, 409, resp.StatusCode)
+
+	log.Println("[Test] DELETE /v2/examples/:id and restore validated successfully.")
+}
+
+// TestUpdateExampleVersionConflict validates the PUT /v2/examples/:id endpoint with optimistic locking.
+// It ensures that an update based on a stale version is rejected with the current representation.
+func TestUpdateExampleVersionConflict(t *testing.T) {
+	// Set up the test database.
+	setupGormTestDB(t)
+	defer teardownTestDB()
+
+	// Add a test example to the database (version 1).
+	testExample := models.Example{Name: "Versioned Example"}
+	if result := db.GormDB.Create(&testExample); result.Error != nil {
+		t.Fatalf("[Error] Failed to add test example: %v", result.Error)
+	}
+	path := "/v2/examples/" + strconv.Itoa(int(testExample.ID))
+
+	// Create a new Fiber app instance with the central error handler and register routes.
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	Register(app)
+
+	// update performs an authenticated PUT request based on the given ETag.
+	update := func(name, ifMatch string) *http.Response {
+		req := httptest.NewRequest("PUT", path, strings.NewReader(`{"name": "`+name+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", ifMatch)
+		req.SetBasicAuth("admin", "password")
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		return resp
+	}
+
+	// The first update succeeds and returns the new version.
+	resp := update("First", `"1"`)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, `"2"`, resp.Header.Get("ETag"))
+
+	// The second update is based on the stale version.
+	resp = update("Second", `"1"`)
+	assert.Equal(t, 409, resp.StatusCode)
+	assert.Equal(t, `"2"`, resp.Header.Get("ETag"))
+
+	// The problem details contain the current representation.
+	var problem struct {
+		Code    string          `json:"code"`
+		Current ExampleResponse `json:"current"`
+	}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&problem))
+	assert.Equal(t, "version_conflict", problem.Code)
+	assert.Equal(t, "First", problem.Current.Name)
+	assert.Equal(t, int64(2), problem.Current.Version)
+
+	log.Println("[Test] PUT /v2/examples/:id version conflict validated successfully.")
+}
+
+// TestExamplesTenantIsolation validates that examples are only visible to the tenant that created them.
+func TestExamplesTenantIsolation(t *testing.T) {
+	// Set up the test database and scope the examples to the tenant of the request.
+	setupGormTestDB(t)
+	defer teardownTestDB()
+	previous := db.GormDB
+	db.GormDB = testhelpers.OpenGormTestDB(t)
+	defer func() { db.GormDB = previous }()
+	assert.NoError(t, db.GormDB.Use(tenant.New(&models.Example{})))
+
+	// Create a new Fiber app instance resolving the tenant from the X-Tenant-ID header.
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Use(middleware.TenantMiddleware(middleware.TenantConfig{Header: "X-Tenant-ID"}))
+	Register(app)
+
+	// Create an example in the acme tenant.
+	req := httptest.NewRequest("POST", "/v2/examples", strings.NewReader(`{"name": "Acme Example"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Tenant-ID", "acme")
+	req.SetBasicAuth("admin", "password")
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, 201, resp.StatusCode)
+	var created ExampleResponse
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+
+	// get performs a GET request in the given tenant, as a user bound to it.
+	get := func(path, id string) *http.Response {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("X-Tenant-ID", id)
+		req.SetBasicAuth("admin", "password")
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		return resp
+	}
+
+	// Only the acme tenant lists and retrieves the example.
+	var examples ExampleListResponse
+	assert.NoError(t, json.NewDecoder(get("/v2/examples", "acme").Body).Decode(&examples))
+	assert.Len(t, examples.Data, 1)
+	assert.NoError(t, json.NewDecoder(get("/v2/examples", "globex").Body).Decode(&examples))
+	assert.Empty(t, examples.Data)
+	path := "/v2/examples/" + strconv.Itoa(int(created.ID))
+	assert.Equal(t, 200, get(path, "acme").StatusCode)
+	assert.Equal(t, 404, get(path, "globex").StatusCode)
+
+	// Anonymous requests cannot select a tenant, even on public routes.
+	req = httptest.NewRequest("GET", "/v2/examples", nil)
+	req.Header.Set("X-Tenant-ID", "acme")
+	resp, err = app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, 401, resp.StatusCode)
+
+	// Requests without a tenant are rejected, as no default tenant is configured.
+	resp, err = app.Test(httptest.NewRequest("GET", "/v2/examples", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, 400, resp.StatusCode)
+
+	log.Println("[Test] Tenant isolation of examples validated successfully.")
+}
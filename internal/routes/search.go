@@ -0,0 +1,82 @@
+// Package routes defines and registers all HTTP routes for the application.
+// This file contains the search endpoint of the examples.
+package routes
+
+import (
+	"gobo/internal/apperror"
+	"gobo/internal/search"
+	"gobo/internal/service"
+	"gobo/internal/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Request struct for searching examples
+type SearchExamplesRequest struct {
+	Q        string `query:"q" validate:"required,notblank,max=100"`       // The searched words; the last one also matches as a prefix.
+	Page     int    `query:"page" validate:"omitempty,min=1"`              // Page number (defaults to 1).
+	PageSize int    `query:"page_size" validate:"omitempty,min=1,max=100"` // Examples per page (defaults to 20).
+}
+
+// SearchHitResponse is an example matching a search.
+type SearchHitResponse struct {
+	ExampleResponse
+	Rank    float64 `json:"rank"`    // Relevance of the example; higher ranks match better.
+	Snippet string  `json:"snippet"` // The name, HTML-escaped, with the matching words in <mark> elements.
+}
+
+// Response struct for a page of search results
+type SearchExamplesResponse struct {
+	Data     []SearchHitResponse `json:"data"`      // The matching examples, best matches first.
+	Page     int                 `json:"page"`      // The page number.
+	PageSize int                 `json:"page_size"` // Examples per page.
+	Total    int64               `json:"total"`     // Number of matching examples.
+}
+
+// searchExamplesHandler searches the examples by name.
+// @Summary      Search Examples
+// @Description  Searches the examples by name, best matches first. All the words must match, the last one as a prefix ("red ca" matches "Red Car"), and names similar to the text also match ("alpah" matches "Alpha"). Each result has a rank and a snippet of its name, HTML-escaped, with the matching words in <mark> elements.
+// @Tags         examples
+// @Produce      json
+// @Param        q query string true "Searched words"
+// @Param        page query int false "Page number" default(1)
+// @Param        page_size query int false "Examples per page (at most 100)" default(20)
+// @Success      200 {object} SearchExamplesResponse
+// @Failure      422 {object} apperror.Problem
+// @Failure      503 {object} apperror.Problem "Search is not available"
+// @Router       /v2/examples/search [get]
+func searchExamplesHandler(c *fiber.Ctx) error {
+	var params SearchExamplesRequest
+	if err := validation.BindAndValidate(c, &params); err != nil {
+		return err
+	}
+	if search.Default == nil {
+		return apperror.New(fiber.StatusServiceUnavailable, "search_unavailable", "").WithKey("error.search_unavailable")
+	}
+	page := service.DefaultPage
+	if params.Page > 0 {
+		page.Number = params.Page
+	}
+	if params.PageSize > 0 {
+		page.Size = params.PageSize
+	}
+
+	result, err := search.Default.Search(c.UserContext(), search.Query{Text: params.Q, Page: page})
+	if err != nil {
+		return apperror.From(err)
+	}
+	response := SearchExamplesResponse{
+		Data:     make([]SearchHitResponse, len(result.Hits)),
+		Page:     page.Number,
+		PageSize: page.Size,
+		Total:    result.Total,
+	}
+	for i, hit := range result.Hits {
+		response.Data[i] = SearchHitResponse{
+			ExampleResponse: newExampleResponse(hit.Example),
+			Rank:            hit.Rank,
+			Snippet:         hit.Snippet,
+		}
+	}
+	return c.JSON(response)
+}
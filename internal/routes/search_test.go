@@ -0,0 +1,65 @@
+// Package routes contains tests for the search endpoint of the examples, with a stub backend.
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gobo/internal/apperror"
+	"gobo/internal/models"
+	"gobo/internal/search"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubSearcher returns a hit per query, recording the queries.
+type stubSearcher struct {
+	queries []search.Query
+}
+
+func (s *stubSearcher) Search(ctx context.Context, query search.Query) (search.Result, error) {
+	s.queries = append(s.queries, query)
+	example := models.Example{Name: "Alpha"}
+	example.ID = 7
+	return search.Result{Hits: []search.Hit{{Example: example, Rank: 0.5, Snippet: "<mark>Alpha</mark>"}}, Total: 11}, nil
+}
+
+// TestSearchExamples validates that searches are validated and answered with the results of the backend.
+func TestSearchExamples(t *testing.T) {
+	previous := search.Default
+	defer func() { search.Default = previous }()
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	Register(app)
+	get := func(url string) *http.Response {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, url, nil), -1)
+		assert.NoError(t, err)
+		return resp
+	}
+
+	search.Default = nil
+	assert.Equal(t, http.StatusServiceUnavailable, get("/v2/examples/search?q=alp").StatusCode)
+
+	stub := &stubSearcher{}
+	search.Default = stub
+	assert.Equal(t, http.StatusUnprocessableEntity, get("/v2/examples/search").StatusCode)
+	assert.Equal(t, http.StatusUnprocessableEntity, get("/v2/examples/search?q=alp&page_size=500").StatusCode)
+
+	resp := get("/v2/examples/search?q=alp&page=2&page_size=5")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	var response SearchExamplesResponse
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	assert.Equal(t, int64(11), response.Total)
+	assert.Equal(t, 2, response.Page)
+	if assert.Len(t, response.Data, 1) {
+		assert.Equal(t, uint(7), response.Data[0].ID)
+		assert.Equal(t, "<mark>Alpha</mark>", response.Data[0].Snippet)
+	}
+	if assert.Len(t, stub.queries, 1) {
+		assert.Equal(t, "alp", stub.queries[0].Text)
+		assert.Equal(t, 5, stub.queries[0].Page.Size)
+	}
+}
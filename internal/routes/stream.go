@@ -0,0 +1,48 @@
+// Package routes defines and registers all HTTP routes for the application.
+// This file contains the Server-Sent Events endpoint streaming the changes of examples.
+package routes
+
+import (
+	"gobo/internal/apperror"
+	"gobo/internal/sse"
+	"gobo/internal/tenant"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// exampleTopic is the topic of the example events (their aggregate type).
+const exampleTopic = "example"
+
+// streamExamplesHandler streams the changes of the examples of the tenant with Server-Sent Events.
+// @Summary      Stream Example Changes
+// @Description  Streams the created, updated, deleted and restored examples of the tenant as Server-Sent Events (e.g., with EventSource). Each event has an ID, a type (e.g., "example.created") and the example as data. Reconnecting clients send the Last-Event-ID header to receive the events they missed; if they are no longer in the history, a "reset" event is sent and the examples should be reloaded. A comment is sent as a heartbeat every 15 seconds.
+// @Tags         examples
+// @Produce      text/event-stream
+// @Security     BasicAuth
+// @Param        Last-Event-ID header string false "ID of the last event received, to resume the stream"
+// @Success      200 {string} string "Event stream"
+// @Failure      401 {object} apperror.Problem
+// @Failure      503 {object} apperror.Problem
+// @Router       /v2/examples/stream [get]
+func streamExamplesHandler(c *fiber.Ctx) error {
+	if sse.Default == nil {
+		return apperror.New(fiber.StatusServiceUnavailable, "stream_unavailable", "").WithKey("error.stream_unavailable")
+	}
+
+	// Requests without a tenant belong to the default tenant, as the examples they change.
+	owner := tenant.FromContext(c.UserContext())
+	if owner == "" {
+		owner = tenant.DefaultID
+	}
+	write, err := sse.Default.Stream(exampleTopic, owner, c.Get("Last-Event-ID"))
+	if err != nil {
+		return apperror.New(fiber.StatusServiceUnavailable, "stream_unavailable", "").WithKey("error.stream_unavailable")
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no") // Disable response buffering in nginx
+	c.Context().SetBodyStreamWriter(write)
+	return nil
+}
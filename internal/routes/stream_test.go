@@ -0,0 +1,86 @@
+// Package routes contains tests for the application's API endpoints.
+// These tests validate the event stream of the examples, which uses an in-memory Redis server.
+package routes
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gobo/internal/apperror"
+	"gobo/internal/middleware"
+	"gobo/internal/sse"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestStreamExamples validates streaming the changes of the examples of a tenant.
+func TestStreamExamples(t *testing.T) {
+	// Replace the application's broker with one using an in-memory Redis server.
+	client := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	defer client.Close()
+	previous := sse.Default
+	sse.Default = sse.New(sse.Config{Client: client, Heartbeat: 100 * time.Millisecond})
+	defer func() { sse.Default = previous }()
+	stop := sse.Default.Start()
+	defer stop()
+
+	// Serve a Fiber app resolving the tenant from the X-Tenant-ID header.
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Use(middleware.TenantMiddleware(middleware.TenantConfig{Header: "X-Tenant-ID", Default: "default"}))
+	Register(app)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	go func() { _ = app.Listener(listener) }()
+	defer func() { _ = app.Shutdown() }()
+
+	// The stream requires authentication.
+	resp, err := app.Test(httptest.NewRequest("GET", "/v2/examples/stream", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, 401, resp.StatusCode)
+
+	req, err := http.NewRequest("GET", "http://"+listener.Addr().String()+"/v2/examples/stream", nil)
+	assert.NoError(t, err)
+	req.SetBasicAuth("admin", "password")
+	req.Header.Set("X-Tenant-ID", "acme")
+	stream, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer stream.Body.Close()
+	assert.Equal(t, 200, stream.StatusCode)
+	assert.Equal(t, "text/event-stream", stream.Header.Get("Content-Type"))
+	lines := bufio.NewScanner(stream.Body)
+	assert.True(t, lines.Scan())
+	assert.Equal(t, "retry: 3000", lines.Text())
+
+	// Only the events of the tenant are streamed.
+	ctx := context.Background()
+	assert.Eventually(t, func() bool { return client.PubSubNumPat(ctx).Val() == 1 }, 5*time.Second, 10*time.Millisecond)
+	_, err = sse.Default.Publish(ctx, "example", sse.Event{Type: "example.created", Tenant: "globex", Data: json.RawMessage(`{"id":1}`)})
+	assert.NoError(t, err)
+	id, err := sse.Default.Publish(ctx, "example", sse.Event{Type: "example.created", Tenant: "acme", Data: json.RawMessage(`{"id":2}`)})
+	assert.NoError(t, err)
+
+	var received []string
+	for len(received) < 3 && lines.Scan() {
+		if lines.Text() != "" {
+			received = append(received, lines.Text())
+		}
+	}
+	assert.Equal(t, []string{"id: " + id, "event: example.created", `data: {"id":2}`}, received)
+
+	// Without Redis, the stream is unavailable.
+	sse.Default = nil
+	req = httptest.NewRequest("GET", "/v2/examples/stream", nil)
+	req.SetBasicAuth("admin", "password")
+	resp, err = app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, 503, resp.StatusCode)
+}
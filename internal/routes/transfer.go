@@ -0,0 +1,262 @@
+// Package routes defines and registers all HTTP routes for the application.
+// This file contains the import and export endpoints of the examples.
+package routes
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gobo/internal/apperror"
+	"gobo/internal/i18n"
+	"gobo/internal/logger"
+	"gobo/internal/service"
+	"gobo/internal/transfer"
+	"gobo/internal/validation"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ImportConfig limits the imported files.
+type ImportConfig struct {
+	MaxBytes     int             // Maximum size of an imported file (defaults to 4 MiB, the body limit of the application)
+	SyncMaxBytes int             // Files up to this size are imported in the request, larger ones by a job (defaults to 256 KiB)
+	Transfer     transfer.Config // Batch size and maximum number of reported errors
+}
+
+// DefaultImportConfig returns the default import configuration.
+//
+// Defaults:
+// - MaxBytes: 4194304 (4 MiB), or the IMPORT_MAX_BYTES environment variable
+// - SyncMaxBytes: 262144 (256 KiB), or the IMPORT_SYNC_MAX_BYTES environment variable
+// - Transfer: transfer.DefaultConfig()
+//
+// Returns:
+// - ImportConfig: The default import configuration.
+func DefaultImportConfig() ImportConfig {
+	config := ImportConfig{
+		MaxBytes:     4 << 20,
+		SyncMaxBytes: 256 << 10,
+		Transfer:     transfer.DefaultConfig(),
+	}
+	if value, err := strconv.Atoi(os.Getenv("IMPORT_MAX_BYTES")); err == nil && value > 0 {
+		config.MaxBytes = value
+	}
+	if value, err := strconv.Atoi(os.Getenv("IMPORT_SYNC_MAX_BYTES")); err == nil && value >= 0 {
+		config.SyncMaxBytes = value
+	}
+	return config
+}
+
+// Request struct for exporting examples
+type ExportExamplesRequest struct {
+	Format         string `query:"format" validate:"omitempty,oneof=csv ndjson xlsx"` // csv (default), ndjson or xlsx.
+	Name           string `query:"name"`                                              // Case-insensitive substring of the name.
+	IncludeDeleted bool   `query:"include_deleted"`                                   // Include soft-deleted examples.
+}
+
+// Request struct for importing examples
+type ImportExamplesRequest struct {
+	Format string `query:"format" validate:"omitempty,oneof=csv ndjson"` // csv or ndjson; defaults to the extension of the file.
+}
+
+// Request struct for endpoints addressing an import
+type ImportIDRequest struct {
+	ID uint `params:"id" validate:"required"` // The ID of the import.
+}
+
+// ImportResponse is the status and the report of an import.
+type ImportResponse struct {
+	ID         uint                `json:"id,omitempty"`          // The ID of the import; omitted for files imported in the request.
+	Status     string              `json:"status"`                // pending, running, completed or failed.
+	Format     string              `json:"format"`                // csv or ndjson.
+	Rows       int                 `json:"rows"`                  // The rows of the file.
+	Created    int                 `json:"created"`               // The created examples.
+	Failed     int                 `json:"failed"`                // The rejected rows.
+	Errors     []transfer.RowError `json:"errors"`                // The errors of the rejected rows, by line.
+	Error      string              `json:"error,omitempty"`       // Why the import failed.
+	CreatedAt  *time.Time          `json:"created_at,omitempty"`  // Upload time.
+	FinishedAt *time.Time          `json:"finished_at,omitempty"` // Time the import completed or failed.
+}
+
+// newImportResponse converts an import run by a job to its API representation.
+func newImportResponse(imp transfer.Import) ImportResponse {
+	response := newReportResponse(imp.Format, imp.Report)
+	response.ID, response.Status, response.Error = imp.ID, imp.Status, imp.Error
+	response.CreatedAt, response.FinishedAt = &imp.CreatedAt, imp.FinishedAt
+	return response
+}
+
+// newReportResponse converts the report of a completed import to its API representation.
+func newReportResponse(format string, report transfer.Report) ImportResponse {
+	errs := report.Errors
+	if errs == nil {
+		errs = []transfer.RowError{}
+	}
+	return ImportResponse{
+		Status:  transfer.StatusCompleted,
+		Format:  format,
+		Rows:    report.Rows,
+		Created: report.Created,
+		Failed:  report.Failed,
+		Errors:  errs,
+	}
+}
+
+// exportExamplesHandler returns the handler streaming the examples as a file.
+// @Summary      Export Examples
+// @Description  Streams the examples as a CSV file with a header row (id, name, version, created_at, updated_at, deleted_at), NDJSON (one example per line) or an XLSX workbook. The examples are read from the database one page at a time, so that exports of any size use constant memory.
+// @Tags         examples
+// @Produce      text/csv
+// @Produce      application/x-ndjson
+// @Produce      application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Security     BasicAuth
+// @Param        format query string false "File format" Enums(csv, ndjson, xlsx) default(csv)
+// @Param        name query string false "Case-insensitive substring of the name"
+// @Param        include_deleted query bool false "Include soft-deleted examples"
+// @Success      200 {file} file
+// @Failure      401 {object} apperror.Problem
+// @Failure      422 {object} apperror.Problem
+// @Router       /v2/examples/export [get]
+func exportExamplesHandler(config transfer.Config) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var params ExportExamplesRequest
+		if err := validation.BindAndValidate(c, &params); err != nil {
+			return err
+		}
+		if params.Format == "" {
+			params.Format = transfer.FormatCSV
+		}
+
+		filter := service.ExampleFilter{Name: params.Name, IncludeDeleted: params.IncludeDeleted}
+		ctx, path := c.UserContext(), c.Path()
+		c.Attachment("examples." + params.Format)
+		c.Set(fiber.HeaderContentType, transfer.ContentType(params.Format))
+		c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+			// The status is sent with the first bytes: errors can only be logged, and truncate the file.
+			if err := transfer.Export(ctx, w, params.Format, filter, config); err != nil {
+				logger.FromContext(ctx).Error("Export failed", zap.Error(err), zap.String("path", path))
+			}
+			_ = w.Flush()
+		})
+		return nil
+	}
+}
+
+// importExamplesHandler returns the handler importing a file of examples.
+// @Summary      Import Examples
+// @Description  Creates an example for each row of a CSV file (with a header row naming a "name" column) or an NDJSON file (one object with a "name" member per line), sent in the "file" field. Other columns, such as the id and version of exported files, are ignored. Each row is validated: valid rows are created even if others are rejected, and the errors of the rejected rows are reported with their line. Small files are imported in the request (200). Larger files are imported by a background job (202): poll the import at the Location header.
+// @Tags         examples
+// @Accept       multipart/form-data
+// @Produce      json
+// @Security     BasicAuth
+// @Param        file formData file true "CSV or NDJSON file"
+// @Param        format query string false "File format; defaults to the extension of the file (.csv, .ndjson or .jsonl)" Enums(csv, ndjson)
+// @Success      200 {object} ImportResponse "Imported in the request"
+// @Success      202 {object} ImportResponse "Import started"
+// @Failure      400 {object} apperror.Problem
+// @Failure      401 {object} apperror.Problem
+// @Failure      413 {object} apperror.Problem
+// @Failure      422 {object} apperror.Problem "Unsupported format or missing name column"
+// @Failure      429 {object} apperror.Problem
+// @Failure      503 {object} apperror.Problem "The job queue is unavailable"
+// @Router       /v2/examples/import [post]
+func importExamplesHandler(config ImportConfig) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var params ImportExamplesRequest
+		if err := validation.BindAndValidate(c, &params); err != nil {
+			return err
+		}
+		header, err := c.FormFile("file")
+		if err != nil {
+			return apperror.BadRequest("").WithKey("error.import_file_required").WithCode("file_required")
+		}
+		if header.Size > int64(config.MaxBytes) {
+			return apperror.New(fiber.StatusRequestEntityTooLarge, "payload_too_large", "").
+				WithKey("error.payload_too_large", i18n.Args{"max": config.MaxBytes})
+		}
+		format := params.Format
+		if format == "" {
+			format = importFormat(header.Filename)
+		}
+		file, err := header.Open()
+		if err != nil {
+			return apperror.From(err)
+		}
+		defer file.Close()
+
+		if header.Size <= int64(config.SyncMaxBytes) {
+			report, err := transfer.ImportExamples(c.UserContext(), file, format, config.Transfer)
+			if err != nil {
+				return apperror.From(err)
+			}
+			return c.JSON(newReportResponse(format, report))
+		}
+
+		queue, err := jobQueue()
+		if err != nil {
+			return jobError(err)
+		}
+		data, err := io.ReadAll(file)
+		if err != nil {
+			return apperror.From(err)
+		}
+		imp, err := transfer.StartImport(c.UserContext(), queue, format, data)
+		if err != nil {
+			return jobError(err)
+		}
+		c.Location(importLocation(c, imp.ID))
+		return c.Status(fiber.StatusAccepted).JSON(newImportResponse(imp))
+	}
+}
+
+// importFormat returns the format of a file from its extension; unknown extensions are rejected by the import.
+func importFormat(filename string) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return transfer.FormatCSV
+	case ".ndjson", ".jsonl":
+		return transfer.FormatNDJSON
+	default:
+		return ""
+	}
+}
+
+// importLocation returns the path of an import, in the version of the request.
+func importLocation(c *fiber.Ctx, id uint) string {
+	return strings.TrimSuffix(c.Path(), "/import") + "/imports/" + strconv.FormatUint(uint64(id), 10)
+}
+
+// getImportHandler returns the status and the report of an import.
+// @Summary      Get Import
+// @Description  Returns the status of an import run by a background job and, once it is completed, the number of created examples and the errors of the rejected rows.
+// @Tags         examples
+// @Produce      json
+// @Security     BasicAuth
+// @Param        id path int true "Import ID"
+// @Success      200 {object} ImportResponse
+// @Failure      401 {object} apperror.Problem
+// @Failure      404 {object} apperror.Problem
+// @Failure      422 {object} apperror.Problem
+// @Router       /v2/examples/imports/{id} [get]
+func getImportHandler(c *fiber.Ctx) error {
+	var params ImportIDRequest
+	if err := validation.BindAndValidate(c, &params); err != nil {
+		return err
+	}
+	imp, err := transfer.FindImport(c.UserContext(), params.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperror.NotFound("").WithKey("error.import_not_found").WithCode("import_not_found")
+	}
+	if err != nil {
+		return apperror.From(err)
+	}
+	return c.JSON(newImportResponse(imp))
+}
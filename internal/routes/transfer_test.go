@@ -0,0 +1,100 @@
+// Package routes contains tests for the import and export endpoints of the examples.
+package routes
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gobo/internal/apperror"
+	"gobo/internal/db"
+	"gobo/internal/models"
+	"gobo/internal/transfer"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// transferApp returns an application serving the import and export handlers.
+func transferApp(config ImportConfig) *fiber.App {
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Get("/examples/export", exportExamplesHandler(transfer.Config{PageSize: 2}))
+	app.Post("/examples/import", importExamplesHandler(config))
+	return app
+}
+
+// postImport uploads a file to the import handler.
+func postImport(t *testing.T, app *fiber.App, filename, content string) *http.Response {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	if filename != "" {
+		part, err := form.CreateFormFile("file", filename)
+		assert.NoError(t, err)
+		_, _ = part.Write([]byte(content))
+	}
+	assert.NoError(t, form.Close())
+	req := httptest.NewRequest(http.MethodPost, "/examples/import", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	resp, err := app.Test(req, -1)
+	assert.NoError(t, err)
+	return resp
+}
+
+// TestImportExamplesValidation validates that invalid uploads are rejected before reaching the database.
+func TestImportExamplesValidation(t *testing.T) {
+	app := transferApp(ImportConfig{MaxBytes: 20, SyncMaxBytes: 20})
+
+	resp := postImport(t, app, "", "")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	resp = postImport(t, app, "examples.csv", "name\n"+strings.Repeat("a", 20))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+	resp = postImport(t, app, "examples.txt", "name\nAlpha\n")
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+	resp = postImport(t, app, "examples.csv", "title\nAlpha\n")
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+	var problem apperror.Problem
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&problem))
+	assert.Equal(t, "missing_column", problem.Code)
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/examples/export?format=pdf", nil), -1)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+}
+
+// TestImportAndExportExamples validates that small files are imported in the request, and that the examples are
+// exported as a file.
+func TestImportAndExportExamples(t *testing.T) {
+	setupGormTestDB(t)
+	defer teardownTestDB()
+	app := transferApp(ImportConfig{MaxBytes: 1 << 20, SyncMaxBytes: 1 << 20})
+
+	resp := postImport(t, app, "examples.ndjson", "{\"name\": \"Alpha\"}\n{\"name\": \"Beta\"}\n{\"name\": \" \"}\n{\"name\": \"Gamma\"}\n")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	var response ImportResponse
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	assert.Equal(t, transfer.StatusCompleted, response.Status)
+	assert.Equal(t, 3, response.Created)
+	assert.Equal(t, 1, response.Failed)
+	if assert.Len(t, response.Errors, 1) {
+		assert.Equal(t, 3, response.Errors[0].Row)
+		assert.Equal(t, "name", response.Errors[0].Field)
+	}
+	var count int64
+	db.GormDB.Model(&models.Example{}).Count(&count)
+	assert.Equal(t, int64(3), count)
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/examples/export", nil), -1)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/csv; charset=utf-8", resp.Header.Get("Content-Type"))
+	assert.Contains(t, resp.Header.Get("Content-Disposition"), "examples.csv")
+	body, _ := io.ReadAll(resp.Body)
+	lines := strings.Split(strings.TrimSpace(string(body)), "\n")
+	if assert.Len(t, lines, 4) {
+		assert.True(t, strings.HasPrefix(lines[0], "id,name,version"))
+		assert.Contains(t, lines[3], "Gamma")
+	}
+}
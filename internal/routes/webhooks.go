@@ -0,0 +1,470 @@
+// Package routes defines and registers all HTTP routes for the application.
+// This file contains the webhook subscription endpoints.
+package routes
+
+import (
+	"encoding/json"
+	"time"
+
+	"gobo/internal/apperror"
+	"gobo/internal/db"
+	"gobo/internal/validation"
+	"gobo/internal/webhooks"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Request struct for creating a webhook subscription
+type CreateWebhookRequest struct {
+	URL         string   `json:"url" validate:"required,http_url,max=2048"`                                                                      // The endpoint receiving the events (POST), over HTTP or HTTPS.
+	Events      []string `json:"events" validate:"required,min=1,dive,oneof=* example.created example.updated example.deleted example.restored"` // The event types sent to the endpoint, or "*" for all.
+	Description string   `json:"description" validate:"max=255"`                                                                                 // Optional free text.
+}
+
+// Request struct for updating a webhook subscription
+type UpdateWebhookRequest struct {
+	URL         string   `json:"url" validate:"required,http_url,max=2048"`                                                                      // The endpoint receiving the events (POST), over HTTP or HTTPS.
+	Events      []string `json:"events" validate:"required,min=1,dive,oneof=* example.created example.updated example.deleted example.restored"` // The event types sent to the endpoint, or "*" for all.
+	Description string   `json:"description" validate:"max=255"`                                                                                 // Optional free text.
+	Active      *bool    `json:"active"`                                                                                                         // Disable the subscription, or enable it again after it was disabled (resets its failures).
+}
+
+// Request struct for endpoints addressing a webhook subscription
+type WebhookIDRequest struct {
+	ID uint `params:"id" validate:"required"` // The ID of the subscription.
+}
+
+// Request struct for endpoints addressing a delivery of a webhook subscription
+type WebhookDeliveryIDRequest struct {
+	ID         uint `params:"id" validate:"required"`          // The ID of the subscription.
+	DeliveryID uint `params:"delivery_id" validate:"required"` // The ID of the delivery.
+}
+
+// Request struct for listing the deliveries of a webhook subscription
+type WebhookDeliveriesRequest struct {
+	Page     int    `query:"page" validate:"omitempty,min=1"`                            // Page number (defaults to 1).
+	PageSize int    `query:"page_size" validate:"omitempty,min=1,max=100"`               // Deliveries per page (defaults to 50).
+	Status   string `query:"status" validate:"omitempty,oneof=pending succeeded failed"` // Only the deliveries in this state.
+}
+
+// Response struct for a webhook subscription
+type WebhookResponse struct {
+	ID          uint       `json:"id"`                    // The ID of the subscription.
+	URL         string     `json:"url"`                   // The endpoint receiving the events.
+	Events      []string   `json:"events"`                // The subscribed event types.
+	Description string     `json:"description"`           // Free text.
+	Active      bool       `json:"active"`                // Whether events are sent.
+	Failures    int        `json:"failures"`              // Consecutive failed attempts.
+	DisabledAt  *time.Time `json:"disabled_at,omitempty"` // When the subscription was disabled after repeated failures.
+	Secret      string     `json:"secret,omitempty"`      // The signing key; only returned when the subscription is created.
+	CreatedAt   time.Time  `json:"created_at"`            // When the subscription was created.
+	UpdatedAt   time.Time  `json:"updated_at"`            // When the subscription was last updated.
+}
+
+// Response struct for the webhook subscriptions
+type WebhookListResponse struct {
+	Data []WebhookResponse `json:"data"` // The subscriptions.
+}
+
+// Response struct for an attempt of a webhook delivery
+type WebhookAttemptResponse struct {
+	ID         uint      `json:"id"`                 // The ID of the attempt.
+	CreatedAt  time.Time `json:"created_at"`         // Time of the request.
+	StatusCode int       `json:"status_code"`        // Response status; 0 if no response was received.
+	DurationMS int64     `json:"duration_ms"`        // Duration of the request, in milliseconds.
+	Error      string    `json:"error,omitempty"`    // Why the attempt failed.
+	Response   string    `json:"response,omitempty"` // Beginning of the response body.
+}
+
+// Response struct for a webhook delivery
+type WebhookDeliveryResponse struct {
+	ID            uint                     `json:"id"`                           // The ID of the delivery.
+	EventID       string                   `json:"event_id"`                     // The ID of the event, identical for replays.
+	EventType     string                   `json:"event_type"`                   // The event type.
+	Payload       json.RawMessage          `json:"payload" swaggertype:"object"` // The request body.
+	Status        string                   `json:"status"`                       // pending, succeeded or failed.
+	Attempts      int                      `json:"attempts"`                     // Attempts so far.
+	NextAttemptAt *time.Time               `json:"next_attempt_at,omitempty"`    // Earliest time of the next attempt of a pending delivery.
+	LastError     string                   `json:"last_error,omitempty"`         // Error of the last failed attempt.
+	DeliveredAt   *time.Time               `json:"delivered_at,omitempty"`       // When the endpoint accepted the event.
+	ReplayOf      *uint                    `json:"replay_of,omitempty"`          // The delivery this one replays.
+	CreatedAt     time.Time                `json:"created_at"`                   // When the delivery was created.
+	AttemptLog    []WebhookAttemptResponse `json:"attempt_log,omitempty"`        // The requests sent, oldest first (single delivery only).
+}
+
+// Response struct for a page of webhook deliveries
+type WebhookDeliveryListResponse struct {
+	Data     []WebhookDeliveryResponse `json:"data"`      // The deliveries, newest first.
+	Page     int                       `json:"page"`      // The page number.
+	PageSize int                       `json:"page_size"` // Deliveries per page.
+	Total    int64                     `json:"total"`     // Number of matching deliveries.
+}
+
+// newWebhookResponse converts a subscription to its API representation, without its secret.
+func newWebhookResponse(subscription webhooks.Subscription) WebhookResponse {
+	return WebhookResponse{
+		ID:          subscription.ID,
+		URL:         subscription.URL,
+		Events:      subscription.Events,
+		Description: subscription.Description,
+		Active:      subscription.Active,
+		Failures:    subscription.Failures,
+		DisabledAt:  subscription.DisabledAt,
+		CreatedAt:   subscription.CreatedAt,
+		UpdatedAt:   subscription.UpdatedAt,
+	}
+}
+
+// newWebhookDeliveryResponse converts a delivery to its API representation.
+func newWebhookDeliveryResponse(delivery webhooks.Delivery) WebhookDeliveryResponse {
+	response := WebhookDeliveryResponse{
+		ID:          delivery.ID,
+		EventID:     delivery.EventID,
+		EventType:   delivery.EventType,
+		Payload:     delivery.Payload,
+		Status:      delivery.Status,
+		Attempts:    delivery.Attempts,
+		LastError:   delivery.LastError,
+		DeliveredAt: delivery.DeliveredAt,
+		ReplayOf:    delivery.ReplayOf,
+		CreatedAt:   delivery.CreatedAt,
+	}
+	if delivery.Status == webhooks.StatusPending {
+		response.NextAttemptAt = &delivery.NextAttemptAt
+	}
+	return response
+}
+
+// findWebhook loads a subscription of the tenant of the request (404 if it does not exist).
+func findWebhook(c *fiber.Ctx, id uint) (webhooks.Subscription, error) {
+	var subscription webhooks.Subscription
+	if err := db.GormDB.WithContext(c.UserContext()).First(&subscription, id).Error; err != nil {
+		return subscription, apperror.From(err)
+	}
+	return subscription, nil
+}
+
+// findWebhookDelivery loads a delivery of a subscription of the tenant of the request (404 if it does not exist).
+func findWebhookDelivery(c *fiber.Ctx, params WebhookDeliveryIDRequest) (webhooks.Delivery, error) {
+	var delivery webhooks.Delivery
+	err := db.GormDB.WithContext(c.UserContext()).
+		Where("subscription_id = ?", params.ID).First(&delivery, params.DeliveryID).Error
+	if err != nil {
+		return delivery, apperror.From(err)
+	}
+	return delivery, nil
+}
+
+// createWebhookHandler subscribes an endpoint to events.
+// @Summary      Create Webhook
+// @Description  Subscribes an endpoint to events of the tenant. Events are sent as POST requests signed with the returned secret: the X-Webhook-Signature header contains "sha256=" followed by the hex HMAC-SHA256 of "<X-Webhook-Timestamp>.<body>". The secret is only returned by this endpoint.
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Security     BasicAuth
+// @Param        request body CreateWebhookRequest true "Create Webhook Request"
+// @Success      201 {object} WebhookResponse
+// @Failure      400 {object} apperror.Problem
+// @Failure      401 {object} apperror.Problem
+// @Failure      422 {object} apperror.Problem
+// @Failure      500 {object} apperror.Problem
+// @Router       /v2/webhooks [post]
+func createWebhookHandler(c *fiber.Ctx) error {
+	var body CreateWebhookRequest
+	if err := validation.BindAndValidate(c, &body); err != nil {
+		return err
+	}
+
+	subscription := webhooks.Subscription{
+		URL:         body.URL,
+		Events:      body.Events,
+		Secret:      webhooks.NewSecret(),
+		Description: body.Description,
+		Active:      true,
+	}
+	if err := db.GormDB.WithContext(c.UserContext()).Create(&subscription).Error; err != nil {
+		return apperror.From(err)
+	}
+
+	response := newWebhookResponse(subscription)
+	response.Secret = subscription.Secret
+	return c.Status(fiber.StatusCreated).JSON(response)
+}
+
+// getWebhooksHandler lists the webhook subscriptions.
+// @Summary      List Webhooks
+// @Description  Lists the webhook subscriptions of the tenant, without their secrets.
+// @Tags         webhooks
+// @Produce      json
+// @Security     BasicAuth
+// @Success      200 {object} WebhookListResponse
+// @Failure      401 {object} apperror.Problem
+// @Failure      500 {object} apperror.Problem
+// @Router       /v2/webhooks [get]
+func getWebhooksHandler(c *fiber.Ctx) error {
+	var subscriptions []webhooks.Subscription
+	if err := db.GormDB.WithContext(c.UserContext()).Order("id").Find(&subscriptions).Error; err != nil {
+		return apperror.From(err)
+	}
+
+	response := WebhookListResponse{Data: make([]WebhookResponse, 0, len(subscriptions))}
+	for _, subscription := range subscriptions {
+		response.Data = append(response.Data, newWebhookResponse(subscription))
+	}
+	return c.JSON(response)
+}
+
+// getWebhookHandler returns a webhook subscription.
+// @Summary      Get Webhook
+// @Description  Returns a webhook subscription, without its secret.
+// @Tags         webhooks
+// @Produce      json
+// @Security     BasicAuth
+// @Param        id path int true "Subscription ID"
+// @Success      200 {object} WebhookResponse
+// @Failure      400 {object} apperror.Problem
+// @Failure      401 {object} apperror.Problem
+// @Failure      404 {object} apperror.Problem
+// @Failure      500 {object} apperror.Problem
+// @Router       /v2/webhooks/{id} [get]
+func getWebhookHandler(c *fiber.Ctx) error {
+	var params WebhookIDRequest
+	if err := validation.BindAndValidate(c, &params); err != nil {
+		return err
+	}
+
+	subscription, err := findWebhook(c, params.ID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(newWebhookResponse(subscription))
+}
+
+// updateWebhookHandler updates a webhook subscription.
+// Enabling a subscription disabled after repeated failures resets its failures; its failed deliveries can be replayed.
+// @Summary      Update Webhook
+// @Description  Updates the endpoint and events of a webhook subscription, and disables or enables it. Enabling a subscription disabled after repeated failures resets its failures.
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Security     BasicAuth
+// @Param        id      path int                  true "Subscription ID"
+// @Param        request body UpdateWebhookRequest true "Update Webhook Request"
+// @Success      200 {object} WebhookResponse
+// @Failure      400 {object} apperror.Problem
+// @Failure      401 {object} apperror.Problem
+// @Failure      404 {object} apperror.Problem
+// @Failure      422 {object} apperror.Problem
+// @Failure      500 {object} apperror.Problem
+// @Router       /v2/webhooks/{id} [put]
+func updateWebhookHandler(c *fiber.Ctx) error {
+	var params WebhookIDRequest
+	if err := validation.BindAndValidate(c, &params); err != nil {
+		return err
+	}
+	var body UpdateWebhookRequest
+	if err := validation.BindAndValidate(c, &body); err != nil {
+		return err
+	}
+
+	subscription, err := findWebhook(c, params.ID)
+	if err != nil {
+		return err
+	}
+	subscription.URL = body.URL
+	subscription.Events = body.Events
+	subscription.Description = body.Description
+	if body.Active != nil && *body.Active != subscription.Active {
+		subscription.Active = *body.Active
+		if subscription.Active {
+			subscription.Failures = 0
+			subscription.DisabledAt = nil
+		}
+	}
+
+	err = db.GormDB.WithContext(c.UserContext()).Model(&subscription).
+		Select("url", "events", "description", "active", "failures", "disabled_at").
+		Updates(&subscription).Error
+	if err != nil {
+		return apperror.From(err)
+	}
+	return c.JSON(newWebhookResponse(subscription))
+}
+
+// deleteWebhookHandler deletes a webhook subscription.
+// @Summary      Delete Webhook
+// @Description  Deletes a webhook subscription. Its pending deliveries are not sent.
+// @Tags         webhooks
+// @Produce      json
+// @Security     BasicAuth
+// @Param        id path int true "Subscription ID"
+// @Success      204
+// @Failure      400 {object} apperror.Problem
+// @Failure      401 {object} apperror.Problem
+// @Failure      404 {object} apperror.Problem
+// @Failure      500 {object} apperror.Problem
+// @Router       /v2/webhooks/{id} [delete]
+func deleteWebhookHandler(c *fiber.Ctx) error {
+	var params WebhookIDRequest
+	if err := validation.BindAndValidate(c, &params); err != nil {
+		return err
+	}
+
+	result := db.GormDB.WithContext(c.UserContext()).Delete(&webhooks.Subscription{}, params.ID)
+	if result.Error != nil {
+		return apperror.From(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return apperror.NotFound("").WithKey("error.not_found")
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// getWebhookDeliveriesHandler lists the deliveries of a webhook subscription, newest first.
+// @Summary      List Webhook Deliveries
+// @Description  Lists the events sent, or to be sent, to a webhook subscription, newest first.
+// @Tags         webhooks
+// @Produce      json
+// @Security     BasicAuth
+// @Param        id         path  int    true  "Subscription ID"
+// @Param        status     query string false "Only the deliveries in this state" Enums(pending, succeeded, failed)
+// @Param        page       query int    false "Page number" minimum(1) default(1)
+// @Param        page_size  query int    false "Deliveries per page" minimum(1) maximum(100) default(50)
+// @Success      200 {object} WebhookDeliveryListResponse
+// @Failure      400 {object} apperror.Problem
+// @Failure      401 {object} apperror.Problem
+// @Failure      404 {object} apperror.Problem
+// @Failure      422 {object} apperror.Problem
+// @Failure      500 {object} apperror.Problem
+// @Router       /v2/webhooks/{id}/deliveries [get]
+func getWebhookDeliveriesHandler(c *fiber.Ctx) error {
+	var params WebhookIDRequest
+	if err := validation.BindAndValidate(c, &params); err != nil {
+		return err
+	}
+	var query WebhookDeliveriesRequest
+	if err := validation.BindAndValidate(c, &query); err != nil {
+		return err
+	}
+	if query.Page == 0 {
+		query.Page = 1
+	}
+	if query.PageSize == 0 {
+		query.PageSize = 50
+	}
+
+	if _, err := findWebhook(c, params.ID); err != nil {
+		return err
+	}
+	tx := db.GormDB.WithContext(c.UserContext()).Model(&webhooks.Delivery{}).Where("subscription_id = ?", params.ID)
+	if query.Status != "" {
+		tx = tx.Where("status = ?", query.Status)
+	}
+	var total int64
+	if err := tx.Count(&total).Error; err != nil {
+		return apperror.From(err)
+	}
+	var deliveries []webhooks.Delivery
+	err := tx.Order("id DESC").Offset((query.Page - 1) * query.PageSize).Limit(query.PageSize).Find(&deliveries).Error
+	if err != nil {
+		return apperror.From(err)
+	}
+
+	response := WebhookDeliveryListResponse{
+		Data:     make([]WebhookDeliveryResponse, 0, len(deliveries)),
+		Page:     query.Page,
+		PageSize: query.PageSize,
+		Total:    total,
+	}
+	for _, delivery := range deliveries {
+		response.Data = append(response.Data, newWebhookDeliveryResponse(delivery))
+	}
+	return c.JSON(response)
+}
+
+// getWebhookDeliveryHandler returns a delivery of a webhook subscription, with its attempts.
+// @Summary      Get Webhook Delivery
+// @Description  Returns a delivery of a webhook subscription, with the requests sent and the responses received.
+// @Tags         webhooks
+// @Produce      json
+// @Security     BasicAuth
+// @Param        id           path int true "Subscription ID"
+// @Param        delivery_id  path int true "Delivery ID"
+// @Success      200 {object} WebhookDeliveryResponse
+// @Failure      400 {object} apperror.Problem
+// @Failure      401 {object} apperror.Problem
+// @Failure      404 {object} apperror.Problem
+// @Failure      500 {object} apperror.Problem
+// @Router       /v2/webhooks/{id}/deliveries/{delivery_id} [get]
+func getWebhookDeliveryHandler(c *fiber.Ctx) error {
+	var params WebhookDeliveryIDRequest
+	if err := validation.BindAndValidate(c, &params); err != nil {
+		return err
+	}
+
+	if _, err := findWebhook(c, params.ID); err != nil {
+		return err
+	}
+	delivery, err := findWebhookDelivery(c, params)
+	if err != nil {
+		return err
+	}
+	var attempts []webhooks.Attempt
+	err = db.GormDB.WithContext(c.UserContext()).Where("delivery_id = ?", delivery.ID).Order("id").Find(&attempts).Error
+	if err != nil {
+		return apperror.From(err)
+	}
+
+	response := newWebhookDeliveryResponse(delivery)
+	for _, attempt := range attempts {
+		response.AttemptLog = append(response.AttemptLog, WebhookAttemptResponse{
+			ID:         attempt.ID,
+			CreatedAt:  attempt.CreatedAt,
+			StatusCode: attempt.StatusCode,
+			DurationMS: attempt.DurationMS,
+			Error:      attempt.Error,
+			Response:   attempt.Response,
+		})
+	}
+	return c.JSON(response)
+}
+
+// replayWebhookDeliveryHandler sends the event of a delivery again, as a new delivery.
+// @Summary      Replay Webhook Delivery
+// @Description  Sends the event of a delivery again, e.g. after the endpoint was fixed. The replay is a new delivery with the same event ID, sent in the background.
+// @Tags         webhooks
+// @Produce      json
+// @Security     BasicAuth
+// @Param        id           path int true "Subscription ID"
+// @Param        delivery_id  path int true "Delivery ID"
+// @Success      202 {object} WebhookDeliveryResponse
+// @Failure      400 {object} apperror.Problem
+// @Failure      401 {object} apperror.Problem
+// @Failure      404 {object} apperror.Problem
+// @Failure      409 {object} apperror.Problem "The subscription is disabled"
+// @Failure      500 {object} apperror.Problem
+// @Router       /v2/webhooks/{id}/deliveries/{delivery_id}/replay [post]
+func replayWebhookDeliveryHandler(c *fiber.Ctx) error {
+	var params WebhookDeliveryIDRequest
+	if err := validation.BindAndValidate(c, &params); err != nil {
+		return err
+	}
+
+	subscription, err := findWebhook(c, params.ID)
+	if err != nil {
+		return err
+	}
+	if !subscription.Active {
+		return apperror.Conflict("").WithKey("error.webhook_disabled").WithCode("webhook_disabled")
+	}
+	delivery, err := findWebhookDelivery(c, params)
+	if err != nil {
+		return err
+	}
+
+	replay, err := webhooks.Replay(db.GormDB.WithContext(c.UserContext()), delivery)
+	if err != nil {
+		return apperror.From(err)
+	}
+	return c.Status(fiber.StatusAccepted).JSON(newWebhookDeliveryResponse(replay))
+}
@@ -0,0 +1,115 @@
+// Package routes contains tests for the application's API endpoints.
+// These tests validate the webhook subscription endpoints.
+package routes
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"gobo/internal/apperror"
+	"gobo/internal/db"
+	"gobo/internal/testhelpers"
+	"gobo/internal/webhooks"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestWebhooks validates managing a webhook subscription, inspecting its deliveries and replaying them.
+func TestWebhooks(t *testing.T) {
+	tables := []interface{}{&webhooks.Subscription{}, &webhooks.Delivery{}, &webhooks.Attempt{}}
+	testhelpers.SetupGormTestDB(t, tables...)
+	defer testhelpers.TeardownGormTestDB(tables...)
+
+	// Create a new Fiber app instance and register routes.
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	Register(app)
+	request := func(method, path, body string, response interface{}) int {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.SetBasicAuth("admin", "password")
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		if response != nil && resp.StatusCode < 300 {
+			assert.NoError(t, json.NewDecoder(resp.Body).Decode(response))
+		} else {
+			_, _ = io.Copy(io.Discard, resp.Body)
+		}
+		return resp.StatusCode
+	}
+
+	// The secret is only returned when the subscription is created.
+	var created WebhookResponse
+	assert.Equal(t, 201, request("POST", "/v2/webhooks", `{"url": "https://example.com/hooks", "events": ["example.created"]}`, &created))
+	assert.True(t, strings.HasPrefix(created.Secret, "whsec_"))
+	assert.True(t, created.Active)
+	assert.Equal(t, 422, request("POST", "/v2/webhooks", `{"url": "https://example.com/hooks", "events": ["example.renamed"]}`, nil))
+	assert.Equal(t, 422, request("POST", "/v2/webhooks", `{"url": "not a url", "events": ["*"]}`, nil))
+	assert.Equal(t, 422, request("POST", "/v2/webhooks", `{"url": "file:///etc/passwd", "events": ["*"]}`, nil))
+
+	var list WebhookListResponse
+	assert.Equal(t, 200, request("GET", "/v2/webhooks", "", &list))
+	if assert.Len(t, list.Data, 1) {
+		assert.Empty(t, list.Data[0].Secret)
+		assert.Equal(t, []string{"example.created"}, list.Data[0].Events)
+	}
+
+	// Record a failed delivery.
+	delivery := webhooks.Delivery{
+		SubscriptionID: created.ID,
+		EventID:        "0b7d2f0e-4a5c-4f0e-9d3e-2f1c7c1b8a11",
+		EventType:      "example.created",
+		Payload:        json.RawMessage(`{"type": "example.created"}`),
+		Status:         webhooks.StatusFailed,
+		Attempts:       1,
+		NextAttemptAt:  time.Now(),
+		LastError:      "unexpected status 500",
+	}
+	delivery.TenantID = "default"
+	assert.NoError(t, db.GormDB.Create(&delivery).Error)
+	assert.NoError(t, db.GormDB.Create(&webhooks.Attempt{DeliveryID: delivery.ID, StatusCode: 500, Error: "unexpected status 500"}).Error)
+
+	path := "/v2/webhooks/" + strconv.Itoa(int(created.ID))
+	deliveryPath := path + "/deliveries/" + strconv.Itoa(int(delivery.ID))
+	var deliveries WebhookDeliveryListResponse
+	assert.Equal(t, 200, request("GET", path+"/deliveries?status=failed", "", &deliveries))
+	assert.EqualValues(t, 1, deliveries.Total)
+	var detail WebhookDeliveryResponse
+	assert.Equal(t, 200, request("GET", deliveryPath, "", &detail))
+	assert.Equal(t, webhooks.StatusFailed, detail.Status)
+	if assert.Len(t, detail.AttemptLog, 1) {
+		assert.Equal(t, 500, detail.AttemptLog[0].StatusCode)
+	}
+	assert.Equal(t, 404, request("GET", "/v2/webhooks/999/deliveries/"+strconv.Itoa(int(delivery.ID)), "", nil))
+
+	// Replay the delivery: a new pending delivery with the same event.
+	var replay WebhookDeliveryResponse
+	assert.Equal(t, 202, request("POST", deliveryPath+"/replay", "", &replay))
+	assert.Equal(t, webhooks.StatusPending, replay.Status)
+	assert.Equal(t, delivery.EventID, replay.EventID)
+	if assert.NotNil(t, replay.ReplayOf) {
+		assert.Equal(t, delivery.ID, *replay.ReplayOf)
+	}
+
+	// Disabled subscriptions cannot replay deliveries; enabling them again resets their failures.
+	assert.NoError(t, db.GormDB.Model(&webhooks.Subscription{}).Where("id = ?", created.ID).
+		Updates(map[string]interface{}{"active": false, "failures": 20, "disabled_at": time.Now()}).Error)
+	assert.Equal(t, http.StatusConflict, request("POST", deliveryPath+"/replay", "", nil))
+	var updated WebhookResponse
+	assert.Equal(t, 200, request("PUT", path, `{"url": "https://example.com/v2/hooks", "events": ["*"], "active": true}`, &updated))
+	assert.True(t, updated.Active)
+	assert.Zero(t, updated.Failures)
+	assert.Nil(t, updated.DisabledAt)
+	assert.Equal(t, "https://example.com/v2/hooks", updated.URL)
+
+	// Delete the subscription.
+	assert.Equal(t, 204, request("DELETE", path, "", nil))
+	assert.Equal(t, 404, request("GET", path, "", nil))
+	assert.Equal(t, 404, request("DELETE", path, "", nil))
+}
@@ -0,0 +1,57 @@
+// Package routes defines and registers all HTTP routes for the application.
+// This file contains the WebSocket endpoint for bidirectional messaging on channels.
+package routes
+
+import (
+	"gobo/internal/apperror"
+	"gobo/internal/middleware"
+	"gobo/internal/tenant"
+	"gobo/internal/ws"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+)
+
+// websocketTenantLocalKey is the Fiber locals key passing the tenant of the request to the upgraded connection.
+const websocketTenantLocalKey = "websocketTenant"
+
+// upgradeWebsocket upgrades the connection and serves it with the application's hub until it is closed.
+// The locals of the request are copied to the connection before the upgrade.
+var upgradeWebsocket = websocket.New(func(conn *websocket.Conn) {
+	owner, _ := conn.Locals(websocketTenantLocalKey).(string)
+	username, _ := conn.Locals(middleware.UsernameLocalKey).(string)
+	hub := ws.Default
+	if hub == nil {
+		_ = conn.Close()
+		return
+	}
+	_ = hub.Serve(conn.Conn, owner, username)
+})
+
+// websocketHandler upgrades an authenticated request to a WebSocket connection for messaging on channels.
+// @Summary      WebSocket Messaging
+// @Description  Upgrades the request to a WebSocket connection exchanging JSON messages on the channels of the tenant. Clients send {"type":"subscribe"|"unsubscribe"|"publish"|"presence"|"ping","channel":"chat","data":{...},"id":"1"}; the server answers with "ack", "error" or "pong" (echoing the id), and sends "message", "join", "leave" and "presence" messages of the subscribed channels. Messages reach the subscribers connected to any instance. Each connection may send 10 messages per second (bursts of 20); further messages are dropped with a "rate_limited" error.
+// @Tags         websocket
+// @Security     BasicAuth
+// @Success      101 {string} string "Switching Protocols"
+// @Failure      401 {object} apperror.Problem
+// @Failure      426 {object} apperror.Problem
+// @Failure      503 {object} apperror.Problem
+// @Router       /v2/ws [get]
+func websocketHandler(c *fiber.Ctx) error {
+	if ws.Default == nil || ws.Default.Ready() != nil {
+		return apperror.New(fiber.StatusServiceUnavailable, "websocket_unavailable", "").WithKey("error.websocket_unavailable")
+	}
+	if !websocket.IsWebSocketUpgrade(c) {
+		return apperror.New(fiber.StatusUpgradeRequired, "upgrade_required", "").WithKey("error.upgrade_required")
+	}
+
+	// Requests without a tenant belong to the default tenant. The user context is not available after the
+	// upgrade, so the tenant is passed in the locals.
+	owner := tenant.FromContext(c.UserContext())
+	if owner == "" {
+		owner = tenant.DefaultID
+	}
+	c.Locals(websocketTenantLocalKey, owner)
+	return upgradeWebsocket(c)
+}
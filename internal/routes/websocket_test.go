@@ -0,0 +1,95 @@
+// Package routes contains tests for the application's API endpoints.
+// These tests validate the WebSocket endpoint, which uses an in-memory Redis server.
+package routes
+
+import (
+	"context"
+	"encoding/base64"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gobo/internal/apperror"
+	"gobo/internal/middleware"
+	"gobo/internal/ws"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/fasthttp/websocket"
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestWebsocket validates messaging on the channels of a tenant over a WebSocket connection.
+func TestWebsocket(t *testing.T) {
+	// Replace the application's hub with one using an in-memory Redis server.
+	client := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	defer client.Close()
+	previous := ws.Default
+	ws.Default = ws.New(ws.Config{Client: client})
+	defer func() { ws.Default = previous }()
+	stop := ws.Default.Start()
+	defer stop()
+
+	// Serve a Fiber app resolving the tenant from the X-Tenant-ID header.
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Use(middleware.TenantMiddleware(middleware.TenantConfig{Header: "X-Tenant-ID", Default: "default"}))
+	Register(app)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	go func() { _ = app.Listener(listener) }()
+	defer func() { _ = app.Shutdown() }()
+
+	// The upgrade requires authentication.
+	url := "ws://" + listener.Addr().String() + "/v2/ws"
+	_, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	assert.Error(t, err)
+	if assert.NotNil(t, resp) {
+		assert.Equal(t, 401, resp.StatusCode)
+	}
+
+	// Plain requests must be upgrades.
+	req := httptest.NewRequest("GET", "/v2/ws", nil)
+	req.SetBasicAuth("admin", "password")
+	plain, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, 426, plain.StatusCode)
+
+	header := http.Header{}
+	header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte("admin:password")))
+	header.Set("X-Tenant-ID", "acme")
+	conn, resp, err := websocket.DefaultDialer.Dial(url, header)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer conn.Close()
+	assert.Equal(t, 101, resp.StatusCode)
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	var message ws.Message
+	assert.NoError(t, conn.ReadJSON(&message))
+	assert.Equal(t, ws.Message{Type: ws.TypeWelcome, User: "admin"}, message)
+
+	// The connection subscribes to the channels of its tenant, as the authenticated user.
+	assert.NoError(t, conn.WriteJSON(ws.Message{Type: ws.TypeSubscribe, Channel: "chat", ID: "1"}))
+	assert.Eventually(t, func() bool {
+		members, _ := ws.Default.Members(context.Background(), "acme", "chat")
+		return len(members) == 1 && members[0] == "admin"
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.NoError(t, ws.Default.Publish(context.Background(), "acme", "chat", ws.Message{Type: ws.TypeMessage, Data: []byte(`{"text":"hello"}`)}))
+	for message.Type != ws.TypeMessage {
+		message = ws.Message{}
+		if !assert.NoError(t, conn.ReadJSON(&message)) {
+			return
+		}
+	}
+	assert.JSONEq(t, `{"text":"hello"}`, string(message.Data))
+
+	// Without Redis, messaging is unavailable.
+	ws.Default = nil
+	plain, err = app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, 503, plain.StatusCode)
+}
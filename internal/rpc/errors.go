@@ -0,0 +1,141 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"gobo/internal/apperror"
+	"gobo/internal/i18n"
+	"gobo/internal/logger"
+	"gobo/internal/service"
+
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// errorDomain is the domain of the ErrorInfo details of the returned errors.
+const errorDomain = "gobo"
+
+// statusError converts an error of an interceptor or a service method into a gRPC status error, as
+// apperror.Handler renders the errors of the HTTP handlers as problem details:
+// - Status errors are returned as they are, context errors become CANCELLED or DEADLINE_EXCEEDED.
+// - Version conflicts become ABORTED, with the current version in the ErrorInfo metadata.
+// - Any other error is converted with apperror.From and mapped by its HTTP status (see codeFor).
+// Server errors are logged with their cause, which also reports them to Sentry.
+//
+// Parameters:
+// - ctx (context.Context): The context of the call, carrying its language.
+// - call (*call): The call.
+// - err (error): The error to convert.
+//
+// Returns:
+// - error: The status error, or nil if err is nil.
+func statusError(ctx context.Context, call *call, err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		// The client canceled the call or its deadline passed: CANCELLED or DEADLINE_EXCEEDED.
+		return status.FromContextError(err).Err()
+	}
+
+	var conflict *service.VersionConflictError
+	if errors.As(err, &conflict) {
+		current := conflict.Current.Version.Int64
+		err = apperror.Conflict("").
+			WithKey("error.version_conflict", i18n.Args{"version": conflict.Version, "current": current}).
+			WithCode("version_conflict").
+			WithExtension("current_version", current)
+	}
+
+	appErr := apperror.From(err)
+	if appErr.Status >= http.StatusInternalServerError {
+		logger.FromContext(ctx).Error("gRPC call failed",
+			zap.Error(err),
+			zap.String("method", call.Method),
+			zap.Int("status", appErr.Status),
+		)
+	}
+	return newStatus(ctx, appErr).Err()
+}
+
+// newStatus builds the status of an application error, localized to the language of the call.
+// The details contain an ErrorInfo with the machine-readable code as the reason and, for validation errors,
+// a BadRequest listing the invalid fields.
+func newStatus(ctx context.Context, err *apperror.Error) *status.Status {
+	language := i18n.FromContext(ctx)
+	if language == "" {
+		language = i18n.DefaultLanguage
+	}
+	message := err.Detail
+	if err.Key != "" {
+		message = i18n.T(language, err.Key, err.Args)
+	}
+
+	info := &errdetails.ErrorInfo{Reason: err.Code, Domain: errorDomain}
+	if version, ok := err.Extensions["current_version"].(int64); ok {
+		info.Metadata = map[string]string{"current_version": strconv.FormatInt(version, 10)}
+	}
+	details := []protoadapt.MessageV1{info}
+	if len(err.Fields) > 0 {
+		violations := make([]*errdetails.BadRequest_FieldViolation, len(err.Fields))
+		for i, field := range err.Fields {
+			description := field.Message
+			if field.Key != "" {
+				description = i18n.T(language, field.Key, field.Args)
+			}
+			violations[i] = &errdetails.BadRequest_FieldViolation{Field: field.Field, Description: description}
+		}
+		details = append(details, &errdetails.BadRequest{FieldViolations: violations})
+	}
+
+	st := status.New(codeFor(err), message)
+	if detailed, detailErr := st.WithDetails(details...); detailErr == nil {
+		return detailed
+	}
+	return st
+}
+
+// codeFor returns the gRPC code of an application error, by its HTTP status.
+func codeFor(err *apperror.Error) codes.Code {
+	switch err.Status {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		switch {
+		case err.Key == "error.duplicate":
+			return codes.AlreadyExists
+		case err.Code == "version_conflict":
+			return codes.Aborted
+		}
+		return codes.FailedPrecondition
+	case http.StatusPreconditionFailed, http.StatusPreconditionRequired:
+		return codes.FailedPrecondition
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	}
+	if err.Status >= http.StatusInternalServerError {
+		return codes.Internal
+	}
+	return codes.Unknown
+}
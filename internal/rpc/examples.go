@@ -0,0 +1,90 @@
+package rpc
+
+import (
+	"context"
+
+	"gobo/internal/models"
+	"gobo/internal/rpc/gobov1"
+	"gobo/internal/service"
+	"gobo/internal/validation"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// exampleServer implements the ExampleService with the service layer, as the /v2/examples handlers.
+type exampleServer struct {
+	gobov1.UnimplementedExampleServiceServer
+}
+
+// updateVersion is the version of an update, required as the If-Match header of the HTTP API is.
+type updateVersion struct {
+	Version int64 `json:"version" validate:"required"`
+}
+
+// newExample converts an example model into its message.
+func newExample(example models.Example) *gobov1.Example {
+	message := &gobov1.Example{
+		Id:        uint64(example.ID),
+		Name:      example.Name,
+		CreatedAt: timestamppb.New(example.CreatedAt),
+		UpdatedAt: timestamppb.New(example.UpdatedAt),
+		Version:   example.Version.Int64,
+	}
+	if example.DeletedAt.Valid {
+		message.DeletedAt = timestamppb.New(example.DeletedAt.Time)
+	}
+	return message
+}
+
+// ListExamples returns the examples of the tenant; all calls are authenticated, so they may include the
+// soft-deleted examples.
+func (s *exampleServer) ListExamples(ctx context.Context, req *gobov1.ListExamplesRequest) (*gobov1.ListExamplesResponse, error) {
+	examples, err := service.ListExamples(ctx, req.GetIncludeDeleted())
+	if err != nil {
+		return nil, err
+	}
+	resp := &gobov1.ListExamplesResponse{Examples: make([]*gobov1.Example, len(examples))}
+	for i, example := range examples {
+		resp.Examples[i] = newExample(example)
+	}
+	return resp, nil
+}
+
+// GetExample returns an example of the tenant.
+func (s *exampleServer) GetExample(ctx context.Context, req *gobov1.GetExampleRequest) (*gobov1.Example, error) {
+	example, err := service.GetExample(ctx, uint(req.GetId()))
+	if err != nil {
+		return nil, err
+	}
+	return newExample(example), nil
+}
+
+// CreateExample creates an example.
+func (s *exampleServer) CreateExample(ctx context.Context, req *gobov1.CreateExampleRequest) (*gobov1.Example, error) {
+	example, err := service.CreateExample(ctx, service.ExampleInput{Name: req.GetName()})
+	if err != nil {
+		return nil, err
+	}
+	return newExample(example), nil
+}
+
+// UpdateExample renames an example if it still has the given version.
+func (s *exampleServer) UpdateExample(ctx context.Context, req *gobov1.UpdateExampleRequest) (*gobov1.Example, error) {
+	if err := validation.Struct(updateVersion{Version: req.GetVersion()}); err != nil {
+		return nil, err
+	}
+	example, err := service.UpdateExample(ctx, uint(req.GetId()), service.ExampleInput{Name: req.GetName()}, req.GetVersion())
+	if err != nil {
+		return nil, err
+	}
+	return newExample(example), nil
+}
+
+// DeleteExample soft deletes an example.
+func (s *exampleServer) DeleteExample(ctx context.Context, req *gobov1.DeleteExampleRequest) (*emptypb.Empty, error) {
+	if err := service.DeleteExample(ctx, uint(req.GetId())); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
@@ -0,0 +1,326 @@
+package rpc
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gobo/internal/apperror"
+	"gobo/internal/audit"
+	"gobo/internal/i18n"
+	"gobo/internal/logger"
+	"gobo/internal/middleware"
+	"gobo/internal/tenant"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Metadata keys read and sent by the interceptors; gRPC metadata keys are lowercase.
+const (
+	requestIDKey      = "x-request-id"
+	authorizationKey  = "authorization"
+	acceptLanguageKey = "accept-language"
+	retryAfterKey     = "retry-after"
+)
+
+// call describes the call being intercepted; inner interceptors complete it for the outer ones (e.g., logging).
+type call struct {
+	Method string // Full method name (e.g., "/gobo.v1.ExampleService/GetExample")
+	Public bool   // Health checks and reflection, which need neither a tenant nor authentication
+	Tenant string // Tenant of the call, once resolved
+	User   string // Authenticated username, once authenticated
+}
+
+// newCall describes a call to the given method.
+func newCall(method string) *call {
+	public := strings.HasPrefix(method, "/grpc.health.v1.Health/") || strings.HasPrefix(method, "/grpc.reflection.")
+	return &call{Method: method, Public: public}
+}
+
+// handler continues a call with the given context: the next interceptor, then the service method.
+type handler func(ctx context.Context) error
+
+// interceptor intercepts unary and streaming calls alike, as Fiber middleware does requests.
+type interceptor func(ctx context.Context, call *call, next handler) error
+
+// chain runs the interceptors in order, then the service method.
+func chain(ctx context.Context, call *call, interceptors []interceptor, last handler) error {
+	if len(interceptors) == 0 {
+		return last(ctx)
+	}
+	return interceptors[0](ctx, call, func(ctx context.Context) error {
+		return chain(ctx, call, interceptors[1:], last)
+	})
+}
+
+// unaryInterceptor adapts the interceptors to unary calls.
+func unaryInterceptor(interceptors []interceptor) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) (interface{}, error) {
+		var resp interface{}
+		err := chain(ctx, newCall(info.FullMethod), interceptors, func(ctx context.Context) error {
+			var err error
+			resp, err = next(ctx, req)
+			return err
+		})
+		return resp, err
+	}
+}
+
+// streamInterceptor adapts the interceptors to streaming calls (e.g., reflection and health watches).
+func streamInterceptor(interceptors []interceptor) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, next grpc.StreamHandler) error {
+		return chain(stream.Context(), newCall(info.FullMethod), interceptors, func(ctx context.Context) error {
+			return next(srv, &serverStream{ServerStream: stream, ctx: ctx})
+		})
+	}
+}
+
+// serverStream is a server stream whose context is the one built by the interceptors.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the context of the call.
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// metadataValue returns the first value of a key of the incoming metadata, or an empty string.
+func metadataValue(ctx context.Context, key string) string {
+	if values := metadata.ValueFromIncomingContext(ctx, key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// requestContext prepares the context of the call, as the Sentry, request ID, audit and locale middleware do:
+// - The Sentry hub of the call is cloned and stored in the context (see logger.FromContext).
+// - The request ID is the x-request-id metadata, or a new UUID; it is returned in the response header.
+// - The request ID and the client IP address are stored as the audit actor (see audit.NewContext).
+// - The language is negotiated from the accept-language metadata and returned as content-language.
+func requestContext(ctx context.Context, call *call, next handler) error {
+	hub := sentry.CurrentHub().Clone()
+	hub.Scope().SetTag("grpc.method", call.Method)
+	ctx = sentry.SetHubOnContext(ctx, hub)
+
+	requestID := metadataValue(ctx, requestIDKey)
+	if requestID == "" {
+		requestID = uuid.NewString()
+	}
+	ip := ""
+	if client, ok := peer.FromContext(ctx); ok && client.Addr != nil {
+		ip = client.Addr.String()
+		if host, _, err := net.SplitHostPort(ip); err == nil {
+			ip = host
+		}
+	}
+	ctx = audit.NewContext(ctx, audit.Actor{RequestID: requestID, IP: ip})
+
+	language := i18n.Negotiate(metadataValue(ctx, acceptLanguageKey))
+	ctx = i18n.NewContext(ctx, language)
+
+	// The header cannot be set once sent; failing to set it does not fail the call.
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDKey, requestID, "content-language", language))
+	return next(ctx)
+}
+
+// logCalls logs every call with its status code and duration, the tenant and the user.
+func logCalls(ctx context.Context, call *call, next handler) error {
+	start := time.Now()
+	err := next(ctx)
+	logger.FromContext(ctx).Named("grpc").Info("gRPC call",
+		zap.String("method", call.Method),
+		zap.String("code", status.Code(err).String()),
+		zap.Duration("duration", time.Since(start)),
+		zap.String("tenant", call.Tenant),
+		zap.String("user", call.User),
+		zap.String("requestId", audit.FromContext(ctx).RequestID),
+	)
+	return err
+}
+
+// recoverPanics recovers panics of the service methods, reports them with the user of the call, and
+// fails the call with INTERNAL instead of crashing the server.
+func recoverPanics(ctx context.Context, call *call, next handler) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			hub := sentry.GetHubFromContext(ctx)
+			if hub == nil {
+				hub = sentry.CurrentHub()
+			}
+			if call.User != "" {
+				hub.Scope().SetUser(sentry.User{Username: call.User})
+			}
+			hub.RecoverWithContext(ctx, recovered)
+			logger.FromContext(ctx).WithOptions(zap.WithCaller(false)).Warn("Recovered from panic",
+				zap.Any("panic", recovered),
+				zap.String("method", call.Method),
+			)
+			// The panic has already been reported, so the error is converted without logging it again.
+			err = newStatus(ctx, apperror.Internal(fmt.Errorf("panic: %v", recovered))).Err()
+		}
+	}()
+	return next(ctx)
+}
+
+// convertErrors converts the errors of the inner interceptors and the service methods into gRPC status
+// errors (see statusError), so that the outer interceptors observe the final status code.
+func convertErrors(ctx context.Context, call *call, next handler) error {
+	return statusError(ctx, call, next(ctx))
+}
+
+// resolveTenant resolves the tenant of the call from the tenant header of the metadata, as TenantMiddleware
+// does from the request header, and stores it in the context (see tenant.NewContext).
+// Invalid, unknown or missing tenants (without a default) fail the call with INVALID_ARGUMENT.
+func resolveTenant(config middleware.TenantConfig) interceptor {
+	header := strings.ToLower(config.Header)
+	if header == "" {
+		header = "x-tenant-id"
+	}
+	allowed := make(map[string]bool, len(config.Allowed))
+	for _, id := range config.Allowed {
+		allowed[id] = true
+	}
+
+	return func(ctx context.Context, call *call, next handler) error {
+		if call.Public {
+			return next(ctx)
+		}
+		id := ""
+		if header != "-" {
+			id = strings.ToLower(strings.TrimSpace(metadataValue(ctx, header)))
+		}
+		if id == "" {
+			id = config.Default
+		}
+
+		switch {
+		case id == "":
+			return apperror.BadRequest("").WithKey("error.tenant_required").WithCode("tenant_required")
+		case !tenant.Valid(id):
+			return apperror.BadRequest("").WithKey("error.invalid_tenant").WithCode("invalid_tenant")
+		case len(allowed) > 0 && !allowed[id] && id != config.Default:
+			return apperror.BadRequest("").WithKey("error.unknown_tenant", i18n.Args{"tenant": id}).WithCode("unknown_tenant")
+		}
+
+		call.Tenant = id
+		if hub := sentry.GetHubFromContext(ctx); hub != nil {
+			hub.Scope().SetTag("tenant", id)
+		}
+		return next(tenant.NewContext(ctx, id))
+	}
+}
+
+// rateWindow counts the calls of a tenant in the current window.
+type rateWindow struct {
+	start time.Time
+	calls int
+}
+
+// tenantLimiter counts the calls of each tenant in its current window.
+type tenantLimiter struct {
+	config  middleware.TenantRateLimitConfig
+	mu      sync.Mutex
+	windows map[string]*rateWindow // Current windows, by tenant
+	swept   time.Time              // Last removal of the expired windows
+}
+
+// newTenantLimiter creates a limiter, with the default quota and window for zero values.
+func newTenantLimiter(config middleware.TenantRateLimitConfig) *tenantLimiter {
+	defaults := middleware.DefaultTenantRateLimitConfig()
+	if config.Max <= 0 {
+		config.Max = defaults.Max
+	}
+	if config.Expiration <= 0 {
+		config.Expiration = defaults.Expiration
+	}
+	return &tenantLimiter{config: config, windows: map[string]*rateWindow{}, swept: time.Now()}
+}
+
+// allow counts a call of the tenant, and reports whether it is within the quota and when the window resets.
+// Expired windows are removed once per window, so that only the tenants called during the last window are kept.
+func (l *tenantLimiter) allow(id string, now time.Time) (bool, time.Duration) {
+	max := l.config.Max
+	if requests, ok := l.config.Limits[id]; ok {
+		max = requests
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if now.Sub(l.swept) >= l.config.Expiration {
+		for key, window := range l.windows {
+			if now.Sub(window.start) >= l.config.Expiration {
+				delete(l.windows, key)
+			}
+		}
+		l.swept = now
+	}
+	window := l.windows[id]
+	if window == nil || now.Sub(window.start) >= l.config.Expiration {
+		window = &rateWindow{start: now}
+		l.windows[id] = window
+	}
+	window.calls++
+	return window.calls <= max, window.start.Add(l.config.Expiration).Sub(now)
+}
+
+// limitTenants limits the calls of each tenant to its quota per window, as TenantRateLimitMiddleware limits
+// its HTTP requests. Calls over the quota fail with RESOURCE_EXHAUSTED and the retry-after header (in seconds).
+// The calls are counted per instance, separately from the HTTP requests. It runs after authenticate, so that
+// unauthenticated calls cannot fill the quotas or the windows with made-up tenants.
+func limitTenants(config middleware.TenantRateLimitConfig) interceptor {
+	limiter := newTenantLimiter(config)
+	return func(ctx context.Context, call *call, next handler) error {
+		if call.Public || call.Tenant == "" {
+			return next(ctx)
+		}
+		if allowed, reset := limiter.allow(call.Tenant, time.Now()); !allowed {
+			retryAfter := int(math.Ceil(reset.Seconds()))
+			_ = grpc.SetHeader(ctx, metadata.Pairs(retryAfterKey, strconv.Itoa(retryAfter)))
+			return apperror.RateLimited("").WithKey("error.rate_limited_retry", i18n.Args{"count": retryAfter})
+		}
+		return next(ctx)
+	}
+}
+
+// authenticate requires the Basic credentials in the authorization metadata, as BasicAuthMiddleware does
+// in the Authorization header, and stores the user as the audit actor (see audit.WithUser).
+// Missing or invalid credentials fail the call with UNAUTHENTICATED.
+func authenticate(username, password string) interceptor {
+	return func(ctx context.Context, call *call, next handler) error {
+		if call.Public {
+			return next(ctx)
+		}
+		authorization := metadataValue(ctx, authorizationKey)
+		if authorization == "" || !strings.HasPrefix(authorization, "Basic ") {
+			return apperror.Unauthorized("").WithKey("error.unauthorized")
+		}
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(authorization, "Basic "))
+		if err != nil {
+			return apperror.Unauthorized("").WithKey("error.invalid_authorization_header")
+		}
+		credentials := strings.SplitN(string(decoded), ":", 2)
+		if len(credentials) != 2 {
+			return apperror.Unauthorized("").WithKey("error.invalid_authorization_header_format")
+		}
+		if credentials[0] != username || credentials[1] != password {
+			return apperror.Unauthorized("").WithKey("error.invalid_credentials")
+		}
+
+		call.User = credentials[0]
+		return next(audit.WithUser(ctx, credentials[0]))
+	}
+}
@@ -0,0 +1,201 @@
+// Package rpc contains tests for the gRPC interceptors and the conversion of errors into gRPC statuses.
+package rpc
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"gobo/internal/apperror"
+	"gobo/internal/audit"
+	"gobo/internal/i18n"
+	"gobo/internal/middleware"
+	"gobo/internal/models"
+	"gobo/internal/service"
+	"gobo/internal/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+	"gorm.io/plugin/optimisticlock"
+)
+
+// run runs a call to the method through the interceptors, with the given metadata, and returns its error.
+func run(method string, md metadata.MD, interceptors []interceptor, last handler) error {
+	ctx := metadata.NewIncomingContext(context.Background(), md)
+	return chain(ctx, newCall(method), interceptors, last)
+}
+
+// basic returns the authorization metadata value of the credentials.
+func basic(username, password string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+}
+
+// TestAuthenticate validates that calls require the Basic credentials, except health checks and reflection.
+func TestAuthenticate(t *testing.T) {
+	interceptors := []interceptor{convertErrors, authenticate("admin", "password")}
+	var user string
+	last := func(ctx context.Context) error {
+		user = audit.FromContext(ctx).User
+		return nil
+	}
+
+	for _, authorization := range []string{"", "Bearer token", "Basic !!!", basic("admin", "wrong")} {
+		err := run("/gobo.v1.ExampleService/GetExample", metadata.Pairs("authorization", authorization), interceptors, last)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err), authorization)
+	}
+
+	err := run("/gobo.v1.ExampleService/GetExample", metadata.Pairs("authorization", basic("admin", "password")), interceptors, last)
+	assert.NoError(t, err)
+	assert.Equal(t, "admin", user)
+
+	// Health checks are public.
+	user = ""
+	assert.NoError(t, run("/grpc.health.v1.Health/Check", nil, interceptors, last))
+	assert.Empty(t, user)
+}
+
+// TestResolveTenant validates that the tenant is read from the metadata, as TenantMiddleware reads it
+// from the header.
+func TestResolveTenant(t *testing.T) {
+	config := middleware.TenantConfig{Header: "X-Tenant-ID", Default: "default", Allowed: []string{"acme"}}
+	interceptors := []interceptor{convertErrors, resolveTenant(config)}
+	var id string
+	last := func(ctx context.Context) error {
+		id = tenant.FromContext(ctx)
+		return nil
+	}
+
+	assert.NoError(t, run("/gobo.v1.ExampleService/GetExample", metadata.Pairs("x-tenant-id", "ACME"), interceptors, last))
+	assert.Equal(t, "acme", id)
+	assert.NoError(t, run("/gobo.v1.ExampleService/GetExample", nil, interceptors, last))
+	assert.Equal(t, "default", id)
+
+	err := run("/gobo.v1.ExampleService/GetExample", metadata.Pairs("x-tenant-id", "globex"), interceptors, last)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	err = run("/gobo.v1.ExampleService/GetExample", metadata.Pairs("x-tenant-id", "-invalid-"), interceptors, last)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// Without a default, calls must name a tenant.
+	interceptors = []interceptor{convertErrors, resolveTenant(middleware.TenantConfig{})}
+	err = run("/gobo.v1.ExampleService/GetExample", nil, interceptors, last)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, "tenant_required", errorInfo(err).GetReason())
+}
+
+// TestLimitTenants validates that the calls of each tenant are limited to its quota.
+func TestLimitTenants(t *testing.T) {
+	config := middleware.TenantRateLimitConfig{Max: 2, Limits: map[string]int{"acme": 3}, Expiration: time.Minute}
+	interceptors := []interceptor{convertErrors, resolveTenant(middleware.TenantConfig{}), limitTenants(config)}
+	last := func(ctx context.Context) error { return nil }
+	call := func(id string) error {
+		return run("/gobo.v1.ExampleService/GetExample", metadata.Pairs("x-tenant-id", id), interceptors, last)
+	}
+
+	assert.NoError(t, call("globex"))
+	assert.NoError(t, call("globex"))
+	err := call("globex")
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), "60")
+
+	// Other tenants have their own quota.
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, call("acme"))
+	}
+	assert.Equal(t, codes.ResourceExhausted, status.Code(call("acme")))
+}
+
+// TestTenantLimiterEviction validates that the windows of the tenants are removed once they expire.
+func TestTenantLimiterEviction(t *testing.T) {
+	limiter := newTenantLimiter(middleware.TenantRateLimitConfig{Max: 1, Expiration: time.Minute})
+	start := time.Now()
+	for i := 0; i < 100; i++ {
+		allowed, _ := limiter.allow(fmt.Sprintf("tenant-%d", i), start)
+		assert.True(t, allowed)
+	}
+	assert.Len(t, limiter.windows, 100)
+
+	// After a window, only the tenants called since are kept.
+	allowed, reset := limiter.allow("acme", start.Add(time.Minute))
+	assert.True(t, allowed)
+	assert.Equal(t, time.Minute, reset)
+	assert.Len(t, limiter.windows, 1)
+}
+
+// TestRecoverPanics validates that panics fail the call with INTERNAL and are counted by the metrics.
+func TestRecoverPanics(t *testing.T) {
+	metrics := NewMetrics()
+	interceptors := []interceptor{requestContext, logCalls, metrics.observe, recoverPanics, convertErrors}
+
+	err := run("/gobo.v1.ExampleService/GetExample", nil, interceptors, func(ctx context.Context) error {
+		panic("boom")
+	})
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.NotContains(t, status.Convert(err).Message(), "boom")
+
+	snapshot := metrics.Snapshot()
+	if assert.Len(t, snapshot, 1) {
+		assert.Equal(t, map[string]int64{"Internal": 1}, snapshot[0].Codes)
+		assert.Equal(t, int64(1), snapshot[0].Errors)
+	}
+}
+
+// TestStatusError validates the conversion of the service errors into gRPC statuses.
+func TestStatusError(t *testing.T) {
+	ctx := context.Background()
+	call := newCall("/gobo.v1.ExampleService/UpdateExample")
+
+	assert.Nil(t, statusError(ctx, call, nil))
+	assert.Equal(t, codes.NotFound, status.Code(statusError(ctx, call, gorm.ErrRecordNotFound)))
+	assert.Equal(t, codes.AlreadyExists, status.Code(statusError(ctx, call, gorm.ErrDuplicatedKey)))
+	assert.Equal(t, codes.FailedPrecondition, status.Code(statusError(ctx, call, service.ErrNotDeleted)))
+	assert.Equal(t, codes.Canceled, status.Code(statusError(ctx, call, context.Canceled)))
+	assert.Equal(t, codes.Internal, status.Code(statusError(ctx, call, errors.New("connection refused"))))
+	assert.NotContains(t, status.Convert(statusError(ctx, call, errors.New("connection refused"))).Message(), "refused")
+
+	// Version conflicts are aborted, with the current version.
+	current := models.Example{Name: "Current"}
+	current.Version = optimisticlock.Version{Int64: 3, Valid: true}
+	err := statusError(ctx, call, &service.VersionConflictError{Version: 2, Current: current})
+	assert.Equal(t, codes.Aborted, status.Code(err))
+	assert.Equal(t, "version_conflict", errorInfo(err).GetReason())
+	assert.Equal(t, "3", errorInfo(err).GetMetadata()["current_version"])
+
+	// Validation errors list the invalid fields, in the language of the call.
+	ctx = i18n.NewContext(ctx, "tr")
+	_, err = service.CreateExample(ctx, service.ExampleInput{})
+	err = statusError(ctx, call, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, i18n.T("tr", "error.invalid_fields"), status.Convert(err).Message())
+	var violations []*errdetails.BadRequest_FieldViolation
+	for _, detail := range status.Convert(err).Details() {
+		if request, ok := detail.(*errdetails.BadRequest); ok {
+			violations = request.GetFieldViolations()
+		}
+	}
+	if assert.Len(t, violations, 1) {
+		assert.Equal(t, "name", violations[0].GetField())
+		assert.Equal(t, i18n.T("tr", "validation.required", i18n.Args{"field": "name"}), violations[0].GetDescription())
+	}
+
+	// Errors already converted are kept.
+	converted := status.Error(codes.Unavailable, "unavailable")
+	assert.Equal(t, converted, statusError(ctx, call, converted))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(statusError(ctx, call, apperror.RateLimited(""))))
+}
+
+// errorInfo returns the ErrorInfo detail of a status error.
+func errorInfo(err error) *errdetails.ErrorInfo {
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			return info
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package rpc
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/status"
+)
+
+// DefaultMetrics collects the metrics of the application's gRPC server; they are exposed by
+// GET /admin/grpc/metrics.
+var DefaultMetrics = NewMetrics()
+
+// Metrics counts the gRPC calls of each method by status code, and their durations.
+type Metrics struct {
+	mu      sync.Mutex
+	methods map[string]*methodMetrics
+}
+
+// methodMetrics are the counters of a method.
+type methodMetrics struct {
+	codes map[string]int64
+	total time.Duration
+	max   time.Duration
+}
+
+// MethodMetrics are the metrics of a method, as returned by Snapshot.
+type MethodMetrics struct {
+	Method    string           `json:"method"`     // Full method name (e.g., "/gobo.v1.ExampleService/GetExample")
+	Calls     int64            `json:"calls"`      // Completed calls
+	Errors    int64            `json:"errors"`     // Calls that did not end with OK
+	Codes     map[string]int64 `json:"codes"`      // Calls by status code (e.g., {"OK": 10, "NotFound": 2})
+	AverageMs float64          `json:"average_ms"` // Average duration of the calls, in milliseconds
+	MaxMs     float64          `json:"max_ms"`     // Longest call, in milliseconds
+}
+
+// NewMetrics creates empty metrics.
+//
+// Returns:
+// - *Metrics: The metrics.
+func NewMetrics() *Metrics {
+	return &Metrics{methods: map[string]*methodMetrics{}}
+}
+
+// Record counts a completed call.
+//
+// Parameters:
+// - method (string): The full method name.
+// - code (string): The status code of the call (e.g., "OK").
+// - duration (time.Duration): The duration of the call.
+func (m *Metrics) Record(method, code string, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	counters, ok := m.methods[method]
+	if !ok {
+		counters = &methodMetrics{codes: map[string]int64{}}
+		m.methods[method] = counters
+	}
+	counters.codes[code]++
+	counters.total += duration
+	if duration > counters.max {
+		counters.max = duration
+	}
+}
+
+// Snapshot returns the metrics of the called methods, by method name.
+//
+// Returns:
+// - []MethodMetrics: The metrics of each called method.
+func (m *Metrics) Snapshot() []MethodMetrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	snapshot := make([]MethodMetrics, 0, len(m.methods))
+	for method, counters := range m.methods {
+		metrics := MethodMetrics{Method: method, Codes: make(map[string]int64, len(counters.codes))}
+		for code, calls := range counters.codes {
+			metrics.Codes[code] = calls
+			metrics.Calls += calls
+			if code != "OK" {
+				metrics.Errors += calls
+			}
+		}
+		if metrics.Calls > 0 {
+			metrics.AverageMs = milliseconds(counters.total) / float64(metrics.Calls)
+		}
+		metrics.MaxMs = milliseconds(counters.max)
+		snapshot = append(snapshot, metrics)
+	}
+	sort.Slice(snapshot, func(i, j int) bool { return snapshot[i].Method < snapshot[j].Method })
+	return snapshot
+}
+
+// milliseconds converts a duration into fractional milliseconds.
+func milliseconds(duration time.Duration) float64 {
+	return float64(duration) / float64(time.Millisecond)
+}
+
+// observe records the status code and duration of every call.
+func (m *Metrics) observe(ctx context.Context, call *call, next handler) error {
+	start := time.Now()
+	err := next(ctx)
+	m.Record(call.Method, status.Code(err).String(), time.Since(start))
+	return err
+}
@@ -0,0 +1,168 @@
+// Package rpc serves the application's gRPC API alongside the Fiber HTTP server.
+// The ExampleService and UserService (see proto/gobo/v1) call the same service layer as the HTTP handlers,
+// so both APIs apply the same validation, tenant scoping, audit log and domain events.
+// Interceptors mirror the HTTP middleware: request ID, audit actor and language, logging, metrics, panic
+// recovery, tenant resolution, Basic authentication and tenant rate limiting (see interceptors.go).
+// The server also implements the standard gRPC health checking protocol and server reflection.
+package rpc
+
+//go:generate protoc -I ../../proto --go_out=../.. --go_opt=module=gobo --go-grpc_out=../.. --go-grpc_opt=module=gobo gobo/v1/examples.proto gobo/v1/users.proto
+
+import (
+	"context"
+	"net"
+	"os"
+
+	"gobo/internal/middleware"
+	"gobo/internal/rpc/gobov1"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// Config defines the gRPC server.
+type Config struct {
+	Address   string                           // Address to listen on (defaults to ":50051")
+	Username  string                           // Username of the Basic authentication (defaults to middleware.AdminUsername, as the HTTP API)
+	Password  string                           // Password of the Basic authentication (defaults to middleware.AdminPassword, as the HTTP API)
+	Tenant    middleware.TenantConfig          // Tenant resolution; the header is read from the request metadata
+	RateLimit middleware.TenantRateLimitConfig // Request quotas of the tenants, counted separately from the HTTP requests
+	Metrics   *Metrics                         // Call metrics; nil uses DefaultMetrics
+}
+
+// DefaultConfig returns the default gRPC server configuration.
+//
+// Defaults:
+// - Address: ":50051", or the GRPC_ADDRESS environment variable (e.g., "127.0.0.1:9090")
+// - Username and Password: middleware.AdminUsername and middleware.AdminPassword
+// - Tenant: middleware.DefaultTenantConfig (the X-Tenant-ID metadata; domains and token claims do not apply, and every call is authenticated)
+// - RateLimit: middleware.DefaultTenantRateLimitConfig
+// - Metrics: DefaultMetrics
+//
+// Returns:
+// - Config: The default gRPC server configuration.
+func DefaultConfig() Config {
+	config := Config{
+		Address:   ":50051",
+		Username:  middleware.AdminUsername,
+		Password:  middleware.AdminPassword,
+		Tenant:    middleware.DefaultTenantConfig(),
+		RateLimit: middleware.DefaultTenantRateLimitConfig(),
+		Metrics:   DefaultMetrics,
+	}
+	if value := os.Getenv("GRPC_ADDRESS"); value != "" {
+		config.Address = value
+	}
+	return config
+}
+
+// Server is the gRPC server of the application.
+type Server struct {
+	config Config
+	server *grpc.Server
+	health *health.Server
+}
+
+// New creates the gRPC server and registers the application's services, health checking and reflection.
+//
+// Parameters:
+// - config (Config): The server configuration.
+//
+// Returns:
+// - *Server: The server, ready to serve.
+func New(config Config) *Server {
+	defaults := DefaultConfig()
+	if config.Address == "" {
+		config.Address = defaults.Address
+	}
+	if config.Metrics == nil {
+		config.Metrics = DefaultMetrics
+	}
+
+	// The interceptors run in this order, the first wrapping all the others (see interceptors.go).
+	// Panics are recovered inside logging and metrics, so that they are logged and counted as INTERNAL.
+	interceptors := []interceptor{
+		requestContext,
+		logCalls,
+		config.Metrics.observe,
+		recoverPanics,
+		convertErrors,
+		resolveTenant(config.Tenant),
+		authenticate(config.Username, config.Password),
+		limitTenants(config.RateLimit),
+	}
+	s := &Server{
+		config: config,
+		server: grpc.NewServer(
+			grpc.ChainUnaryInterceptor(unaryInterceptor(interceptors)),
+			grpc.ChainStreamInterceptor(streamInterceptor(interceptors)),
+		),
+		health: health.NewServer(),
+	}
+
+	gobov1.RegisterExampleServiceServer(s.server, &exampleServer{})
+	gobov1.RegisterUserServiceServer(s.server, &userServer{})
+	healthpb.RegisterHealthServer(s.server, s.health)
+	reflection.Register(s.server)
+
+	// Report the server and each service as serving.
+	for _, name := range []string{"", gobov1.ExampleService_ServiceDesc.ServiceName, gobov1.UserService_ServiceDesc.ServiceName} {
+		s.health.SetServingStatus(name, healthpb.HealthCheckResponse_SERVING)
+	}
+	return s
+}
+
+// Address returns the address the server listens on with ListenAndServe.
+func (s *Server) Address() string {
+	return s.config.Address
+}
+
+// Serve accepts connections on the listener until the server is shut down.
+//
+// Parameters:
+// - listener (net.Listener): The listener.
+//
+// Returns:
+// - error: An error if serving fails; nil after Shutdown.
+func (s *Server) Serve(listener net.Listener) error {
+	return s.server.Serve(listener)
+}
+
+// ListenAndServe listens on the configured address and accepts connections until the server is shut down.
+//
+// Returns:
+// - error: An error if the address cannot be listened on or serving fails; nil after Shutdown.
+func (s *Server) ListenAndServe() error {
+	listener, err := net.Listen("tcp", s.config.Address)
+	if err != nil {
+		return err
+	}
+	return s.Serve(listener)
+}
+
+// Shutdown stops the server gracefully: health checks report NOT_SERVING, new calls are refused and
+// running calls finish. Calls still running when the context is done are canceled.
+//
+// Parameters:
+// - ctx (context.Context): Bounds the wait for the running calls.
+//
+// Returns:
+// - error: The context's error if the running calls had to be canceled.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.health.Shutdown()
+	stopped := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		s.server.Stop()
+		<-stopped
+		return ctx.Err()
+	}
+}
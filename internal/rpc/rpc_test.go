@@ -0,0 +1,198 @@
+// Package rpc contains tests for the gRPC server, which is served in memory.
+// The Example and User services use the test database.
+package rpc
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"gobo/internal/db"
+	"gobo/internal/events"
+	"gobo/internal/middleware"
+	"gobo/internal/models"
+	"gobo/internal/rpc/gobov1"
+	"gobo/internal/testhelpers"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// serve starts a server with the given configuration on an in-memory listener and returns a client
+// connection to it; both are closed at the end of the test.
+func serve(t *testing.T, config Config) *grpc.ClientConn {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	server := New(config)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(func() { _ = server.Shutdown(context.Background()) })
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("[Error] Failed to connect to the server: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+// testConfig returns the configuration of the test servers: the default credentials, a tenant from the
+// X-Tenant-ID metadata, and separate metrics.
+func testConfig() Config {
+	return Config{
+		Username:  "admin",
+		Password:  "password",
+		Tenant:    middleware.TenantConfig{Header: "X-Tenant-ID", Default: "default"},
+		RateLimit: middleware.TenantRateLimitConfig{Max: 1000, Expiration: time.Minute},
+		Metrics:   NewMetrics(),
+	}
+}
+
+// authenticated returns a context with the admin credentials.
+func authenticated() context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", basic("admin", "password"))
+}
+
+// TestHealthAndReflection validates that health checks and reflection are served without authentication,
+// and that the services require it.
+func TestHealthAndReflection(t *testing.T) {
+	config := testConfig()
+	conn := serve(t, config)
+	ctx := context.Background()
+
+	health, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: "gobo.v1.ExampleService"})
+	assert.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, health.GetStatus())
+
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if assert.NoError(t, err) {
+		assert.NoError(t, stream.Send(&reflectionpb.ServerReflectionRequest{
+			MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+		}))
+		resp, err := stream.Recv()
+		if assert.NoError(t, err) {
+			services := []string{}
+			for _, service := range resp.GetListServicesResponse().GetService() {
+				services = append(services, service.GetName())
+			}
+			assert.Subset(t, services, []string{"gobo.v1.ExampleService", "gobo.v1.UserService", "grpc.health.v1.Health"})
+		}
+		_ = stream.CloseSend()
+	}
+
+	// The services require authentication; the call is logged and counted before reaching the database.
+	var header metadata.MD
+	_, err = gobov1.NewExampleServiceClient(conn).GetExample(ctx, &gobov1.GetExampleRequest{Id: 1}, grpc.Header(&header))
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.NotEmpty(t, header.Get("x-request-id"))
+	metrics := config.Metrics.Snapshot()
+	if assert.NotEmpty(t, metrics) {
+		last := metrics[0]
+		assert.Equal(t, "/gobo.v1.ExampleService/GetExample", last.Method)
+		assert.Equal(t, map[string]int64{"Unauthenticated": 1}, last.Codes)
+	}
+}
+
+// TestExampleService validates the ExampleService operations.
+func TestExampleService(t *testing.T) {
+	testhelpers.SetupGormTestDB(t, &models.Example{}, &events.Message{})
+	defer testhelpers.TeardownGormTestDB(&models.Example{}, &events.Message{})
+
+	client := gobov1.NewExampleServiceClient(serve(t, testConfig()))
+	ctx := authenticated()
+
+	// Create an example; invalid names are rejected.
+	created, err := client.CreateExample(ctx, &gobov1.CreateExampleRequest{Name: "gRPC Example"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "gRPC Example", created.GetName())
+	assert.Equal(t, int64(1), created.GetVersion())
+	assert.Nil(t, created.GetDeletedAt())
+	_, err = client.CreateExample(ctx, &gobov1.CreateExampleRequest{Name: " "})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// The creation is published as an event, as through the HTTP API.
+	var count int64
+	db.GormDB.Model(&events.Message{}).Where("type = ?", events.ExampleCreated).Count(&count)
+	assert.Equal(t, int64(1), count)
+
+	got, err := client.GetExample(ctx, &gobov1.GetExampleRequest{Id: created.GetId()})
+	assert.NoError(t, err)
+	assert.Equal(t, created.GetName(), got.GetName())
+
+	// Updates require the current version.
+	_, err = client.UpdateExample(ctx, &gobov1.UpdateExampleRequest{Id: created.GetId(), Name: "Renamed"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	updated, err := client.UpdateExample(ctx, &gobov1.UpdateExampleRequest{Id: created.GetId(), Name: "Renamed", Version: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), updated.GetVersion())
+	_, err = client.UpdateExample(ctx, &gobov1.UpdateExampleRequest{Id: created.GetId(), Name: "Stale", Version: 1})
+	assert.Equal(t, codes.Aborted, status.Code(err))
+	assert.Equal(t, "2", errorInfo(err).GetMetadata()["current_version"])
+
+	// Deleted examples are only listed on request.
+	_, err = client.DeleteExample(ctx, &gobov1.DeleteExampleRequest{Id: created.GetId()})
+	assert.NoError(t, err)
+	_, err = client.GetExample(ctx, &gobov1.GetExampleRequest{Id: created.GetId()})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	list, err := client.ListExamples(ctx, &gobov1.ListExamplesRequest{})
+	assert.NoError(t, err)
+	assert.Empty(t, list.GetExamples())
+	list, err = client.ListExamples(ctx, &gobov1.ListExamplesRequest{IncludeDeleted: true})
+	assert.NoError(t, err)
+	if assert.Len(t, list.GetExamples(), 1) {
+		assert.NotNil(t, list.GetExamples()[0].GetDeletedAt())
+	}
+}
+
+// TestUserService validates the UserService operations.
+func TestUserService(t *testing.T) {
+	testhelpers.SetupGormTestDB(t, &models.User{})
+	defer testhelpers.TeardownGormTestDB(&models.User{})
+
+	client := gobov1.NewUserServiceClient(serve(t, testConfig()))
+	ctx := authenticated()
+
+	created, err := client.CreateUser(ctx, &gobov1.CreateUserRequest{Username: "jane", Email: "jane@example.com", Password: "correct horse"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "jane", created.GetUsername())
+
+	// The password is stored hashed.
+	var stored models.User
+	assert.NoError(t, db.GormDB.First(&stored, created.GetId()).Error)
+	assert.NotEqual(t, "correct horse", stored.Password)
+
+	// Usernames are unique, and the fields are validated.
+	_, err = client.CreateUser(ctx, &gobov1.CreateUserRequest{Username: "jane", Email: "other@example.com", Password: "correct horse"})
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+	_, err = client.CreateUser(ctx, &gobov1.CreateUserRequest{Username: "john", Email: "not an email", Password: "short"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	updated, err := client.UpdateUser(ctx, &gobov1.UpdateUserRequest{Id: created.GetId(), Email: "jane@example.org"})
+	assert.NoError(t, err)
+	assert.Equal(t, "jane@example.org", updated.GetEmail())
+
+	list, err := client.ListUsers(ctx, &gobov1.ListUsersRequest{})
+	assert.NoError(t, err)
+	assert.Len(t, list.GetUsers(), 1)
+
+	_, err = client.DeleteUser(ctx, &gobov1.DeleteUserRequest{Id: created.GetId()})
+	assert.NoError(t, err)
+	_, err = client.GetUser(ctx, &gobov1.GetUserRequest{Id: created.GetId()})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = client.DeleteUser(ctx, &gobov1.DeleteUserRequest{Id: created.GetId()})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
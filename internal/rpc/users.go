@@ -0,0 +1,84 @@
+package rpc
+
+import (
+	"context"
+
+	"gobo/internal/models"
+	"gobo/internal/rpc/gobov1"
+	"gobo/internal/service"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// userServer implements the UserService with the service layer.
+type userServer struct {
+	gobov1.UnimplementedUserServiceServer
+}
+
+// newUser converts a user model into its message; the password hash is never returned.
+func newUser(user models.User) *gobov1.User {
+	return &gobov1.User{
+		Id:        uint64(user.ID),
+		Username:  user.Username,
+		Email:     user.Email,
+		CreatedAt: timestamppb.New(user.CreatedAt),
+		UpdatedAt: timestamppb.New(user.UpdatedAt),
+		Version:   user.Version.Int64,
+	}
+}
+
+// ListUsers returns the users of the tenant.
+func (s *userServer) ListUsers(ctx context.Context, req *gobov1.ListUsersRequest) (*gobov1.ListUsersResponse, error) {
+	users, err := service.ListUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp := &gobov1.ListUsersResponse{Users: make([]*gobov1.User, len(users))}
+	for i, user := range users {
+		resp.Users[i] = newUser(user)
+	}
+	return resp, nil
+}
+
+// GetUser returns a user of the tenant.
+func (s *userServer) GetUser(ctx context.Context, req *gobov1.GetUserRequest) (*gobov1.User, error) {
+	user, err := service.GetUser(ctx, uint(req.GetId()))
+	if err != nil {
+		return nil, err
+	}
+	return newUser(user), nil
+}
+
+// CreateUser creates a user.
+func (s *userServer) CreateUser(ctx context.Context, req *gobov1.CreateUserRequest) (*gobov1.User, error) {
+	user, err := service.CreateUser(ctx, service.CreateUserInput{
+		Username: req.GetUsername(),
+		Email:    req.GetEmail(),
+		Password: req.GetPassword(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newUser(user), nil
+}
+
+// UpdateUser changes the email and, if set, the password of a user.
+func (s *userServer) UpdateUser(ctx context.Context, req *gobov1.UpdateUserRequest) (*gobov1.User, error) {
+	user, err := service.UpdateUser(ctx, uint(req.GetId()), service.UpdateUserInput{
+		Email:    req.GetEmail(),
+		Password: req.GetPassword(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newUser(user), nil
+}
+
+// DeleteUser soft deletes a user.
+func (s *userServer) DeleteUser(ctx context.Context, req *gobov1.DeleteUserRequest) (*emptypb.Empty, error) {
+	if err := service.DeleteUser(ctx, uint(req.GetId())); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
@@ -0,0 +1,169 @@
+// Package scheduler runs periodic tasks (e.g., purges and statistics) once across all instances.
+// This file implements running the tasks on schedule.
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"gobo/internal/audit"
+	"gobo/internal/logger"
+
+	"go.uber.org/zap"
+)
+
+// maxMissed bounds the search for missed runs, e.g. of a task running every second after a long downtime.
+const maxMissed = 100000
+
+// missed returns the runs scheduled after last and up to now, at most the latest maxCatchUp.
+func (t *task) missed(last, now time.Time) []time.Time {
+	var slots []time.Time
+	for next, i := t.schedule.Next(last), 0; !next.After(now) && i < maxMissed; next, i = t.schedule.Next(next), i+1 {
+		if len(slots) == maxCatchUp {
+			slots = slots[1:]
+		}
+		slots = append(slots, next)
+	}
+	return slots
+}
+
+// next returns the scheduled time of the next run after the run scheduled at last, applying the
+// catch-up policy of the task to the runs missed until now.
+func (t *task) next(last, now time.Time) time.Time {
+	missed := t.missed(last, now)
+	switch {
+	case len(missed) == 0:
+		return t.schedule.Next(last)
+	case t.CatchUp == CatchUpOnce:
+		return missed[len(missed)-1]
+	case t.CatchUp == CatchUpAll:
+		return missed[0]
+	default:
+		return t.schedule.Next(now)
+	}
+}
+
+// Start starts running the registered tasks on schedule, until the returned function is called.
+// Every run is claimed with a Redis lock, so that it is executed by a single instance; the instances
+// that lose the lock skip the run. Runs are recorded in the scheduled_runs table.
+//
+// Returns:
+// - func(): Stops the scheduler and waits for running tasks, whose context is canceled, to return.
+func (s *Scheduler) Start() func() {
+	// Changes are recorded in the audit log with the scheduler as the actor.
+	ctx, cancel := context.WithCancel(audit.NewContext(context.Background(), audit.Actor{User: Actor}))
+	log := logger.FromContext(ctx).Named("scheduler")
+	if s.config.Client == nil {
+		log.Warn("Scheduler not started: Redis is not connected")
+		return cancel
+	}
+
+	s.mu.Lock()
+	tasks := slices.Clone(s.tasks)
+	s.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, t := range tasks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.loop(ctx, t, log.With(zap.String("task", t.Name)))
+		}()
+	}
+
+	return func() {
+		cancel()
+		wg.Wait()
+	}
+}
+
+// loop runs a task at its scheduled times until ctx is done.
+func (s *Scheduler) loop(ctx context.Context, t *task, log *zap.Logger) {
+	// Missed runs are counted from the last recorded run; new tasks start from now.
+	last := time.Now()
+	var previous Run
+	result := s.db.WithContext(ctx).Where("task = ?", t.Name).Order("scheduled_at DESC").Limit(1).Find(&previous)
+	if result.Error != nil {
+		log.Error("Failed to read the last run", zap.Error(result.Error))
+	} else if result.RowsAffected > 0 {
+		last = previous.ScheduledAt.Local() // Cron expressions use the location of the time.
+	}
+
+	for {
+		next := t.next(last, time.Now())
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		s.execute(ctx, t, next, log)
+		last = next
+	}
+}
+
+// execute claims the run of a task scheduled at the given time and executes it, unless another instance did.
+// Returns whether the run was executed by this instance.
+func (s *Scheduler) execute(ctx context.Context, t *task, scheduledAt time.Time, log *zap.Logger) bool {
+	// The lock outlives the run on the instances whose clocks are late; later, the run history prevents
+	// starting instances from catching up on it.
+	key := s.config.Prefix + t.Name + ":" + strconv.FormatInt(scheduledAt.Unix(), 10)
+	locked, err := s.config.Client.SetNX(ctx, key, s.config.Instance, t.Timeout+time.Minute).Result()
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Error("Failed to acquire the run lock", zap.Error(err))
+		}
+		return false
+	}
+	if !locked {
+		return false
+	}
+
+	// The run is recorded even if the scheduler is stopping.
+	recordCtx := context.WithoutCancel(ctx)
+	run := Run{Task: t.Name, ScheduledAt: scheduledAt, StartedAt: time.Now(), Status: StatusRunning, Instance: s.config.Instance}
+	if err := s.db.WithContext(recordCtx).Create(&run).Error; err != nil {
+		log.Error("Failed to record the run", zap.Error(err))
+	}
+
+	err = call(ctx, t)
+	finished := time.Now()
+	updates := map[string]interface{}{"finished_at": finished, "status": StatusSucceeded, "error": ""}
+	fields := []zap.Field{zap.Time("scheduledAt", scheduledAt), zap.Duration("duration", finished.Sub(run.StartedAt))}
+	if err != nil {
+		updates["status"], updates["error"] = StatusFailed, err.Error()
+		log.Error("Scheduled task failed", append(fields, zap.Error(err))...)
+	} else {
+		log.Info("Scheduled task completed", fields...)
+	}
+
+	if run.ID != 0 {
+		if err := s.db.WithContext(recordCtx).Model(&run).Updates(updates).Error; err != nil {
+			log.Error("Failed to record the run", zap.Error(err))
+		}
+	}
+	// Remove the history older than the retention period, except this run: it is the last run of the task.
+	cutoff := finished.Add(-s.config.Retention)
+	err = s.db.WithContext(recordCtx).Where("task = ? AND started_at < ? AND id <> ?", t.Name, cutoff, run.ID).Delete(&Run{}).Error
+	if err != nil {
+		log.Error("Failed to remove the old runs", zap.Error(err))
+	}
+	return true
+}
+
+// call runs a task with its timeout, converting panics to errors.
+func call(ctx context.Context, t *task) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, t.Timeout)
+	defer cancel()
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return t.Run(ctx)
+}
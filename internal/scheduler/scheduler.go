@@ -0,0 +1,203 @@
+// Package scheduler runs periodic tasks (e.g., purges and statistics) once across all instances.
+// Tasks are registered in code with a cron expression or an interval (see Scheduler.Add); each run is
+// claimed with a Redis lock, so that a single instance executes it, and recorded in the scheduled_runs table.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"gobo/internal/cache"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/robfig/cron/v3"
+	"gorm.io/gorm"
+)
+
+// Actor is the actor of the changes made by scheduled tasks in the audit log.
+const Actor = "system:scheduler"
+
+// Run states.
+const (
+	StatusRunning   = "running"   // The run has started
+	StatusSucceeded = "succeeded" // The task returned no error
+	StatusFailed    = "failed"    // The task returned an error, panicked or timed out
+)
+
+// CatchUp defines what happens to the runs missed while no instance was running (e.g., during a deployment).
+type CatchUp int
+
+const (
+	CatchUpSkip CatchUp = iota // Missed runs are skipped; the task runs at its next scheduled time (default)
+	CatchUpOnce                // The task runs once at startup for all missed runs, then on schedule
+	CatchUpAll                 // The task runs once at startup for every missed run, oldest first
+)
+
+// maxCatchUp is the maximum number of missed runs made up with CatchUpAll.
+const maxCatchUp = 100
+
+// Task is a periodic task.
+type Task struct {
+	Name     string                          // Unique name, recorded with the runs (e.g., "stats.recompute")
+	Schedule string                          // Cron expression (e.g., "0 3 * * *", "@hourly", "CRON_TZ=Europe/Istanbul 0 3 * * *") or Every(...)
+	Run      func(ctx context.Context) error // The task; its context is canceled after Timeout or at shutdown
+	CatchUp  CatchUp                         // What to do with the runs missed while no instance was running
+	Timeout  time.Duration                   // Maximum duration of a run (defaults to Config.Timeout)
+}
+
+// Every returns the schedule of a task running at a fixed interval (e.g., Every(5*time.Minute)).
+//
+// Parameters:
+// - interval (time.Duration): The time between two runs.
+//
+// Returns:
+// - string: The schedule.
+func Every(interval time.Duration) string {
+	return "@every " + interval.String()
+}
+
+// Run represents the "scheduled_runs" table: the history of the runs of the tasks.
+// Fields:
+// - ID: The primary key of the record.
+// - Task: The name of the task.
+// - ScheduledAt: The scheduled time of the run; a missed run made up later keeps its scheduled time.
+// - StartedAt, FinishedAt: When the run started and finished; FinishedAt is NULL while it runs.
+// - Status: running, succeeded or failed.
+// - Error: The error of a failed run.
+// - Instance: The instance that executed the run (hostname and process ID).
+type Run struct {
+	ID          uint       `gorm:"primaryKey"`                                               // Primary key.
+	Task        string     `gorm:"type:varchar(100);not null;index:idx_scheduled_runs_task"` // Task name.
+	ScheduledAt time.Time  `gorm:"not null;index:idx_scheduled_runs_task"`                   // Scheduled time.
+	StartedAt   time.Time  `gorm:"not null"`                                                 // Start time.
+	FinishedAt  *time.Time // End time; NULL while running.
+	Status      string     `gorm:"type:varchar(20);not null"` // running, succeeded or failed.
+	Error       string     `gorm:"type:text"`                 // Error of a failed run.
+	Instance    string     `gorm:"type:varchar(255)"`         // Instance that executed the run.
+}
+
+// TableName returns the table of the run history.
+func (Run) TableName() string {
+	return "scheduled_runs"
+}
+
+// Config defines the scheduler.
+type Config struct {
+	Prefix    string                // Prefix of the Redis lock keys (defaults to "scheduler:")
+	Timeout   time.Duration         // Default maximum duration of a run (defaults to 1 hour)
+	Retention time.Duration         // How long the run history is kept (defaults to 30 days)
+	Instance  string                // Name of this instance in the run history (defaults to hostname:pid)
+	Client    redis.UniversalClient // Redis client; nil uses cache.RedisClient
+}
+
+// DefaultConfig returns the default scheduler configuration.
+//
+// Defaults:
+// - Prefix: "scheduler:"
+// - Timeout: 1 hour
+// - Retention: 30 days, or the SCHEDULER_HISTORY_RETENTION environment variable (e.g., "720h")
+// - Instance: the hostname and process ID (e.g., "web-1:42")
+//
+// Returns:
+// - Config: The default scheduler configuration.
+func DefaultConfig() Config {
+	hostname, _ := os.Hostname()
+	config := Config{
+		Prefix:    "scheduler:",
+		Timeout:   time.Hour,
+		Retention: 30 * 24 * time.Hour,
+		Instance:  fmt.Sprintf("%s:%d", hostname, os.Getpid()),
+	}
+	if value, err := time.ParseDuration(os.Getenv("SCHEDULER_HISTORY_RETENTION")); err == nil && value > 0 {
+		config.Retention = value
+	}
+	return config
+}
+
+// interval is the schedule of Every. Unlike cron.ConstantDelaySchedule, its runs are aligned to
+// multiples of the interval since the Unix epoch, so that all instances schedule the same runs.
+type interval time.Duration
+
+// Next returns the first multiple of the interval after t.
+func (i interval) Next(t time.Time) time.Time {
+	return t.Truncate(time.Duration(i)).Add(time.Duration(i))
+}
+
+// task is a registered task with its parsed schedule.
+type task struct {
+	Task
+	schedule cron.Schedule
+}
+
+// Scheduler runs periodic tasks once across all instances. Register the tasks with Add, then start it with Start.
+type Scheduler struct {
+	db     *gorm.DB
+	config Config
+	mu     sync.Mutex
+	tasks  []*task
+}
+
+// New creates a scheduler.
+//
+// Parameters:
+// - db (*gorm.DB): The GORM database connection instance, storing the run history.
+// - config (Config): The scheduler configuration; zero values use DefaultConfig.
+//
+// Returns:
+// - *Scheduler: The scheduler.
+func New(db *gorm.DB, config Config) *Scheduler {
+	defaults := DefaultConfig()
+	if config.Prefix == "" {
+		config.Prefix = defaults.Prefix
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaults.Timeout
+	}
+	if config.Retention <= 0 {
+		config.Retention = defaults.Retention
+	}
+	if config.Instance == "" {
+		config.Instance = defaults.Instance
+	}
+	if config.Client == nil && cache.RedisClient != nil {
+		config.Client = cache.RedisClient
+	}
+	return &Scheduler{db: db, config: config}
+}
+
+// Add registers a task. Tasks added after Start are not run.
+//
+// Parameters:
+// - t (Task): The task.
+//
+// Returns:
+// - error: An error if the name is empty or already registered, or the schedule is invalid.
+func (s *Scheduler) Add(t Task) error {
+	if t.Name == "" || t.Run == nil {
+		return errors.New("scheduler: a task needs a name and a function")
+	}
+	schedule, err := cron.ParseStandard(t.Schedule)
+	if err != nil {
+		return fmt.Errorf("scheduler: invalid schedule of %s: %w", t.Name, err)
+	}
+	if constant, ok := schedule.(cron.ConstantDelaySchedule); ok {
+		schedule = interval(constant.Delay)
+	}
+	if t.Timeout <= 0 {
+		t.Timeout = s.config.Timeout
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, existing := range s.tasks {
+		if existing.Name == t.Name {
+			return fmt.Errorf("scheduler: task %s is already registered", t.Name)
+		}
+	}
+	s.tasks = append(s.tasks, &task{Task: t, schedule: schedule})
+	return nil
+}
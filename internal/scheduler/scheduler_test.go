@@ -0,0 +1,132 @@
+// Package scheduler contains tests for the scheduler.
+// These tests validate the schedules, the catch-up policies and running each task once across instances.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gobo/internal/audit"
+	"gobo/internal/db"
+	"gobo/internal/testhelpers"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// newTask returns a registered task with the given schedule and catch-up policy.
+func newTask(t *testing.T, schedule string, catchUp CatchUp) *task {
+	s := New(nil, Config{})
+	assert.NoError(t, s.Add(Task{Name: "test", Schedule: schedule, CatchUp: catchUp, Run: func(context.Context) error { return nil }}))
+	return s.tasks[0]
+}
+
+// TestAdd verifies that invalid and duplicate tasks are rejected.
+func TestAdd(t *testing.T) {
+	s := New(nil, Config{Timeout: time.Minute})
+	noop := func(context.Context) error { return nil }
+
+	assert.NoError(t, s.Add(Task{Name: "stats", Schedule: "0 3 * * *", Run: noop}))
+	assert.Equal(t, time.Minute, s.tasks[0].Timeout)
+	assert.Error(t, s.Add(Task{Name: "stats", Schedule: "@hourly", Run: noop}), "Expected a duplicate name to be rejected")
+	assert.Error(t, s.Add(Task{Name: "invalid", Schedule: "every day", Run: noop}))
+	assert.Error(t, s.Add(Task{Name: "empty", Schedule: "@hourly"}))
+}
+
+// TestNext verifies the next run of the tasks, with the catch-up policies.
+func TestNext(t *testing.T) {
+	at := func(hour, minute int) time.Time { return time.Date(2024, 1, 1, hour, minute, 0, 0, time.UTC) }
+
+	// Intervals are aligned, so that all instances schedule the same runs.
+	assert.Equal(t, at(10, 15), newTask(t, Every(15*time.Minute), CatchUpSkip).next(at(10, 7), at(10, 7)))
+
+	// Without missed runs, the task runs at its next scheduled time.
+	hourly := newTask(t, "0 * * * *", CatchUpSkip)
+	assert.Equal(t, at(14, 0), hourly.next(at(13, 0), at(13, 30)))
+
+	// Runs missed since 10:00 are skipped, made up once or made up one by one.
+	assert.Equal(t, at(14, 0), hourly.next(at(10, 0), at(13, 30)))
+	assert.Equal(t, at(13, 0), newTask(t, "0 * * * *", CatchUpOnce).next(at(10, 0), at(13, 30)))
+	assert.Equal(t, at(11, 0), newTask(t, "0 * * * *", CatchUpAll).next(at(10, 0), at(13, 30)))
+
+	// Catching up is limited to the latest runs.
+	assert.Equal(t, at(11, 51), newTask(t, "* * * * *", CatchUpAll).next(at(0, 0), at(13, 30)))
+}
+
+// TestExecute verifies that a run is executed by a single instance and recorded in the history.
+func TestExecute(t *testing.T) {
+	testhelpers.SetupGormTestDB(t, &Run{})
+	defer testhelpers.TeardownGormTestDB(&Run{})
+	client := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	defer client.Close()
+	ctx := audit.NewContext(context.Background(), audit.Actor{User: Actor})
+
+	var runs atomic.Int32
+	fail := errors.New("stats unavailable")
+	scheduledAt := time.Now().Truncate(time.Minute)
+	for i, instance := range []string{"web-1", "web-2"} {
+		s := New(db.GormDB, Config{Client: client, Instance: instance})
+		assert.NoError(t, s.Add(Task{Name: "stats", Schedule: "* * * * *", Run: func(ctx context.Context) error {
+			assert.Equal(t, Actor, audit.FromContext(ctx).User)
+			runs.Add(1)
+			return fail
+		}}))
+		assert.Equal(t, i == 0, s.execute(ctx, s.tasks[0], scheduledAt, zap.NewNop()), "Expected the first instance only to run the task")
+	}
+	assert.EqualValues(t, 1, runs.Load())
+
+	var history []Run
+	assert.NoError(t, db.GormDB.Find(&history).Error)
+	if assert.Len(t, history, 1) {
+		assert.Equal(t, "stats", history[0].Task)
+		assert.Equal(t, "web-1", history[0].Instance)
+		assert.Equal(t, StatusFailed, history[0].Status)
+		assert.Equal(t, "stats unavailable", history[0].Error)
+		assert.True(t, history[0].ScheduledAt.Equal(scheduledAt))
+		assert.NotNil(t, history[0].FinishedAt)
+	}
+
+	// Panics fail the run.
+	s := New(db.GormDB, Config{Client: client})
+	assert.NoError(t, s.Add(Task{Name: "panic", Schedule: "@hourly", Run: func(context.Context) error { panic("boom") }}))
+	assert.True(t, s.execute(ctx, s.tasks[0], scheduledAt, zap.NewNop()))
+	var run Run
+	assert.NoError(t, db.GormDB.Where("task = ?", "panic").First(&run).Error)
+	assert.Equal(t, "panic: boom", run.Error)
+}
+
+// TestStartCatchUp verifies that a task catches up on the runs missed since its last recorded run.
+func TestStartCatchUp(t *testing.T) {
+	testhelpers.SetupGormTestDB(t, &Run{})
+	defer testhelpers.TeardownGormTestDB(&Run{})
+	client := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	defer client.Close()
+
+	last := time.Now().Truncate(time.Hour).Add(-3 * time.Hour)
+	assert.NoError(t, db.GormDB.Create(&Run{Task: "stats", ScheduledAt: last, StartedAt: last, Status: StatusSucceeded}).Error)
+
+	ran := make(chan struct{}, 3)
+	s := New(db.GormDB, Config{Client: client})
+	assert.NoError(t, s.Add(Task{Name: "stats", Schedule: "@hourly", CatchUp: CatchUpOnce, Run: func(context.Context) error {
+		ran <- struct{}{}
+		return nil
+	}}))
+	stop := s.Start()
+	select {
+	case <-ran:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the missed runs to be made up")
+	}
+	stop()
+
+	var history []Run
+	assert.NoError(t, db.GormDB.Order("scheduled_at").Find(&history).Error)
+	if assert.Len(t, history, 2, "Expected the missed runs to be made up once") {
+		assert.True(t, history[1].ScheduledAt.Equal(last.Add(3*time.Hour)), "Expected the latest missed run to be recorded")
+	}
+}
@@ -0,0 +1,165 @@
+// Package search finds examples by their name with a pluggable backend.
+// This file contains the Postgres backend.
+package search
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+
+	"gobo/internal/models"
+	"gobo/internal/validation"
+
+	"gorm.io/gorm"
+)
+
+// Column is the tsvector column of the examples, generated from their name.
+const Column = "search_vector"
+
+// PostgresConfig defines the Postgres backend.
+type PostgresConfig struct {
+	Language string // Text search configuration of the tsvector column and the queries (defaults to "simple")
+}
+
+// DefaultPostgresConfig returns the default configuration of the Postgres backend.
+//
+// Defaults:
+// - Language: "simple" (no stemming nor stop words), or the SEARCH_LANGUAGE environment variable (e.g., "english")
+//
+// Returns:
+// - PostgresConfig: The default configuration.
+func DefaultPostgresConfig() PostgresConfig {
+	config := PostgresConfig{Language: "simple"}
+	if language := os.Getenv("SEARCH_LANGUAGE"); language != "" {
+		config.Language = language
+	}
+	return config
+}
+
+// Postgres searches the examples with the full-text search of Postgres and the trigram similarity of pg_trgm.
+// Words match the tsvector column of the examples, the last one as a prefix (e.g., "alp" matches "Alpha"), and
+// the whole text also matches names similar to it (e.g., "alpah" matches "Alpha"). Matches are ranked by the sum
+// of ts_rank and the trigram similarity.
+type Postgres struct {
+	db     *gorm.DB
+	config PostgresConfig
+}
+
+// NewPostgres creates the Postgres backend. Run Migrate before searching.
+//
+// Parameters:
+// - db (*gorm.DB): The database connection.
+// - config (PostgresConfig): The configuration.
+//
+// Returns:
+// - *Postgres: The backend.
+func NewPostgres(db *gorm.DB, config PostgresConfig) *Postgres {
+	return &Postgres{db: db, config: config}
+}
+
+// Migrate adds the tsvector column of the examples, generated from their name so that Postgres keeps it up to
+// date, and the GIN indexes of the column and of the trigrams of the name. It is idempotent.
+// If the language changes, the column must be dropped to be generated again.
+//
+// Returns:
+// - error: An error if the database is not Postgres or a statement fails (e.g., the pg_trgm extension is not
+// available).
+func (p *Postgres) Migrate() error {
+	if p.db.Dialector.Name() != "postgres" {
+		return fmt.Errorf("search: full-text search requires postgres, not %s", p.db.Dialector.Name())
+	}
+	statements := []string{
+		"CREATE EXTENSION IF NOT EXISTS pg_trgm",
+		fmt.Sprintf("ALTER TABLE examples ADD COLUMN IF NOT EXISTS %s tsvector "+
+			"GENERATED ALWAYS AS (to_tsvector(%s, coalesce(name, ''))) STORED", Column, quote(p.config.Language)),
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS idx_examples_%s ON examples USING GIN (%s)", Column, Column),
+		"CREATE INDEX IF NOT EXISTS idx_examples_name_trgm ON examples USING GIN (name gin_trgm_ops)",
+	}
+	return p.db.Transaction(func(tx *gorm.DB) error {
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return fmt.Errorf("search: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// hit is a row of the results.
+type hit struct {
+	models.Example `gorm:"embedded"`
+	Rank           float64
+	Snippet        string
+}
+
+// Search returns a page of the examples of the tenant matching the query, best matches first.
+//
+// Parameters:
+// - ctx (context.Context): The context of the request, carrying the tenant.
+// - query (Query): The searched text and the page.
+//
+// Returns:
+// - Result: The page of matching examples.
+// - error: A validation error if the page is invalid, or an error if the query fails.
+func (p *Postgres) Search(ctx context.Context, query Query) (Result, error) {
+	if err := validation.Struct(query.Page); err != nil {
+		return Result{}, err
+	}
+	language, text := quote(p.config.Language), strings.TrimSpace(query.Text)
+	tsquery := fmt.Sprintf("to_tsquery(%s, @tsquery)", language)
+	args := map[string]interface{}{"tsquery": prefixQuery(text), "text": text}
+
+	// The rows are scanned in a transaction, so that row-level security applies to them (see tenant.Plugin).
+	var total int64
+	hits := []hit{}
+	err := p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		matching := func() *gorm.DB {
+			return tx.Model(&models.Example{}).
+				Where(fmt.Sprintf("(%s @@ %s OR name %% @text)", Column, tsquery), args)
+		}
+		if err := matching().Count(&total).Error; err != nil || total == 0 {
+			return err
+		}
+
+		// The matching words are marked with control characters, since the names are escaped afterwards.
+		return matching().
+			Select("examples.*, "+
+				fmt.Sprintf("ts_rank(%s, to_tsquery(%s, ?)) + similarity(name, ?) AS rank, ", Column, language)+
+				fmt.Sprintf("ts_headline(%s, name, to_tsquery(%s, ?), ", language, language)+
+				"'StartSel=' || chr(2) || ', StopSel=' || chr(3) || ', HighlightAll=true') AS snippet",
+				args["tsquery"], text, args["tsquery"]).
+			Order("rank DESC, id").
+			Offset((query.Page.Number - 1) * query.Page.Size).
+			Limit(query.Page.Size).
+			Scan(&hits).Error
+	})
+	if err != nil {
+		return Result{}, err
+	}
+	result := Result{Hits: make([]Hit, len(hits)), Total: total}
+	for i, hit := range hits {
+		result.Hits[i] = Hit{Example: hit.Example, Rank: hit.Rank, Snippet: Highlight(hit.Snippet)}
+	}
+	return result, nil
+}
+
+// prefixQuery converts a text to a tsquery matching all its words, the last one as a prefix: "big red ca"
+// becomes "big & red & ca:*". Characters other than letters and digits separate words, so that the text cannot
+// inject tsquery operators. A text without words returns a query matching nothing.
+func prefixQuery(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		return ""
+	}
+	words[len(words)-1] += ":*"
+	return strings.Join(words, " & ")
+}
+
+// quote returns a string literal of SQL.
+func quote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
@@ -0,0 +1,71 @@
+// Package search contains tests for the Postgres backend, which use the test database.
+package search
+
+import (
+	"context"
+	"testing"
+
+	"gobo/internal/db"
+	"gobo/internal/models"
+	"gobo/internal/service"
+	"gobo/internal/testhelpers"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestPostgresSearch validates the prefix and fuzzy matching, ranking and snippets of the Postgres backend.
+func TestPostgresSearch(t *testing.T) {
+	testhelpers.SetupGormTestDB(t, &models.Example{})
+	defer testhelpers.TeardownGormTestDB(&models.Example{})
+	if db.GormDB.Dialector.Name() != "postgres" {
+		t.Skip("full-text search requires postgres")
+	}
+
+	searcher := NewPostgres(db.GormDB, PostgresConfig{Language: "simple"})
+	assert.NoError(t, searcher.Migrate())
+	assert.NoError(t, searcher.Migrate()) // Idempotent
+	for _, name := range []string{"Alpha", "Alphabet soup", "Red car", "Green <b>bus</b>", "Blue car"} {
+		assert.NoError(t, db.GormDB.Create(&models.Example{Name: name}).Error)
+	}
+	assert.NoError(t, db.GormDB.Delete(&models.Example{}, "name = ?", "Blue car").Error)
+	search := func(text string, page service.Page) Result {
+		result, err := searcher.Search(context.Background(), Query{Text: text, Page: page})
+		assert.NoError(t, err)
+		return result
+	}
+	names := func(result Result) []string {
+		names := []string{}
+		for _, hit := range result.Hits {
+			names = append(names, hit.Example.Name)
+		}
+		return names
+	}
+
+	// The last word matches as a prefix; exact matches rank first.
+	result := search("alpha", service.DefaultPage)
+	assert.Equal(t, []string{"Alpha", "Alphabet soup"}, names(result))
+	assert.Greater(t, result.Hits[0].Rank, result.Hits[1].Rank)
+	assert.Equal(t, "<mark>Alphabet</mark> soup", result.Hits[1].Snippet)
+
+	// All the words must match; deleted examples are not found.
+	result = search("red ca", service.DefaultPage)
+	assert.Equal(t, []string{"Red car"}, names(result))
+	assert.Equal(t, "<mark>Red</mark> <mark>car</mark>", result.Hits[0].Snippet)
+	assert.Equal(t, int64(1), search("car", service.DefaultPage).Total)
+
+	// Misspelled names match by similarity, and snippets are escaped.
+	assert.Equal(t, []string{"Alpha"}, names(search("alpah", service.DefaultPage)))
+	result = search("bus", service.DefaultPage)
+	if assert.Len(t, result.Hits, 1) {
+		assert.Contains(t, result.Hits[0].Snippet, "<mark>bus</mark>")
+		assert.NotContains(t, result.Hits[0].Snippet, "<b>")
+	}
+
+	// Results are paginated.
+	result = search("alp", service.Page{Number: 2, Size: 1})
+	assert.Equal(t, int64(2), result.Total)
+	assert.Equal(t, []string{"Alphabet soup"}, names(result))
+	assert.Empty(t, search("zzz", service.DefaultPage).Hits)
+	_, err := searcher.Search(context.Background(), Query{Text: "alp", Page: service.Page{Number: 1, Size: 500}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,65 @@
+// Package search finds examples by their name with a pluggable backend.
+// Handlers call the Searcher in Default, which the application sets up at startup; the Postgres backend (see
+// NewPostgres) ranks full-text matches of a tsvector column and fuzzy matches of pg_trgm. Another backend (e.g., a
+// search engine) can be plugged in by implementing Searcher.
+package search
+
+import (
+	"context"
+	"errors"
+	"html"
+	"strings"
+
+	"gobo/internal/models"
+	"gobo/internal/service"
+)
+
+// Default is the application's search backend, set up at startup.
+var Default Searcher
+
+// ErrUnavailable is returned when no search backend is set up.
+var ErrUnavailable = errors.New("search: no backend is set up")
+
+// Searcher finds the examples of the tenant of the context.
+type Searcher interface {
+	// Search returns a page of the examples matching the query, best matches first.
+	Search(ctx context.Context, query Query) (Result, error)
+}
+
+// Query defines a search.
+type Query struct {
+	Text string       // The searched words; the last word also matches as a prefix
+	Page service.Page // The page of results
+}
+
+// Hit is an example matching a query.
+type Hit struct {
+	Example models.Example // The example
+	Rank    float64        // Relevance of the example; higher ranks match better
+	Snippet string         // The name, HTML-escaped, with the matching words in <mark> elements
+}
+
+// Result is a page of the examples matching a query.
+type Result struct {
+	Hits  []Hit // The examples of the page, best matches first
+	Total int64 // The number of matching examples
+}
+
+// Markers delimiting the matching words in the snippets of the backends, replaced by <mark> elements once the
+// snippets are escaped (see Highlight).
+const (
+	StartMark = "\x02"
+	StopMark  = "\x03"
+)
+
+// Highlight escapes a snippet for HTML and replaces its markers with <mark> elements, so that the snippets can
+// be displayed as is without allowing names to inject markup.
+//
+// Parameters:
+// - snippet (string): The snippet, with the matching words between StartMark and StopMark.
+//
+// Returns:
+// - string: The HTML snippet.
+func Highlight(snippet string) string {
+	return strings.NewReplacer(StartMark, "<mark>", StopMark, "</mark>").Replace(html.EscapeString(snippet))
+}
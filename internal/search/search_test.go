@@ -0,0 +1,24 @@
+// Package search contains tests for the building of queries and snippets, which does not require a database.
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestPrefixQuery validates that texts are converted to tsqueries matching all their words, the last one as a
+// prefix, without tsquery operators.
+func TestPrefixQuery(t *testing.T) {
+	assert.Equal(t, "alp:*", prefixQuery("alp"))
+	assert.Equal(t, "big & red & ca:*", prefixQuery("  Big RED  ca"))
+	assert.Equal(t, "a & b & c:*", prefixQuery("a&b|!(c):*"))
+	assert.Equal(t, "çay & 2:*", prefixQuery("Çay 2"))
+	assert.Equal(t, "", prefixQuery("&|!"))
+}
+
+// TestHighlight validates that snippets are escaped before their markers are replaced.
+func TestHighlight(t *testing.T) {
+	assert.Equal(t, "<mark>Red</mark> &lt;b&gt;car&lt;/b&gt;", Highlight(StartMark+"Red"+StopMark+" <b>car</b>"))
+	assert.Equal(t, "Tom &amp; Jerry", Highlight("Tom & Jerry"))
+}
@@ -0,0 +1,203 @@
+// Package service implements the application's operations on its resources, independently of the transport.
+// This file contains the bulk operations on the examples.
+package service
+
+import (
+	"context"
+	"net/http"
+
+	"gobo/internal/apperror"
+	"gobo/internal/db"
+	"gobo/internal/events"
+	"gobo/internal/models"
+	"gobo/internal/validation"
+
+	"gorm.io/gorm"
+)
+
+// Bulk modes.
+const (
+	BulkAllOrNothing = "all_or_nothing" // All the operations are applied in a single transaction, or none
+	BulkBestEffort   = "best_effort"    // Each operation is applied independently of the others
+)
+
+// Bulk actions.
+const (
+	BulkCreate = "create" // Create an example
+	BulkUpdate = "update" // Rename an example with the expected version
+	BulkDelete = "delete" // Soft delete an example
+)
+
+// DefaultBulkBatchSize is the number of examples inserted per statement by default.
+const DefaultBulkBatchSize = 100
+
+// ErrBulkRolledBack is the error of the operations that were not applied because another operation of an
+// all-or-nothing request failed.
+var ErrBulkRolledBack = apperror.New(http.StatusFailedDependency, "rolled_back", "").WithKey("error.bulk_rolled_back")
+
+// BulkOperation is an operation of a bulk request.
+type BulkOperation struct {
+	Action  string `json:"action" validate:"required,oneof=create update delete"` // create, update or delete.
+	ID      uint   `json:"id" validate:"required_unless=Action create"`           // The ID of the updated or deleted example.
+	Name    string `json:"name"`                                                  // The name of the created or updated example.
+	Version int64  `json:"version" validate:"required_if=Action update"`          // The version being updated.
+}
+
+// BulkResult is the outcome of an operation of a bulk request.
+type BulkResult struct {
+	Action  string         // The action of the operation
+	Example models.Example // The created or updated example; zero for deletes and failed operations
+	Err     error          // Why the operation failed; nil if it was applied
+}
+
+// BulkExamples applies a list of operations on the examples of the tenant and returns the outcome of each one,
+// in the order of the operations. The operations are validated first. The created examples are inserted with
+// CreateInBatches, before the updates and deletes are applied in order; each change publishes its event.
+//
+// In the all-or-nothing mode, nothing is applied if an operation is invalid or fails: the failed operations
+// return their error and the others ErrBulkRolledBack. In the best-effort mode, the valid operations are applied
+// even if others fail; if a batch of creates fails, its examples are created one by one, so that only the
+// failing ones are rejected.
+//
+// Parameters:
+// - ctx (context.Context): The context of the request.
+// - operations ([]BulkOperation): The operations.
+// - mode (string): BulkAllOrNothing or BulkBestEffort.
+// - batchSize (int): The examples inserted per statement; zero or less uses DefaultBulkBatchSize.
+//
+// Returns:
+// - []BulkResult: The outcome of each operation.
+func BulkExamples(ctx context.Context, operations []BulkOperation, mode string, batchSize int) []BulkResult {
+	if batchSize <= 0 {
+		batchSize = DefaultBulkBatchSize
+	}
+	results := make([]BulkResult, len(operations))
+	creates := []int{} // Indexes of the valid creates
+	changes := []int{} // Indexes of the valid updates and deletes
+	for i, operation := range operations {
+		results[i].Action = operation.Action
+		if err := validateBulkOperation(operation); err != nil {
+			results[i].Err = err
+		} else if operation.Action == BulkCreate {
+			creates = append(creates, i)
+		} else {
+			changes = append(changes, i)
+		}
+	}
+
+	if mode == BulkBestEffort {
+		tx := db.GormDB.WithContext(ctx)
+		for start := 0; start < len(creates); start += batchSize {
+			createExamples(tx, operations, results, creates[start:min(start+batchSize, len(creates))], batchSize)
+		}
+		for _, i := range changes {
+			_ = tx.Transaction(func(tx *gorm.DB) error {
+				results[i].Example, results[i].Err = applyBulkChange(tx, operations[i])
+				return results[i].Err
+			})
+		}
+		return results
+	}
+
+	if len(creates)+len(changes) < len(operations) {
+		return rollBack(results)
+	}
+	err := db.GormDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		examples, err := insertExamples(tx, operations, creates, batchSize)
+		if err != nil {
+			for _, i := range creates {
+				results[i].Err = err
+			}
+			return err
+		}
+		for j, i := range creates {
+			results[i].Example = examples[j]
+		}
+		for _, i := range changes {
+			results[i].Example, results[i].Err = applyBulkChange(tx, operations[i])
+			if results[i].Err != nil {
+				return results[i].Err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return rollBack(results)
+	}
+	return results
+}
+
+// validateBulkOperation validates an operation and, for creates and updates, the fields of the example.
+func validateBulkOperation(operation BulkOperation) error {
+	if err := validation.Struct(operation); err != nil {
+		return err
+	}
+	if operation.Action == BulkDelete {
+		return nil
+	}
+	return validation.Struct(ExampleInput{Name: operation.Name})
+}
+
+// insertExamples inserts the examples of the given creates with CreateInBatches and publishes their events,
+// in the transaction.
+func insertExamples(tx *gorm.DB, operations []BulkOperation, indexes []int, batchSize int) ([]models.Example, error) {
+	if len(indexes) == 0 {
+		return nil, nil
+	}
+	examples := make([]models.Example, len(indexes))
+	for j, i := range indexes {
+		examples[j].Name = operations[i].Name
+	}
+	if err := tx.CreateInBatches(&examples, batchSize).Error; err != nil {
+		return nil, err
+	}
+	created := make([]events.Event, len(examples))
+	for j, example := range examples {
+		created[j] = exampleEvent(events.ExampleCreated, example)
+	}
+	return examples, events.Publish(tx, created...)
+}
+
+// createExamples creates a batch of examples in the best-effort mode: in a single transaction or, if it fails,
+// one by one.
+func createExamples(tx *gorm.DB, operations []BulkOperation, results []BulkResult, indexes []int, batchSize int) {
+	var examples []models.Example
+	err := tx.Transaction(func(tx *gorm.DB) error {
+		var err error
+		examples, err = insertExamples(tx, operations, indexes, batchSize)
+		return err
+	})
+	if err == nil {
+		for j, i := range indexes {
+			results[i].Example = examples[j]
+		}
+		return
+	}
+	if len(indexes) == 1 {
+		results[indexes[0]].Err = err
+		return
+	}
+	for _, i := range indexes {
+		createExamples(tx, operations, results, []int{i}, batchSize)
+	}
+}
+
+// applyBulkChange applies an update or a delete in the transaction.
+func applyBulkChange(tx *gorm.DB, operation BulkOperation) (models.Example, error) {
+	if operation.Action == BulkDelete {
+		return models.Example{}, deleteExample(tx, operation.ID)
+	}
+	return updateExample(tx, operation.ID, ExampleInput{Name: operation.Name}, operation.Version)
+}
+
+// rollBack marks the results of an all-or-nothing request that was not applied: the operations that did not fail
+// return ErrBulkRolledBack.
+func rollBack(results []BulkResult) []BulkResult {
+	for i := range results {
+		results[i].Example = models.Example{}
+		if results[i].Err == nil {
+			results[i].Err = ErrBulkRolledBack
+		}
+	}
+	return results
+}
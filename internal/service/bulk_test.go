@@ -0,0 +1,34 @@
+// Package service contains tests for the bulk operations that do not reach the database.
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gobo/internal/apperror"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestBulkValidation validates that an all-or-nothing request with an invalid operation is rolled back before
+// reaching the database.
+func TestBulkValidation(t *testing.T) {
+	results := BulkExamples(context.Background(), []BulkOperation{
+		{Action: BulkCreate, Name: "Alpha"},
+		{Action: BulkCreate, Name: " "},
+		{Action: BulkUpdate, ID: 1, Name: "Beta"},
+		{Action: BulkDelete},
+		{Action: "rename", ID: 1},
+	}, BulkAllOrNothing, 0)
+
+	assert.Len(t, results, 5)
+	assert.ErrorIs(t, results[0].Err, ErrBulkRolledBack)
+	assert.Equal(t, BulkCreate, results[0].Action)
+	for _, i := range []int{1, 2, 3, 4} {
+		var appErr *apperror.Error
+		if assert.True(t, errors.As(results[i].Err, &appErr), i) {
+			assert.Equal(t, 422, appErr.Status, i)
+		}
+	}
+}
@@ -0,0 +1,358 @@
+// Package service implements the application's operations on its resources, independently of the transport.
+// The HTTP handlers (see routes) and the gRPC server (see rpc) call the same functions, so that both apply the
+// same validation, tenant scoping, audit log and domain events. Queries use db.GormDB with the given context,
+// which carries the tenant and the user of the request.
+//
+// Errors are returned as is: validation errors as *apperror.Error, database errors as GORM errors; transports
+// convert them with apperror.From.
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"gobo/internal/apperror"
+	"gobo/internal/db"
+	"gobo/internal/events"
+	"gobo/internal/models"
+	"gobo/internal/validation"
+
+	"gorm.io/gorm"
+	"gorm.io/plugin/optimisticlock"
+)
+
+// Example is the representation of an example returned by the APIs and sent as the payload of its events.
+type Example struct {
+	ID        uint       `json:"id"`                   // The ID of the example.
+	Name      string     `json:"name"`                 // The name of the example.
+	CreatedAt time.Time  `json:"created_at"`           // When the example was created.
+	UpdatedAt time.Time  `json:"updated_at"`           // When the example was last updated.
+	DeletedAt *time.Time `json:"deleted_at,omitempty"` // When the example was soft deleted; omitted if it is not deleted.
+	Version   int64      `json:"version"`              // Version of the example, incremented on every update (also sent as ETag).
+}
+
+// NewExample converts an example model into its representation.
+//
+// Parameters:
+// - example (models.Example): The example model.
+//
+// Returns:
+// - Example: The representation of the example.
+func NewExample(example models.Example) Example {
+	response := Example{
+		ID:        example.ID,
+		Name:      example.Name,
+		CreatedAt: example.CreatedAt,
+		UpdatedAt: example.UpdatedAt,
+		Version:   example.Version.Int64,
+	}
+	if example.DeletedAt.Valid {
+		response.DeletedAt = &example.DeletedAt.Time
+	}
+	return response
+}
+
+// ExampleInput contains the fields of a created or renamed example.
+type ExampleInput struct {
+	Name string `json:"name" validate:"required,notblank,max=100"` // The name of the example.
+}
+
+// ErrNotDeleted is returned when restoring an example that is not deleted.
+var ErrNotDeleted = apperror.Conflict("").WithKey("error.not_deleted").WithCode("not_deleted")
+
+// VersionConflictError is returned when an example was modified since the version being updated was read.
+type VersionConflictError struct {
+	Version int64          // The version being updated.
+	Current models.Example // The current example.
+}
+
+// Error returns the error message.
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("version conflict: version %d was updated, current version is %d", e.Version, e.Current.Version.Int64)
+}
+
+// exampleEvent returns the domain event of a change of an example; its payload is the example.
+func exampleEvent(eventType string, example models.Example) events.Event {
+	return events.Event{
+		Type:          eventType,
+		AggregateType: "example",
+		AggregateID:   strconv.FormatUint(uint64(example.ID), 10),
+		Payload:       NewExample(example),
+	}
+}
+
+// ListExamples returns the examples of the tenant.
+//
+// Parameters:
+// - ctx (context.Context): The context of the request.
+// - includeDeleted (bool): Include the soft-deleted examples; callers must restrict it to administrators.
+//
+// Returns:
+// - []models.Example: The examples.
+// - error: An error if the query fails.
+func ListExamples(ctx context.Context, includeDeleted bool) ([]models.Example, error) {
+	query := db.GormDB.WithContext(ctx)
+	if includeDeleted {
+		query = query.Unscoped()
+	}
+	examples := []models.Example{}
+	if err := query.Find(&examples).Error; err != nil {
+		return nil, err
+	}
+	return examples, nil
+}
+
+// ExampleFilter restricts the listed examples. Zero values do not filter.
+type ExampleFilter struct {
+	Name           string // Case-insensitive substring of the name
+	IncludeDeleted bool   // Include the soft-deleted examples; callers must restrict it to administrators
+}
+
+// FindExamples returns a page of the examples of the tenant matching the filter, by ID.
+//
+// Parameters:
+// - ctx (context.Context): The context of the request.
+// - filter (ExampleFilter): The conditions.
+// - page (Page): The page to return.
+//
+// Returns:
+// - []models.Example: The examples of the page.
+// - int64: The number of matching examples.
+// - error: A validation error if the page is invalid, or an error if the query fails.
+func FindExamples(ctx context.Context, filter ExampleFilter, page Page) ([]models.Example, int64, error) {
+	examples := []models.Example{}
+	total, err := paginate(filteredExamples(ctx, filter), page, &examples)
+	if err != nil {
+		return nil, 0, err
+	}
+	return examples, total, nil
+}
+
+// ScanExamples reads the examples of the tenant matching the filter by ID, one batch at a time, so that all of
+// them can be processed without loading them in memory. Batches are read after the last ID of the previous one,
+// so that examples created or deleted meanwhile do not shift them.
+//
+// Parameters:
+// - ctx (context.Context): The context of the request.
+// - filter (ExampleFilter): The conditions.
+// - batchSize (int): The examples per batch.
+// - fn (func([]models.Example) error): Called with each batch; an error stops the scan.
+//
+// Returns:
+// - error: The error of fn, or an error if a query fails.
+func ScanExamples(ctx context.Context, filter ExampleFilter, batchSize int, fn func([]models.Example) error) error {
+	var last uint
+	for {
+		examples := []models.Example{}
+		err := filteredExamples(ctx, filter).Where("id > ?", last).Order("id").Limit(batchSize).Find(&examples).Error
+		if err != nil {
+			return err
+		}
+		if len(examples) == 0 {
+			return nil
+		}
+		if err := fn(examples); err != nil {
+			return err
+		}
+		if len(examples) < batchSize {
+			return nil
+		}
+		last = examples[len(examples)-1].ID
+	}
+}
+
+// filteredExamples returns the query of the examples of the tenant matching the filter.
+func filteredExamples(ctx context.Context, filter ExampleFilter) *gorm.DB {
+	query := db.GormDB.WithContext(ctx).Model(&models.Example{})
+	if filter.IncludeDeleted {
+		query = query.Unscoped()
+	}
+	if filter.Name != "" {
+		query = query.Where(`LOWER(name) LIKE ? ESCAPE '\'`, containing(filter.Name))
+	}
+	return query
+}
+
+// ExamplesByID returns the examples of the tenant with the given IDs, in a single query.
+//
+// Parameters:
+// - ctx (context.Context): The context of the request.
+// - ids ([]uint): The IDs of the examples.
+//
+// Returns:
+// - map[uint]models.Example: The examples, by ID; deleted and missing examples are left out.
+// - error: An error if the query fails.
+func ExamplesByID(ctx context.Context, ids []uint) (map[uint]models.Example, error) {
+	examples := []models.Example{}
+	if err := db.GormDB.WithContext(ctx).Where("id IN ?", ids).Find(&examples).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]models.Example, len(examples))
+	for _, example := range examples {
+		byID[example.ID] = example
+	}
+	return byID, nil
+}
+
+// GetExample returns an example of the tenant.
+//
+// Parameters:
+// - ctx (context.Context): The context of the request.
+// - id (uint): The ID of the example.
+//
+// Returns:
+// - models.Example: The example.
+// - error: gorm.ErrRecordNotFound if the example does not exist or is deleted.
+func GetExample(ctx context.Context, id uint) (models.Example, error) {
+	var example models.Example
+	err := db.GormDB.WithContext(ctx).First(&example, id).Error
+	return example, err
+}
+
+// CreateExample creates an example and publishes the example.created event.
+// The event is stored in the outbox in the same transaction, so it is sent if and only if the example exists.
+//
+// Parameters:
+// - ctx (context.Context): The context of the request.
+// - input (ExampleInput): The fields of the example.
+//
+// Returns:
+// - models.Example: The created example.
+// - error: A validation error (422), or an error if the example cannot be stored (e.g., a duplicate).
+func CreateExample(ctx context.Context, input ExampleInput) (models.Example, error) {
+	if err := validation.Struct(input); err != nil {
+		return models.Example{}, err
+	}
+	example := models.Example{Name: input.Name}
+	err := db.GormDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&example).Error; err != nil {
+			return err
+		}
+		return events.Publish(tx, exampleEvent(events.ExampleCreated, example))
+	})
+	return example, err
+}
+
+// UpdateExample renames an example if it still has the expected version ("WHERE version = ?"), increments its
+// version and publishes the example.updated event.
+//
+// Parameters:
+// - ctx (context.Context): The context of the request.
+// - id (uint): The ID of the example.
+// - input (ExampleInput): The new fields of the example.
+// - version (int64): The version being updated.
+//
+// Returns:
+// - models.Example: The updated example or, on a version conflict, the current example.
+// - error: A validation error, gorm.ErrRecordNotFound if the example does not exist, or a *VersionConflictError.
+func UpdateExample(ctx context.Context, id uint, input ExampleInput, version int64) (models.Example, error) {
+	if err := validation.Struct(input); err != nil {
+		return models.Example{}, err
+	}
+	var example models.Example
+	err := db.GormDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		example, err = updateExample(tx, id, input, version)
+		return err
+	})
+	var conflict *VersionConflictError
+	if errors.As(err, &conflict) {
+		return conflict.Current, err
+	}
+	if err != nil {
+		return models.Example{}, err
+	}
+	return example, nil
+}
+
+// updateExample renames an example in a transaction (see UpdateExample); the input must be valid.
+func updateExample(tx *gorm.DB, id uint, input ExampleInput, version int64) (models.Example, error) {
+	example := models.Example{}
+	example.ID = id
+	if version > 0 {
+		example.Version = optimisticlock.Version{Int64: version, Valid: true}
+	}
+	result := tx.Model(&example).Update("name", input.Name)
+	if result.Error != nil {
+		return models.Example{}, result.Error
+	}
+
+	// Read the current representation: not found if the example does not exist.
+	if err := tx.First(&example, id).Error; err != nil {
+		return models.Example{}, err
+	}
+	if result.RowsAffected == 0 {
+		return models.Example{}, &VersionConflictError{Version: version, Current: example}
+	}
+	return example, events.Publish(tx, exampleEvent(events.ExampleUpdated, example))
+}
+
+// DeleteExample soft deletes an example and publishes the example.deleted event.
+//
+// Parameters:
+// - ctx (context.Context): The context of the request.
+// - id (uint): The ID of the example.
+//
+// Returns:
+// - error: A not found error if the example does not exist or is already deleted.
+func DeleteExample(ctx context.Context, id uint) error {
+	return db.GormDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return deleteExample(tx, id)
+	})
+}
+
+// deleteExample soft deletes an example in a transaction (see DeleteExample).
+func deleteExample(tx *gorm.DB, id uint) error {
+	// Set deleted_at; already deleted examples are not matched.
+	result := tx.Delete(&models.Example{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return apperror.NotFound("").WithKey("error.not_found")
+	}
+
+	var example models.Example
+	if err := tx.Unscoped().First(&example, id).Error; err != nil {
+		return err
+	}
+	return events.Publish(tx, exampleEvent(events.ExampleDeleted, example))
+}
+
+// RestoreExample restores a soft-deleted example that has not been purged yet, and publishes the
+// example.restored event.
+//
+// Parameters:
+// - ctx (context.Context): The context of the request.
+// - id (uint): The ID of the example.
+//
+// Returns:
+// - models.Example: The restored example.
+// - error: gorm.ErrRecordNotFound if the example never existed or was purged, or ErrNotDeleted.
+func RestoreExample(ctx context.Context, id uint) (models.Example, error) {
+	// Look the example up including soft-deleted rows.
+	tx := db.GormDB.WithContext(ctx)
+	var example models.Example
+	if err := tx.Unscoped().First(&example, id).Error; err != nil {
+		return models.Example{}, err
+	}
+	if !example.DeletedAt.Valid {
+		return models.Example{}, ErrNotDeleted
+	}
+
+	var restored models.Example
+	err := tx.Transaction(func(tx *gorm.DB) error {
+		if _, err := models.Restore(tx, &models.Example{}, "id = ?", example.ID); err != nil {
+			return err
+		}
+		// Read into a new value: scanning NULL does not reset the DeletedAt already loaded.
+		if err := tx.First(&restored, example.ID).Error; err != nil {
+			return err
+		}
+		return events.Publish(tx, exampleEvent(events.ExampleRestored, restored))
+	})
+	return restored, err
+}
@@ -0,0 +1,84 @@
+// Package service implements the application's operations on its resources, independently of the transport.
+// This file contains the pagination and filtering of the lists.
+package service
+
+import (
+	"context"
+	"strings"
+
+	"gobo/internal/audit"
+	"gobo/internal/db"
+	"gobo/internal/validation"
+
+	"gorm.io/gorm"
+)
+
+// Page selects a page of a list.
+type Page struct {
+	Number int `json:"page" validate:"min=1"`             // 1-based page number.
+	Size   int `json:"pageSize" validate:"min=1,max=100"` // Items per page.
+}
+
+// DefaultPage is the first page of 20 items.
+var DefaultPage = Page{Number: 1, Size: 20}
+
+// paginate counts the rows matching the query, then reads the requested page, by ID.
+//
+// Parameters:
+// - query (*gorm.DB): The query, with its model and conditions.
+// - page (Page): The page to read.
+// - out (interface{}): A pointer to the slice receiving the page.
+//
+// Returns:
+// - int64: The number of matching rows.
+// - error: A validation error (422) if the page is invalid, or an error if the query fails.
+func paginate(query *gorm.DB, page Page, out interface{}) (int64, error) {
+	if err := validation.Struct(page); err != nil {
+		return 0, err
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return 0, err
+	}
+	err := query.Order("id").Offset((page.Number - 1) * page.Size).Limit(page.Size).Find(out).Error
+	return total, err
+}
+
+// containing returns the LIKE pattern matching the strings that contain the value, ignoring the case;
+// use it with "LOWER(column) LIKE ? ESCAPE '\'".
+func containing(value string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(strings.ToLower(value))
+	return "%" + escaped + "%"
+}
+
+// History returns the latest audit log entries of records, newest first, in a single query.
+// The entries beyond the limit are left out by the query, so that records changed often are not loaded whole.
+//
+// Parameters:
+// - ctx (context.Context): The context of the request.
+// - entity (string): The table of the records (e.g., "examples").
+// - ids ([]string): The primary keys of the records.
+// - limit (int): The maximum number of entries of each record.
+//
+// Returns:
+// - map[string][]audit.Entry: The entries of each record, by primary key.
+// - error: An error if the query fails.
+func History(ctx context.Context, entity string, ids []string, limit int) (map[string][]audit.Entry, error) {
+	tx := db.GormDB.WithContext(ctx)
+	ranked := tx.Model(&audit.Entry{}).
+		Select("*, ROW_NUMBER() OVER (PARTITION BY entity_id ORDER BY id DESC) AS position").
+		Where("entity = ? AND entity_id IN ?", entity, ids)
+	entries := []audit.Entry{}
+	err := tx.Table("(?) AS audit_logs", ranked).
+		Where("position <= ?", limit).
+		Order("id DESC").
+		Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	history := make(map[string][]audit.Entry, len(ids))
+	for _, entry := range entries {
+		history[entry.EntityID] = append(history[entry.EntityID], entry)
+	}
+	return history, nil
+}
@@ -0,0 +1,201 @@
+// Package service implements the application's operations on its resources, independently of the transport.
+// This file contains the operations on the users.
+package service
+
+import (
+	"context"
+
+	"gobo/internal/db"
+	"gobo/internal/models"
+	"gobo/internal/validation"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// CreateUserInput contains the fields of a created user.
+type CreateUserInput struct {
+	Username string `json:"username" validate:"required,notblank,max=100"`
+	Email    string `json:"email" validate:"required,email,max=100"`
+	Password string `json:"password" validate:"required,min=8,max=72"` // bcrypt only uses the first 72 bytes.
+}
+
+// UpdateUserInput contains the fields of an updated user.
+type UpdateUserInput struct {
+	Email    string `json:"email" validate:"required,email,max=100"`
+	Password string `json:"password" validate:"omitempty,min=8,max=72"` // Empty keeps the current password.
+}
+
+// hashPassword returns the bcrypt hash of a password, which is stored instead of the password.
+func hashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	return string(hash), err
+}
+
+// CheckPassword reports whether a password matches the stored hash of a user.
+//
+// Parameters:
+// - user (models.User): The user.
+// - password (string): The password to check.
+//
+// Returns:
+// - bool: True if the password matches.
+func CheckPassword(user models.User, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) == nil
+}
+
+// ListUsers returns the users of the tenant, by ID.
+//
+// Parameters:
+// - ctx (context.Context): The context of the request.
+//
+// Returns:
+// - []models.User: The users.
+// - error: An error if the query fails.
+func ListUsers(ctx context.Context) ([]models.User, error) {
+	users := []models.User{}
+	if err := db.GormDB.WithContext(ctx).Order("id").Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// UserFilter restricts the listed users. Zero values do not filter.
+type UserFilter struct {
+	Username string // Case-insensitive substring of the username
+	Email    string // Case-insensitive substring of the email address
+}
+
+// FindUsers returns a page of the users of the tenant matching the filter, by ID.
+//
+// Parameters:
+// - ctx (context.Context): The context of the request.
+// - filter (UserFilter): The conditions.
+// - page (Page): The page to return.
+//
+// Returns:
+// - []models.User: The users of the page.
+// - int64: The number of matching users.
+// - error: A validation error if the page is invalid, or an error if the query fails.
+func FindUsers(ctx context.Context, filter UserFilter, page Page) ([]models.User, int64, error) {
+	query := db.GormDB.WithContext(ctx).Model(&models.User{})
+	if filter.Username != "" {
+		query = query.Where(`LOWER(username) LIKE ? ESCAPE '\'`, containing(filter.Username))
+	}
+	if filter.Email != "" {
+		query = query.Where(`LOWER(email) LIKE ? ESCAPE '\'`, containing(filter.Email))
+	}
+	users := []models.User{}
+	total, err := paginate(query, page, &users)
+	if err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+// UsersByID returns the users of the tenant with the given IDs, in a single query.
+//
+// Parameters:
+// - ctx (context.Context): The context of the request.
+// - ids ([]uint): The IDs of the users.
+//
+// Returns:
+// - map[uint]models.User: The users, by ID; deleted and missing users are left out.
+// - error: An error if the query fails.
+func UsersByID(ctx context.Context, ids []uint) (map[uint]models.User, error) {
+	users := []models.User{}
+	if err := db.GormDB.WithContext(ctx).Where("id IN ?", ids).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]models.User, len(users))
+	for _, user := range users {
+		byID[user.ID] = user
+	}
+	return byID, nil
+}
+
+// GetUser returns a user of the tenant.
+//
+// Parameters:
+// - ctx (context.Context): The context of the request.
+// - id (uint): The ID of the user.
+//
+// Returns:
+// - models.User: The user.
+// - error: gorm.ErrRecordNotFound if the user does not exist or is deleted.
+func GetUser(ctx context.Context, id uint) (models.User, error) {
+	var user models.User
+	err := db.GormDB.WithContext(ctx).First(&user, id).Error
+	return user, err
+}
+
+// CreateUser creates a user; its password is stored hashed.
+//
+// Parameters:
+// - ctx (context.Context): The context of the request.
+// - input (CreateUserInput): The fields of the user.
+//
+// Returns:
+// - models.User: The created user.
+// - error: A validation error (422), or an error if the user cannot be stored (e.g., a duplicate username or email).
+func CreateUser(ctx context.Context, input CreateUserInput) (models.User, error) {
+	if err := validation.Struct(input); err != nil {
+		return models.User{}, err
+	}
+	hash, err := hashPassword(input.Password)
+	if err != nil {
+		return models.User{}, err
+	}
+	user := models.User{Username: input.Username, Email: input.Email, Password: hash}
+	err = db.GormDB.WithContext(ctx).Create(&user).Error
+	return user, err
+}
+
+// UpdateUser changes the email and, if set, the password of a user.
+//
+// Parameters:
+// - ctx (context.Context): The context of the request.
+// - id (uint): The ID of the user.
+// - input (UpdateUserInput): The new fields of the user.
+//
+// Returns:
+// - models.User: The updated user.
+// - error: A validation error, gorm.ErrRecordNotFound if the user does not exist, or an error if the user cannot be stored.
+func UpdateUser(ctx context.Context, id uint, input UpdateUserInput) (models.User, error) {
+	if err := validation.Struct(input); err != nil {
+		return models.User{}, err
+	}
+	changes := map[string]interface{}{"email": input.Email}
+	if input.Password != "" {
+		hash, err := hashPassword(input.Password)
+		if err != nil {
+			return models.User{}, err
+		}
+		changes["password"] = hash
+	}
+
+	var user models.User
+	err := db.GormDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&user, id).Error; err != nil {
+			return err
+		}
+		return tx.Model(&user).Updates(changes).Error
+	})
+	return user, err
+}
+
+// DeleteUser soft deletes a user.
+//
+// Parameters:
+// - ctx (context.Context): The context of the request.
+// - id (uint): The ID of the user.
+//
+// Returns:
+// - error: gorm.ErrRecordNotFound if the user does not exist or is already deleted.
+func DeleteUser(ctx context.Context, id uint) error {
+	result := db.GormDB.WithContext(ctx).Delete(&models.User{}, id)
+	if result.Error == nil && result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return result.Error
+}
@@ -0,0 +1,43 @@
+// Package service contains tests for the operations on the users that do not reach the database.
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gobo/internal/apperror"
+	"gobo/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCheckPassword validates that passwords are compared with their stored hash.
+func TestCheckPassword(t *testing.T) {
+	hash, err := hashPassword("correct horse")
+	assert.NoError(t, err)
+	assert.NotEqual(t, "correct horse", hash)
+
+	user := models.User{Password: hash}
+	assert.True(t, CheckPassword(user, "correct horse"))
+	assert.False(t, CheckPassword(user, "wrong horse"))
+}
+
+// TestUserInputValidation validates that invalid users are rejected before reaching the database.
+func TestUserInputValidation(t *testing.T) {
+	_, err := CreateUser(context.Background(), CreateUserInput{Username: "jane", Email: "not an email", Password: "short"})
+	var appErr *apperror.Error
+	if assert.True(t, errors.As(err, &appErr)) {
+		assert.Equal(t, 422, appErr.Status)
+		fields := []string{}
+		for _, field := range appErr.Fields {
+			fields = append(fields, field.Field)
+		}
+		assert.ElementsMatch(t, []string{"email", "password"}, fields)
+	}
+
+	// An empty password keeps the current one, but a new one must be long enough.
+	_, err = UpdateUser(context.Background(), 1, UpdateUserInput{Email: "jane@example.com", Password: "short"})
+	assert.True(t, errors.As(err, &appErr))
+	assert.Equal(t, "password", appErr.Fields[0].Field)
+}
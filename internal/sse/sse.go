@@ -0,0 +1,342 @@
+// Package sse pushes resource changes to clients in real time with Server-Sent Events.
+// Events are published to a topic (e.g., "example") with Broker.Publish: they are added to a bounded
+// history in a Redis Stream, for clients resuming with Last-Event-ID, and fanned out to the clients
+// connected to every instance with Redis pub/sub (see Broker.Start).
+package sse
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gobo/internal/cache"
+	"gobo/internal/logger"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// Errors returned by the broker.
+var (
+	ErrUnavailable = errors.New("sse: Redis is not connected")
+	ErrClosed      = errors.New("sse: the broker is stopped")
+)
+
+// Default is the application's broker, set up at startup.
+var Default *Broker
+
+// Event is a change pushed to the clients.
+type Event struct {
+	ID     string          `json:"id"`     // ID of the event in the history stream (e.g., "1760870400000-0"), sent as the SSE id
+	Type   string          `json:"type"`   // Event type, sent as the SSE event name (e.g., "example.created")
+	Tenant string          `json:"tenant"` // Tenant of the change; only its clients receive the event
+	Data   json.RawMessage `json:"data"`   // Data of the event (JSON), sent as the SSE data
+}
+
+// Config defines the broker.
+type Config struct {
+	Prefix      string                // Prefix of the Redis keys and channels, followed by the topic (defaults to "sse:")
+	HistorySize int64                 // Approximate number of events kept per topic for resuming clients (defaults to 1000)
+	Heartbeat   time.Duration         // Time between two heartbeats of an idle stream (defaults to 15 seconds)
+	Buffer      int                   // Events buffered per client; slower clients are disconnected and resume (defaults to 64)
+	Client      redis.UniversalClient // Redis client; nil uses cache.RedisClient
+}
+
+// DefaultConfig returns the default broker configuration.
+//
+// Defaults:
+// - Prefix: "sse:" (e.g., example events are kept in the "sse:example" stream and sent on the "sse:example" channel)
+// - HistorySize: 1000, or the SSE_HISTORY_SIZE environment variable
+// - Heartbeat: 15 seconds, or the SSE_HEARTBEAT environment variable (e.g., "30s")
+// - Buffer: 64
+//
+// Returns:
+// - Config: The default broker configuration.
+func DefaultConfig() Config {
+	config := Config{
+		Prefix:      "sse:",
+		HistorySize: 1000,
+		Heartbeat:   15 * time.Second,
+		Buffer:      64,
+	}
+	if value, err := strconv.ParseInt(os.Getenv("SSE_HISTORY_SIZE"), 10, 64); err == nil && value > 0 {
+		config.HistorySize = value
+	}
+	if value, err := time.ParseDuration(os.Getenv("SSE_HEARTBEAT")); err == nil && value > 0 {
+		config.Heartbeat = value
+	}
+	return config
+}
+
+// Broker publishes events and fans them out to the subscribed clients of this instance.
+// Its methods are safe for concurrent use.
+type Broker struct {
+	config      Config
+	mu          sync.Mutex
+	subscribers map[string]map[*Subscription]struct{} // Subscriptions by topic
+	closed      bool
+}
+
+// New creates a broker. Start it with Start to receive the events published by all instances.
+//
+// Parameters:
+// - config (Config): The broker configuration; zero values use DefaultConfig.
+//
+// Returns:
+// - *Broker: The broker.
+func New(config Config) *Broker {
+	defaults := DefaultConfig()
+	if config.Prefix == "" {
+		config.Prefix = defaults.Prefix
+	}
+	if config.HistorySize <= 0 {
+		config.HistorySize = defaults.HistorySize
+	}
+	if config.Heartbeat <= 0 {
+		config.Heartbeat = defaults.Heartbeat
+	}
+	if config.Buffer <= 0 {
+		config.Buffer = defaults.Buffer
+	}
+	return &Broker{config: config, subscribers: map[string]map[*Subscription]struct{}{}}
+}
+
+// client returns the Redis client of the broker.
+func (b *Broker) client() (redis.UniversalClient, error) {
+	if b.config.Client != nil {
+		return b.config.Client, nil
+	}
+	if cache.RedisClient != nil {
+		return cache.RedisClient, nil
+	}
+	return nil, ErrUnavailable
+}
+
+// Publish adds an event to the history of a topic and sends it to the clients of all instances.
+// The history and the channel are written by a single script, so that clients receive the events in history order.
+//
+// Parameters:
+// - ctx (context.Context): The context of the Redis commands.
+// - topic (string): The topic (e.g., "example").
+// - event (Event): The event; its ID is assigned by the history stream.
+//
+// Returns:
+// - string: The ID of the event.
+// - error: An error if Redis is not connected or the event cannot be stored.
+func (b *Broker) Publish(ctx context.Context, topic string, event Event) (string, error) {
+	client, err := b.client()
+	if err != nil {
+		return "", err
+	}
+	key := b.config.Prefix + topic
+
+	// The message contains the ID assigned by the stream, so both are written by a script.
+	event.ID = ""
+	message, err := json.Marshal(event)
+	if err != nil {
+		return "", err
+	}
+	id, err := publish.Run(ctx, client, []string{key}, b.config.HistorySize, event.Type, event.Tenant, string(event.Data), string(message)).Text()
+	return id, err
+}
+
+// publish adds an event to the history stream, trimmed to about ARGV[1] events, then publishes it on the
+// channel of the same name. ARGV[5] is the event encoded as JSON with an empty ID ('{"id":"",...'); the ID
+// is inserted as text, so that the data is not re-encoded (e.g., its numbers) by Lua.
+var publish = redis.NewScript(`
+local id = redis.call("XADD", KEYS[1], "MAXLEN", "~", ARGV[1], "*", "type", ARGV[2], "tenant", ARGV[3], "data", ARGV[4])
+redis.call("PUBLISH", KEYS[1], '{"id":"' .. id .. '"' .. string.sub(ARGV[5], 9))
+return id`)
+
+// Subscribe registers a client receiving the events of a topic and tenant published from now on.
+// Close the subscription when the client disconnects.
+//
+// Parameters:
+// - topic (string): The topic (e.g., "example").
+// - tenant (string): The tenant of the client.
+//
+// Returns:
+// - *Subscription: The subscription.
+// - error: ErrClosed if the broker is stopped.
+func (b *Broker) Subscribe(topic, tenant string) (*Subscription, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil, ErrClosed
+	}
+	s := &Subscription{broker: b, topic: topic, tenant: tenant, events: make(chan Event, b.config.Buffer)}
+	if b.subscribers[topic] == nil {
+		b.subscribers[topic] = map[*Subscription]struct{}{}
+	}
+	b.subscribers[topic][s] = struct{}{}
+	return s, nil
+}
+
+// History returns the events of a topic and tenant published after the given event, oldest first.
+//
+// Parameters:
+// - ctx (context.Context): The context of the Redis commands.
+// - topic (string): The topic (e.g., "example").
+// - tenant (string): The tenant of the client.
+// - after (string): The ID of the last event received by the client (Last-Event-ID).
+//
+// Returns:
+// - []Event: The events published after the given event.
+// - bool: False if the given event is no longer in the history (or is invalid): events may have been missed.
+// - error: An error if Redis is not connected or the history cannot be read.
+func (b *Broker) History(ctx context.Context, topic, tenant, after string) ([]Event, bool, error) {
+	client, err := b.client()
+	if err != nil {
+		return nil, false, err
+	}
+	if _, ok := parseID(after); !ok {
+		return nil, false, nil
+	}
+	key := b.config.Prefix + topic
+
+	// The last event received must still be in the history; otherwise, earlier events may have been trimmed.
+	last, err := client.XRange(ctx, key, after, after).Result()
+	if err != nil {
+		return nil, false, err
+	}
+	entries, err := client.XRange(ctx, key, "("+after, "+").Result()
+	if err != nil {
+		return nil, false, err
+	}
+	var events []Event
+	for _, entry := range entries {
+		event := Event{ID: entry.ID}
+		event.Type, _ = entry.Values["type"].(string)
+		event.Tenant, _ = entry.Values["tenant"].(string)
+		data, _ := entry.Values["data"].(string)
+		event.Data = json.RawMessage(data)
+		if event.Tenant == tenant {
+			events = append(events, event)
+		}
+	}
+	return events, len(last) > 0, nil
+}
+
+// Start receives the events published by all instances and sends them to the subscribed clients of this
+// instance, until the returned function is called.
+//
+// Returns:
+// - func(): Stops receiving events and closes the subscriptions, ending their streams.
+func (b *Broker) Start() func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	log := logger.FromContext(ctx).Named("sse")
+	done := make(chan struct{})
+
+	client, err := b.client()
+	if err != nil {
+		log.Warn("Event streams not started: Redis is not connected")
+		close(done)
+	} else {
+		// The subscription is re-established automatically if the connection is lost.
+		pubsub := client.PSubscribe(ctx, b.config.Prefix+"*")
+		go func() {
+			defer close(done)
+			defer pubsub.Close()
+			for message := range pubsub.Channel() {
+				var event Event
+				if err := json.Unmarshal([]byte(message.Payload), &event); err != nil {
+					log.Warn("Ignored an invalid event", zap.String("channel", message.Channel), zap.Error(err))
+					continue
+				}
+				b.dispatch(strings.TrimPrefix(message.Channel, b.config.Prefix), event)
+			}
+		}()
+		go func() {
+			<-ctx.Done()
+			_ = pubsub.Close()
+		}()
+	}
+
+	return func() {
+		cancel()
+		<-done
+		b.close()
+	}
+}
+
+// dispatch sends an event to the subscriptions of its topic and tenant. Subscriptions whose buffer is
+// full are closed: their clients reconnect and resume from the history.
+func (b *Broker) dispatch(topic string, event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for s := range b.subscribers[topic] {
+		if s.tenant != event.Tenant {
+			continue
+		}
+		select {
+		case s.events <- event:
+		default:
+			b.remove(s)
+		}
+	}
+}
+
+// close stops the broker and closes all subscriptions.
+func (b *Broker) close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for _, subscriptions := range b.subscribers {
+		for s := range subscriptions {
+			b.remove(s)
+		}
+	}
+}
+
+// remove unregisters a subscription and closes its channel. The caller holds b.mu.
+func (b *Broker) remove(s *Subscription) {
+	if _, ok := b.subscribers[s.topic][s]; !ok {
+		return
+	}
+	delete(b.subscribers[s.topic], s)
+	close(s.events)
+}
+
+// Subscription receives the events of a topic and tenant.
+type Subscription struct {
+	broker *Broker
+	topic  string
+	tenant string
+	events chan Event
+}
+
+// Events returns the channel of the events. It is closed when the subscription or the broker is closed,
+// or when the client is too slow.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Close unregisters the subscription.
+func (s *Subscription) Close() {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	s.broker.remove(s)
+}
+
+// parseID parses a stream ID ("<milliseconds>-<sequence>") into comparable parts.
+func parseID(id string) ([2]uint64, bool) {
+	ms, seq, found := strings.Cut(id, "-")
+	if !found {
+		return [2]uint64{}, false
+	}
+	a, errA := strconv.ParseUint(ms, 10, 64)
+	b, errB := strconv.ParseUint(seq, 10, 64)
+	return [2]uint64{a, b}, errA == nil && errB == nil
+}
+
+// newer reports whether the stream ID a is after the stream ID b.
+func newer(a, b string) bool {
+	x, _ := parseID(a)
+	y, _ := parseID(b)
+	return x[0] > y[0] || (x[0] == y[0] && x[1] > y[1])
+}
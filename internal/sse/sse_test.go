@@ -0,0 +1,199 @@
+// Package sse contains tests for the event streams.
+// These tests validate publishing events, fanning them out to the subscribed clients and resuming streams,
+// with an in-memory Redis server.
+package sse
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+// newBroker returns a started broker using a new in-memory Redis server.
+func newBroker(t *testing.T, config Config) *Broker {
+	client := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	config.Client = client
+	b := New(config)
+	stop := b.Start()
+	t.Cleanup(stop)
+	return b
+}
+
+// receive returns the next event of a subscription.
+func receive(t *testing.T, s *Subscription) (Event, bool) {
+	select {
+	case event, ok := <-s.Events():
+		return event, ok
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected an event")
+		return Event{}, false
+	}
+}
+
+// TestPublish verifies that events are sent to the subscriptions of their topic and tenant, with their ID.
+func TestPublish(t *testing.T) {
+	b := newBroker(t, Config{})
+	ctx := context.Background()
+	acme, err := b.Subscribe("example", "acme")
+	assert.NoError(t, err)
+	globex, err := b.Subscribe("example", "globex")
+	assert.NoError(t, err)
+	users, err := b.Subscribe("user", "acme")
+	assert.NoError(t, err)
+
+	// Wait for the pub/sub subscription of the broker.
+	assert.Eventually(t, func() bool {
+		return b.config.Client.PubSubNumPat(ctx).Val() == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	id, err := b.Publish(ctx, "example", Event{Type: "example.created", Tenant: "acme", Data: json.RawMessage(`{"id": 1, "price": 1.10}`)})
+	assert.NoError(t, err)
+	_, err = b.Publish(ctx, "example", Event{Type: "example.created", Tenant: "globex", Data: json.RawMessage(`{"id": 2}`)})
+	assert.NoError(t, err)
+
+	event, _ := receive(t, acme)
+	assert.Equal(t, id, event.ID)
+	assert.Equal(t, "example.created", event.Type)
+	assert.Equal(t, `{"id":1,"price":1.10}`, string(event.Data), "Expected the numbers to be sent unchanged")
+	event, _ = receive(t, globex)
+	assert.JSONEq(t, `{"id": 2}`, string(event.Data))
+	assert.Empty(t, users.Events(), "Expected other topics not to receive the event")
+	assert.Empty(t, acme.Events(), "Expected other tenants' events not to be received")
+}
+
+// TestHistory verifies that resuming clients receive the events they missed, or learn that events were trimmed.
+func TestHistory(t *testing.T) {
+	b := newBroker(t, Config{HistorySize: 10})
+	ctx := context.Background()
+
+	first, err := b.Publish(ctx, "example", Event{Type: "example.created", Tenant: "acme", Data: json.RawMessage(`{"id": 1}`)})
+	assert.NoError(t, err)
+	_, err = b.Publish(ctx, "example", Event{Type: "example.created", Tenant: "globex", Data: json.RawMessage(`{"id": 2}`)})
+	assert.NoError(t, err)
+	third, err := b.Publish(ctx, "example", Event{Type: "example.updated", Tenant: "acme", Data: json.RawMessage(`{"id": 1}`)})
+	assert.NoError(t, err)
+
+	events, complete, err := b.History(ctx, "example", "acme", first)
+	assert.NoError(t, err)
+	assert.True(t, complete)
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, third, events[0].ID)
+		assert.Equal(t, "example.updated", events[0].Type)
+	}
+
+	// The history is trimmed to about HistorySize events.
+	assert.NoError(t, b.config.Client.XTrimMaxLen(ctx, "sse:example", 1).Err())
+	_, complete, err = b.History(ctx, "example", "acme", first)
+	assert.NoError(t, err)
+	assert.False(t, complete, "Expected a trimmed event to be reported")
+	_, complete, err = b.History(ctx, "example", "acme", "invalid")
+	assert.NoError(t, err)
+	assert.False(t, complete)
+}
+
+// TestSlowClient verifies that clients that do not keep up are disconnected, and that stopping the broker ends the streams.
+func TestSlowClient(t *testing.T) {
+	b := New(Config{Buffer: 1})
+	slow, err := b.Subscribe("example", "acme")
+	assert.NoError(t, err)
+	other, err := b.Subscribe("example", "acme")
+	assert.NoError(t, err)
+
+	b.dispatch("example", Event{ID: "1-0", Tenant: "acme"})
+	<-other.Events()
+	b.dispatch("example", Event{ID: "2-0", Tenant: "acme"})
+	_, ok := <-slow.Events()
+	assert.True(t, ok)
+	_, ok = <-slow.Events()
+	assert.False(t, ok, "Expected the slow client to be disconnected")
+	event, ok := <-other.Events()
+	assert.True(t, ok)
+	assert.Equal(t, "2-0", event.ID)
+
+	b.close()
+	_, ok = <-other.Events()
+	assert.False(t, ok)
+	other.Close()
+	_, err = b.Subscribe("example", "acme")
+	assert.ErrorIs(t, err, ErrClosed)
+}
+
+// TestStream verifies the event stream: the missed events, the live events without duplicates, and heartbeats.
+func TestStream(t *testing.T) {
+	b := newBroker(t, Config{Heartbeat: 50 * time.Millisecond})
+	ctx := context.Background()
+	first, err := b.Publish(ctx, "example", Event{Type: "example.created", Tenant: "acme", Data: json.RawMessage(`{"id": 1}`)})
+	assert.NoError(t, err)
+	second, err := b.Publish(ctx, "example", Event{Type: "example.deleted", Tenant: "acme", Data: json.RawMessage("{\n\"id\": 1}")})
+	assert.NoError(t, err)
+
+	write, err := b.Stream("example", "acme", first)
+	assert.NoError(t, err)
+	reader, writer := io.Pipe()
+	go func() {
+		write(bufio.NewWriter(writer))
+		_ = writer.Close()
+	}()
+	// next returns the next line of the stream, skipping heartbeats.
+	lines := bufio.NewScanner(reader)
+	next := func() string {
+		for lines.Scan() {
+			if lines.Text() != ": heartbeat" {
+				return lines.Text()
+			}
+			lines.Scan() // Blank line ending the heartbeat
+		}
+		t.Fatal("Expected the stream to continue")
+		return ""
+	}
+
+	assert.Equal(t, "retry: 3000", next())
+	assert.Equal(t, "", next())
+	assert.Equal(t, "id: "+second, next())
+	assert.Equal(t, "event: example.deleted", next())
+	assert.Equal(t, "data: {", next())
+	assert.Equal(t, `data: "id": 1}`, next())
+	assert.Equal(t, "", next())
+
+	// Live events already sent from the history are skipped.
+	b.dispatch("example", Event{ID: second, Type: "example.deleted", Tenant: "acme"})
+	b.dispatch("example", Event{ID: "9999999999999-0", Type: "example.restored", Tenant: "acme", Data: json.RawMessage(`{"id": 1}`)})
+	assert.Equal(t, "id: 9999999999999-0", next())
+	assert.Equal(t, "event: example.restored", next())
+	assert.Equal(t, `data: {"id": 1}`, next())
+	assert.Equal(t, "", next())
+
+	assert.True(t, lines.Scan())
+	assert.Equal(t, ": heartbeat", lines.Text(), "Expected a heartbeat when no event is sent")
+
+	// The stream ends when the client disconnects.
+	_ = reader.Close()
+	assert.Eventually(t, func() bool {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		return len(b.subscribers["example"]) == 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// A client whose last event was trimmed is told to reload.
+	write, err = b.Stream("example", "acme", "1-0")
+	assert.NoError(t, err)
+	var output strings.Builder
+	done := make(chan struct{})
+	go func() {
+		w := bufio.NewWriter(&output)
+		write(w)
+		close(done)
+	}()
+	b.close()
+	<-done
+	assert.Contains(t, output.String(), "event: reset\ndata: {}\n\n")
+}
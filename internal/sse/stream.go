@@ -0,0 +1,135 @@
+// Package sse pushes resource changes to clients in real time with Server-Sent Events.
+// This file implements writing the event streams.
+package sse
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"gobo/internal/logger"
+
+	"go.uber.org/zap"
+)
+
+// EventReset tells a resuming client that events were missed (its Last-Event-ID is no longer in the history):
+// it should reload the resources, then process the following events.
+const EventReset = "reset"
+
+const (
+	historyTimeout = 5 * time.Second // Bounds reading the history when a client resumes
+	retryDelay     = 3 * time.Second // Time a disconnected client waits before reconnecting
+)
+
+// Stream subscribes a client to the events of a topic and tenant, and returns the function writing its
+// event stream (e.g., with fasthttp's SetBodyStreamWriter). The subscription is made before returning,
+// so that no event published after the call is missed.
+//
+// The stream:
+// - Starts with the events published after lastEventID, if set, from the history; if the event is no longer
+// in the history, a "reset" event is sent instead.
+// - Continues with the events published from now on, each with its ID, type and data.
+// - Sends a comment as a heartbeat when no event was sent for the heartbeat interval, so that proxies keep the
+// connection open and disconnected clients are detected.
+// - Ends when the client disconnects, the client is too slow, or the broker is stopped.
+//
+// Parameters:
+// - topic (string): The topic (e.g., "example").
+// - tenant (string): The tenant of the client; it only receives the events of its tenant.
+// - lastEventID (string): The Last-Event-ID header sent by a reconnecting client, or empty.
+//
+// Returns:
+// - func(*bufio.Writer): Writes the stream until it ends.
+// - error: ErrClosed if the broker is stopped.
+func (b *Broker) Stream(topic, tenant, lastEventID string) (func(w *bufio.Writer), error) {
+	subscription, err := b.Subscribe(topic, tenant)
+	if err != nil {
+		return nil, err
+	}
+	log := logger.FromContext(context.Background()).Named("sse").With(zap.String("topic", topic), zap.String("tenant", tenant))
+
+	return func(w *bufio.Writer) {
+		defer subscription.Close()
+
+		// Tell the client how long to wait before reconnecting, and send the headers right away.
+		if _, err := fmt.Fprintf(w, "retry: %d\n\n", retryDelay.Milliseconds()); err != nil || w.Flush() != nil {
+			return
+		}
+
+		// Resume from the history; live events already sent from the history are skipped.
+		last := ""
+		if lastEventID != "" {
+			ctx, cancel := context.WithTimeout(context.Background(), historyTimeout)
+			events, complete, err := b.History(ctx, topic, tenant, lastEventID)
+			cancel()
+			if err != nil {
+				log.Warn("Failed to read the event history", zap.Error(err))
+			}
+			if !complete {
+				events = []Event{{Type: EventReset, Data: []byte("{}")}}
+			}
+			for _, event := range events {
+				if err := Write(w, event); err != nil {
+					return
+				}
+				if event.ID != "" {
+					last = event.ID
+				}
+			}
+			if w.Flush() != nil {
+				return
+			}
+		}
+
+		heartbeat := time.NewTicker(b.config.Heartbeat)
+		defer heartbeat.Stop()
+		for {
+			select {
+			case event, ok := <-subscription.Events():
+				if !ok {
+					return
+				}
+				if last != "" && !newer(event.ID, last) {
+					continue
+				}
+				if Write(w, event) != nil || w.Flush() != nil {
+					return
+				}
+				heartbeat.Reset(b.config.Heartbeat)
+			case <-heartbeat.C:
+				if _, err := w.WriteString(": heartbeat\n\n"); err != nil || w.Flush() != nil {
+					return // The client disconnected.
+				}
+			}
+		}
+	}, nil
+}
+
+// Write writes an event in the Server-Sent Events format:
+//
+//	id: 1760870400000-0
+//	event: example.created
+//	data: {"id":1,"name":"Example"}
+//
+// Parameters:
+// - w (*bufio.Writer): The response body.
+// - event (Event): The event.
+//
+// Returns:
+// - error: An error if the event cannot be written.
+func Write(w *bufio.Writer, event Event) error {
+	if event.ID != "" {
+		fmt.Fprintf(w, "id: %s\n", event.ID)
+	}
+	if event.Type != "" {
+		fmt.Fprintf(w, "event: %s\n", event.Type)
+	}
+	// Every line of the data is sent as a data field.
+	for _, line := range bytes.Split(event.Data, []byte("\n")) {
+		fmt.Fprintf(w, "data: %s\n", line)
+	}
+	_, err := w.WriteString("\n")
+	return err
+}
@@ -0,0 +1,357 @@
+// Package tenant isolates the data of the customers (tenants) hosted on one deployment.
+// This file implements the GORM plugin scoping tenant-owned models to the tenant of the context.
+package tenant
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Column is the column holding the tenant of a tenant-owned record.
+const Column = "tenant_id"
+
+// scopedClause marks statements already scoped to their tenant (like GORM's soft delete does).
+const scopedClause = "tenant_scoped"
+
+// startedTransaction marks statements run in a transaction started by the plugin (like GORM's default transactions).
+const startedTransaction = "tenant:started_transaction"
+
+// ErrTenantMismatch is returned when a record of another tenant is written.
+var ErrTenantMismatch = errors.New("tenant: record belongs to another tenant")
+
+// Plugin is a GORM plugin scoping the statements on tenant-owned models to the tenant of the
+// statement's context (see NewContext):
+// - Queries, counts, updates and deletes get a "WHERE tenant_id = ?" condition.
+// - Created records get the tenant assigned; creating a record of another tenant fails with ErrTenantMismatch.
+// - Statements whose context has no tenant (e.g., background jobs and migrations) are not scoped.
+// - Raw SQL (db.Raw, db.Exec) is never scoped; row-level security protects it (see WithRowLevelSecurity).
+type Plugin struct {
+	models           []interface{}
+	tables           map[string]struct{}
+	rowLevelSecurity bool
+}
+
+// New creates the tenant plugin for the given models.
+// Register it with db.Use after connecting to the database.
+//
+// Parameters:
+// - models (...interface{}): Pointers to the tenant-owned models (e.g., &models.Example{}), which must have a tenant_id column.
+//
+// Returns:
+// - *Plugin: The plugin.
+func New(models ...interface{}) *Plugin {
+	return &Plugin{models: models}
+}
+
+// WithRowLevelSecurity makes the plugin set the settings read by the row-level security policies (see
+// EnableRowLevelSecurity) for every statement of a tenant or of the bypass (see Bypass), including raw SQL.
+// The settings only last for a transaction, so statements run outside one (e.g., queries and db.Exec) are run in
+// their own. Rows, Row and Scan return rows that are read after the statement, so they cannot be: outside a
+// transaction, they see no rows of the tenant-owned tables. It only has an effect on Postgres.
+//
+// Returns:
+// - *Plugin: The plugin.
+func (p *Plugin) WithRowLevelSecurity() *Plugin {
+	p.rowLevelSecurity = true
+	return p
+}
+
+// Name returns the name of the plugin.
+func (p *Plugin) Name() string {
+	return "tenant"
+}
+
+// Initialize resolves the tables of the tenant-owned models and registers the callbacks.
+// The scope is added before the other callbacks, so that they (e.g., the audit plugin) see it.
+func (p *Plugin) Initialize(db *gorm.DB) error {
+	p.tables = make(map[string]struct{}, len(p.models))
+	for _, model := range p.models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return fmt.Errorf("tenant: %w", err)
+		}
+		if stmt.Schema.LookUpField(Column) == nil {
+			return fmt.Errorf("tenant: %s has no %s column", stmt.Schema.Name, Column)
+		}
+		p.tables[stmt.Schema.Table] = struct{}{}
+	}
+
+	callbacks := db.Callback()
+	if err := callbacks.Create().Before("gorm:before_create").Register("tenant:assign", p.assign); err != nil {
+		return err
+	}
+	if err := callbacks.Query().Before("gorm:query").Register("tenant:scope", p.scope); err != nil {
+		return err
+	}
+	if err := callbacks.Row().Before("gorm:row").Register("tenant:scope", p.scope); err != nil {
+		return err
+	}
+	if err := callbacks.Update().Before("gorm:before_update").Register("tenant:scope", p.scopeUpdate); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().Before("gorm:before_delete").Register("tenant:scope", p.scopeDelete); err != nil {
+		return err
+	}
+	if !p.rowLevelSecurity || db.Dialector.Name() != "postgres" {
+		return nil
+	}
+
+	// Set the tenant of the transaction once GORM has begun it (before gorm:before_create, etc.), or begin one for the
+	// statement and commit it afterwards.
+	if err := callbacks.Create().Before("gorm:before_create").Register("tenant:begin_transaction", beginTransaction); err != nil {
+		return err
+	}
+	if err := callbacks.Create().After("gorm:commit_or_rollback_transaction").Register("tenant:commit_transaction", commitTransaction); err != nil {
+		return err
+	}
+	if err := callbacks.Update().Before("gorm:before_update").Register("tenant:begin_transaction", beginTransaction); err != nil {
+		return err
+	}
+	if err := callbacks.Update().After("gorm:commit_or_rollback_transaction").Register("tenant:commit_transaction", commitTransaction); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().Before("gorm:before_delete").Register("tenant:begin_transaction", beginTransaction); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().After("gorm:commit_or_rollback_transaction").Register("tenant:commit_transaction", commitTransaction); err != nil {
+		return err
+	}
+	if err := callbacks.Query().Before("gorm:query").Register("tenant:begin_transaction", beginTransaction); err != nil {
+		return err
+	}
+	if err := callbacks.Query().After("gorm:after_query").Register("tenant:commit_transaction", commitTransaction); err != nil {
+		return err
+	}
+	if err := callbacks.Raw().Before("gorm:raw").Register("tenant:begin_transaction", beginTransaction); err != nil {
+		return err
+	}
+	if err := callbacks.Raw().After("gorm:raw").Register("tenant:commit_transaction", commitTransaction); err != nil {
+		return err
+	}
+	// The rows are read after the statement, so the transaction cannot be committed: only enclosing ones are set.
+	return callbacks.Row().Before("gorm:row").Register("tenant:set_config", setConfig)
+}
+
+// owned returns the tenant of the statement if it changes or reads a tenant-owned model.
+func (p *Plugin) owned(db *gorm.DB) (string, bool) {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return "", false
+	}
+	if _, ok := p.tables[db.Statement.Schema.Table]; !ok {
+		return "", false
+	}
+	id := FromContext(db.Statement.Context)
+	return id, id != ""
+}
+
+// assign sets the tenant of the records being created.
+func (p *Plugin) assign(db *gorm.DB) {
+	id, ok := p.owned(db)
+	if !ok {
+		return
+	}
+	field := db.Statement.Schema.LookUpField(Column)
+	set := func(item reflect.Value) {
+		item = reflect.Indirect(item)
+		if item.Kind() != reflect.Struct || item.Type() != db.Statement.Schema.ModelType {
+			return
+		}
+		value, zero := field.ValueOf(db.Statement.Context, item)
+		switch {
+		case zero:
+			if err := field.Set(db.Statement.Context, item, id); err != nil {
+				_ = db.AddError(fmt.Errorf("tenant: %w", err))
+			}
+		case value != id:
+			_ = db.AddError(ErrTenantMismatch)
+		}
+	}
+
+	switch value := db.Statement.ReflectValue; value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			set(value.Index(i))
+		}
+	case reflect.Struct:
+		set(value)
+	}
+}
+
+// scope adds the tenant condition to a query or count.
+func (p *Plugin) scope(db *gorm.DB) {
+	if id, ok := p.owned(db); ok {
+		where(db, id)
+	}
+}
+
+// scopeUpdate adds the tenant condition to an update, and the tenant to a saved record without one.
+func (p *Plugin) scopeUpdate(db *gorm.DB) {
+	id, ok := p.owned(db)
+	if !ok {
+		return
+	}
+	// db.Save writes all columns of the record, including the tenant.
+	if reflect.ValueOf(db.Statement.Dest).Kind() == reflect.Ptr && db.Statement.Dest == db.Statement.Model {
+		p.assign(db)
+	}
+	// Let GORM reject updates without conditions (ErrMissingWhereClause) instead of updating all the tenant's records.
+	if conditioned(db) || db.AllowGlobalUpdate {
+		where(db, id)
+	}
+}
+
+// scopeDelete adds the tenant condition to a delete.
+func (p *Plugin) scopeDelete(db *gorm.DB) {
+	// Like updates, deletes without conditions are left for GORM to reject.
+	if id, ok := p.owned(db); ok && (conditioned(db) || db.AllowGlobalUpdate) {
+		where(db, id)
+	}
+}
+
+// where adds the "tenant_id = ?" condition to the statement, once.
+func where(db *gorm.DB, id string) {
+	if _, scoped := db.Statement.Clauses[scopedClause]; scoped {
+		return
+	}
+	column := clause.Column{Table: clause.CurrentTable, Name: Column}
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{clause.Eq{Column: column, Value: id}}})
+	db.Statement.Clauses[scopedClause] = clause.Clause{}
+}
+
+// conditioned reports whether an update or delete selects records: with a WHERE clause
+// or by the primary keys of its model values.
+func conditioned(db *gorm.DB) bool {
+	if _, ok := db.Statement.Clauses["WHERE"]; ok {
+		return true
+	}
+	field := db.Statement.Schema.PrioritizedPrimaryField
+	if field == nil {
+		return false
+	}
+	hasKey := func(item reflect.Value) bool {
+		item = reflect.Indirect(item)
+		if item.Kind() != reflect.Struct || item.Type() != db.Statement.Schema.ModelType {
+			return false
+		}
+		_, zero := field.ValueOf(db.Statement.Context, item)
+		return !zero
+	}
+	switch value := db.Statement.ReflectValue; value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if hasKey(value.Index(i)) {
+				return true
+			}
+		}
+	case reflect.Struct:
+		return hasKey(value)
+	}
+	return false
+}
+
+// beginTransaction begins a transaction for a statement of a tenant or of the bypass run outside one, and sets
+// the tenant of the transaction for the row-level security policies.
+func beginTransaction(db *gorm.DB) {
+	if !restricted(db) {
+		return
+	}
+	if _, ok := db.Statement.ConnPool.(gorm.TxCommitter); !ok {
+		tx := db.Begin()
+		if tx.Error != nil {
+			_ = db.AddError(fmt.Errorf("tenant: %w", tx.Error))
+			return
+		}
+		db.Statement.ConnPool = tx.Statement.ConnPool
+		db.InstanceSet(startedTransaction, true)
+	}
+	setConfig(db)
+}
+
+// commitTransaction commits the transaction begun for a statement, or rolls it back if the statement failed.
+func commitTransaction(db *gorm.DB) {
+	if _, ok := db.InstanceGet(startedTransaction); !ok {
+		return
+	}
+	if db.Error != nil {
+		db.Rollback()
+	} else {
+		db.Commit()
+	}
+	db.Statement.ConnPool = db.ConnPool
+}
+
+// setConfig sets the tenant, or the bypass, of the current transaction for the row-level security policies.
+// Outside transactions the settings would only last for the set_config statement itself, so it is skipped.
+func setConfig(db *gorm.DB) {
+	if !restricted(db) {
+		return
+	}
+	if _, ok := db.Statement.ConnPool.(gorm.TxCommitter); !ok {
+		return
+	}
+	id, bypass := FromContext(db.Statement.Context), ""
+	if id == "" {
+		bypass = "on"
+	}
+	const sql = "SELECT set_config('app.tenant_id', $1, true), set_config('app.tenant_bypass', $2, true)"
+	if _, err := db.Statement.ConnPool.ExecContext(db.Statement.Context, sql, id, bypass); err != nil {
+		_ = db.AddError(fmt.Errorf("tenant: %w", err))
+	}
+}
+
+// restricted reports whether a statement is run for a tenant or for the bypass, and must set it.
+func restricted(db *gorm.DB) bool {
+	if db.Error != nil || db.DryRun {
+		return false
+	}
+	return FromContext(db.Statement.Context) != "" || Bypassed(db.Statement.Context)
+}
+
+// EnableRowLevelSecurity creates Postgres row-level security policies restricting the rows of the
+// models' tables to the tenant set in the transaction (see Plugin.WithRowLevelSecurity).
+// The policies also apply to the table owner, and to raw SQL. Transactions without a tenant see no rows,
+// unless they bypass the policies (see Bypass): give that context to background jobs and migrations.
+// Run it after migrating the tables; it is safe to run repeatedly.
+//
+// Parameters:
+// - db (*gorm.DB): The GORM database connection instance.
+// - models (...interface{}): Pointers to the tenant-owned models.
+//
+// Returns:
+// - error: An error if a statement fails, or if the database is not Postgres.
+func EnableRowLevelSecurity(db *gorm.DB, models ...interface{}) error {
+	if db.Dialector.Name() != "postgres" {
+		return fmt.Errorf("tenant: row-level security requires postgres, not %s", db.Dialector.Name())
+	}
+	const policy = `current_setting('app.tenant_bypass', true) = 'on' OR ? = current_setting('app.tenant_id', true)`
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, model := range models {
+			stmt := &gorm.Statement{DB: tx}
+			if err := stmt.Parse(model); err != nil {
+				return fmt.Errorf("tenant: %w", err)
+			}
+			table := clause.Table{Name: stmt.Schema.Table}
+			column := clause.Column{Name: Column}
+			statements := []struct {
+				sql  string
+				vars []interface{}
+			}{
+				{"ALTER TABLE ? ENABLE ROW LEVEL SECURITY", []interface{}{table}},
+				{"ALTER TABLE ? FORCE ROW LEVEL SECURITY", []interface{}{table}},
+				{"DROP POLICY IF EXISTS tenant_isolation ON ?", []interface{}{table}},
+				{"CREATE POLICY tenant_isolation ON ? USING (" + policy + ") WITH CHECK (" + policy + ")", []interface{}{table, column, column}},
+			}
+			for _, statement := range statements {
+				if err := tx.Exec(statement.sql, statement.vars...).Error; err != nil {
+					return fmt.Errorf("tenant: %s: %w", stmt.Schema.Table, err)
+				}
+			}
+		}
+		return nil
+	})
+}
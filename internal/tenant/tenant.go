@@ -0,0 +1,91 @@
+// Package tenant isolates the data of the customers (tenants) hosted on one deployment.
+// The tenant of a request is stored in its context (see NewContext); a GORM plugin (see Plugin)
+// scopes every query and insert of tenant-owned models to it, so queries must use db.WithContext.
+package tenant
+
+import (
+	"context"
+	"regexp"
+)
+
+// DefaultID is the tenant of records created before multi-tenancy, and of requests
+// that do not name a tenant when no other default is configured.
+const DefaultID = "default"
+
+// idPattern matches valid tenant IDs: lowercase DNS labels, so that every tenant can have a subdomain.
+var idPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// contextKey is the type of the context key under which the tenant is stored.
+type contextKey struct{}
+
+// bypassKey is the type of the context key marking contexts not restricted to a tenant.
+type bypassKey struct{}
+
+// Valid reports whether the tenant ID is well-formed: 1 to 63 lowercase letters, digits
+// and hyphens, neither starting nor ending with a hyphen (e.g., "acme").
+//
+// Parameters:
+// - id (string): The tenant ID.
+//
+// Returns:
+// - bool: True if the ID is valid.
+func Valid(id string) bool {
+	return idPattern.MatchString(id)
+}
+
+// NewContext returns a copy of the context carrying the given tenant.
+//
+// Parameters:
+// - ctx (context.Context): The parent context.
+// - id (string): The tenant ID.
+//
+// Returns:
+// - context.Context: The derived context.
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the tenant stored in the context.
+// Contexts without a tenant (e.g., background jobs) are not scoped to a tenant.
+//
+// Parameters:
+// - ctx (context.Context): The context, typically the statement's or request's user context.
+//
+// Returns:
+// - string: The tenant ID, or an empty string if none is set.
+func FromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// Bypass returns a copy of the context whose statements bypass the row-level security policies (see
+// EnableRowLevelSecurity): background jobs working across tenants (e.g., the outbox relay) and migrations.
+// Statements of contexts with neither a tenant nor the bypass see no rows of the tenant-owned tables.
+//
+// Parameters:
+// - ctx (context.Context): The parent context.
+//
+// Returns:
+// - context.Context: The derived context.
+func Bypass(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassKey{}, true)
+}
+
+// Bypassed reports whether the statements of the context bypass the row-level security policies (see Bypass).
+// A tenant stored in the context takes precedence.
+//
+// Parameters:
+// - ctx (context.Context): The context, typically the statement's.
+//
+// Returns:
+// - bool: True if the context bypasses the policies.
+func Bypassed(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	bypassed, _ := ctx.Value(bypassKey{}).(bool)
+	return bypassed && FromContext(ctx) == ""
+}
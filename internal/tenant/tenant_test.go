@@ -0,0 +1,139 @@
+// Package tenant_test contains tests for the tenant package.
+// These tests validate the tenant IDs, the tenant context and the GORM plugin.
+package tenant_test
+
+import (
+	"context"
+	"testing"
+
+	"gobo/internal/db"
+	"gobo/internal/models"
+	"gobo/internal/tenant"
+	"gobo/internal/testhelpers"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// TestValid verifies that tenant IDs must be lowercase DNS labels.
+func TestValid(t *testing.T) {
+	for _, id := range []string{"acme", "a", "acme-corp", "42"} {
+		assert.True(t, tenant.Valid(id), id)
+	}
+	for _, id := range []string{"", "Acme", "-acme", "acme-", "acme.corp", "acme_corp", string(make([]byte, 64))} {
+		assert.False(t, tenant.Valid(id), id)
+	}
+}
+
+// TestContext verifies that the tenant is carried by the context.
+func TestContext(t *testing.T) {
+	assert.Empty(t, tenant.FromContext(context.Background()))
+	assert.Equal(t, "acme", tenant.FromContext(tenant.NewContext(context.Background(), "acme")))
+
+	// A tenant takes precedence over the bypass.
+	assert.False(t, tenant.Bypassed(context.Background()))
+	assert.True(t, tenant.Bypassed(tenant.Bypass(context.Background())))
+	assert.False(t, tenant.Bypassed(tenant.NewContext(tenant.Bypass(context.Background()), "acme")))
+}
+
+// TestPlugin verifies that queries, inserts, updates and deletes are scoped to the tenant of the context.
+func TestPlugin(t *testing.T) {
+	testhelpers.SetupGormTestDB(t, &models.Example{})
+	defer testhelpers.TeardownGormTestDB(&models.Example{})
+	conn := testhelpers.OpenGormTestDB(t)
+	assert.NoError(t, conn.Use(tenant.New(&models.Example{})))
+
+	acme := conn.WithContext(tenant.NewContext(context.Background(), "acme"))
+	globex := conn.WithContext(tenant.NewContext(context.Background(), "globex"))
+
+	// Inserts are assigned the tenant of the context.
+	acmeExample := models.Example{Name: "Acme"}
+	assert.NoError(t, acme.Create(&acmeExample).Error)
+	assert.Equal(t, "acme", acmeExample.TenantID)
+	globexExamples := []models.Example{{Name: "Globex 1"}, {Name: "Globex 2"}}
+	assert.NoError(t, globex.Create(&globexExamples).Error)
+	assert.Equal(t, "globex", globexExamples[1].TenantID)
+
+	// Records of another tenant cannot be created.
+	other := models.Example{Name: "Other"}
+	other.TenantID = "globex"
+	assert.ErrorIs(t, acme.Create(&other).Error, tenant.ErrTenantMismatch)
+
+	// Queries and counts only see the tenant's records.
+	var examples []models.Example
+	assert.NoError(t, acme.Find(&examples).Error)
+	assert.Len(t, examples, 1)
+	var count int64
+	assert.NoError(t, globex.Model(&models.Example{}).Count(&count).Error)
+	assert.Equal(t, int64(2), count)
+	assert.ErrorIs(t, globex.First(&models.Example{}, acmeExample.ID).Error, gorm.ErrRecordNotFound)
+
+	// Updates and deletes of another tenant's records change nothing.
+	result := globex.Model(&models.Example{}).Where("id = ?", acmeExample.ID).Update("name", "Taken")
+	assert.NoError(t, result.Error)
+	assert.Equal(t, int64(0), result.RowsAffected)
+	result = globex.Delete(&models.Example{}, acmeExample.ID)
+	assert.NoError(t, result.Error)
+	assert.Equal(t, int64(0), result.RowsAffected)
+
+	// Saving a record without its tenant keeps it in the tenant.
+	saved := models.Example{Name: "Saved"}
+	saved.ID = acmeExample.ID
+	saved.CreatedAt = acmeExample.CreatedAt
+	assert.NoError(t, acme.Save(&saved).Error)
+	assert.Equal(t, "acme", saved.TenantID)
+
+	// Updates and deletes without conditions are still rejected.
+	assert.ErrorIs(t, acme.Model(&models.Example{}).Update("name", "All").Error, gorm.ErrMissingWhereClause)
+	assert.ErrorIs(t, acme.Delete(&models.Example{}).Error, gorm.ErrMissingWhereClause)
+
+	// Without a tenant (e.g., background jobs), all records are visible.
+	assert.NoError(t, conn.Model(&models.Example{}).Count(&count).Error)
+	assert.Equal(t, int64(3), count)
+}
+
+// TestRowLevelSecurity verifies that the policies restrict every statement of a tenant to its rows, including
+// raw SQL outside transactions, that statements without a tenant see no rows, and that the bypass sees all of them.
+func TestRowLevelSecurity(t *testing.T) {
+	testhelpers.SetupGormTestDB(t, &models.Example{})
+	defer testhelpers.TeardownGormTestDB(&models.Example{})
+	if db.GormDB.Dialector.Name() != "postgres" {
+		t.Skip("row-level security requires postgres")
+	}
+	var bypasses bool
+	assert.NoError(t, db.GormDB.Raw("SELECT rolsuper OR rolbypassrls FROM pg_roles WHERE rolname = current_user").Scan(&bypasses).Error)
+	if bypasses {
+		t.Skip("the role of the test database bypasses row-level security")
+	}
+
+	conn := testhelpers.OpenGormTestDB(t)
+	assert.NoError(t, conn.Use(tenant.New(&models.Example{}).WithRowLevelSecurity()))
+	assert.NoError(t, tenant.EnableRowLevelSecurity(conn, &models.Example{}))
+	acme := conn.WithContext(tenant.NewContext(context.Background(), "acme"))
+	globex := conn.WithContext(tenant.NewContext(context.Background(), "globex"))
+	assert.NoError(t, acme.Create(&models.Example{Name: "Acme"}).Error)
+	assert.NoError(t, globex.Create(&models.Example{Name: "Globex"}).Error)
+	count := func(tx *gorm.DB) int64 {
+		var count int64
+		assert.NoError(t, tx.Raw("SELECT count(*) FROM examples").Find(&count).Error)
+		return count
+	}
+
+	// Raw SQL run outside a transaction only reads and writes the tenant's rows.
+	assert.Equal(t, int64(1), count(acme))
+	result := acme.Exec("UPDATE examples SET name = 'Renamed'")
+	assert.NoError(t, result.Error)
+	assert.Equal(t, int64(1), result.RowsAffected)
+
+	// Rows scanned in a transaction are restricted as well.
+	assert.NoError(t, globex.Transaction(func(tx *gorm.DB) error {
+		var names []string
+		err := tx.Raw("SELECT name FROM examples").Scan(&names).Error
+		assert.Equal(t, []string{"Globex"}, names)
+		return err
+	}))
+
+	// Statements without a tenant see no rows, unless they bypass the policies.
+	assert.Equal(t, int64(0), count(conn))
+	assert.Equal(t, int64(2), count(conn.WithContext(tenant.Bypass(context.Background()))))
+}
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/joho/godotenv"
+	"gorm.io/gorm"
 )
 
 // SetupGormTestDB initializes the test database using GORM.
@@ -56,3 +57,28 @@ func TeardownGormTestDB(models ...interface{}) {
 
 	log.Println("[Teardown] Test database teardown completed.")
 }
+
+// OpenGormTestDB opens a separate connection to the test database, closed at the end of the test.
+// Plugins registered on it with Use (e.g., the tenant and audit plugins) do not affect db.GormDB and the
+// other tests. Call SetupGormTestDB first.
+//
+// Parameters:
+// - t (*testing.T): The test context for managing test state.
+//
+// Returns:
+// - *gorm.DB: The connection.
+func OpenGormTestDB(t *testing.T) *gorm.DB {
+	conn, err := gorm.Open(db.GormDB.Dialector, &gorm.Config{
+		Logger:         db.GormDB.Logger,
+		TranslateError: db.GormDB.TranslateError,
+	})
+	if err != nil {
+		t.Fatalf("[Error] Error opening the test database: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := conn.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+	return conn
+}
@@ -0,0 +1,186 @@
+// Package transfer moves examples in and out of the application as files.
+// This file contains the exports.
+package transfer
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"gobo/internal/models"
+	"gobo/internal/service"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// exportColumns are the columns of the CSV and XLSX exports.
+var exportColumns = []string{"id", "name", "version", "created_at", "updated_at", "deleted_at"}
+
+// exportWriter writes the exported examples in a format.
+type exportWriter interface {
+	write(example service.Example) error
+	close() error
+}
+
+// Export writes the examples of the tenant matching the filter to w, by ID. The examples are read one page at
+// a time and written as they are read, so that exports of any size use constant memory (XLSX workbooks are
+// buffered by excelize, which spills large sheets to a temporary file).
+//
+// Parameters:
+// - ctx (context.Context): The context of the request, carrying the tenant.
+// - w (io.Writer): The destination of the file.
+// - format (string): FormatCSV, FormatNDJSON or FormatXLSX.
+// - filter (service.ExampleFilter): The exported examples.
+// - config (Config): The page size.
+//
+// Returns:
+// - error: An error if the format is unknown, a query fails or the file cannot be written.
+func Export(ctx context.Context, w io.Writer, format string, filter service.ExampleFilter, config Config) error {
+	writer, err := newExportWriter(w, format)
+	if err != nil {
+		return err
+	}
+	err = service.ScanExamples(ctx, filter, config.withDefaults().PageSize, func(examples []models.Example) error {
+		for _, example := range examples {
+			if err := writer.write(service.NewExample(example)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return writer.close()
+}
+
+// newExportWriter returns the writer of a format.
+func newExportWriter(w io.Writer, format string) (exportWriter, error) {
+	switch format {
+	case FormatCSV:
+		writer := &csvWriter{csv: csv.NewWriter(w)}
+		return writer, writer.csv.Write(exportColumns)
+	case FormatNDJSON:
+		return &ndjsonWriter{encoder: json.NewEncoder(w)}, nil
+	case FormatXLSX:
+		return newXLSXWriter(w)
+	default:
+		return nil, fmt.Errorf("transfer: unknown format %q", format)
+	}
+}
+
+// formulaPrefixes are the first characters that make spreadsheet applications read a cell as a formula.
+const formulaPrefixes = "=+-@\t\r"
+
+// exportRow returns the columns of an example, as strings. Text that a spreadsheet would read as a formula is
+// escaped (see escapeFormula).
+func exportRow(example service.Example) []string {
+	deletedAt := ""
+	if example.DeletedAt != nil {
+		deletedAt = example.DeletedAt.UTC().Format(time.RFC3339)
+	}
+	return []string{
+		strconv.FormatUint(uint64(example.ID), 10),
+		escapeFormula(example.Name),
+		strconv.FormatInt(example.Version, 10),
+		example.CreatedAt.UTC().Format(time.RFC3339),
+		example.UpdatedAt.UTC().Format(time.RFC3339),
+		deletedAt,
+	}
+}
+
+// escapeFormula prefixes a value starting like a formula with a quote, so that spreadsheet applications
+// display it as text instead of evaluating it (e.g., "=HYPERLINK(...)" is exported as "'=HYPERLINK(...)").
+func escapeFormula(value string) string {
+	if value != "" && strings.IndexByte(formulaPrefixes, value[0]) >= 0 {
+		return "'" + value
+	}
+	return value
+}
+
+// csvWriter writes a CSV file with a header row.
+type csvWriter struct {
+	csv *csv.Writer
+}
+
+func (w *csvWriter) write(example service.Example) error {
+	return w.csv.Write(exportRow(example))
+}
+
+func (w *csvWriter) close() error {
+	w.csv.Flush()
+	return w.csv.Error()
+}
+
+// ndjsonWriter writes the examples as they are returned by the API, one per line.
+type ndjsonWriter struct {
+	encoder *json.Encoder
+}
+
+func (w *ndjsonWriter) write(example service.Example) error {
+	return w.encoder.Encode(example)
+}
+
+func (w *ndjsonWriter) close() error {
+	return nil
+}
+
+// xlsxWriter writes a workbook with an "Examples" sheet, with the stream writer of excelize.
+type xlsxWriter struct {
+	w      io.Writer
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	row    int
+}
+
+// newXLSXWriter creates the workbook and writes the header row.
+func newXLSXWriter(w io.Writer) (*xlsxWriter, error) {
+	file := excelize.NewFile()
+	if err := file.SetSheetName("Sheet1", "Examples"); err != nil {
+		return nil, err
+	}
+	stream, err := file.NewStreamWriter("Examples")
+	if err != nil {
+		return nil, err
+	}
+	writer := &xlsxWriter{w: w, file: file, stream: stream}
+	header := make([]interface{}, len(exportColumns))
+	for i, column := range exportColumns {
+		header[i] = column
+	}
+	return writer, writer.setRow(header)
+}
+
+// setRow writes the next row of the sheet.
+func (w *xlsxWriter) setRow(values []interface{}) error {
+	w.row++
+	cell, err := excelize.CoordinatesToCellName(1, w.row)
+	if err != nil {
+		return err
+	}
+	return w.stream.SetRow(cell, values)
+}
+
+func (w *xlsxWriter) write(example service.Example) error {
+	row := exportRow(example)
+	values := make([]interface{}, len(row))
+	for i, value := range row {
+		values[i] = value
+	}
+	// IDs and versions are written as numbers.
+	values[0], values[2] = example.ID, example.Version
+	return w.setRow(values)
+}
+
+func (w *xlsxWriter) close() error {
+	defer w.file.Close()
+	if err := w.stream.Flush(); err != nil {
+		return err
+	}
+	return w.file.Write(w.w)
+}
@@ -0,0 +1,251 @@
+// Package transfer moves examples in and out of the application as files.
+// This file contains the imports.
+package transfer
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"gobo/internal/apperror"
+	"gobo/internal/i18n"
+	"gobo/internal/service"
+)
+
+// maxLineBytes is the maximum length of a line of an NDJSON file.
+const maxLineBytes = 1 << 20
+
+// Errors of the imported files, which stop the import.
+var (
+	ErrUnsupportedFormat = apperror.New(http.StatusUnprocessableEntity, "unsupported_format", "").WithKey("error.import_unsupported_format")
+	ErrMissingColumn     = apperror.New(http.StatusUnprocessableEntity, "missing_column", "").WithKey("error.import_missing_column", i18n.Args{"column": "name"})
+	ErrLineTooLong       = apperror.New(http.StatusUnprocessableEntity, "line_too_long", "").WithKey("error.import_line_too_long", i18n.Args{"max": maxLineBytes})
+)
+
+// RowError is the error of a rejected row of an imported file.
+type RowError struct {
+	Row     int    `json:"row"`             // The line of the row in the file (the header of a CSV file is line 1).
+	Field   string `json:"field,omitempty"` // The invalid column, if the error concerns a single one.
+	Code    string `json:"code"`            // Machine-readable reason (e.g., "required", "malformed").
+	Message string `json:"message"`         // Human-readable description.
+}
+
+// Report is the outcome of an import.
+type Report struct {
+	Rows    int        `json:"rows"`    // The rows of the file.
+	Created int        `json:"created"` // The created examples.
+	Failed  int        `json:"failed"`  // The rejected rows.
+	Errors  []RowError `json:"errors"`  // The errors of the rejected rows, up to Config.MaxErrors.
+}
+
+// row is a row read from an imported file.
+type row struct {
+	line int    // The line of the row in the file
+	name string // The name of the example
+	err  error  // Why the row could not be read; the row is rejected
+}
+
+// rowReader reads the rows of an imported file; it returns io.EOF after the last one.
+type rowReader interface {
+	next() (row, error)
+}
+
+// ImportExamples creates an example for each row of a CSV or NDJSON file. CSV files have a header row with a
+// "name" column, and NDJSON files an object with a "name" member per line; other columns and members (e.g., the
+// id and version of exported files) are ignored. Each row is validated, and the valid rows are created in
+// batches in the best-effort mode of service.BulkExamples: rejected rows do not prevent the others from being
+// created, and are reported with their line.
+//
+// Parameters:
+// - ctx (context.Context): The context of the request or job, carrying the tenant and the actor.
+// - r (io.Reader): The file.
+// - format (string): FormatCSV or FormatNDJSON.
+// - config (Config): The batch size and the maximum number of reported errors.
+//
+// Returns:
+// - Report: The counts and the errors of the rejected rows.
+// - error: A 422 error if the format is not supported, the CSV header has no "name" column or a line is too long,
+// or an error if the file cannot be read. Rows created before the error are counted in the report.
+func ImportExamples(ctx context.Context, r io.Reader, format string, config Config) (Report, error) {
+	config = config.withDefaults()
+	report := Report{Errors: []RowError{}}
+	reader, err := newRowReader(r, format)
+	if err != nil {
+		return report, err
+	}
+
+	language := i18n.FromContext(ctx)
+	reject := func(line int, err error) {
+		report.Failed++
+		for _, rowErr := range rowErrors(language, line, err) {
+			if len(report.Errors) < config.MaxErrors {
+				report.Errors = append(report.Errors, rowErr)
+			}
+		}
+	}
+	batch := make([]row, 0, config.BatchSize)
+	flush := func() {
+		operations := make([]service.BulkOperation, len(batch))
+		for i, row := range batch {
+			operations[i] = service.BulkOperation{Action: service.BulkCreate, Name: row.name}
+		}
+		for i, result := range service.BulkExamples(ctx, operations, service.BulkBestEffort, config.BatchSize) {
+			if result.Err != nil {
+				reject(batch[i].line, result.Err)
+			} else {
+				report.Created++
+			}
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		row, err := reader.next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return report, err
+		}
+		report.Rows++
+		if row.err != nil {
+			reject(row.line, row.err)
+			continue
+		}
+		if batch = append(batch, row); len(batch) == config.BatchSize {
+			flush()
+		}
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+	}
+	if len(batch) > 0 {
+		flush()
+	}
+	return report, nil
+}
+
+// rowErrors converts the error of a row: validation errors have an error per field.
+func rowErrors(language string, line int, err error) []RowError {
+	appErr := apperror.From(err)
+	if len(appErr.Fields) == 0 {
+		return []RowError{{Row: line, Code: appErr.Code, Message: errorMessage(language, appErr)}}
+	}
+	errs := make([]RowError, len(appErr.Fields))
+	for i, field := range appErr.Fields {
+		message := field.Message
+		if field.Key != "" {
+			message = i18n.T(language, field.Key, field.Args)
+		}
+		errs[i] = RowError{Row: line, Field: field.Field, Code: field.Code, Message: message}
+	}
+	return errs
+}
+
+// errorMessage returns the message of an error in the language, or in the default language if it is empty.
+func errorMessage(language string, err error) string {
+	appErr := apperror.From(err)
+	if appErr.Key != "" {
+		return i18n.T(language, appErr.Key, appErr.Args)
+	}
+	return appErr.Detail
+}
+
+// errMalformed is the error of a row that cannot be parsed.
+func errMalformed(detail string) error {
+	return apperror.New(http.StatusUnprocessableEntity, "malformed", detail).
+		WithKey("error.import_malformed_row", i18n.Args{"reason": detail})
+}
+
+// newRowReader returns the reader of a format.
+func newRowReader(r io.Reader, format string) (rowReader, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVReader(r)
+	case FormatNDJSON:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64*1024), maxLineBytes)
+		return &ndjsonReader{scanner: scanner}, nil
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+// csvReader reads the rows of a CSV file, whose first row names the columns.
+type csvReader struct {
+	csv  *csv.Reader
+	name int // The index of the "name" column
+}
+
+// newCSVReader reads the header row and finds the "name" column, ignoring its case and a byte order mark.
+func newCSVReader(r io.Reader) (*csvReader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1 // Rows with missing columns are rejected one by one
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, ErrMissingColumn
+	}
+	if err != nil {
+		return nil, errMalformed(err.Error())
+	}
+	for i, column := range header {
+		if strings.EqualFold(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")), "name") {
+			return &csvReader{csv: reader, name: i}, nil
+		}
+	}
+	return nil, ErrMissingColumn
+}
+
+func (r *csvReader) next() (row, error) {
+	record, err := r.csv.Read()
+	if errors.Is(err, io.EOF) {
+		return row{}, err
+	}
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return row{line: parseErr.StartLine, err: errMalformed(parseErr.Err.Error())}, nil
+	}
+	if err != nil {
+		return row{}, err
+	}
+	line, _ := r.csv.FieldPos(0)
+	if r.name >= len(record) {
+		return row{line: line}, nil // Validated as a missing name
+	}
+	return row{line: line, name: record[r.name]}, nil
+}
+
+// ndjsonReader reads the rows of an NDJSON file, skipping blank lines.
+type ndjsonReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func (r *ndjsonReader) next() (row, error) {
+	for r.scanner.Scan() {
+		r.line++
+		text := strings.TrimSpace(r.scanner.Text())
+		if text == "" {
+			continue
+		}
+		var object struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal([]byte(text), &object); err != nil {
+			return row{line: r.line, err: errMalformed(err.Error())}, nil
+		}
+		return row{line: r.line, name: object.Name}, nil
+	}
+	if errors.Is(r.scanner.Err(), bufio.ErrTooLong) {
+		return row{}, ErrLineTooLong
+	}
+	if err := r.scanner.Err(); err != nil {
+		return row{}, err
+	}
+	return row{}, io.EOF
+}
@@ -0,0 +1,79 @@
+// Package transfer contains tests for the reading of imported files, which does not require a database.
+package transfer
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"gobo/internal/apperror"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// readRows reads all the rows of a file.
+func readRows(t *testing.T, format, content string) ([]row, error) {
+	reader, err := newRowReader(strings.NewReader(content), format)
+	if err != nil {
+		return nil, err
+	}
+	rows := []row{}
+	for {
+		row, err := reader.next()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return rows, err
+		}
+		rows = append(rows, row)
+	}
+}
+
+// code returns the code of an application error.
+func code(err error) string {
+	var appErr *apperror.Error
+	if errors.As(err, &appErr) {
+		return appErr.Code
+	}
+	return ""
+}
+
+// TestReadCSV validates that the name column is found by its header, and that malformed rows are rejected one
+// by one with their line.
+func TestReadCSV(t *testing.T) {
+	rows, err := readRows(t, FormatCSV, "\ufeffid, Name ,version\n1,Alpha,1\n\n2,\"Beta, \"\"quoted\"\"\",3\n3\n4,\"Gam\"ma,1\n5,Delta,1\n")
+	assert.NoError(t, err)
+	if assert.Len(t, rows, 5) {
+		assert.Equal(t, row{line: 2, name: "Alpha"}, rows[0])
+		assert.Equal(t, row{line: 4, name: `Beta, "quoted"`}, rows[1])
+		assert.Equal(t, row{line: 5}, rows[2])
+		assert.Equal(t, 6, rows[3].line)
+		assert.Equal(t, "malformed", code(rows[3].err))
+		assert.Equal(t, row{line: 7, name: "Delta"}, rows[4])
+	}
+
+	_, err = readRows(t, FormatCSV, "id,title\n1,Alpha\n")
+	assert.ErrorIs(t, err, ErrMissingColumn)
+	_, err = readRows(t, FormatCSV, "")
+	assert.ErrorIs(t, err, ErrMissingColumn)
+	_, err = readRows(t, FormatXLSX, "name\nAlpha\n")
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+}
+
+// TestReadNDJSON validates that blank lines are skipped, and that lines that are not JSON objects are rejected.
+func TestReadNDJSON(t *testing.T) {
+	rows, err := readRows(t, FormatNDJSON, "{\"id\": 1, \"name\": \"Alpha\"}\n\n  \n{\"name\": 3}\nnot json\n{}\n")
+	assert.NoError(t, err)
+	if assert.Len(t, rows, 4) {
+		assert.Equal(t, row{line: 1, name: "Alpha"}, rows[0])
+		assert.Equal(t, 4, rows[1].line)
+		assert.Equal(t, "malformed", code(rows[1].err))
+		assert.Equal(t, "malformed", code(rows[2].err))
+		assert.Equal(t, row{line: 6}, rows[3])
+	}
+
+	_, err = readRows(t, FormatNDJSON, `{"name": "`+strings.Repeat("a", maxLineBytes)+`"}`)
+	assert.ErrorIs(t, err, ErrLineTooLong)
+}
@@ -0,0 +1,165 @@
+// Package transfer moves examples in and out of the application as files.
+// This file contains the imports run by background jobs.
+package transfer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gobo/internal/apperror"
+	"gobo/internal/db"
+	"gobo/internal/jobs"
+	"gobo/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// JobType is the type of the jobs importing files.
+const JobType = "examples.import"
+
+// Import statuses.
+const (
+	StatusPending   = "pending"   // Waiting for a worker
+	StatusRunning   = "running"   // Being imported
+	StatusCompleted = "completed" // Imported; rejected rows are listed in the errors
+	StatusFailed    = "failed"    // Stopped by an error of the file or of the job
+)
+
+// ErrInterrupted is the error of an import whose job was interrupted (e.g., by a restart). It is not resumed,
+// since part of its rows may already be created.
+var ErrInterrupted = apperror.New(http.StatusInternalServerError, "import_interrupted", "").
+	WithKey("error.import_interrupted")
+
+// Import is an import run by a background job. The uploaded file is stored with it until it is imported, so
+// that any instance can run the job.
+type Import struct {
+	ID                 uint       `gorm:"primaryKey"` // Primary key.
+	CreatedAt          time.Time  // Upload time.
+	models.TenantOwned            // Tenant importing the file.
+	Format             string     `gorm:"type:varchar(10);not null"`                         // csv or ndjson.
+	Status             string     `gorm:"type:varchar(20);not null;default:'pending';index"` // pending, running, completed or failed.
+	Data               []byte     // The uploaded file, cleared once it is imported.
+	Report             Report     `gorm:"type:text;serializer:json"` // The outcome of the import.
+	Error              string     `gorm:"type:text"`                 // Why the import failed.
+	StartedAt          *time.Time // Time a worker started the import.
+	FinishedAt         *time.Time // Time the import completed or failed.
+}
+
+// TableName returns the table of the imports.
+func (Import) TableName() string {
+	return "imports"
+}
+
+// importPayload is the payload of the import jobs.
+type importPayload struct {
+	ID uint `json:"id"` // The ID of the import
+}
+
+// StartImport stores an uploaded file and enqueues the job importing it.
+//
+// Parameters:
+// - ctx (context.Context): The context of the request, carrying the tenant.
+// - queue (*jobs.Queue): The job queue.
+// - format (string): FormatCSV or FormatNDJSON.
+// - data ([]byte): The file.
+//
+// Returns:
+// - Import: The pending import.
+// - error: ErrUnsupportedFormat, an error of the queue (e.g., jobs.ErrUnavailable), or an error if the import
+// cannot be stored.
+func StartImport(ctx context.Context, queue *jobs.Queue, format string, data []byte) (Import, error) {
+	if format != FormatCSV && format != FormatNDJSON {
+		return Import{}, ErrUnsupportedFormat
+	}
+	imp := Import{Format: format, Status: StatusPending, Data: data, Report: Report{Errors: []RowError{}}}
+	if err := db.GormDB.WithContext(ctx).Create(&imp).Error; err != nil {
+		return Import{}, err
+	}
+	// The second attempt only runs if the first one was lost (e.g., its instance crashed): it finds the import
+	// running, and marks it as interrupted (see runImport).
+	_, err := queue.Enqueue(ctx, JobType, importPayload{ID: imp.ID}, jobs.Options{
+		MaxAttempts: 2,
+		UniqueKey:   strconv.FormatUint(uint64(imp.ID), 10),
+	})
+	if err != nil {
+		// Nothing will import the file.
+		db.GormDB.WithContext(ctx).Delete(&imp)
+		return Import{}, err
+	}
+	imp.Data = nil
+	return imp, nil
+}
+
+// FindImport returns an import of the tenant, without its file.
+//
+// Parameters:
+// - ctx (context.Context): The context of the request, carrying the tenant.
+// - id (uint): The ID of the import.
+//
+// Returns:
+// - Import: The import.
+// - error: gorm.ErrRecordNotFound if the import does not exist.
+func FindImport(ctx context.Context, id uint) (Import, error) {
+	var imp Import
+	err := db.GormDB.WithContext(ctx).Omit("data").First(&imp, id).Error
+	return imp, err
+}
+
+// Register registers the handler of the import jobs with a queue.
+//
+// Parameters:
+// - queue (*jobs.Queue): The job queue.
+// - config (Config): The configuration of the imports.
+func Register(queue *jobs.Queue, config Config) {
+	jobs.Register(queue, JobType, func(ctx context.Context, payload importPayload) error {
+		return runImport(ctx, payload.ID, config)
+	})
+}
+
+// runImport imports the file of a pending import and stores its report. Imports are not retried: errors are
+// permanent, and an import that is not pending was interrupted (its first attempt was lost), and is marked as failed.
+func runImport(ctx context.Context, id uint, config Config) error {
+	tx := db.GormDB.WithContext(ctx)
+	var imp Import
+	if err := tx.First(&imp, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return jobs.Permanent(err)
+		}
+		return err
+	}
+	if imp.Status != StatusPending {
+		if imp.Status == StatusRunning {
+			finishImport(ctx, imp, imp.Report, ErrInterrupted)
+		}
+		return jobs.Permanent(ErrInterrupted)
+	}
+
+	now := time.Now()
+	err := tx.Model(&imp).Updates(map[string]interface{}{"status": StatusRunning, "started_at": now}).Error
+	if err != nil {
+		return err
+	}
+	report, err := ImportExamples(ctx, bytes.NewReader(imp.Data), imp.Format, config)
+	finishImport(ctx, imp, report, err)
+	if err != nil {
+		return jobs.Permanent(err)
+	}
+	return nil
+}
+
+// finishImport stores the report of an import and clears its file.
+func finishImport(ctx context.Context, imp Import, report Report, err error) {
+	now := time.Now()
+	finished := Import{Status: StatusCompleted, Report: report, FinishedAt: &now}
+	if err != nil {
+		finished.Status, finished.Error = StatusFailed, errorMessage("", err)
+	}
+	// Stored without the cancellation of the job, so that the outcome of a timed out import is kept.
+	db.GormDB.WithContext(context.WithoutCancel(ctx)).Model(&imp).
+		Select("status", "report", "error", "data", "finished_at").
+		Updates(&finished)
+}
@@ -0,0 +1,81 @@
+// Package transfer moves examples in and out of the application as files.
+// Exports stream the examples as CSV, NDJSON or XLSX, one batch at a time (see Export). Imports read CSV or
+// NDJSON files, validate each row and create the valid ones in batches, reporting the errors of the others by
+// row (see ImportExamples). Large files are imported by a background job (see StartImport and Register).
+package transfer
+
+import (
+	"os"
+	"strconv"
+)
+
+// File formats.
+const (
+	FormatCSV    = "csv"    // Comma-separated values with a header row
+	FormatNDJSON = "ndjson" // One JSON object per line
+	FormatXLSX   = "xlsx"   // Excel workbook (exports only)
+)
+
+// contentTypes are the media types of the formats.
+var contentTypes = map[string]string{
+	FormatCSV:    "text/csv; charset=utf-8",
+	FormatNDJSON: "application/x-ndjson",
+	FormatXLSX:   "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// ContentType returns the media type of a format.
+//
+// Parameters:
+// - format (string): FormatCSV, FormatNDJSON or FormatXLSX.
+//
+// Returns:
+// - string: The media type, or an empty string if the format is unknown.
+func ContentType(format string) string {
+	return contentTypes[format]
+}
+
+// Config defines how files are exported and imported.
+type Config struct {
+	PageSize  int // Examples read per query by exports (defaults to 500)
+	BatchSize int // Rows created per transaction by imports (defaults to 500)
+	MaxErrors int // Row errors kept in the report of an import; further errors are only counted (defaults to 1000)
+}
+
+// DefaultConfig returns the default transfer configuration.
+//
+// Defaults:
+// - PageSize: 500, or the EXPORT_PAGE_SIZE environment variable
+// - BatchSize: 500, or the IMPORT_BATCH_SIZE environment variable
+// - MaxErrors: 1000, or the IMPORT_MAX_ERRORS environment variable
+//
+// Returns:
+// - Config: The default transfer configuration.
+func DefaultConfig() Config {
+	return Config{
+		PageSize:  intFromEnv("EXPORT_PAGE_SIZE", 500),
+		BatchSize: intFromEnv("IMPORT_BATCH_SIZE", 500),
+		MaxErrors: intFromEnv("IMPORT_MAX_ERRORS", 1000),
+	}
+}
+
+// withDefaults returns the configuration with its zero values replaced by the defaults.
+func (c Config) withDefaults() Config {
+	if c.PageSize <= 0 {
+		c.PageSize = 500
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = 500
+	}
+	if c.MaxErrors <= 0 {
+		c.MaxErrors = 1000
+	}
+	return c
+}
+
+// intFromEnv returns the positive integer in the environment variable, or the fallback.
+func intFromEnv(name string, fallback int) int {
+	if value, err := strconv.Atoi(os.Getenv(name)); err == nil && value > 0 {
+		return value
+	}
+	return fallback
+}
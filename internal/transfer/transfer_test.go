@@ -0,0 +1,186 @@
+// Package transfer contains tests for the exports and imports of examples, which use the test database.
+package transfer
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"gobo/internal/db"
+	"gobo/internal/events"
+	"gobo/internal/jobs"
+	"gobo/internal/models"
+	"gobo/internal/service"
+	"gobo/internal/testhelpers"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/xuri/excelize/v2"
+)
+
+// TestImportExamples validates that the valid rows are created in batches and the others reported by line.
+func TestImportExamples(t *testing.T) {
+	testhelpers.SetupGormTestDB(t, &models.Example{}, &events.Message{})
+	defer testhelpers.TeardownGormTestDB(&models.Example{}, &events.Message{})
+
+	file := "name\n" + strings.Join([]string{"Alpha", " ", "Beta", strings.Repeat("x", 101), "Gamma"}, "\n") + "\n"
+	report, err := ImportExamples(context.Background(), strings.NewReader(file), FormatCSV, Config{BatchSize: 2, MaxErrors: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, 5, report.Rows)
+	assert.Equal(t, 3, report.Created)
+	assert.Equal(t, 2, report.Failed)
+	if assert.Len(t, report.Errors, 1) { // Further errors are only counted
+		assert.Equal(t, RowError{Row: 3, Field: "name", Code: "notblank", Message: report.Errors[0].Message}, report.Errors[0])
+	}
+
+	var count int64
+	db.GormDB.Model(&models.Example{}).Count(&count)
+	assert.Equal(t, int64(3), count)
+	db.GormDB.Model(&events.Message{}).Where("type = ?", events.ExampleCreated).Count(&count)
+	assert.Equal(t, int64(3), count)
+
+	_, err = ImportExamples(context.Background(), strings.NewReader("title\nAlpha\n"), FormatCSV, Config{})
+	assert.ErrorIs(t, err, ErrMissingColumn)
+}
+
+// TestExport validates that the examples are exported in each format, across pages.
+func TestExport(t *testing.T) {
+	testhelpers.SetupGormTestDB(t, &models.Example{})
+	defer testhelpers.TeardownGormTestDB(&models.Example{})
+
+	for i := 1; i <= 5; i++ {
+		assert.NoError(t, db.GormDB.Create(&models.Example{Name: fmt.Sprintf("Example %d", i)}).Error)
+	}
+	assert.NoError(t, db.GormDB.Delete(&models.Example{}, 2).Error)
+	config := Config{PageSize: 2}
+
+	var buffer bytes.Buffer
+	assert.NoError(t, Export(context.Background(), &buffer, FormatCSV, service.ExampleFilter{}, config))
+	records, err := csv.NewReader(&buffer).ReadAll()
+	assert.NoError(t, err)
+	if assert.Len(t, records, 5) {
+		assert.Equal(t, exportColumns, records[0])
+		assert.Equal(t, []string{"1", "Example 1", "1"}, records[1][:3])
+		assert.Equal(t, "Example 5", records[4][1])
+	}
+
+	buffer.Reset()
+	filter := service.ExampleFilter{Name: "example 2", IncludeDeleted: true}
+	assert.NoError(t, Export(context.Background(), &buffer, FormatNDJSON, filter, config))
+	var example service.Example
+	assert.NoError(t, json.Unmarshal(buffer.Bytes(), &example))
+	assert.Equal(t, "Example 2", example.Name)
+	assert.NotNil(t, example.DeletedAt)
+
+	buffer.Reset()
+	assert.NoError(t, Export(context.Background(), &buffer, FormatXLSX, service.ExampleFilter{}, config))
+	workbook, err := excelize.OpenReader(&buffer)
+	if assert.NoError(t, err) {
+		rows, err := workbook.GetRows("Examples")
+		assert.NoError(t, err)
+		assert.Len(t, rows, 5)
+		assert.Equal(t, []string{"3", "Example 3"}, rows[2][:2])
+	}
+}
+
+// TestExportFormulas validates that names starting like a spreadsheet formula are exported as text in the CSV
+// and XLSX files, and unchanged in the NDJSON files.
+func TestExportFormulas(t *testing.T) {
+	names := []string{"=SUM(A1:A2)", "+1", "-1", "@SUM(A1)", "\tTab", "\rReturn", "Plain = text"}
+	expected := []string{"'=SUM(A1:A2)", "'+1", "'-1", "'@SUM(A1)", "'\tTab", "'\rReturn", "Plain = text"}
+	write := func(format string) *bytes.Buffer {
+		var buffer bytes.Buffer
+		writer, err := newExportWriter(&buffer, format)
+		assert.NoError(t, err)
+		for i, name := range names {
+			assert.NoError(t, writer.write(service.Example{ID: uint(i + 1), Name: name, Version: 1}))
+		}
+		assert.NoError(t, writer.close())
+		return &buffer
+	}
+
+	records, err := csv.NewReader(write(FormatCSV)).ReadAll()
+	assert.NoError(t, err)
+	if assert.Len(t, records, len(names)+1) {
+		for i, name := range expected {
+			assert.Equal(t, name, records[i+1][1])
+		}
+	}
+
+	workbook, err := excelize.OpenReader(write(FormatXLSX))
+	if assert.NoError(t, err) {
+		for i, name := range expected {
+			value, err := workbook.GetCellValue("Examples", fmt.Sprintf("B%d", i+2))
+			assert.NoError(t, err)
+			assert.Equal(t, name, value)
+			formula, err := workbook.GetCellFormula("Examples", fmt.Sprintf("B%d", i+2))
+			assert.NoError(t, err)
+			assert.Empty(t, formula)
+		}
+	}
+
+	var example service.Example
+	assert.NoError(t, json.NewDecoder(write(FormatNDJSON)).Decode(&example))
+	assert.Equal(t, names[0], example.Name)
+}
+
+// TestRunImport validates that import jobs store their report, and that interrupted imports are not resumed.
+func TestRunImport(t *testing.T) {
+	testhelpers.SetupGormTestDB(t, &models.Example{}, &events.Message{}, &Import{})
+	defer testhelpers.TeardownGormTestDB(&models.Example{}, &events.Message{}, &Import{})
+
+	imp := Import{Format: FormatNDJSON, Status: StatusPending, Data: []byte("{\"name\": \"Alpha\"}\n{\"name\": \"\"}\n")}
+	assert.NoError(t, db.GormDB.Create(&imp).Error)
+	assert.NoError(t, runImport(context.Background(), imp.ID, Config{}))
+
+	imp, err := FindImport(context.Background(), imp.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusCompleted, imp.Status)
+	assert.Equal(t, 1, imp.Report.Created)
+	assert.Equal(t, 1, imp.Report.Failed)
+	assert.Equal(t, 2, imp.Report.Errors[0].Row)
+	assert.NotNil(t, imp.FinishedAt)
+	var stored Import
+	db.GormDB.First(&stored, imp.ID)
+	assert.Empty(t, stored.Data)
+
+	// A job finding its import running was interrupted.
+	running := Import{Format: FormatCSV, Status: StatusRunning, Data: []byte("name\nAlpha\n")}
+	assert.NoError(t, db.GormDB.Create(&running).Error)
+	assert.Error(t, runImport(context.Background(), running.ID, Config{}))
+	running, _ = FindImport(context.Background(), running.ID)
+	assert.Equal(t, StatusFailed, running.Status)
+	assert.Contains(t, running.Error, "interrupted")
+}
+
+// TestStartImport validates that an import job is attempted again if its first attempt is lost, so that the
+// import is marked as interrupted.
+func TestStartImport(t *testing.T) {
+	testhelpers.SetupGormTestDB(t, &Import{})
+	defer testhelpers.TeardownGormTestDB(&Import{})
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+	queue := jobs.New(jobs.Config{Client: client})
+
+	_, err := StartImport(context.Background(), queue, "xml", []byte("<examples/>"))
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+
+	imp, err := StartImport(context.Background(), queue, FormatCSV, []byte("name\nAlpha\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, StatusPending, imp.Status)
+	var job jobs.Job
+	for _, key := range server.Keys() {
+		if strings.HasPrefix(key, "jobs:job:") {
+			data, _ := server.Get(key)
+			assert.NoError(t, json.Unmarshal([]byte(data), &job))
+		}
+	}
+	assert.Equal(t, JobType, job.Type)
+	assert.Equal(t, 2, job.MaxAttempts)
+}
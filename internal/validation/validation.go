@@ -0,0 +1,192 @@
+// Package validation provides declarative, struct-tag based validation of request DTOs.
+// Rules are declared with `validate` tags (see github.com/go-playground/validator) and are
+// also picked up by swag, so the generated Swagger schema documents the same constraints.
+package validation
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+
+	"gobo/internal/apperror"
+	"gobo/internal/i18n"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/go-playground/validator/v10/non-standard/validators"
+	"github.com/gofiber/fiber/v2"
+)
+
+var (
+	validate     *validator.Validate // Shared validator instance; it caches struct metadata
+	validateOnce sync.Once
+)
+
+// Validator returns the shared validator instance.
+// Custom rules can be registered on it with RegisterValidation at startup.
+//
+// Returns:
+// - *validator.Validate: The shared validator.
+func Validator() *validator.Validate {
+	validateOnce.Do(func() {
+		validate = validator.New(validator.WithRequiredStructEnabled())
+		// Report fields by the name the client sent rather than the Go field name.
+		validate.RegisterTagNameFunc(fieldName)
+		// notblank rejects strings consisting only of whitespace.
+		_ = validate.RegisterValidation("notblank", validators.NotBlank)
+	})
+	return validate
+}
+
+// fieldName returns the client-facing name of a struct field from its json, query or params tag.
+func fieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "query", "params"} {
+		name := strings.Split(field.Tag.Get(tag), ",")[0]
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// Struct validates a struct against its `validate` tags.
+//
+// Parameters:
+// - value (interface{}): The struct (or pointer to struct) to validate.
+//
+// Returns:
+// - error: nil if the struct is valid, otherwise an *apperror.Error (422) listing every invalid field.
+func Struct(value interface{}) error {
+	err := Validator().Struct(value)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		// The value itself cannot be validated (e.g., it is not a struct).
+		return apperror.Internal(err)
+	}
+
+	fields := make([]apperror.FieldError, 0, len(validationErrors))
+	for _, fieldErr := range validationErrors {
+		key, args := message(fieldErr)
+		fields = append(fields, apperror.FieldError{
+			Field:   fieldPath(fieldErr),
+			Code:    fieldErr.Tag(),
+			Message: i18n.T(i18n.DefaultLanguage, key, args),
+			Key:     key,
+			Args:    args,
+		})
+	}
+	return apperror.Validation("", fields...).WithKey("error.invalid_fields")
+}
+
+// BindAndValidate binds the request body, query string and path parameters to out and validates it.
+// The body is parsed according to its Content-Type (json, xml or form) using the `json`/`form` tags;
+// query and path parameters are bound to fields with `query` and `params` tags respectively.
+//
+// Parameters:
+// - c (*fiber.Ctx): The Fiber context of the request.
+// - out (interface{}): A pointer to the request DTO.
+//
+// Returns:
+// - error: nil on success, an *apperror.Error with status 400 if the request cannot be parsed,
+// or with status 422 if it fails validation.
+func BindAndValidate(c *fiber.Ctx, out interface{}) error {
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(out); err != nil {
+			return apperror.BadRequest("").WithKey("error.invalid_request_body").WithCause(err)
+		}
+	}
+	if hasTag(out, "query") {
+		if err := c.QueryParser(out); err != nil {
+			return apperror.BadRequest("").WithKey("error.invalid_query_parameters").WithCause(err)
+		}
+	}
+	if hasTag(out, "params") {
+		if err := c.ParamsParser(out); err != nil {
+			return apperror.BadRequest("").WithKey("error.invalid_path_parameters").WithCause(err)
+		}
+	}
+	return Struct(out)
+}
+
+// hasTag reports whether any field of the struct pointed to by out has the given tag.
+// Query and path parameters are only bound to DTOs that declare them, so that a query
+// parameter can never overwrite a field sent in the body.
+func hasTag(out interface{}, tag string) bool {
+	t := reflect.TypeOf(out)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if _, ok := t.Field(i).Tag.Lookup(tag); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldPath returns the dotted path of the invalid field without the top-level struct name
+// (e.g., "items[0].name" instead of "CreateBulkRequest.items[0].name").
+func fieldPath(fieldErr validator.FieldError) string {
+	namespace := fieldErr.Namespace()
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return fieldErr.Field()
+}
+
+// message returns the message key and arguments describing a validation failure.
+// Messages are defined in the i18n catalogs under the "validation." prefix.
+func message(fieldErr validator.FieldError) (string, i18n.Args) {
+	field := fieldPath(fieldErr)
+	param := fieldErr.Param()
+	isString := fieldErr.Kind() == reflect.String
+	args := i18n.Args{"field": field, "param": param}
+	withCount := func(key string) (string, i18n.Args) {
+		args["count"] = param
+		return key, args
+	}
+
+	switch fieldErr.Tag() {
+	case "required", "required_if", "required_unless", "required_with", "required_without":
+		return "validation.required", args
+	case "min", "gte":
+		if isString {
+			return withCount("validation.min_length")
+		}
+		return "validation.min", args
+	case "max", "lte":
+		if isString {
+			return withCount("validation.max_length")
+		}
+		return "validation.max", args
+	case "len":
+		if isString {
+			return withCount("validation.len_string")
+		}
+		return withCount("validation.len_items")
+	case "gt", "lt", "email", "alphanum", "notblank":
+		return "validation." + fieldErr.Tag(), args
+	case "oneof":
+		args["param"] = strings.Join(strings.Fields(param), ", ")
+		return "validation.oneof", args
+	case "url", "http_url":
+		return "validation.url", args
+	case "uuid", "uuid4":
+		return "validation.uuid", args
+	case "datetime":
+		return "validation.datetime", args
+	default:
+		args["tag"] = fieldErr.Tag()
+		return "validation.invalid", args
+	}
+}
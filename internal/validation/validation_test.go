@@ -0,0 +1,123 @@
+// Package validation_test contains tests for the validation package.
+// These tests validate request binding and the reported field errors.
+package validation_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gobo/internal/apperror"
+	"gobo/internal/validation"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// searchRequest is a DTO combining body, query and path parameters.
+type searchRequest struct {
+	Term   string `json:"term" validate:"required,max=10"`
+	Limit  int    `query:"limit" validate:"omitempty,min=1,max=50"`
+	Locale string `params:"locale" validate:"oneof=en tr"`
+}
+
+// newTestApp creates an app with a route binding searchRequest.
+func newTestApp(bound *searchRequest) *fiber.App {
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Post("/:locale/search", func(c *fiber.Ctx) error {
+		if err := validation.BindAndValidate(c, bound); err != nil {
+			return err
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+	return app
+}
+
+// TestBindAndValidate_Valid verifies that body, query and path parameters are bound.
+func TestBindAndValidate_Valid(t *testing.T) {
+	var bound searchRequest
+	app := newTestApp(&bound)
+
+	req := httptest.NewRequest("POST", "/tr/search?limit=20", strings.NewReader(`{"term": "gobo"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 204, resp.StatusCode)
+	assert.Equal(t, searchRequest{Term: "gobo", Limit: 20, Locale: "tr"}, bound)
+}
+
+// TestBindAndValidate_Invalid verifies that every invalid field is reported with its client-facing name.
+func TestBindAndValidate_Invalid(t *testing.T) {
+	var bound searchRequest
+	app := newTestApp(&bound)
+
+	req := httptest.NewRequest("POST", "/de/search?limit=100", strings.NewReader(`{"term": "far too long term"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 422, resp.StatusCode)
+
+	var problem apperror.Problem
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&problem))
+	assert.Equal(t, apperror.CodeValidation, problem.Code)
+	assert.ElementsMatch(t, []apperror.FieldError{
+		{Field: "term", Code: "max", Message: "term must be at most 10 characters long"},
+		{Field: "limit", Code: "max", Message: "limit must be at most 50"},
+		{Field: "locale", Code: "oneof", Message: "locale must be one of: en, tr"},
+	}, problem.Errors)
+}
+
+// TestBindAndValidate_MalformedBody verifies that a body that cannot be parsed results in 400 Bad Request.
+func TestBindAndValidate_MalformedBody(t *testing.T) {
+	var bound searchRequest
+	app := newTestApp(&bound)
+
+	req := httptest.NewRequest("POST", "/en/search", strings.NewReader(`{"term":`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 400, resp.StatusCode)
+}
+
+// TestStruct_NestedFields verifies the field paths of nested structs and slices.
+func TestStruct_NestedFields(t *testing.T) {
+	type item struct {
+		Name string `json:"name" validate:"required,notblank"`
+	}
+	type bulkRequest struct {
+		Items []item `json:"items" validate:"required,min=1,dive"`
+	}
+
+	err := validation.Struct(bulkRequest{Items: []item{{Name: "ok"}, {Name: "   "}}})
+	appErr := apperror.From(err)
+	if assert.NotNil(t, appErr) && assert.Len(t, appErr.Fields, 1) {
+		assert.Equal(t, "items[1].name", appErr.Fields[0].Field)
+		assert.Equal(t, "notblank", appErr.Fields[0].Code)
+	}
+
+	assert.NoError(t, validation.Struct(bulkRequest{Items: []item{{Name: "ok"}}}))
+}
+
+// TestBindAndValidate_Localized verifies that field messages follow the Accept-Language header.
+func TestBindAndValidate_Localized(t *testing.T) {
+	var bound searchRequest
+	app := newTestApp(&bound)
+
+	req := httptest.NewRequest("POST", "/en/search", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Language", "tr-TR")
+	resp, err := app.Test(req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 422, resp.StatusCode)
+
+	var problem apperror.Problem
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&problem))
+	assert.Equal(t, "İşlenemeyen Varlık", problem.Title)
+	assert.Equal(t, "İstek geçersiz alanlar içeriyor.", problem.Detail)
+	assert.Equal(t, []apperror.FieldError{{Field: "term", Code: "required", Message: "term alanı zorunludur"}}, problem.Errors)
+}
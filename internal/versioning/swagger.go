@@ -0,0 +1,86 @@
+// Package versioning provides API version groups (/v1, /v2), header-based version negotiation,
+// registration of one handler for several versions, and deprecation headers.
+// This file derives one Swagger document per version from the generated document.
+package versioning
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/swaggo/swag"
+)
+
+// SwaggerInstance returns the name of the Swagger instance documenting a version (e.g., "v1").
+// Use it as swagger.Config.InstanceName to serve the version's document.
+func SwaggerInstance(version string) string {
+	return "swagger-" + version
+}
+
+// RegisterSwagger registers one Swagger instance per version.
+// Each document contains only the paths of its version, relative to the version's base path
+// (e.g., /v1/examples is documented as /examples with basePath /v1). The documents are derived
+// lazily from the default instance generated by swag, so the docs package only needs to be imported.
+func (r *Router) RegisterSwagger() {
+	for _, version := range r.config.Versions {
+		name := SwaggerInstance(version.Name)
+		if swag.GetSwagger(name) == nil {
+			swag.Register(name, versionDoc{version: version})
+		}
+	}
+}
+
+// versionDoc is a Swagger document restricted to the paths of one version.
+type versionDoc struct {
+	version Version
+}
+
+// ReadDoc returns the version's document, or an empty string if no document was generated.
+func (d versionDoc) ReadDoc() string {
+	source, err := swag.ReadDoc()
+	if err != nil {
+		return ""
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal([]byte(source), &doc); err != nil {
+		return ""
+	}
+
+	prefix := "/" + d.version.Name
+	paths := map[string]interface{}{}
+	if all, ok := doc["paths"].(map[string]interface{}); ok {
+		for path, item := range all {
+			if relative, ok := strings.CutPrefix(path, prefix); ok && (relative == "" || strings.HasPrefix(relative, "/")) {
+				if relative == "" {
+					relative = "/"
+				}
+				paths[relative] = markDeprecated(item, d.version.Deprecation != nil)
+			}
+		}
+	}
+	doc["paths"] = paths
+	doc["basePath"] = prefix
+	if info, ok := doc["info"].(map[string]interface{}); ok {
+		info["version"] = d.version.Name
+	}
+
+	result, err := json.Marshal(doc)
+	if err != nil {
+		return ""
+	}
+	return string(result)
+}
+
+// markDeprecated flags every operation of a path item as deprecated.
+func markDeprecated(item interface{}, deprecated bool) interface{} {
+	operations, ok := item.(map[string]interface{})
+	if !deprecated || !ok {
+		return item
+	}
+	for _, operation := range operations {
+		if op, ok := operation.(map[string]interface{}); ok {
+			op["deprecated"] = true
+		}
+	}
+	return item
+}
@@ -0,0 +1,249 @@
+// Package versioning provides API version groups (/v1, /v2), header-based version negotiation,
+// registration of one handler for several versions, and deprecation headers.
+package versioning
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"gobo/internal/apperror"
+	"gobo/internal/i18n"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// HeaderAPIVersion is the request header selecting a version for unversioned paths (e.g., "2" or "v2"),
+// and the response header announcing the version that served the request.
+const HeaderAPIVersion = "API-Version"
+
+// mediaTypeVersion matches vendor media types selecting a version, e.g. "application/vnd.gobo.v2+json".
+var mediaTypeVersion = regexp.MustCompile(`application/vnd\.gobo\.(v\d+)\+json`)
+
+// Deprecation describes the deprecation of a version or a route.
+type Deprecation struct {
+	Date   time.Time // When the API was deprecated (Deprecation header, RFC 9745)
+	Sunset time.Time // When the API will be removed (Sunset header, RFC 8594); zero if not scheduled
+	Link   string    // Documentation of the deprecation (Link header with rel="deprecation"); optional
+}
+
+// Version is an API version whose routes are mounted under /<Name>.
+type Version struct {
+	Name        string       // Version name and path prefix (e.g., "v1")
+	Deprecation *Deprecation // Marks every route of the version as deprecated; nil if supported
+}
+
+// Config defines the versions of the API.
+type Config struct {
+	Versions    []Version    // Supported versions, oldest first
+	Default     string       // Version serving unversioned paths when the client does not select one
+	Unversioned *Deprecation // Deprecation announced on unversioned paths without a version header; nil for none
+}
+
+// Adapter adapts a handler to a version, e.g. by converting the request or response
+// between the shape the handler implements and the shape of an older version.
+type Adapter func(next fiber.Handler) fiber.Handler
+
+// Binding registers a handler for a version, wrapped by the version's adapters.
+type Binding struct {
+	Version  string    // The version name (e.g., "v1")
+	Adapters []Adapter // Adapters applied to the handler, outermost first
+}
+
+// In creates a binding for the given version.
+//
+// Parameters:
+// - version (string): The version name (e.g., "v1").
+// - adapters (...Adapter): Adapters applied to the handler for this version, outermost first.
+//
+// Returns:
+// - Binding: The binding, for use with Router.Handle.
+func In(version string, adapters ...Adapter) Binding {
+	return Binding{Version: version, Adapters: adapters}
+}
+
+// Router mounts version groups on a Fiber router and registers versioned routes.
+type Router struct {
+	config    Config
+	groups    map[string]fiber.Router
+	mu        sync.RWMutex
+	resources map[string]struct{} // First path segments of versioned routes (e.g., "examples")
+}
+
+// New creates the version groups (/v1, /v2, ...) on the given router.
+// Every response of a group announces its version in the API-Version header,
+// and the routes of deprecated versions announce their deprecation.
+// Unversioned paths of versioned resources (e.g., /examples) are routed to a version by
+// the negotiation middleware, which is registered first (see negotiate).
+//
+// Parameters:
+// - app (fiber.Router): The application or group to mount the versions on.
+// - config (Config): The versions of the API.
+//
+// Returns:
+// - *Router: The versioned router.
+func New(app fiber.Router, config Config) *Router {
+	r := &Router{
+		config:    config,
+		groups:    make(map[string]fiber.Router, len(config.Versions)),
+		resources: map[string]struct{}{},
+	}
+	app.Use(r.negotiate())
+	for _, version := range config.Versions {
+		version := version
+		r.groups[version.Name] = app.Group("/"+version.Name, func(c *fiber.Ctx) error {
+			c.Set(HeaderAPIVersion, version.Name)
+			if version.Deprecation != nil {
+				version.Deprecation.apply(c, "")
+			}
+			return c.Next()
+		})
+	}
+	return r
+}
+
+// Group returns the route group of a version, for routes that exist in a single version.
+// It panics if the version is not configured.
+func (r *Router) Group(version string) fiber.Router {
+	group, ok := r.groups[version]
+	if !ok {
+		panic(fmt.Sprintf("versioning: unknown API version %q", version))
+	}
+	return group
+}
+
+// Handle registers the handlers for a route in every bound version.
+// The adapters of a binding wrap the last handler (the endpoint), so middleware
+// such as authentication runs unchanged in every version.
+//
+// Parameters:
+// - method (string): The HTTP method (e.g., fiber.MethodPost).
+// - path (string): The route path relative to the version prefix (e.g., "/examples").
+// - bindings ([]Binding): The versions to register the route in.
+// - handlers (...fiber.Handler): The middleware and the endpoint handler.
+func (r *Router) Handle(method, path string, bindings []Binding, handlers ...fiber.Handler) {
+	if len(handlers) == 0 {
+		panic("versioning: missing handler for " + method + " " + path)
+	}
+	for _, binding := range bindings {
+		endpoint := handlers[len(handlers)-1]
+		for i := len(binding.Adapters) - 1; i >= 0; i-- {
+			endpoint = binding.Adapters[i](endpoint)
+		}
+
+		chain := make([]fiber.Handler, 0, len(handlers))
+		chain = append(chain, handlers[:len(handlers)-1]...)
+		chain = append(chain, endpoint)
+		r.Group(binding.Version).Add(method, path, chain...)
+	}
+
+	r.mu.Lock()
+	r.resources[firstSegment(path)] = struct{}{}
+	r.mu.Unlock()
+}
+
+// negotiate returns a middleware routing unversioned paths of versioned resources
+// (e.g., /examples) to a version. The version is selected by the API-Version header
+// or a vendor media type in the Accept header (application/vnd.gobo.v2+json), and
+// defaults to Config.Default. Requests relying on the default announce the deprecation
+// of unversioned paths (Config.Unversioned).
+func (r *Router) negotiate() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		path := c.Path()
+		segment := firstSegment(path)
+		if _, versioned := r.groups[segment]; versioned || !r.isResource(segment) {
+			return c.Next()
+		}
+
+		version, explicit := requestedVersion(c)
+		if !explicit {
+			version = r.config.Default
+		}
+		if _, ok := r.groups[version]; !ok {
+			return apperror.BadRequest("").
+				WithKey("error.unsupported_api_version", i18n.Args{"version": version, "supported": strings.Join(r.names(), ", ")}).
+				WithCode("unsupported_api_version")
+		}
+
+		target := "/" + version + path
+		if !explicit && r.config.Unversioned != nil {
+			r.config.Unversioned.apply(c, target)
+		}
+		c.Path(target)
+		return c.Next()
+	}
+}
+
+// isResource reports whether a path segment belongs to a versioned route.
+func (r *Router) isResource(segment string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.resources[segment]
+	return ok
+}
+
+// names returns the configured version names.
+func (r *Router) names() []string {
+	names := make([]string, 0, len(r.config.Versions))
+	for _, version := range r.config.Versions {
+		names = append(names, version.Name)
+	}
+	return names
+}
+
+// requestedVersion returns the version selected by the request headers, normalized to "v<N>".
+func requestedVersion(c *fiber.Ctx) (string, bool) {
+	if header := strings.TrimSpace(c.Get(HeaderAPIVersion)); header != "" {
+		header = strings.ToLower(header)
+		if !strings.HasPrefix(header, "v") {
+			header = "v" + header
+		}
+		return header, true
+	}
+	if match := mediaTypeVersion.FindStringSubmatch(c.Get(fiber.HeaderAccept)); match != nil {
+		return match[1], true
+	}
+	return "", false
+}
+
+// firstSegment returns the first segment of a path ("/examples/1" returns "examples").
+func firstSegment(path string) string {
+	segment, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	return segment
+}
+
+// Deprecated returns a middleware marking a single route as deprecated.
+//
+// Parameters:
+// - deprecation (Deprecation): When the route was deprecated and when it will be removed.
+//
+// Returns:
+// - fiber.Handler: The middleware adding the deprecation headers.
+func Deprecated(deprecation Deprecation) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		deprecation.apply(c, "")
+		return c.Next()
+	}
+}
+
+// apply sets the Deprecation, Sunset and Link headers.
+// successor, if not empty, is linked as the successor version of the requested resource.
+func (d Deprecation) apply(c *fiber.Ctx, successor string) {
+	if d.Date.IsZero() {
+		c.Set("Deprecation", "true")
+	} else {
+		c.Set("Deprecation", fmt.Sprintf("@%d", d.Date.Unix()))
+	}
+	if !d.Sunset.IsZero() {
+		c.Set("Sunset", d.Sunset.UTC().Format(http.TimeFormat))
+	}
+	if d.Link != "" {
+		c.Append(fiber.HeaderLink, fmt.Sprintf(`<%s>; rel="deprecation"; type="text/html"`, d.Link))
+	}
+	if successor != "" {
+		c.Append(fiber.HeaderLink, fmt.Sprintf(`<%s>; rel="successor-version"`, successor))
+	}
+}
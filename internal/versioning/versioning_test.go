@@ -0,0 +1,158 @@
+// Package versioning_test contains tests for the versioning package.
+// These tests validate version groups, negotiation, adapters, deprecation headers and Swagger documents.
+package versioning_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gobo/internal/apperror"
+	"gobo/internal/versioning"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/swaggo/swag"
+)
+
+var (
+	deprecatedAt = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+	sunsetAt     = time.Date(2027, time.April, 30, 0, 0, 0, 0, time.UTC)
+)
+
+// newTestApp creates an app with a v1 (deprecated, adapted) and v2 version of GET /items.
+func newTestApp() (*fiber.App, *versioning.Router) {
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	versions := versioning.New(app, versioning.Config{
+		Versions: []versioning.Version{
+			{Name: "v1", Deprecation: &versioning.Deprecation{Date: deprecatedAt, Sunset: sunsetAt, Link: "https://example.com/migrate"}},
+			{Name: "v2"},
+		},
+		Default:     "v1",
+		Unversioned: &versioning.Deprecation{Date: deprecatedAt},
+	})
+
+	// v1 returns the body in upper case.
+	upper := func(next fiber.Handler) fiber.Handler {
+		return func(c *fiber.Ctx) error {
+			if err := next(c); err != nil {
+				return err
+			}
+			return c.SendString(string(c.Response().Body()) + " (v1)")
+		}
+	}
+	versions.Handle(fiber.MethodGet, "/items",
+		[]versioning.Binding{versioning.In("v1", upper), versioning.In("v2")},
+		func(c *fiber.Ctx) error { return c.SendString("items") },
+	)
+	app.Get("/other", func(c *fiber.Ctx) error { return c.SendString("other") })
+	return app, versions
+}
+
+// get performs a GET request with the given headers and returns the response and its body.
+func get(t *testing.T, app *fiber.App, path string, headers map[string]string) (*http.Response, string) {
+	req := httptest.NewRequest("GET", path, nil)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	return resp, string(body)
+}
+
+// TestVersionGroups verifies that each version serves its adapted handler and announces itself.
+func TestVersionGroups(t *testing.T) {
+	app, _ := newTestApp()
+
+	resp, body := get(t, app, "/v2/items", nil)
+	assert.Equal(t, "items", body)
+	assert.Equal(t, "v2", resp.Header.Get(versioning.HeaderAPIVersion))
+	assert.Empty(t, resp.Header.Get("Deprecation"))
+
+	resp, body = get(t, app, "/v1/items", nil)
+	assert.Equal(t, "items (v1)", body)
+	assert.Equal(t, "v1", resp.Header.Get(versioning.HeaderAPIVersion))
+	assert.Equal(t, "@1792368000", resp.Header.Get("Deprecation"))
+	assert.Equal(t, "Fri, 30 Apr 2027 00:00:00 GMT", resp.Header.Get("Sunset"))
+	assert.Equal(t, `<https://example.com/migrate>; rel="deprecation"; type="text/html"`, resp.Header.Get("Link"))
+}
+
+// TestNegotiation verifies that unversioned paths are routed by header or to the default version.
+func TestNegotiation(t *testing.T) {
+	app, _ := newTestApp()
+
+	// Without a version header the default version serves the request, and the path is deprecated.
+	resp, body := get(t, app, "/items", nil)
+	assert.Equal(t, "items (v1)", body)
+	assert.Contains(t, resp.Header.Get("Link"), `</v1/items>; rel="successor-version"`)
+
+	// The API-Version header and vendor media types select a version.
+	resp, body = get(t, app, "/items", map[string]string{versioning.HeaderAPIVersion: "2"})
+	assert.Equal(t, "items", body)
+	assert.Empty(t, resp.Header.Get("Deprecation"))
+
+	_, body = get(t, app, "/items", map[string]string{"Accept": "application/vnd.gobo.v2+json"})
+	assert.Equal(t, "items", body)
+
+	// Unknown versions are rejected.
+	resp, _ = get(t, app, "/items", map[string]string{versioning.HeaderAPIVersion: "v9"})
+	assert.Equal(t, 400, resp.StatusCode)
+
+	// Unversioned routes are not affected.
+	resp, body = get(t, app, "/other", map[string]string{versioning.HeaderAPIVersion: "v9"})
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, "other", body)
+}
+
+// TestDeprecated verifies the route-level deprecation middleware.
+func TestDeprecated(t *testing.T) {
+	app := fiber.New()
+	app.Get("/legacy", versioning.Deprecated(versioning.Deprecation{}), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+
+	resp, _ := get(t, app, "/legacy", nil)
+	assert.Equal(t, "true", resp.Header.Get("Deprecation"))
+	assert.Empty(t, resp.Header.Get("Sunset"))
+}
+
+// testDoc is a minimal generated Swagger document.
+type testDoc struct{}
+
+// ReadDoc returns a document with unversioned and versioned paths.
+func (testDoc) ReadDoc() string {
+	return `{"swagger": "2.0", "basePath": "/", "info": {"title": "Test", "version": "1.0"}, "paths": {
+		"/": {"get": {}},
+		"/v1/items": {"get": {"summary": "v1"}},
+		"/v2/items": {"get": {"summary": "v2"}},
+		"/v10/items": {"get": {}}
+	}}`
+}
+
+// TestRegisterSwagger verifies that each version gets a document with only its own paths.
+func TestRegisterSwagger(t *testing.T) {
+	if swag.GetSwagger(swag.Name) == nil {
+		swag.Register(swag.Name, testDoc{})
+	}
+	_, versions := newTestApp()
+	versions.RegisterSwagger()
+
+	source, err := swag.ReadDoc(versioning.SwaggerInstance("v1"))
+	assert.NoError(t, err)
+
+	var doc struct {
+		BasePath string                                       `json:"basePath"`
+		Info     map[string]string                            `json:"info"`
+		Paths    map[string]map[string]map[string]interface{} `json:"paths"`
+	}
+	assert.NoError(t, json.Unmarshal([]byte(source), &doc))
+	assert.Equal(t, "/v1", doc.BasePath)
+	assert.Equal(t, "v1", doc.Info["version"])
+	assert.Len(t, doc.Paths, 1)
+	assert.Equal(t, "v1", doc.Paths["/items"]["get"]["summary"])
+	assert.Equal(t, true, doc.Paths["/items"]["get"]["deprecated"], "Operations of deprecated versions should be flagged")
+}
@@ -0,0 +1,381 @@
+// Package webhooks notifies partners of domain events with HTTP callbacks.
+// This file implements the dispatcher sending the deliveries.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"gobo/internal/logger"
+	"gobo/internal/tenant"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	maxBackoff   = 6 * time.Hour // Longest delay between two attempts
+	responseSize = 1024          // Bytes of the response body recorded with an attempt
+)
+
+// Config defines how deliveries are sent.
+type Config struct {
+	Interval     time.Duration // Time between two polls of the pending deliveries (defaults to 1 second)
+	BatchSize    int           // Deliveries read per poll (defaults to 100)
+	Concurrency  int           // Deliveries sent in parallel (defaults to 10)
+	Timeout      time.Duration // Timeout of a request (defaults to 10 seconds)
+	MaxAttempts  int           // Attempts before a delivery is abandoned (defaults to 8)
+	Backoff      time.Duration // Delay before the first retry, doubled at every attempt up to 6 hours, with jitter (defaults to 30 seconds)
+	DisableAfter int           // Consecutive failed attempts after which a subscription is disabled (defaults to 20)
+	AllowPrivate bool          // Send to private, loopback and link-local addresses (e.g., in development); see NewClient
+	Client       *http.Client  // HTTP client; nil uses NewClient with the timeout
+}
+
+// DefaultConfig returns the default dispatcher configuration.
+//
+// Defaults:
+// - Interval: 1 second
+// - BatchSize: 100
+// - Concurrency: 10, or the WEBHOOK_CONCURRENCY environment variable
+// - Timeout: 10 seconds, or the WEBHOOK_TIMEOUT environment variable (e.g., "5s")
+// - MaxAttempts: 8, or the WEBHOOK_MAX_ATTEMPTS environment variable
+// - Backoff: 30 seconds
+// - DisableAfter: 20, or the WEBHOOK_DISABLE_AFTER environment variable
+// - AllowPrivate: false, or true if the WEBHOOK_ALLOW_PRIVATE environment variable is "true"
+//
+// Returns:
+// - Config: The default dispatcher configuration.
+func DefaultConfig() Config {
+	config := Config{
+		Interval:     time.Second,
+		BatchSize:    100,
+		Concurrency:  intFromEnv("WEBHOOK_CONCURRENCY", 10),
+		Timeout:      10 * time.Second,
+		MaxAttempts:  intFromEnv("WEBHOOK_MAX_ATTEMPTS", 8),
+		Backoff:      30 * time.Second,
+		DisableAfter: intFromEnv("WEBHOOK_DISABLE_AFTER", 20),
+		AllowPrivate: os.Getenv("WEBHOOK_ALLOW_PRIVATE") == "true",
+	}
+	if value, err := time.ParseDuration(os.Getenv("WEBHOOK_TIMEOUT")); err == nil && value > 0 {
+		config.Timeout = value
+	}
+	return config
+}
+
+// intFromEnv returns the positive integer in the environment variable, or the fallback.
+func intFromEnv(name string, fallback int) int {
+	if value, err := strconv.Atoi(os.Getenv(name)); err == nil && value > 0 {
+		return value
+	}
+	return fallback
+}
+
+// withDefaults returns the configuration with zero values replaced by the defaults.
+func (config Config) withDefaults() Config {
+	defaults := DefaultConfig()
+	if config.Interval <= 0 {
+		config.Interval = defaults.Interval
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaults.BatchSize
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = defaults.Concurrency
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaults.Timeout
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = defaults.MaxAttempts
+	}
+	if config.Backoff <= 0 {
+		config.Backoff = defaults.Backoff
+	}
+	if config.DisableAfter <= 0 {
+		config.DisableAfter = defaults.DisableAfter
+	}
+	if config.Client == nil {
+		config.Client = NewClient(config.Timeout, config.AllowPrivate)
+	}
+	return config
+}
+
+// errForbiddenAddress is the error of the connections to addresses webhooks may not be sent to.
+var errForbiddenAddress = errors.New("webhooks: private, loopback and link-local addresses are not allowed")
+
+// NewClient returns the HTTP client sending the webhooks. The subscription URLs are chosen by the tenants, so
+// that the requests cannot reach the internal network unless allowed:
+// - Connections to private, loopback, link-local, multicast and unspecified addresses are refused when they are
+// dialed, after the host is resolved, so that DNS records pointing to them (or changing to them) are refused too.
+// - Redirects are not followed: the redirect response is the outcome of the attempt.
+// - Proxies are not used, so that the addresses checked are those of the endpoints.
+//
+// Parameters:
+// - timeout (time.Duration): The timeout of a request.
+// - allowPrivate (bool): Allow private, loopback and link-local addresses (e.g., in development).
+//
+// Returns:
+// - *http.Client: The HTTP client.
+func NewClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, KeepAlive: 30 * time.Second}
+	if !allowPrivate {
+		dialer.Control = refusePrivate
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// refusePrivate refuses connections to addresses that are not public (see NewClient).
+func refusePrivate(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return fmt.Errorf("%w: %s", errForbiddenAddress, host)
+	}
+	return nil
+}
+
+// Dispatch sends the pending deliveries that are due, and records the outcome of every attempt.
+// Each delivery is claimed with a lease before it is sent, so that several instances can dispatch
+// at the same time without sending a delivery twice.
+//
+// Outcome of an attempt:
+// - 2xx response: the delivery succeeded, and the failures of the subscription are reset.
+// - Other response or network error: the delivery is retried with exponential backoff and jitter, or
+// abandoned after MaxAttempts. After DisableAfter consecutive failures, the subscription is disabled.
+//
+// Parameters:
+// - ctx (context.Context): The context of the requests and database statements.
+// - db (*gorm.DB): The GORM database connection instance.
+// - config (Config): The dispatcher configuration; zero values use DefaultConfig.
+//
+// Returns:
+// - int: The number of attempts made.
+// - error: An error if the deliveries cannot be read.
+func Dispatch(ctx context.Context, db *gorm.DB, config Config) (int, error) {
+	config = config.withDefaults()
+	tx := db.WithContext(ctx)
+	now := time.Now()
+
+	var due []Delivery
+	err := tx.Where("status = ? AND next_attempt_at <= ? AND (locked_until IS NULL OR locked_until < ?)", StatusPending, now, now).
+		Order("next_attempt_at").Limit(config.BatchSize).Find(&due).Error
+	if err != nil {
+		return 0, err
+	}
+
+	log := logger.FromContext(ctx).Named("webhooks")
+	attempts := 0
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, config.Concurrency)
+	for _, delivery := range due {
+		// Claim the delivery; another instance may have claimed it since it was read.
+		lease := time.Now().Add(config.Timeout + time.Minute)
+		result := tx.Model(&Delivery{}).
+			Where("id = ? AND status = ? AND (locked_until IS NULL OR locked_until < ?)", delivery.ID, StatusPending, time.Now()).
+			Update("locked_until", lease)
+		if result.Error != nil {
+			return attempts, result.Error
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+
+		attempts++
+		slots <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			if err := deliver(ctx, tx, config, delivery); err != nil {
+				log.Error("Failed to record a webhook delivery", zap.Uint("deliveryId", delivery.ID), zap.Error(err))
+			}
+		}()
+	}
+	wg.Wait()
+	return attempts, nil
+}
+
+// deliver sends a claimed delivery to its subscription and records the outcome.
+func deliver(ctx context.Context, tx *gorm.DB, config Config, delivery Delivery) error {
+	var subscription Subscription
+	err := tx.Unscoped().First(&subscription, delivery.SubscriptionID).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return err
+	}
+	switch {
+	case err != nil || subscription.DeletedAt.Valid:
+		return abandon(tx, delivery, "subscription deleted")
+	case !subscription.Active:
+		return abandon(tx, delivery, "subscription disabled")
+	}
+
+	attempt := send(ctx, config.Client, subscription, delivery)
+	if err := tx.Create(&attempt).Error; err != nil {
+		return err
+	}
+	log := logger.FromContext(ctx).Named("webhooks").With(
+		zap.Uint("deliveryId", delivery.ID),
+		zap.Uint("subscriptionId", subscription.ID),
+		zap.String("event", delivery.EventType),
+		zap.Int("statusCode", attempt.StatusCode),
+	)
+
+	delivery.Attempts++
+	updates := map[string]interface{}{"attempts": delivery.Attempts, "locked_until": nil, "last_error": attempt.Error}
+	if attempt.Error == "" {
+		updates["status"], updates["delivered_at"] = StatusSucceeded, attempt.CreatedAt
+		log.Debug("Webhook delivered")
+		if err := tx.Model(&Delivery{}).Where("id = ?", delivery.ID).Updates(updates).Error; err != nil {
+			return err
+		}
+		if subscription.Failures == 0 {
+			return nil
+		}
+		return tx.Model(&Subscription{}).Where("id = ?", subscription.ID).Update("failures", 0).Error
+	}
+
+	if delivery.Attempts >= config.MaxAttempts {
+		updates["status"] = StatusFailed
+		log.Warn("Webhook delivery abandoned", zap.Int("attempts", delivery.Attempts), zap.String("error", attempt.Error))
+	} else {
+		updates["next_attempt_at"] = time.Now().Add(backoff(config.Backoff, delivery.Attempts))
+		log.Info("Webhook delivery failed, retrying", zap.Int("attempts", delivery.Attempts), zap.String("error", attempt.Error))
+	}
+	if err := tx.Model(&Delivery{}).Where("id = ?", delivery.ID).Updates(updates).Error; err != nil {
+		return err
+	}
+
+	// Count the consecutive failures of the subscription, and disable it after too many.
+	err = tx.Model(&Subscription{}).Where("id = ?", subscription.ID).Update("failures", gorm.Expr("failures + 1")).Error
+	if err != nil {
+		return err
+	}
+	result := tx.Model(&Subscription{}).Where("id = ? AND active = ? AND failures >= ?", subscription.ID, true, config.DisableAfter).
+		Updates(map[string]interface{}{"active": false, "disabled_at": time.Now()})
+	if result.RowsAffected > 0 {
+		log.Warn("Webhook subscription disabled after repeated failures", zap.Int("failures", config.DisableAfter))
+	}
+	return result.Error
+}
+
+// abandon marks a delivery as failed without sending it.
+func abandon(tx *gorm.DB, delivery Delivery, reason string) error {
+	return tx.Model(&Delivery{}).Where("id = ?", delivery.ID).
+		Updates(map[string]interface{}{"status": StatusFailed, "last_error": reason, "locked_until": nil}).Error
+}
+
+// send makes an attempt to deliver an event, and returns its outcome.
+func send(ctx context.Context, client *http.Client, subscription Subscription, delivery Delivery) Attempt {
+	attempt := Attempt{DeliveryID: delivery.ID, CreatedAt: time.Now()}
+	defer func() { attempt.DurationMS = time.Since(attempt.CreatedAt).Milliseconds() }()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	timestamp := attempt.CreatedAt.Unix()
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "gobo-webhooks")
+	request.Header.Set(HeaderID, delivery.EventID)
+	request.Header.Set(HeaderEvent, delivery.EventType)
+	request.Header.Set(HeaderDelivery, strconv.FormatUint(uint64(delivery.ID), 10))
+	request.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	request.Header.Set(HeaderSignature, Sign(subscription.Secret, timestamp, delivery.Payload))
+
+	response, err := client.Do(request)
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	defer response.Body.Close()
+	content, _ := io.ReadAll(io.LimitReader(response.Body, responseSize))
+	attempt.StatusCode = response.StatusCode
+	attempt.Response = string(content)
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		attempt.Error = fmt.Sprintf("unexpected status %d", response.StatusCode)
+	}
+	return attempt
+}
+
+// backoff returns the delay before the next attempt: the base delay doubled at every attempt, up to
+// maxBackoff, of which a random half is added ("equal jitter") so that retries of many deliveries spread out.
+func backoff(base time.Duration, attempts int) time.Duration {
+	delay := base << (attempts - 1)
+	if delay > maxBackoff || delay <= 0 {
+		delay = maxBackoff
+	}
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// StartDispatcher starts a background job sending the webhook deliveries (see Dispatch).
+// The job polls the deliveries at every interval until none is due, and runs until the returned function is called.
+//
+// Parameters:
+// - db (*gorm.DB): The GORM database connection instance.
+// - config (Config): The dispatcher configuration; zero values use DefaultConfig.
+//
+// Returns:
+// - func(): Stops the job and waits for the running attempts to finish.
+func StartDispatcher(db *gorm.DB, config Config) func() {
+	config = config.withDefaults()
+	// The deliveries of every tenant are sent.
+	ctx, cancel := context.WithCancel(tenant.Bypass(context.Background()))
+	done := make(chan struct{})
+	log := logger.FromContext(ctx).Named("webhooks")
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(config.Interval)
+		defer ticker.Stop()
+
+		for {
+			for {
+				attempts, err := Dispatch(ctx, db, config)
+				if err != nil && ctx.Err() == nil {
+					log.Error("Failed to dispatch webhooks", zap.Error(err))
+				}
+				if err != nil || attempts < config.BatchSize || ctx.Err() != nil {
+					break
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
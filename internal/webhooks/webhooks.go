@@ -0,0 +1,283 @@
+// Package webhooks notifies partners of domain events with HTTP callbacks.
+// Partners subscribe a URL to event types (see Subscription). When events are published, a delivery is
+// created for every matching subscription in the transaction of the change (see Hook); the dispatcher
+// (see StartDispatcher) sends them with an HMAC-SHA256 signature, and retries failures with backoff.
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strconv"
+	"time"
+
+	"gobo/internal/events"
+	"gobo/internal/models"
+	"gobo/internal/tenant"
+
+	"gorm.io/gorm"
+)
+
+// Delivery states.
+const (
+	StatusPending   = "pending"   // Waiting for its first attempt or a retry
+	StatusSucceeded = "succeeded" // The endpoint responded with a 2xx status
+	StatusFailed    = "failed"    // Abandoned after the last attempt, or the subscription was disabled or deleted
+)
+
+// AllEvents subscribes to every event type.
+const AllEvents = "*"
+
+// Headers of the webhook requests.
+const (
+	HeaderID        = "X-Webhook-ID"        // Event ID, identical for retries and replays; used to drop duplicates
+	HeaderEvent     = "X-Webhook-Event"     // Event type (e.g., "example.created")
+	HeaderDelivery  = "X-Webhook-Delivery"  // Delivery ID
+	HeaderTimestamp = "X-Webhook-Timestamp" // Time of the attempt (Unix seconds), covered by the signature
+	HeaderSignature = "X-Webhook-Signature" // "sha256=" followed by the hex HMAC-SHA256 of "<timestamp>.<body>"
+)
+
+// ErrInvalidSignature is returned by Verify if a request was not signed with the secret, or is too old.
+var ErrInvalidSignature = errors.New("webhooks: invalid signature")
+
+// Subscription represents the "webhook_subscriptions" table: an endpoint notified of events.
+// Fields:
+// - Base: The primary key, timestamps and soft delete (see models.Base).
+// - TenantOwned: The tenant owning the subscription; it only receives the events of its tenant.
+// - URL: The endpoint receiving the events (POST).
+// - Events: The event types sent to the endpoint, or "*" for all.
+// - Secret: The key signing the requests; only returned when the subscription is created.
+// - Description: A free text describing the subscription.
+// - Active: Whether events are sent; subscriptions are disabled after repeated failures.
+// - Failures: The consecutive failed attempts; reset by a successful attempt.
+// - DisabledAt: When the subscription was disabled after repeated failures.
+type Subscription struct {
+	models.Base                   // Primary key, timestamps and soft delete.
+	models.TenantOwned            // Tenant owning the subscription.
+	URL                string     `gorm:"type:varchar(2048);not null"`        // Endpoint receiving the events.
+	Events             []string   `gorm:"type:text;not null;serializer:json"` // Subscribed event types.
+	Secret             string     `gorm:"type:varchar(100);not null"`         // Signing key.
+	Description        string     `gorm:"type:varchar(255)"`                  // Free text.
+	Active             bool       `gorm:"not null;default:true"`              // Whether events are sent.
+	Failures           int        `gorm:"not null;default:0"`                 // Consecutive failed attempts.
+	DisabledAt         *time.Time // Time of the automatic disabling.
+}
+
+// TableName returns the table of the subscriptions.
+func (Subscription) TableName() string {
+	return "webhook_subscriptions"
+}
+
+// Matches reports whether the subscription receives the given event type.
+//
+// Parameters:
+// - eventType (string): The event type (e.g., "example.created").
+//
+// Returns:
+// - bool: True if the subscription lists the event type or "*".
+func (s Subscription) Matches(eventType string) bool {
+	return slices.Contains(s.Events, AllEvents) || slices.Contains(s.Events, eventType)
+}
+
+// Delivery represents the "webhook_deliveries" table: an event sent, or to be sent, to a subscription.
+// Fields:
+// - ID, CreatedAt: The primary key and creation time.
+// - TenantOwned: The tenant of the subscription.
+// - SubscriptionID: The subscription receiving the event.
+// - EventID, EventType: The event (see events.Message).
+// - Payload: The request body.
+// - Status: pending, succeeded or failed.
+// - Attempts, NextAttemptAt, LastError: The attempts so far, when to try next, and the error of the last failure.
+// - LockedUntil: Set while a dispatcher sends the delivery, so that other instances skip it.
+// - DeliveredAt: When the endpoint accepted the event.
+// - ReplayOf: The delivery this one replays, if any.
+type Delivery struct {
+	ID                 uint            `gorm:"primaryKey"` // Primary key.
+	CreatedAt          time.Time       // Creation time.
+	models.TenantOwned                 // Tenant of the subscription.
+	SubscriptionID     uint            `gorm:"not null;index"`                                    // Subscription.
+	EventID            string          `gorm:"type:varchar(36);not null;index"`                   // Event ID.
+	EventType          string          `gorm:"type:varchar(100);not null"`                        // Event type.
+	Payload            json.RawMessage `gorm:"type:jsonb;not null"`                               // Request body.
+	Status             string          `gorm:"type:varchar(20);not null;default:'pending';index"` // pending, succeeded or failed.
+	Attempts           int             `gorm:"not null;default:0"`                                // Attempts so far.
+	NextAttemptAt      time.Time       `gorm:"not null;index"`                                    // Earliest time of the next attempt.
+	LastError          string          `gorm:"type:text"`                                         // Error of the last failed attempt.
+	LockedUntil        *time.Time      // Lease of the dispatcher sending the delivery.
+	DeliveredAt        *time.Time      // Time the endpoint accepted the event.
+	ReplayOf           *uint           // Replayed delivery.
+}
+
+// TableName returns the table of the deliveries.
+func (Delivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+// Attempt represents the "webhook_attempts" table: a request sent for a delivery.
+// Fields:
+// - ID, CreatedAt: The primary key and time of the request.
+// - DeliveryID: The delivery.
+// - StatusCode: The response status; 0 if no response was received.
+// - DurationMS: The duration of the request, in milliseconds.
+// - Error: Why the attempt failed; empty for a 2xx response.
+// - Response: The beginning of the response body (at most 1 KB).
+type Attempt struct {
+	ID         uint      `gorm:"primaryKey"` // Primary key.
+	CreatedAt  time.Time // Time of the request.
+	DeliveryID uint      `gorm:"not null;index"` // Delivery.
+	StatusCode int       // Response status.
+	DurationMS int64     // Request duration.
+	Error      string    `gorm:"type:text"` // Failure reason.
+	Response   string    `gorm:"type:text"` // Beginning of the response body.
+}
+
+// TableName returns the table of the attempts.
+func (Attempt) TableName() string {
+	return "webhook_attempts"
+}
+
+// body is the JSON body of the webhook requests.
+type body struct {
+	ID        string          `json:"id"`         // Event ID
+	Type      string          `json:"type"`       // Event type
+	Tenant    string          `json:"tenant"`     // Tenant of the change
+	CreatedAt time.Time       `json:"created_at"` // When the event was published
+	Data      json.RawMessage `json:"data"`       // Payload of the event
+}
+
+// NewSecret generates the signing key of a new subscription.
+//
+// Returns:
+// - string: A random key ("whsec_" followed by 64 hex digits).
+func NewSecret() string {
+	key := make([]byte, 32)
+	_, _ = rand.Read(key) // Never fails (see crypto/rand.Read).
+	return "whsec_" + hex.EncodeToString(key)
+}
+
+// Sign returns the signature of a request body, as sent in the X-Webhook-Signature header.
+//
+// Parameters:
+// - secret (string): The secret of the subscription.
+// - timestamp (int64): The time of the request (Unix seconds), sent in the X-Webhook-Timestamp header.
+// - payload ([]byte): The request body.
+//
+// Returns:
+// - string: "sha256=" followed by the hex HMAC-SHA256 of "<timestamp>.<body>".
+func Sign(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature of a received webhook request, as receivers should.
+//
+// Parameters:
+// - secret (string): The secret of the subscription.
+// - timestamp (string): The X-Webhook-Timestamp header.
+// - signature (string): The X-Webhook-Signature header.
+// - payload ([]byte): The request body.
+// - tolerance (time.Duration): The maximum age of the request, protecting against replay attacks (e.g., 5 minutes).
+//
+// Returns:
+// - error: ErrInvalidSignature if the signature does not match or the request is too old.
+func Verify(secret, timestamp, signature string, payload []byte, tolerance time.Duration) error {
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if age := time.Since(time.Unix(seconds, 0)); age > tolerance || age < -tolerance {
+		return ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(Sign(secret, seconds, payload)), []byte(signature)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Hook creates the deliveries of published events for the active subscriptions of their tenant,
+// in the transaction of the change. Register it with events.AddHook at startup.
+//
+// Parameters:
+// - tx (*gorm.DB): The transaction of the change.
+// - messages ([]events.Message): The published events.
+//
+// Returns:
+// - error: An error if the subscriptions cannot be read or the deliveries stored.
+func Hook(tx *gorm.DB, messages []events.Message) error {
+	subscriptions := map[string][]Subscription{} // Active subscriptions per tenant
+	var deliveries []Delivery
+	for _, message := range messages {
+		owner := message.TenantID
+		if owner == "" {
+			owner = tenant.DefaultID
+		}
+		if _, ok := subscriptions[owner]; !ok {
+			var active []Subscription
+			if err := tx.Where("tenant_id = ? AND active = ?", owner, true).Find(&active).Error; err != nil {
+				return err
+			}
+			subscriptions[owner] = active
+		}
+
+		for _, subscription := range subscriptions[owner] {
+			if !subscription.Matches(message.Type) {
+				continue
+			}
+			payload, err := json.Marshal(body{
+				ID:        message.EventID,
+				Type:      message.Type,
+				Tenant:    owner,
+				CreatedAt: message.CreatedAt,
+				Data:      message.Payload,
+			})
+			if err != nil {
+				return err
+			}
+			delivery := Delivery{
+				SubscriptionID: subscription.ID,
+				EventID:        message.EventID,
+				EventType:      message.Type,
+				Payload:        payload,
+				Status:         StatusPending,
+				NextAttemptAt:  time.Now(),
+			}
+			delivery.TenantID = owner
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return tx.Create(&deliveries).Error
+}
+
+// Replay creates a new delivery sending the same event again to the subscription, e.g. after the
+// partner fixed their endpoint. The replay carries the same event ID.
+//
+// Parameters:
+// - tx (*gorm.DB): The GORM database connection instance, with the request context.
+// - delivery (Delivery): The delivery to replay.
+//
+// Returns:
+// - Delivery: The new delivery.
+// - error: An error if the delivery cannot be stored.
+func Replay(tx *gorm.DB, delivery Delivery) (Delivery, error) {
+	replay := Delivery{
+		SubscriptionID: delivery.SubscriptionID,
+		EventID:        delivery.EventID,
+		EventType:      delivery.EventType,
+		Payload:        delivery.Payload,
+		Status:         StatusPending,
+		NextAttemptAt:  time.Now(),
+		ReplayOf:       &delivery.ID,
+	}
+	replay.TenantID = delivery.TenantID
+	err := tx.Create(&replay).Error
+	return replay, err
+}
@@ -0,0 +1,249 @@
+// Package webhooks_test contains tests for the webhooks package.
+// These tests validate the signatures, creating the deliveries of events, and sending them with retries.
+package webhooks_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gobo/internal/db"
+	"gobo/internal/events"
+	"gobo/internal/testhelpers"
+	"gobo/internal/webhooks"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// tables lists the tables used by the tests.
+var tables = []interface{}{&events.Message{}, &webhooks.Subscription{}, &webhooks.Delivery{}, &webhooks.Attempt{}}
+
+var addHook sync.Once
+
+// setup creates the tables, dropped at the end of the test, and registers the hook creating the deliveries.
+func setup(t *testing.T) {
+	testhelpers.SetupGormTestDB(t, tables...)
+	t.Cleanup(func() { testhelpers.TeardownGormTestDB(tables...) })
+	addHook.Do(func() { events.AddHook(webhooks.Hook) })
+}
+
+// jsonField returns a field of a JSON object.
+func jsonField(t *testing.T, object json.RawMessage, name string) string {
+	var fields map[string]json.RawMessage
+	assert.NoError(t, json.Unmarshal(object, &fields))
+	return string(fields[name])
+}
+
+// newSubscription stores a subscription of the default tenant.
+func newSubscription(t *testing.T, url string, eventTypes ...string) webhooks.Subscription {
+	subscription := webhooks.Subscription{URL: url, Events: eventTypes, Secret: webhooks.NewSecret(), Active: true}
+	subscription.TenantID = "default"
+	assert.NoError(t, db.GormDB.Create(&subscription).Error)
+	return subscription
+}
+
+// publish publishes an example event, creating its deliveries with the hook.
+func publish(t *testing.T, eventType string) {
+	assert.NoError(t, db.GormDB.Transaction(func(tx *gorm.DB) error {
+		return events.Publish(tx, events.Event{Type: eventType, AggregateType: "example", AggregateID: "1", Payload: map[string]string{"name": "Example"}})
+	}))
+}
+
+// TestSign verifies that signatures are checked with the secret, the body and the timestamp.
+func TestSign(t *testing.T) {
+	secret := webhooks.NewSecret()
+	assert.Len(t, secret, 70)
+	assert.NotEqual(t, secret, webhooks.NewSecret())
+
+	payload := []byte(`{"id":"1"}`)
+	now := time.Now().Unix()
+	timestamp := strconv.FormatInt(now, 10)
+	signature := webhooks.Sign(secret, now, payload)
+	assert.Regexp(t, `^sha256=[0-9a-f]{64}$`, signature)
+
+	assert.NoError(t, webhooks.Verify(secret, timestamp, signature, payload, 5*time.Minute))
+	assert.ErrorIs(t, webhooks.Verify(webhooks.NewSecret(), timestamp, signature, payload, 5*time.Minute), webhooks.ErrInvalidSignature)
+	assert.ErrorIs(t, webhooks.Verify(secret, timestamp, signature, []byte(`{"id":"2"}`), 5*time.Minute), webhooks.ErrInvalidSignature)
+	assert.ErrorIs(t, webhooks.Verify(secret, strconv.FormatInt(now+1, 10), signature, payload, 5*time.Minute), webhooks.ErrInvalidSignature)
+
+	// Old requests are rejected, so that captured requests cannot be replayed.
+	old := now - 3600
+	assert.ErrorIs(t, webhooks.Verify(secret, strconv.FormatInt(old, 10), webhooks.Sign(secret, old, payload), payload, 5*time.Minute), webhooks.ErrInvalidSignature)
+}
+
+// TestHook verifies that events create deliveries for the active subscriptions of their tenant only.
+func TestHook(t *testing.T) {
+	setup(t)
+
+	all := newSubscription(t, "https://example.com/all", webhooks.AllEvents)
+	created := newSubscription(t, "https://example.com/created", events.ExampleCreated)
+	newSubscription(t, "https://example.com/deleted", events.ExampleDeleted)
+	inactive := newSubscription(t, "https://example.com/inactive", webhooks.AllEvents)
+	assert.NoError(t, db.GormDB.Model(&inactive).Update("active", false).Error)
+	other := webhooks.Subscription{URL: "https://example.com/other", Events: []string{webhooks.AllEvents}, Secret: "secret", Active: true}
+	other.TenantID = "acme"
+	assert.NoError(t, db.GormDB.Create(&other).Error)
+
+	publish(t, events.ExampleCreated)
+
+	var deliveries []webhooks.Delivery
+	assert.NoError(t, db.GormDB.Order("subscription_id").Find(&deliveries).Error)
+	if assert.Len(t, deliveries, 2) {
+		assert.Equal(t, all.ID, deliveries[0].SubscriptionID)
+		assert.Equal(t, created.ID, deliveries[1].SubscriptionID)
+		assert.Equal(t, webhooks.StatusPending, deliveries[0].Status)
+		assert.Equal(t, deliveries[0].EventID, deliveries[1].EventID)
+		assert.JSONEq(t, `"example.created"`, jsonField(t, deliveries[0].Payload, "type"))
+		assert.JSONEq(t, `{"name": "Example"}`, jsonField(t, deliveries[0].Payload, "data"))
+	}
+}
+
+// TestDispatch verifies that deliveries are signed, retried with backoff and abandoned after the last attempt,
+// and that subscriptions failing repeatedly are disabled.
+func TestDispatch(t *testing.T) {
+	setup(t)
+	ctx := context.Background()
+
+	var healthy atomic.Bool
+	healthy.Store(true)
+	var secret atomic.Value
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.NoError(t, webhooks.Verify(secret.Load().(string), r.Header.Get(webhooks.HeaderTimestamp), r.Header.Get(webhooks.HeaderSignature), body, time.Minute))
+		assert.Equal(t, events.ExampleCreated, r.Header.Get(webhooks.HeaderEvent))
+		assert.NotEmpty(t, r.Header.Get(webhooks.HeaderID))
+		if !healthy.Load() {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+	subscription := newSubscription(t, server.URL, events.ExampleCreated)
+	secret.Store(subscription.Secret)
+	config := webhooks.Config{MaxAttempts: 2, Backoff: time.Hour, DisableAfter: 3, AllowPrivate: true}
+
+	// Successful attempts are recorded.
+	publish(t, events.ExampleCreated)
+	attempts, err := webhooks.Dispatch(ctx, db.GormDB, config)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, attempts)
+	var delivery webhooks.Delivery
+	assert.NoError(t, db.GormDB.First(&delivery).Error)
+	assert.Equal(t, webhooks.StatusSucceeded, delivery.Status)
+	assert.NotNil(t, delivery.DeliveredAt)
+	assert.Nil(t, delivery.LockedUntil)
+
+	// Failed attempts are retried after the backoff, with jitter.
+	healthy.Store(false)
+	publish(t, events.ExampleCreated)
+	attempts, err = webhooks.Dispatch(ctx, db.GormDB, config)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, attempts)
+	delivery = webhooks.Delivery{}
+	assert.NoError(t, db.GormDB.Last(&delivery).Error)
+	assert.Equal(t, webhooks.StatusPending, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, "unexpected status 503", delivery.LastError)
+	assert.WithinRange(t, delivery.NextAttemptAt, time.Now().Add(29*time.Minute), time.Now().Add(time.Hour))
+	attempts, err = webhooks.Dispatch(ctx, db.GormDB, config)
+	assert.NoError(t, err)
+	assert.Zero(t, attempts, "Expected the retry to wait for the backoff")
+
+	var attempt webhooks.Attempt
+	assert.NoError(t, db.GormDB.Where("delivery_id = ?", delivery.ID).First(&attempt).Error)
+	assert.Equal(t, http.StatusServiceUnavailable, attempt.StatusCode)
+	assert.Equal(t, "unavailable\n", attempt.Response)
+
+	// The delivery is abandoned after the last attempt.
+	assert.NoError(t, db.GormDB.Model(&delivery).Update("next_attempt_at", time.Now()).Error)
+	_, err = webhooks.Dispatch(ctx, db.GormDB, config)
+	assert.NoError(t, err)
+	assert.NoError(t, db.GormDB.First(&delivery, delivery.ID).Error)
+	assert.Equal(t, webhooks.StatusFailed, delivery.Status)
+	assert.Equal(t, 2, delivery.Attempts)
+
+	// The subscription is disabled after consecutive failures; its pending deliveries are abandoned.
+	publish(t, events.ExampleCreated)
+	_, err = webhooks.Dispatch(ctx, db.GormDB, config)
+	assert.NoError(t, err)
+	assert.NoError(t, db.GormDB.First(&subscription, subscription.ID).Error)
+	assert.False(t, subscription.Active)
+	assert.Equal(t, 3, subscription.Failures)
+	assert.NotNil(t, subscription.DisabledAt)
+
+	assert.NoError(t, db.GormDB.Model(&webhooks.Delivery{}).Where("status = ?", webhooks.StatusPending).Update("next_attempt_at", time.Now()).Error)
+	attempts, err = webhooks.Dispatch(ctx, db.GormDB, config)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, attempts)
+	var pending int64
+	assert.NoError(t, db.GormDB.Model(&webhooks.Delivery{}).Where("status = ?", webhooks.StatusPending).Count(&pending).Error)
+	assert.Zero(t, pending)
+}
+
+// TestReplay verifies that a replayed delivery is sent again with the same event ID.
+func TestReplay(t *testing.T) {
+	setup(t)
+
+	var mu sync.Mutex
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, r.Header.Get(webhooks.HeaderID))
+	}))
+	defer server.Close()
+	newSubscription(t, server.URL, webhooks.AllEvents)
+
+	publish(t, events.ExampleDeleted)
+	_, err := webhooks.Dispatch(context.Background(), db.GormDB, webhooks.Config{Concurrency: 1, AllowPrivate: true})
+	assert.NoError(t, err)
+	var delivery webhooks.Delivery
+	assert.NoError(t, db.GormDB.First(&delivery).Error)
+
+	replay, err := webhooks.Replay(db.GormDB, delivery)
+	assert.NoError(t, err)
+	assert.Equal(t, delivery.ID, *replay.ReplayOf)
+	_, err = webhooks.Dispatch(context.Background(), db.GormDB, webhooks.Config{Concurrency: 1, AllowPrivate: true})
+	assert.NoError(t, err)
+
+	mu.Lock()
+	assert.Equal(t, []string{delivery.EventID, delivery.EventID}, received)
+	mu.Unlock()
+	assert.NoError(t, db.GormDB.First(&replay, replay.ID).Error)
+	assert.Equal(t, webhooks.StatusSucceeded, replay.Status)
+}
+
+// TestNewClient verifies that webhooks are not sent to the internal network and do not follow redirects.
+func TestNewClient(t *testing.T) {
+	var requests atomic.Int32
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	defer target.Close()
+	redirect := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusFound))
+	defer redirect.Close()
+
+	// Loopback, private and link-local addresses are refused when dialed.
+	client := webhooks.NewClient(time.Second, false)
+	for _, url := range []string{target.URL, "http://localhost:" + target.URL[len("http://127.0.0.1:"):], "http://10.0.0.1/", "http://169.254.169.254/"} {
+		_, err := client.Post(url, "application/json", nil)
+		assert.ErrorContains(t, err, "not allowed", url)
+	}
+	assert.Zero(t, requests.Load())
+
+	// Redirects are returned rather than followed.
+	client = webhooks.NewClient(time.Second, true)
+	response, err := client.Post(redirect.URL, "application/json", nil)
+	if assert.NoError(t, err) {
+		response.Body.Close()
+		assert.Equal(t, http.StatusFound, response.StatusCode)
+	}
+	assert.Zero(t, requests.Load())
+}
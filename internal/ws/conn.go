@@ -0,0 +1,386 @@
+// Package ws provides bidirectional messaging over WebSocket connections.
+// This file implements serving a connection: reading the requests of the client and writing its messages.
+package ws
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fasthttp/websocket"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// Close codes sent to the clients.
+const (
+	closeGoingAway = websocket.CloseGoingAway     // The server is shutting down; reconnect to another instance
+	closeTryLater  = websocket.CloseTryAgainLater // The client did not keep up with its messages; reconnect
+	closeNormal    = websocket.CloseNormalClosure // The client closed the connection
+)
+
+const (
+	writeTimeout   = 10 * time.Second // Bounds writing a message to a client
+	requestTimeout = 5 * time.Second  // Bounds the Redis commands of a request
+)
+
+// conn is a connection served by the hub.
+type conn struct {
+	hub      *Hub
+	ws       *websocket.Conn
+	id       string              // Random ID distinguishing the connections of a user in the presence sets
+	tenant   string              // Tenant of the user; channels are scoped to it
+	user     string              // Authenticated user
+	channels map[string]struct{} // Subscribed channels; only used by the reading goroutine
+	limiter  limiter
+
+	mu        sync.Mutex
+	messages  chan []byte // Messages to write; closed when the connection is shut down
+	closed    bool
+	closeCode int
+	closeText string
+}
+
+// Serve serves a WebSocket connection of an authenticated user until it is closed, by the client, by the hub
+// when it stops, or because the client is too slow. The connection is closed when Serve returns.
+//
+// Parameters:
+// - ws (*websocket.Conn): The upgraded connection.
+// - tenant (string): The tenant of the user; its channels are not visible to other tenants.
+// - user (string): The authenticated user, sent with its messages and presence.
+//
+// Returns:
+// - error: ErrClosed if the hub is stopped; errors of the connection itself end it silently.
+func (h *Hub) Serve(ws *websocket.Conn, tenant, user string) error {
+	c := &conn{
+		hub:      h,
+		ws:       ws,
+		id:       newID(),
+		tenant:   tenant,
+		user:     user,
+		channels: map[string]struct{}{},
+		limiter:  limiter{rate: h.config.Rate, burst: float64(h.config.Burst), tokens: float64(h.config.Burst), last: time.Now()},
+		messages: make(chan []byte, h.config.Buffer),
+	}
+	if err := h.register(c); err != nil {
+		_ = ws.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(closeGoingAway, "server shutting down"), time.Now().Add(writeTimeout))
+		_ = ws.Close()
+		return err
+	}
+
+	written := make(chan struct{})
+	go func() {
+		defer close(written)
+		c.write()
+	}()
+	c.reply(Message{Type: TypeWelcome, User: user})
+	c.read()
+
+	// Leave the channels, then let the writer send the close frame.
+	h.unregister(c)
+	c.leaveAll()
+	c.shutdown(closeNormal, "")
+	<-written
+	_ = ws.Close()
+	return nil
+}
+
+// read handles the requests of the client until the connection fails or is closed.
+func (c *conn) read() {
+	ws := c.ws
+	ws.SetReadLimit(c.hub.config.MaxMessageSize)
+	// Clients must answer the pings (or send messages) within two ping intervals.
+	deadline := func() time.Time { return time.Now().Add(2 * c.hub.config.PingInterval) }
+	_ = ws.SetReadDeadline(deadline())
+	ws.SetPongHandler(func(string) error { return ws.SetReadDeadline(deadline()) })
+
+	for {
+		kind, data, err := ws.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, closeGoingAway, closeNormal, websocket.CloseNoStatusReceived) {
+				c.hub.logger().Debug("WebSocket connection failed", zap.String("user", c.user), zap.Error(err))
+			}
+			return
+		}
+		_ = ws.SetReadDeadline(deadline())
+		if !c.limiter.allow(time.Now()) {
+			c.fail("", ErrorRateLimited)
+			continue
+		}
+		var request Message
+		if kind != websocket.TextMessage || json.Unmarshal(data, &request) != nil {
+			c.fail("", ErrorInvalidMessage)
+			continue
+		}
+		c.handle(request)
+	}
+}
+
+// handle answers a request of the client.
+func (c *conn) handle(request Message) {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	switch request.Type {
+	case TypePing:
+		c.reply(Message{Type: TypePong, ID: request.ID})
+		return
+	case TypeSubscribe, TypeUnsubscribe, TypePublish, TypePresence:
+		if !ValidChannel(request.Channel) {
+			c.fail(request.ID, ErrorInvalidChannel)
+			return
+		}
+	default:
+		c.fail(request.ID, ErrorInvalidMessage)
+		return
+	}
+
+	var err error
+	switch request.Type {
+	case TypeSubscribe:
+		if _, ok := c.channels[request.Channel]; ok {
+			break
+		}
+		if len(c.channels) >= c.hub.config.MaxChannels {
+			c.fail(request.ID, ErrorTooManyChannels)
+			return
+		}
+		err = c.join(ctx, request.Channel)
+	case TypeUnsubscribe:
+		if _, ok := c.channels[request.Channel]; ok {
+			err = c.leave(ctx, request.Channel)
+		}
+	case TypePublish:
+		err = c.hub.Publish(ctx, c.tenant, request.Channel, Message{Type: TypeMessage, User: c.user, Data: request.Data})
+	case TypePresence:
+		var members []string
+		if members, err = c.hub.Members(ctx, c.tenant, request.Channel); err == nil {
+			c.reply(Message{Type: TypePresence, ID: request.ID, Channel: request.Channel, Members: members})
+			return
+		}
+	}
+	if err != nil {
+		c.hub.logger().Warn("Failed to handle a WebSocket request", zap.String("type", request.Type), zap.Error(err))
+		c.fail(request.ID, ErrorUnavailable)
+		return
+	}
+	c.reply(Message{Type: TypeAck, ID: request.ID})
+}
+
+// join subscribes the connection to a channel, records its presence and announces it to the subscribers.
+func (c *conn) join(ctx context.Context, channel string) error {
+	client, err := c.hub.client()
+	if err != nil {
+		return err
+	}
+	key := c.hub.presenceKey(c.tenant, channel)
+	if err := c.refresh(ctx, client, key); err != nil {
+		return err
+	}
+	c.channels[channel] = struct{}{}
+	c.hub.subscribe(c, c.hub.channelKey(c.tenant, channel))
+	return c.hub.Publish(ctx, c.tenant, channel, Message{Type: TypeJoin, User: c.user})
+}
+
+// leave unsubscribes the connection from a channel, removes its presence and announces it to the subscribers.
+func (c *conn) leave(ctx context.Context, channel string) error {
+	delete(c.channels, channel)
+	c.hub.mu.Lock()
+	c.hub.unsubscribe(c, c.hub.channelKey(c.tenant, channel))
+	c.hub.mu.Unlock()
+
+	client, err := c.hub.client()
+	if err != nil {
+		return err
+	}
+	if err := client.ZRem(ctx, c.hub.presenceKey(c.tenant, channel), c.member()).Err(); err != nil {
+		return err
+	}
+	return c.hub.Publish(ctx, c.tenant, channel, Message{Type: TypeLeave, User: c.user})
+}
+
+// leaveAll leaves the channels of a closed connection.
+func (c *conn) leaveAll() {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	for channel := range c.channels {
+		if err := c.leave(ctx, channel); err != nil {
+			c.hub.logger().Warn("Failed to leave a WebSocket channel", zap.String("channel", channel), zap.Error(err))
+		}
+	}
+}
+
+// refresh records the presence of the connection in a channel until the presence TTL. The set itself expires
+// when no connection refreshes it anymore.
+func (c *conn) refresh(ctx context.Context, client redis.UniversalClient, key string) error {
+	ttl := c.hub.config.PresenceTTL
+	expires := float64(time.Now().Add(ttl).UnixMilli())
+	if err := client.ZAdd(ctx, key, redis.Z{Score: expires, Member: c.member()}).Err(); err != nil {
+		return err
+	}
+	return client.PExpire(ctx, key, ttl).Err()
+}
+
+// refreshAll refreshes the presence of the connection in its channels.
+func (c *conn) refreshAll(channels []string) {
+	client, err := c.hub.client()
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	for _, channel := range channels {
+		if err := c.refresh(ctx, client, c.hub.presenceKey(c.tenant, channel)); err != nil {
+			c.hub.logger().Warn("Failed to refresh a WebSocket presence", zap.String("channel", channel), zap.Error(err))
+		}
+	}
+}
+
+// member returns the member of the connection in the presence sets: "<connection ID>:<user>".
+func (c *conn) member() string {
+	return c.id + ":" + c.user
+}
+
+// memberUser returns the user of a member of a presence set.
+func memberUser(member string) string {
+	_, user, _ := strings.Cut(member, ":")
+	return user
+}
+
+// write sends the messages of the connection and the pings, until the connection is shut down; it then
+// sends the close frame.
+func (c *conn) write() {
+	ping := time.NewTicker(c.hub.config.PingInterval)
+	defer ping.Stop()
+	presence := time.NewTicker(c.hub.config.PresenceTTL / 2)
+	defer presence.Stop()
+
+	for {
+		select {
+		case message, ok := <-c.messages:
+			if !ok {
+				c.mu.Lock()
+				code, text := c.closeCode, c.closeText
+				c.mu.Unlock()
+				_ = c.ws.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, text), time.Now().Add(writeTimeout))
+				// Wait briefly for the client to answer the close frame, which ends the reading goroutine.
+				_ = c.ws.SetReadDeadline(time.Now().Add(writeTimeout))
+				return
+			}
+			_ = c.ws.SetWriteDeadline(time.Now().Add(writeTimeout))
+			if err := c.ws.WriteMessage(websocket.TextMessage, message); err != nil {
+				_ = c.ws.Close() // Ends the reading goroutine.
+				c.drain()
+				return
+			}
+		case <-ping.C:
+			if err := c.ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeTimeout)); err != nil {
+				_ = c.ws.Close()
+				c.drain()
+				return
+			}
+		case <-presence.C:
+			c.refreshAll(c.subscribed())
+		}
+	}
+}
+
+// drain discards the messages of a failed connection until it is shut down.
+func (c *conn) drain() {
+	for range c.messages {
+	}
+}
+
+// subscribed returns the channels of the connection, from the hub (the channels map belongs to the reader).
+func (c *conn) subscribed() []string {
+	prefix := c.hub.config.Prefix + c.tenant + ":"
+	c.hub.mu.Lock()
+	defer c.hub.mu.Unlock()
+	var channels []string
+	for key, conns := range c.hub.subscribers {
+		if _, ok := conns[c]; ok {
+			channels = append(channels, strings.TrimPrefix(key, prefix))
+		}
+	}
+	return channels
+}
+
+// send queues a message to the client. If the client is too slow, the connection is shut down.
+//
+// Returns:
+// - bool: False if the connection is shut down.
+func (c *conn) send(message []byte) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return false
+	}
+	select {
+	case c.messages <- message:
+		return true
+	default:
+		c.close(closeTryLater, "too slow")
+		return false
+	}
+}
+
+// reply queues a message of the protocol to the client.
+func (c *conn) reply(message Message) {
+	payload, _ := json.Marshal(message)
+	c.send(payload)
+}
+
+// fail queues an error to the client.
+func (c *conn) fail(id, code string) {
+	c.reply(Message{Type: TypeError, ID: id, Error: code})
+}
+
+// shutdown makes the writer send the close frame after the queued messages, which ends the connection.
+func (c *conn) shutdown(code int, text string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.close(code, text)
+}
+
+// close shuts the connection down. The caller holds c.mu.
+func (c *conn) close(code int, text string) {
+	if c.closed {
+		return
+	}
+	c.closed = true
+	c.closeCode, c.closeText = code, text
+	close(c.messages)
+}
+
+// limiter is a token bucket limiting the messages of a connection. It is only used by the reading goroutine.
+type limiter struct {
+	rate   float64 // Tokens added per second
+	burst  float64 // Maximum tokens
+	tokens float64
+	last   time.Time
+}
+
+// allow takes a token if one is available.
+func (l *limiter) allow(now time.Time) bool {
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
+
+// newID returns a random connection ID.
+func newID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
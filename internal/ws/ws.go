@@ -0,0 +1,414 @@
+// Package ws provides bidirectional messaging over WebSocket connections.
+// Clients subscribe to channels (e.g., "chat.general") of their tenant, publish messages to them and see who
+// else is subscribed (presence). Messages are fanned out with Redis pub/sub, so that they reach the subscribers
+// connected to every instance (see Hub.Start), and presence is tracked in Redis sorted sets.
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"gobo/internal/cache"
+	"gobo/internal/logger"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// Errors returned by the hub.
+var (
+	ErrUnavailable = errors.New("ws: Redis is not connected")
+	ErrClosed      = errors.New("ws: the hub is stopped")
+)
+
+// Default is the application's hub, set up at startup.
+var Default *Hub
+
+// channelPattern restricts the channel names (e.g., "chat.general", "example:42").
+var channelPattern = regexp.MustCompile(`^[a-z0-9_.:-]{1,100}$`)
+
+// ValidChannel reports whether a channel name is valid: 1 to 100 lowercase letters, digits, '_', '.', ':' or '-'.
+//
+// Parameters:
+// - channel (string): The channel name.
+//
+// Returns:
+// - bool: True if the name is valid.
+func ValidChannel(channel string) bool {
+	return channelPattern.MatchString(channel)
+}
+
+// Config defines the hub.
+type Config struct {
+	Prefix         string                // Prefix of the Redis keys and channels (defaults to "ws:")
+	Rate           float64               // Messages per second a connection may send (defaults to 10)
+	Burst          int                   // Messages a connection may send at once above the rate (defaults to 20)
+	MaxChannels    int                   // Channels a connection may subscribe to (defaults to 100)
+	MaxMessageSize int64                 // Largest message a client may send, in bytes (defaults to 64 KiB)
+	PingInterval   time.Duration         // Time between two pings; clients not answering for two intervals are disconnected (defaults to 30 seconds)
+	PresenceTTL    time.Duration         // Time after which the presence of a connection that is not refreshed expires (defaults to 1 minute)
+	Buffer         int                   // Messages buffered per connection; slower clients are disconnected (defaults to 64)
+	Client         redis.UniversalClient // Redis client; nil uses cache.RedisClient
+}
+
+// DefaultConfig returns the default hub configuration.
+//
+// Defaults:
+// - Prefix: "ws:" (e.g., the messages of the "chat" channel of the "acme" tenant are sent on "ws:acme:chat")
+// - Rate: 10, or the WS_RATE_LIMIT environment variable
+// - Burst: 20, or the WS_RATE_BURST environment variable
+// - MaxChannels: 100
+// - MaxMessageSize: 64 KiB
+// - PingInterval: 30 seconds, or the WS_PING_INTERVAL environment variable (e.g., "1m")
+// - PresenceTTL: 1 minute
+// - Buffer: 64
+//
+// Returns:
+// - Config: The default hub configuration.
+func DefaultConfig() Config {
+	config := Config{
+		Prefix:         "ws:",
+		Rate:           10,
+		Burst:          20,
+		MaxChannels:    100,
+		MaxMessageSize: 64 << 10,
+		PingInterval:   30 * time.Second,
+		PresenceTTL:    time.Minute,
+		Buffer:         64,
+	}
+	if value, err := strconv.ParseFloat(os.Getenv("WS_RATE_LIMIT"), 64); err == nil && value > 0 {
+		config.Rate = value
+	}
+	if value, err := strconv.Atoi(os.Getenv("WS_RATE_BURST")); err == nil && value > 0 {
+		config.Burst = value
+	}
+	if value, err := time.ParseDuration(os.Getenv("WS_PING_INTERVAL")); err == nil && value > 0 {
+		config.PingInterval = value
+	}
+	return config
+}
+
+// Message is a message of the protocol, sent as a JSON text frame in both directions.
+//
+// Clients send:
+// - {"type":"subscribe","channel":"chat","id":"1"}: Receive the messages of a channel, and announce the presence of the user.
+// - {"type":"unsubscribe","channel":"chat","id":"2"}: Stop receiving the messages of a channel.
+// - {"type":"publish","channel":"chat","data":{...},"id":"3"}: Send a message to the subscribers of a channel, including the sender.
+// - {"type":"presence","channel":"chat","id":"4"}: List the users subscribed to a channel.
+// - {"type":"ping","id":"5"}: Check the connection.
+//
+// The server sends:
+// - {"type":"welcome","user":"admin"}: The connection is ready.
+// - {"type":"ack","id":"1"}: A request succeeded.
+// - {"type":"error","id":"1","error":"invalid_channel"}: A request failed (see the Error* codes).
+// - {"type":"message","channel":"chat","user":"admin","data":{...}}: A message published to a subscribed channel.
+// - {"type":"join","channel":"chat","user":"admin"} and {"type":"leave",...}: A user subscribed to or left a subscribed channel.
+// - {"type":"presence","channel":"chat","members":["admin"],"id":"4"}: The users subscribed to a channel.
+// - {"type":"pong","id":"5"}: The answer to a ping.
+type Message struct {
+	Type    string          `json:"type"`
+	ID      string          `json:"id,omitempty"`      // Chosen by the client to match the answer to its request
+	Channel string          `json:"channel,omitempty"` // Channel of the message
+	User    string          `json:"user,omitempty"`    // User who sent the message, joined or left
+	Data    json.RawMessage `json:"data,omitempty"`    // Data of a published message (JSON)
+	Members []string        `json:"members,omitempty"` // Users subscribed to the channel
+	Error   string          `json:"error,omitempty"`   // Error code
+}
+
+// Types of the messages.
+const (
+	TypeSubscribe   = "subscribe"
+	TypeUnsubscribe = "unsubscribe"
+	TypePublish     = "publish"
+	TypePresence    = "presence"
+	TypePing        = "ping"
+	TypeWelcome     = "welcome"
+	TypeAck         = "ack"
+	TypeError       = "error"
+	TypeMessage     = "message"
+	TypeJoin        = "join"
+	TypeLeave       = "leave"
+	TypePong        = "pong"
+)
+
+// Error codes sent to the clients.
+const (
+	ErrorInvalidMessage  = "invalid_message"   // The message is not valid JSON or has an unknown type
+	ErrorInvalidChannel  = "invalid_channel"   // The channel name is not valid
+	ErrorTooManyChannels = "too_many_channels" // The connection is subscribed to MaxChannels channels
+	ErrorRateLimited     = "rate_limited"      // The connection sent messages faster than the rate limit; the message was dropped
+	ErrorUnavailable     = "unavailable"       // Redis cannot be reached
+)
+
+// Hub fans the messages out to the connections of this instance.
+// Its methods are safe for concurrent use.
+type Hub struct {
+	config      Config
+	mu          sync.Mutex
+	subscribers map[string]map[*conn]struct{} // Connections by Redis channel
+	conns       map[*conn]struct{}
+	closed      bool
+}
+
+// New creates a hub. Start it with Start to receive the messages published by all instances.
+//
+// Parameters:
+// - config (Config): The hub configuration; zero values use DefaultConfig.
+//
+// Returns:
+// - *Hub: The hub.
+func New(config Config) *Hub {
+	defaults := DefaultConfig()
+	if config.Prefix == "" {
+		config.Prefix = defaults.Prefix
+	}
+	if config.Rate <= 0 {
+		config.Rate = defaults.Rate
+	}
+	if config.Burst <= 0 {
+		config.Burst = defaults.Burst
+	}
+	if config.MaxChannels <= 0 {
+		config.MaxChannels = defaults.MaxChannels
+	}
+	if config.MaxMessageSize <= 0 {
+		config.MaxMessageSize = defaults.MaxMessageSize
+	}
+	if config.PingInterval <= 0 {
+		config.PingInterval = defaults.PingInterval
+	}
+	if config.PresenceTTL <= 0 {
+		config.PresenceTTL = defaults.PresenceTTL
+	}
+	if config.Buffer <= 0 {
+		config.Buffer = defaults.Buffer
+	}
+	return &Hub{config: config, subscribers: map[string]map[*conn]struct{}{}, conns: map[*conn]struct{}{}}
+}
+
+// client returns the Redis client of the hub.
+func (h *Hub) client() (redis.UniversalClient, error) {
+	if h.config.Client != nil {
+		return h.config.Client, nil
+	}
+	if cache.RedisClient != nil {
+		return cache.RedisClient, nil
+	}
+	return nil, ErrUnavailable
+}
+
+// Ready reports whether the hub accepts connections.
+//
+// Returns:
+// - error: ErrUnavailable if Redis is not connected, or ErrClosed if the hub is stopped.
+func (h *Hub) Ready() error {
+	if _, err := h.client(); err != nil {
+		return err
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return ErrClosed
+	}
+	return nil
+}
+
+// channelKey returns the Redis channel of a channel of a tenant.
+func (h *Hub) channelKey(tenant, channel string) string {
+	return h.config.Prefix + tenant + ":" + channel
+}
+
+// presenceKey returns the Redis sorted set of the connections subscribed to a channel of a tenant.
+func (h *Hub) presenceKey(tenant, channel string) string {
+	return h.config.Prefix + "presence:" + tenant + ":" + channel
+}
+
+// Publish sends a message to the subscribers of a channel of a tenant, on all instances.
+// Use it to push messages from the server (e.g., {"type":"message","data":{...}}).
+//
+// Parameters:
+// - ctx (context.Context): The context of the Redis command.
+// - tenant (string): The tenant of the channel.
+// - channel (string): The channel (e.g., "chat").
+// - message (Message): The message; its channel is set.
+//
+// Returns:
+// - error: An error if the channel is invalid, Redis is not connected or the message cannot be published.
+func (h *Hub) Publish(ctx context.Context, tenant, channel string, message Message) error {
+	if !ValidChannel(channel) {
+		return errors.New("ws: invalid channel " + strconv.Quote(channel))
+	}
+	client, err := h.client()
+	if err != nil {
+		return err
+	}
+	message.ID = ""
+	message.Channel = channel
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	return client.Publish(ctx, h.channelKey(tenant, channel), payload).Err()
+}
+
+// Members returns the users subscribed to a channel of a tenant, on all instances, sorted by name.
+// Connections whose presence expired (e.g., on a crashed instance) are removed.
+//
+// Parameters:
+// - ctx (context.Context): The context of the Redis commands.
+// - tenant (string): The tenant of the channel.
+// - channel (string): The channel (e.g., "chat").
+//
+// Returns:
+// - []string: The users, each listed once.
+// - error: An error if Redis is not connected or the presence cannot be read.
+func (h *Hub) Members(ctx context.Context, tenant, channel string) ([]string, error) {
+	client, err := h.client()
+	if err != nil {
+		return nil, err
+	}
+	key := h.presenceKey(tenant, channel)
+	now := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	if err := client.ZRemRangeByScore(ctx, key, "-inf", "("+now).Err(); err != nil {
+		return nil, err
+	}
+	entries, err := client.ZRange(ctx, key, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	members := []string{}
+	seen := map[string]bool{}
+	for _, entry := range entries {
+		if user := memberUser(entry); !seen[user] {
+			seen[user] = true
+			members = append(members, user)
+		}
+	}
+	sort.Strings(members)
+	return members, nil
+}
+
+// Start receives the messages published by all instances and sends them to the subscribed connections of
+// this instance, until the returned function is called. It returns once subscribed to the Redis channels.
+//
+// Returns:
+// - func(): Stops receiving messages and closes the connections (with the "going away" close code).
+func (h *Hub) Start() func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	log := logger.FromContext(ctx).Named("ws")
+	done := make(chan struct{})
+
+	client, err := h.client()
+	if err != nil {
+		log.Warn("WebSocket hub not started: Redis is not connected")
+		close(done)
+	} else {
+		// The subscription is re-established automatically if the connection is lost.
+		pubsub := client.PSubscribe(ctx, h.config.Prefix+"*")
+		// Wait for the subscription, so that the messages published once Start returns are received.
+		if _, err := pubsub.ReceiveTimeout(ctx, requestTimeout); err != nil {
+			log.Warn("WebSocket hub subscription not confirmed", zap.Error(err))
+		}
+		go func() {
+			defer close(done)
+			defer pubsub.Close()
+			for message := range pubsub.Channel() {
+				h.dispatch(message.Channel, []byte(message.Payload))
+			}
+		}()
+		go func() {
+			<-ctx.Done()
+			_ = pubsub.Close()
+		}()
+	}
+
+	return func() {
+		cancel()
+		<-done
+		h.close()
+	}
+}
+
+// dispatch sends a message to the connections subscribed to its Redis channel. Connections whose buffer is
+// full are closed: their clients reconnect and subscribe again.
+func (h *Hub) dispatch(key string, payload []byte) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for c := range h.subscribers[key] {
+		if !c.send(payload) {
+			h.unsubscribe(c, key)
+		}
+	}
+}
+
+// register adds a connection to the hub.
+func (h *Hub) register(c *conn) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return ErrClosed
+	}
+	h.conns[c] = struct{}{}
+	return nil
+}
+
+// unregister removes a connection and its subscriptions from the hub.
+func (h *Hub) unregister(c *conn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.conns, c)
+	for channel := range c.channels {
+		h.unsubscribe(c, h.channelKey(c.tenant, channel))
+	}
+}
+
+// subscribe adds a connection to the subscribers of a Redis channel.
+func (h *Hub) subscribe(c *conn, key string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.subscribers[key] == nil {
+		h.subscribers[key] = map[*conn]struct{}{}
+	}
+	h.subscribers[key][c] = struct{}{}
+}
+
+// unsubscribe removes a connection from the subscribers of a Redis channel. The caller holds h.mu.
+func (h *Hub) unsubscribe(c *conn, key string) {
+	delete(h.subscribers[key], c)
+	if len(h.subscribers[key]) == 0 {
+		delete(h.subscribers, key)
+	}
+}
+
+// close stops the hub and closes all connections.
+func (h *Hub) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for c := range h.conns {
+		c.shutdown(closeGoingAway, "server shutting down")
+	}
+}
+
+// Stats returns the number of connections and subscribed Redis channels of this instance.
+//
+// Returns:
+// - int: The open connections.
+// - int: The channels with at least one subscribed connection.
+func (h *Hub) Stats() (int, int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.conns), len(h.subscribers)
+}
+
+// logger returns the logger of the hub.
+func (h *Hub) logger() *zap.Logger {
+	return logger.FromContext(context.Background()).Named("ws")
+}
@@ -0,0 +1,201 @@
+// Package ws contains tests for the WebSocket messaging.
+// These tests validate the protocol, the fan-out across instances, presence and rate limits,
+// with WebSocket connections to a test server and an in-memory Redis server.
+package ws
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/fasthttp/websocket"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+// newHub returns a started hub using the given Redis client; it receives the messages published from now on.
+func newHub(t *testing.T, client redis.UniversalClient, config Config) *Hub {
+	config.Client = client
+	h := New(config)
+	stop := h.Start()
+	t.Cleanup(stop)
+	return h
+}
+
+// newClient returns a new in-memory Redis client.
+func newClient(t *testing.T) *redis.Client {
+	client := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	return client
+}
+
+// connect opens a WebSocket connection served by the hub for a user of a tenant, and reads the welcome message.
+func connect(t *testing.T, h *Hub, tenant, user string) *websocket.Conn {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err == nil {
+			_ = h.Serve(conn, tenant, user)
+		}
+	}))
+	t.Cleanup(server.Close)
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	assert.Equal(t, Message{Type: TypeWelcome, User: user}, next(t, conn))
+	return conn
+}
+
+// send sends a request.
+func send(t *testing.T, conn *websocket.Conn, request string) {
+	assert.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(request)))
+}
+
+// next returns the next message of a connection.
+func next(t *testing.T, conn *websocket.Conn) Message {
+	t.Helper()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var message Message
+	if err := conn.ReadJSON(&message); err != nil {
+		t.Fatalf("Expected a message: %v", err)
+	}
+	return message
+}
+
+// until returns the next message of the given type, skipping the others (e.g., acks and presence changes).
+func until(t *testing.T, conn *websocket.Conn, kind string) Message {
+	t.Helper()
+	for {
+		if message := next(t, conn); message.Type == kind {
+			return message
+		}
+	}
+}
+
+// TestMessaging verifies subscribing, publishing across instances and tenants, presence and leaving.
+func TestMessaging(t *testing.T) {
+	client := newClient(t)
+	// Two instances share the Redis server.
+	first := newHub(t, client, Config{})
+	second := newHub(t, client, Config{})
+
+	alice := connect(t, first, "acme", "alice")
+	bob := connect(t, second, "acme", "bob")
+	carol := connect(t, second, "globex", "carol")
+
+	send(t, alice, `{"type":"subscribe","channel":"chat","id":"1"}`)
+	// Subscribers see their own join, which may arrive before the ack.
+	assert.ElementsMatch(t, []Message{{Type: TypeAck, ID: "1"}, {Type: TypeJoin, Channel: "chat", User: "alice"}}, []Message{next(t, alice), next(t, alice)})
+	send(t, bob, `{"type":"subscribe","channel":"chat","id":"1"}`)
+	assert.Equal(t, Message{Type: TypeJoin, Channel: "chat", User: "bob"}, until(t, alice, TypeJoin))
+	send(t, carol, `{"type":"subscribe","channel":"chat","id":"1"}`)
+	until(t, carol, TypeAck)
+
+	// Messages reach the subscribers of the tenant on all instances, including the sender.
+	send(t, carol, `{"type":"publish","channel":"chat","data":{"text":"globex"}}`)
+	send(t, bob, `{"type":"publish","channel":"chat","data":{"text":"hello"},"id":"2"}`)
+	message := until(t, alice, TypeMessage)
+	assert.Equal(t, "chat", message.Channel)
+	assert.Equal(t, "bob", message.User)
+	assert.JSONEq(t, `{"text":"hello"}`, string(message.Data), "Expected other tenants' messages not to be received")
+	assert.JSONEq(t, `{"text":"hello"}`, string(until(t, bob, TypeMessage).Data))
+
+	// Presence lists the users of the tenant subscribed on all instances.
+	send(t, alice, `{"type":"presence","channel":"chat","id":"3"}`)
+	assert.Equal(t, Message{Type: TypePresence, ID: "3", Channel: "chat", Members: []string{"alice", "bob"}}, until(t, alice, TypePresence))
+
+	// Users leave their channels when they unsubscribe or disconnect.
+	send(t, bob, `{"type":"unsubscribe","channel":"chat","id":"4"}`)
+	assert.Equal(t, Message{Type: TypeLeave, Channel: "chat", User: "bob"}, until(t, alice, TypeLeave))
+	send(t, bob, `{"type":"subscribe","channel":"chat","id":"5"}`)
+	until(t, alice, TypeJoin)
+	_ = bob.Close()
+	assert.Equal(t, Message{Type: TypeLeave, Channel: "chat", User: "bob"}, until(t, alice, TypeLeave))
+	members, err := first.Members(context.Background(), "acme", "chat")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"alice"}, members)
+
+	// Invalid requests are answered with errors.
+	send(t, alice, `{"type":"subscribe","channel":"Chat!","id":"6"}`)
+	assert.Equal(t, Message{Type: TypeError, ID: "6", Error: ErrorInvalidChannel}, until(t, alice, TypeError))
+	send(t, alice, `{"type":"shout","id":"7"}`)
+	assert.Equal(t, Message{Type: TypeError, ID: "7", Error: ErrorInvalidMessage}, until(t, alice, TypeError))
+	send(t, alice, `not json`)
+	assert.Equal(t, Message{Type: TypeError, Error: ErrorInvalidMessage}, until(t, alice, TypeError))
+	send(t, alice, `{"type":"ping","id":"8"}`)
+	assert.Equal(t, Message{Type: TypePong, ID: "8"}, until(t, alice, TypePong))
+}
+
+// TestLimits verifies the rate limit and the channel limit of a connection.
+func TestLimits(t *testing.T) {
+	h := newHub(t, newClient(t), Config{Rate: 0.001, Burst: 3, MaxChannels: 1})
+	conn := connect(t, h, "acme", "alice")
+
+	send(t, conn, `{"type":"subscribe","channel":"one","id":"1"}`)
+	assert.Equal(t, Message{Type: TypeAck, ID: "1"}, until(t, conn, TypeAck))
+	send(t, conn, `{"type":"subscribe","channel":"two","id":"2"}`)
+	assert.Equal(t, Message{Type: TypeError, ID: "2", Error: ErrorTooManyChannels}, until(t, conn, TypeError))
+	send(t, conn, `{"type":"ping","id":"3"}`)
+	assert.Equal(t, Message{Type: TypePong, ID: "3"}, until(t, conn, TypePong))
+	send(t, conn, `{"type":"ping","id":"4"}`)
+	assert.Equal(t, Message{Type: TypeError, Error: ErrorRateLimited}, until(t, conn, TypeError), "Expected the message to be dropped")
+
+	// The bucket refills at the rate.
+	l := limiter{rate: 2, burst: 1, tokens: 0, last: time.Unix(0, 0)}
+	assert.False(t, l.allow(time.Unix(0, 0)))
+	assert.True(t, l.allow(time.Unix(0, int64(500*time.Millisecond))))
+	assert.False(t, l.allow(time.Unix(0, int64(500*time.Millisecond))))
+	assert.True(t, l.allow(time.Unix(10, 0)))
+	assert.False(t, l.allow(time.Unix(10, 0)), "Expected the tokens to be capped at the burst")
+}
+
+// TestPresenceExpiry verifies that the presence of connections that are no longer refreshed expires.
+func TestPresenceExpiry(t *testing.T) {
+	client := newClient(t)
+	h := New(Config{Client: client})
+	ctx := context.Background()
+	key := h.presenceKey("acme", "chat")
+	live := strconv.FormatInt(time.Now().Add(time.Minute).UnixMilli(), 10)
+	expired := strconv.FormatInt(time.Now().Add(-time.Second).UnixMilli(), 10)
+	for member, score := range map[string]string{"a1:alice": live, "a2:alice": live, "b1:bob": expired, "c1:carol:x": live} {
+		assert.NoError(t, client.Do(ctx, "ZADD", key, score, member).Err())
+	}
+
+	members, err := h.Members(ctx, "acme", "chat")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"alice", "carol:x"}, members)
+	assert.Equal(t, int64(3), client.ZCard(ctx, key).Val(), "Expected the expired presence to be removed")
+	members, err = h.Members(ctx, "globex", "chat")
+	assert.NoError(t, err)
+	assert.Empty(t, members)
+}
+
+// TestShutdown verifies that slow clients are disconnected, and that stopping the hub closes the connections.
+func TestShutdown(t *testing.T) {
+	slow := &conn{messages: make(chan []byte, 1)}
+	assert.True(t, slow.send([]byte("1")))
+	assert.False(t, slow.send([]byte("2")), "Expected the slow client to be disconnected")
+	assert.Equal(t, closeTryLater, slow.closeCode)
+	assert.False(t, slow.send([]byte("3")))
+
+	client := newClient(t)
+	h := New(Config{Client: client})
+	stop := h.Start()
+	conn := connect(t, h, "acme", "alice")
+	assert.NoError(t, h.Ready())
+	stop()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, _, err := conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway), "Expected the going away close code, got %v", err)
+	assert.Eventually(t, func() bool {
+		conns, channels := h.Stats()
+		return conns == 0 && channels == 0
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.ErrorIs(t, h.Ready(), ErrClosed)
+	assert.ErrorIs(t, New(Config{}).Ready(), ErrUnavailable)
+}